@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"path/filepath"
@@ -46,7 +46,7 @@ func (r *Berkeley) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -62,14 +62,14 @@ func (r *Berkeley) getBookId(sUrl string) (bookId string) {
 
 func (r *Berkeley) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
 	if err != nil || canvases == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
-	log.Printf(" %d files \n", len(canvases))
+	r.dt.Logf(" %d files \n", len(canvases))
 	r.do(canvases)
 	return "", nil
 }
@@ -78,7 +78,6 @@ func (r *Berkeley) do(canvases []string) (msg string, err error) {
 	if canvases == nil {
 		return
 	}
-	fmt.Println()
 	referer := r.dt.Url
 	size := len(canvases)
 	for i, dUrl := range canvases {
@@ -92,7 +91,7 @@ func (r *Berkeley) do(canvases []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d,  URL: %s\n", i+1, size, dUrl)
+		r.dt.Logf("Get %d/%d,  URL: %s\n", i+1, size, dUrl)
 		ctx := context.Background()
 		opts := gohttp.Options{
 			DestFile:    dest,
@@ -106,7 +105,6 @@ func (r *Berkeley) do(canvases []string) (msg string, err error) {
 			},
 		}
 		gohttp.FastGet(ctx, dUrl, opts)
-		fmt.Println()
 	}
 	return "", err
 }
@@ -127,7 +125,7 @@ func (r *Berkeley) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []stri
 
 	var resT = make([]BerkeleyResponse, 0, 64)
 	if err = json.Unmarshal(bs, &resT); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	for _, ret := range resT {
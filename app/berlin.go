@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -41,7 +41,7 @@ func (r *Berlin) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -57,11 +57,11 @@ func (r *Berlin) getBookId(sUrl string) (bookId string) {
 
 func (r *Berlin) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -78,10 +78,10 @@ func (r *Berlin) download() (msg string, err error) {
 
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -109,32 +109,23 @@ func (r *Berlin) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string
 	}
 	var manifest = new(iiif.ManifestResponse)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			if config.Conf.UseDziRs {
-				//dezoomify-rs URL
-				//iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-				//https://ngcs-core.staatsbibliothek-berlin.de/dzi/PPN3303598630/PHYS_0001.dzi
-				m := regexp.MustCompile("/dc/([A-z0-9]+)-([A-z0-9]+)/full").FindStringSubmatch(image.Resource.Id)
-				iiiInfo := fmt.Sprintf("https://ngcs-core.staatsbibliothek-berlin.de/dzi/%s/PHYS_%s.dzi", r.dt.BookId, m[2])
-				canvases = append(canvases, iiiInfo)
-			} else {
-				//JPEG URL
-				//imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-				//https://content.staatsbibliothek-berlin.de/dc/3303598630-0001/full/full/0/default.jpg
-				canvases = append(canvases, image.Resource.Id)
-			}
+	return canvasUrls(r.dt, manifest, func(image iiif.Image) string {
+		if config.Conf.UseDziRs {
+			//dezoomify-rs URL
+			//https://ngcs-core.staatsbibliothek-berlin.de/dzi/PPN3303598630/PHYS_0001.dzi
+			m := regexp.MustCompile("/dc/([A-z0-9]+)-([A-z0-9]+)/full").FindStringSubmatch(image.Resource.Id)
+			return fmt.Sprintf("https://ngcs-core.staatsbibliothek-berlin.de/dzi/%s/PHYS_%s.dzi", r.dt.BookId, m[2])
 		}
-	}
-	return canvases, nil
+		//JPEG URL
+		//https://content.staatsbibliothek-berlin.de/dc/3303598630-0001/full/full/0/default.jpg
+		return image.Resource.Id
+	}), nil
 
 }
 
@@ -176,18 +167,17 @@ func (r *Berlin) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
@@ -197,7 +187,7 @@ func (r *Berlin) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -205,15 +195,12 @@ func (r *Berlin) doNormal(imgUrls []string) bool {
 			continue
 		}
 		ext := util.FileExt(uri)
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + ext
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, ext)
 		if FileExist(dest) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -229,10 +216,8 @@ func (r *Berlin) doNormal(imgUrls []string) bool {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return true
 }
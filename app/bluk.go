@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -39,7 +39,7 @@ func (r *Bluk) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -55,11 +55,11 @@ func (r *Bluk) getBookId(sUrl string) (bookId string) {
 
 func (r *Bluk) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -76,10 +76,10 @@ func (r *Bluk) download() (msg string, err error) {
 
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -115,6 +115,7 @@ func (r *Bluk) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 	}
 	size := len(m)
 	canvases = make([]string, 0, size)
+	r.dt.Labels = make([]string, 0, size)
 	for _, id := range m {
 		if id == "##" {
 			continue
@@ -122,6 +123,8 @@ func (r *Bluk) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 		//dezoomify-rs URL
 		dziUrl := fmt.Sprintf("http://www.bl.uk/manuscripts/Proxy.ashx?view=%s.xml", id)
 		canvases = append(canvases, dziUrl)
+		//or_6814!1_f001r 叶码为 f001r
+		r.dt.Labels = append(r.dt.Labels, id[strings.LastIndex(id, "_")+1:])
 
 	}
 	return canvases, nil
@@ -166,18 +169,17 @@ func (r *Bluk) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
@@ -187,7 +189,7 @@ func (r *Bluk) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -195,15 +197,12 @@ func (r *Bluk) doNormal(imgUrls []string) bool {
 			continue
 		}
 		ext := util.FileExt(uri)
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + ext
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, ext)
 		if FileExist(dest) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -219,10 +218,8 @@ func (r *Bluk) doNormal(imgUrls []string) bool {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return true
 }
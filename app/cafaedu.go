@@ -3,12 +3,12 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -72,7 +72,7 @@ func (r *CafaEdu) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.ServerUrl = "dlibgate.cafa.edu.cn"
@@ -89,11 +89,11 @@ func (r *CafaEdu) getBookId(sUrl string) (bookId string) {
 
 func (r *CafaEdu) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -110,10 +110,10 @@ func (r *CafaEdu) download() (msg string, err error) {
 
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -145,7 +145,7 @@ func (r *CafaEdu) getCanvases(apiUrl string, jar *cookiejar.Jar) (canvases []str
 	}
 	var manifest = new(CafaEduResponse)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	canvases = make([]string, 0, len(manifest.Item.Tiles))
@@ -216,6 +216,7 @@ func (r *CafaEdu) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
@@ -226,8 +227,8 @@ func (r *CafaEdu) doDezoomifyRs(iiifUrls []string) bool {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
@@ -237,7 +238,7 @@ func (r *CafaEdu) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -252,8 +253,7 @@ func (r *CafaEdu) doNormal(imgUrls []string) bool {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -269,10 +269,8 @@ func (r *CafaEdu) doNormal(imgUrls []string) bool {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return true
 }
@@ -3,13 +3,14 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/cuhk"
+	"bookget/pkg/dedup"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -40,20 +41,22 @@ func (r *Cuhk) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	//OpenWebBrowser(sUrl, []string{})
-	WaitNewCookie()
+	if err = WaitNewCookie(); err != nil {
+		return "", err
+	}
 	return r.download()
 }
 
 func (r *Cuhk) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -64,10 +67,10 @@ func (r *Cuhk) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -91,6 +94,7 @@ func (r *Cuhk) doDezoomifyRs(iiifUrls []string) bool {
 	}
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
@@ -101,7 +105,7 @@ func (r *Cuhk) doDezoomifyRs(iiifUrls []string) bool {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
 		cookies := gohttp.ReadCookieFile(config.Conf.CookieFile)
 		args := []string{"--dezoomer=deepzoom",
 			"-H", "Origin:" + referer,
@@ -109,15 +113,15 @@ func (r *Cuhk) doDezoomifyRs(iiifUrls []string) bool {
 			"-H", "User-Agent:" + config.Conf.UserAgent,
 			"-H", "cookie:" + cookies,
 		}
-		util.StartProcess(uri, dest, args)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
 
 func (r *Cuhk) doNormal(imgUrls []string) {
-	fmt.Println()
 	referer := r.dt.Url
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if uri == "" || !config.PageRange(i, size) {
@@ -129,7 +133,7 @@ func (r *Cuhk) doNormal(imgUrls []string) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d,  URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d,  URL: %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -147,12 +151,17 @@ func (r *Cuhk) doNormal(imgUrls []string) {
 			if err == nil && resp.GetStatusCode() == 200 {
 				break
 			}
-			WaitNewCookieWithMsg(uri)
+			//重复页、占位图被拒收（--dedup requeue）不是 cookie 失效
+			if errors.Is(err, dedup.ErrSuspect) {
+				break
+			}
+			if err = WaitNewCookieWithMsg(uri); err != nil {
+				r.dt.LogErr(err)
+				return
+			}
 		}
 		util.PrintSleepTime(config.Conf.Speed)
-		fmt.Println()
 	}
-	fmt.Println()
 
 }
 
@@ -185,7 +194,7 @@ func (r *Cuhk) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 	}
 	data := []byte("{\"pages\":" + string(matches[1]) + "]}")
 	if err = json.Unmarshal(data, &resp); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 	}
 	for _, page := range resp.ImagePage {
 		var imgUrl string
@@ -208,7 +217,9 @@ func (r *Cuhk) getBodyWithLoop(sUrl string, jar *cookiejar.Jar) (bs []byte, err
 	for i := 0; i < 1000; i++ {
 		bs, err = r.getBody(sUrl, jar)
 		if err != nil {
-			WaitNewCookie()
+			if err = WaitNewCookie(); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		break
@@ -245,7 +256,7 @@ func (r *Cuhk) getCanvasesJPEG2000(sUrl string, jar *cookiejar.Jar) (imagePage [
 	if matches != nil {
 		data := []byte("{\"pages\":" + string(matches[1]) + "]}")
 		if err = json.Unmarshal(data, &resp); err != nil {
-			log.Printf("json.Unmarshal failed: %s\n", err)
+			r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		}
 		imagePage = make([]cuhk.ImagePage, len(resp.ImagePage))
 		copy(imagePage, resp.ImagePage)
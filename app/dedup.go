@@ -0,0 +1,149 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/dedup"
+	"bookget/pkg/verify"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	dedupMu    sync.Mutex
+	dedupBooks = map[string]*dedup.Book{} //图书目录 → 本次运行已下载页面的指纹
+	dedupKnown *dedup.Known
+)
+
+func dedupOptions() dedup.Options {
+	return dedup.Options{
+		Distance: config.Conf.Dedup.Distance,
+		Repeat:   config.Conf.Dedup.Repeat,
+		Known:    dedupKnown,
+	}
+}
+
+func loadPlaceholders() {
+	known, err := dedup.LoadKnown(config.Conf.Dedup.Placeholders)
+	if err != nil {
+		log.Printf("dedup: %v\n", err)
+	}
+	dedupKnown = known
+}
+
+// checkDuplicate 检查刚保存的页面是否为重复页或占位图，写入 dedup.json。
+// requeue 模式下 dest 可疑时返回 *dedup.SuspectError（由保存文件的一方删除 dest）；
+// 前面已保存的页面不在下载中删除，由 bookget dedup --requeue 处理
+func checkDuplicate(dest string) error {
+	mode := config.Conf.Dedup.Mode
+	if mode == "off" || !verify.IsPageFile(dest) {
+		return nil
+	}
+	dir, name := filepath.Split(dest)
+	dir = filepath.Clean(dir)
+	fp, err := dedup.Compute(dest, name)
+	if err != nil {
+		log.Printf("dedup: %v\n", err)
+		return nil
+	}
+
+	dedupMu.Lock()
+	b, ok := dedupBooks[dir]
+	if !ok {
+		b = dedup.NewBook(dedupOptions())
+		dedupBooks[dir] = b
+	}
+	findings := b.Add(fp)
+	dedupMu.Unlock()
+	if len(findings) == 0 {
+		return nil
+	}
+
+	if err = dedup.Append(dir, findings...); err != nil {
+		log.Printf("dedup: %v\n", err)
+	}
+	var rejected error
+	for _, f := range findings {
+		printFinding(f)
+		if mode == "requeue" && f.File == name && f.Suspect(config.Conf.Dedup.Duplicates) {
+			rejected = &dedup.SuspectError{Finding: f}
+		}
+	}
+	return rejected
+}
+
+func printFinding(f dedup.Finding) {
+	switch {
+	case f.Of != "":
+		log.Printf("[%s] %s = %s %s\n", f.Kind, f.File, f.Of, f.Reason)
+	default:
+		log.Printf("[%s] %s %s\n", f.Kind, f.File, f.Reason)
+	}
+}
+
+// DedupBooks 检查 dir 下所有已下载图书的重复页与占位图，写入各自的 dedup.json；
+// requeue=true 时删除可疑页面并重新下载
+func DedupBooks(dir string, requeue bool) (suspects int, err error) {
+	loadPlaceholders()
+	opts := dedupOptions()
+	err = verify.WalkDirs(dir, func(bookDir string) {
+		m, err := verify.Load(bookDir)
+		if err != nil {
+			log.Printf("dedup: %v\n", err)
+			return
+		}
+		fps := make([]*dedup.Fingerprint, 0, len(m.Pages))
+		for _, p := range m.Pages {
+			fp, err := dedup.Compute(filepath.Join(bookDir, p.File), p.File)
+			if err != nil {
+				continue
+			}
+			fps = append(fps, fp)
+		}
+		r := &dedup.Report{Findings: dedup.Analyze(fps, opts)}
+		if err = r.Save(bookDir); err != nil {
+			log.Printf("dedup: %v\n", err)
+		}
+
+		var bad []dedup.Finding
+		for _, f := range r.Findings {
+			if f.Suspect(config.Conf.Dedup.Duplicates) {
+				bad = append(bad, f)
+			}
+		}
+		suspects += len(bad)
+		if len(r.Findings) == 0 {
+			fmt.Printf("[OK]   %s  %d pages\n", bookDir, len(fps))
+			return
+		}
+		fmt.Printf("[WARN] %s  %d pages, suspect: %d, report only: %d\n", bookDir, len(fps), len(bad), len(r.Findings)-len(bad))
+		for _, f := range r.Findings {
+			fmt.Printf("       %-14s %s", f.Kind, f.File)
+			if f.Of != "" {
+				fmt.Printf(" = %s", f.Of)
+			}
+			if f.Reason != "" {
+				fmt.Printf("  (%s)", f.Reason)
+			}
+			if f.MD5 != "" {
+				fmt.Printf("  md5=%s phash=%s", f.MD5, f.PHash)
+			}
+			fmt.Println()
+		}
+		if !requeue || len(bad) == 0 {
+			return
+		}
+		for _, f := range bad {
+			_ = os.Remove(filepath.Join(bookDir, f.File))
+		}
+		res, err := verify.Dir(bookDir, true)
+		if err != nil {
+			log.Printf("dedup: %v\n", err)
+			return
+		}
+		repairBook(res)
+	})
+	return suspects, err
+}
@@ -4,9 +4,9 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	xcrypt "bookget/pkg/crypt"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
@@ -54,7 +54,7 @@ func (r *DpmBj) Run(sUrl string) (msg string, err error) {
 	r.dt.VolumeId = getBookId(r.dt.Url)
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -95,7 +95,7 @@ func (r *DpmBj) download() (msg string, err error) {
 	r.dt.Title = r.getTitle(bs)
 
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s %s %s\n", name, r.dt.Title, r.dt.Url)
+	r.dt.Logf("Get %s %s %s\n", name, r.dt.Title, r.dt.Url)
 
 	if cipherText == nil || len(cipherText) == 0 {
 		return "cipherText not found", err
@@ -123,7 +123,7 @@ func (r *DpmBj) do(dest string, dziFormat iiif.DziFormat) (msg string, err error
 	if util.FileExist(outfile) {
 		return "", nil
 	}
-	if ret := util.StartProcess(dest, outfile, args); ret == true {
+	if dezoomify(dest, outfile, r.dt.Url, args) {
 		os.Remove(dest)
 	}
 	return "", err
@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
@@ -52,7 +52,7 @@ func (r DziCnLib) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -60,19 +60,19 @@ func (r DziCnLib) Run(sUrl string) (msg string, err error) {
 
 func (r DziCnLib) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	r.ServerUrl = r.getServerUri()
 	if r.ServerUrl == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err.Error())
+		r.dt.LogErr(err)
 		return
 	}
-	log.Printf(" %d pages \n", len(canvases))
+	r.dt.Logf(" %d pages \n", len(canvases))
 	return r.do(canvases)
 }
 
@@ -97,7 +97,7 @@ func (r DziCnLib) do(dziUrls []string) (msg string, err error) {
 		if FileExist(outfile) {
 			continue
 		}
-		if ret := util.StartProcess(inputUri, outfile, args); ret == true {
+		if dezoomify(inputUri, outfile, r.dt.Url, args) {
 			os.Remove(inputUri)
 		}
 		util.PrintSleepTime(config.Conf.Speed)
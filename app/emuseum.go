@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -41,7 +41,7 @@ func (d *Emuseum) Run(sUrl string) (msg string, err error) {
 	d.dt.Url = sUrl
 	d.dt.BookId = d.getBookId(d.dt.Url)
 	if d.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	d.dt.Jar, _ = cookiejar.New(nil)
 	return d.download()
@@ -60,11 +60,11 @@ func (d *Emuseum) getBookId(sUrl string) (bookId string) {
 
 func (d *Emuseum) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", d.dt.Index)
-	log.Printf("Get %s  %s\n", name, d.dt.Url)
+	d.dt.Logf("Get %s  %s\n", name, d.dt.Url)
 
 	respVolume, err := d.getVolumes(d.dt.Url, d.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		d.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -81,10 +81,10 @@ func (d *Emuseum) download() (msg string, err error) {
 
 		canvases, err := d.getCanvases(vol, d.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			d.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		d.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		d.do(canvases)
 	}
 	return "", nil
@@ -120,32 +120,18 @@ func (d *Emuseum) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []strin
 	}
 	var manifest = new(iiif.ManifestResponse)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		d.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			if strings.Contains(image.Resource.Service.Id, "/100001001002.tif") {
-				image.Resource.Service.Id = strings.Replace(image.Resource.Service.Id, "/100001001002.tif", "/100001001001.tif", 1)
-				image.Resource.Id = strings.Replace(image.Resource.Id, "/100001001002.tif", "/100001001001.tif", 1)
-			}
-			if config.Conf.UseDziRs {
-				//dezoomify-rs URL
-				iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-				canvases = append(canvases, iiiInfo)
-			} else {
-				//JPEG URL
-				imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-				canvases = append(canvases, imgUrl)
-			}
+	return canvasUrls(d.dt, manifest, func(image iiif.Image) string {
+		if strings.Contains(image.Resource.Service.Id, "/100001001002.tif") {
+			image.Resource.Service.Id = strings.Replace(image.Resource.Service.Id, "/100001001002.tif", "/100001001001.tif", 1)
 		}
-	}
-	return canvases, nil
+		return iiifImageUrl(image)
+	}), nil
 
 }
 
@@ -182,18 +168,17 @@ func (d *Emuseum) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(d.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := d.dt.SavePath + filename
+		dest := pageDest(d.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		d.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
@@ -203,7 +188,7 @@ func (d *Emuseum) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(d.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -211,15 +196,12 @@ func (d *Emuseum) doNormal(imgUrls []string) bool {
 			continue
 		}
 		ext := util.FileExt(uri)
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + ext
-		dest := d.dt.SavePath + filename
+		dest := pageDest(d.dt, i, ext)
 		if FileExist(dest) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		d.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -235,10 +217,8 @@ func (d *Emuseum) doNormal(imgUrls []string) bool {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return true
 }
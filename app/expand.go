@@ -0,0 +1,28 @@
+package app
+
+import (
+	"bookget/pkg/expand"
+	"bookget/pkg/i18n"
+	"log"
+)
+
+// ExpandUrls 把书单、合集、检索结果等网址展开为其中的图书网址，其余网址原样保留；结果去重
+func ExpandUrls(urls []string) []string {
+	fetch := func(u string) ([]byte, error) {
+		return getBody(u, nil)
+	}
+	var out []string
+	for _, u := range urls {
+		members, ok, err := expand.Expand(u, fetch)
+		if !ok {
+			out = append(out, u)
+			continue
+		}
+		if err != nil {
+			log.Printf("expand %s: %v\n", u, err)
+		}
+		log.Println(i18n.T("expand.found", u, len(members)))
+		out = append(out, members...)
+	}
+	return expand.Dedup(out)
+}
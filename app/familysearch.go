@@ -3,16 +3,16 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/family"
+	"bookget/pkg/authstore"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
-	"os"
 	"regexp"
 	"strings"
 )
@@ -49,7 +49,7 @@ func (r *Familysearch) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.baseUrl, r.sgBaseUrl, _ = r.getBaseUrl(r.dt.Url)
 	r.dt.Jar, _ = cookiejar.New(nil)
@@ -57,7 +57,9 @@ func (r *Familysearch) Run(sUrl string) (msg string, err error) {
 	//r.apiUrl = r.dt.UrlParsed.Scheme + "://" + r.dt.UrlParsed.Host + "/search/filmdata/filmdatainfo"
 	//https://www.familysearch.org/search/filmdatainfo/image-data
 	r.apiUrl = r.dt.UrlParsed.Scheme + "://" + r.dt.UrlParsed.Host + "/search/filmdatainfo/image-data"
-	WaitNewCookie()
+	if err = WaitNewCookie(); err != nil {
+		return "", err
+	}
 	return r.download()
 }
 
@@ -97,7 +99,7 @@ func (r *Familysearch) getBaseUrl(sUrl string) (baseUrl, sgBaseUrl string, err e
 
 func (r *Familysearch) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	var canvases []string
 	imageData, err := r.getImageData(r.dt.Url)
@@ -109,7 +111,7 @@ func (r *Familysearch) download() (msg string, err error) {
 		return "", err
 	}
 	size := len(canvases)
-	log.Printf(" %d pages.\n", size)
+	r.dt.Logf(" %d pages.\n", size)
 
 	r.do(canvases)
 	return "", nil
@@ -141,8 +143,8 @@ func (r *Familysearch) do(iiifUrls []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
+		dezoomify(uri, dest, uri, args)
 		util.PrintSleepTime(config.Conf.Speed)
 	}
 	return "", err
@@ -326,13 +328,15 @@ func (r *Familysearch) postJson(sUrl string, d interface{}) ([]byte, error) {
 	return bs, err
 }
 
+// getSessionId 优先使用凭据文件中的 Bearer token，否则取 cookie fssessionid
 func (r *Familysearch) getSessionId() string {
-	bs, _ := os.ReadFile(config.Conf.CookieFile)
-	cookies := string(bs)
+	creds := authstore.Default.For(r.dt.UrlParsed.Host)
+	if auth := creds.Authorization(); auth != "" {
+		return auth
+	}
 	//fssessionid=e10ce618-f7f7-45de-b2c3-d1a31d080d58-prod;
-	m := regexp.MustCompile(`fssessionid=([^;]+);`).FindStringSubmatch(cookies)
-	if m != nil {
-		return "bearer " + m[1]
+	if sid, ok := creds.Cookie("fssessionid"); ok {
+		return "bearer " + sid
 	}
 	return ""
 }
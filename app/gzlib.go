@@ -3,10 +3,10 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -38,7 +38,7 @@ func (r Gzlib) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -58,11 +58,11 @@ func (r Gzlib) getBookId(sUrl string) (bookId string) {
 
 func (r Gzlib) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
 	if err != nil || canvases == nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 	}
 	return r.do(canvases)
 }
@@ -71,7 +71,6 @@ func (r Gzlib) do(dUrls []string) (msg string, err error) {
 	if dUrls == nil {
 		return
 	}
-	fmt.Println()
 	size := len(dUrls)
 	ctx := context.Background()
 	requestCookie := r.dt.Jar.Cookies(r.dt.UrlParsed)
@@ -99,7 +98,7 @@ func (r Gzlib) do(dUrls []string) (msg string, err error) {
 		}
 		_, err = gohttp.FastGet(ctx, uri, opts)
 		if err != nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
 	}
@@ -3,10 +3,10 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -39,7 +39,7 @@ func (r *HannomNlv) Run(sUrl string) (msg string, err error) {
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	return r.download()
 }
@@ -59,13 +59,13 @@ func (r *HannomNlv) getBookId(sUrl string) (bookId string) {
 
 func (r *HannomNlv) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
 	if err != nil || canvases == nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 	}
-	log.Printf(" %d pages \n", len(canvases))
+	r.dt.Logf(" %d pages \n", len(canvases))
 	r.do(canvases)
 	return "", nil
 }
@@ -75,7 +75,7 @@ func (r *HannomNlv) do(imgUrls []string) (msg string, err error) {
 		return "", nil
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -89,8 +89,7 @@ func (r *HannomNlv) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -106,11 +105,9 @@ func (r *HannomNlv) do(imgUrls []string) (msg string, err error) {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", nil
 }
 
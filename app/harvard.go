@@ -3,13 +3,14 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/iiif"
+	"bookget/pkg/dedup"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -38,7 +39,7 @@ func (r *Harvard) GetRouterInit(sUrl string) (map[string]interface{}, error) {
 }
 
 func (r *Harvard) Run(sUrl string) (msg string, err error) {
-	if strings.Contains(sUrl, "curiosity.pkg.harvard.edu") {
+	if strings.Contains(sUrl, "curiosity.lib.harvard.edu") {
 		bs, err := r.getBody(sUrl, nil)
 		if err != nil {
 			return "", err
@@ -52,7 +53,7 @@ func (r *Harvard) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	//WaitNewCookie()
@@ -73,6 +74,11 @@ func (r *Harvard) getBookId(sUrl string) (bookId string) {
 	if m != nil {
 		return m[1]
 	}
+	//https://nrs.harvard.edu/urn-3:FHCL:1234567
+	m = regexp.MustCompile(`nrs\.harvard\.edu/+(urn-3:[A-z0-9-_:.]+)`).FindStringSubmatch(sUrl)
+	if m != nil {
+		return strings.ReplaceAll(m[1], ":", "_")
+	}
 	return ""
 }
 
@@ -83,11 +89,11 @@ func (r *Harvard) download() (msg string, err error) {
 	}
 
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -104,10 +110,10 @@ func (r *Harvard) download() (msg string, err error) {
 
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -123,7 +129,7 @@ func (r *Harvard) do(imgUrls []string) (msg string, err error) {
 }
 
 func (r *Harvard) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string, err error) {
-	if strings.Contains(sUrl, "listview.pkg.harvard.edu") {
+	if strings.Contains(sUrl, "listview.lib.harvard.edu") {
 		bs, err := r.getBody(sUrl, nil)
 		if err != nil {
 			return nil, err
@@ -136,7 +142,7 @@ func (r *Harvard) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string,
 			volUrl := "https://nrs.harvard.edu" + strings.Replace(string(m[1]), "//", "/", -1)
 			volumes = append(volumes, volUrl)
 		}
-	} else if strings.Contains(sUrl, "iiif.pkg.harvard.edu") {
+	} else if strings.Contains(sUrl, "iiif.lib.harvard.edu") || strings.Contains(sUrl, "nrs.harvard.edu") {
 		volumes = append(volumes, sUrl)
 	}
 	return volumes, nil
@@ -144,7 +150,7 @@ func (r *Harvard) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string,
 
 func (r *Harvard) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string, err error) {
 	var manifestUri = sUrl
-	if strings.Contains(sUrl, "iiif.pkg.harvard.edu/manifests/view/") ||
+	if strings.Contains(sUrl, "iiif.lib.harvard.edu/manifests/view/") ||
 		strings.Contains(sUrl, "nrs.harvard.edu") {
 		bs, err := r.getBody(sUrl, jar)
 		if err != nil {
@@ -155,7 +161,7 @@ func (r *Harvard) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []strin
 		if match != nil {
 			manifestUri = string(match[1])
 		} else {
-			return nil, errors.New("requested URL was not found.")
+			return nil, i18n.Error("app.url_not_found")
 		}
 	}
 	bs, err := r.getBody(manifestUri, jar)
@@ -164,28 +170,13 @@ func (r *Harvard) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []strin
 	}
 	var manifest = new(iiif.ManifestResponse)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			if config.Conf.UseDziRs {
-				//dezoomify-rs URL
-				iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-				canvases = append(canvases, iiiInfo)
-			} else {
-				//JPEG URL
-				imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-				canvases = append(canvases, imgUrl)
-			}
-		}
-	}
-	return canvases, nil
+	return canvasUrls(r.dt, manifest, nil), nil
 
 }
 
@@ -222,17 +213,16 @@ func (r *Harvard) doDezoomifyRs(iiifUrls []string) bool {
 	}
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
 		cookies := gohttp.ReadCookieFile(config.Conf.CookieFile)
 		args := []string{
 			"-H", "Origin:" + referer,
@@ -240,7 +230,7 @@ func (r *Harvard) doDezoomifyRs(iiifUrls []string) bool {
 			"-H", "User-Agent:" + config.Conf.UserAgent,
 			"-H", "cookie:" + cookies,
 		}
-		util.StartProcess(uri, dest, args)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
@@ -250,21 +240,18 @@ func (r *Harvard) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, "")
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
 		ext := util.FileExt(uri)
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + ext
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, ext)
 		if FileExist(dest) {
 			continue
 		}
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -280,20 +267,25 @@ func (r *Harvard) doNormal(imgUrls []string) bool {
 			if err == nil && resp.GetStatusCode() == 200 {
 				break
 			}
-			WaitNewCookieWithMsg(uri)
+			//重复页、占位图被拒收（--dedup requeue）不是 cookie 失效
+			if errors.Is(err, dedup.ErrSuspect) {
+				break
+			}
+			if err = WaitNewCookieWithMsg(uri); err != nil {
+				r.dt.LogErr(err)
+				return false
+			}
 		}
 		util.PrintSleepTime(config.Conf.Speed)
-		fmt.Println()
 
 	}
-	fmt.Println()
 	return true
 }
 
 func (r *Harvard) tryEmail(sUrl string, jar *cookiejar.Jar) (bs []byte, err error) {
 	bs, err = r.getBody(sUrl, jar)
 	if err != nil {
-		fmt.Println("当前地区 IP 受限访问，请使用其它方法。该站可使用Email接收PDF。详见网页 “Print/Save” PDF\n")
+		r.dt.Logf("%s", i18n.T("app.harvard_ip_blocked"))
 	}
 	return bs, err
 }
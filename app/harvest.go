@@ -0,0 +1,67 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/discovery"
+	"bookget/pkg/metrics"
+	"bookget/pkg/queue"
+	"log"
+	"sync/atomic"
+)
+
+// Harvest 读取 IIIF Change Discovery 活动流，下载上次抓取以来新增或更新的 manifest。
+// 进度保存在 config.CacheDir() 下，full=true 时忽略进度、重新处理整个流；返回下载失败的 manifest 数
+func Harvest(stream string, full bool) (failed int, err error) {
+	cp, err := discovery.Load(discovery.CheckpointPath(config.CacheDir(), stream), stream)
+	if err != nil {
+		return 0, err
+	}
+	if full {
+		cp.Reset()
+	}
+	fetch := func(u string) ([]byte, error) {
+		return getBody(u, nil)
+	}
+	changes, newest, err := discovery.Changes(stream, cp, fetch)
+	if err != nil {
+		return 0, err
+	}
+	if cp.LastCrawl.IsZero() {
+		log.Printf("%s  %d manifest(s)", stream, len(changes))
+	} else {
+		log.Printf("%s  %d change(s) since %s", stream, len(changes), cp.LastCrawl.Format("2006-01-02 15:04:05"))
+	}
+
+	size := config.Conf.MaxConcurrent
+	if size <= 0 {
+		size = 1
+	}
+	q := queue.NewConcurrentQueue(size)
+	metrics.RegisterQueue("harvest", q)
+	var nFailed atomic.Int32
+	for k, ch := range changes {
+		if ch.Deleted {
+			log.Printf("harvest: %s was deleted, the downloaded copy is kept\n", ch.Id)
+			logSaveErr(cp.Deleted(ch))
+			continue
+		}
+		q.Go(func() {
+			var i IIIF
+			if _, err := i.AutoDetectManifest(k+1, ch.Id); err != nil {
+				log.Printf("harvest: %s: %v\n", ch.Id, err)
+				nFailed.Add(1)
+				logSaveErr(cp.Failed(ch))
+				return
+			}
+			logSaveErr(cp.Done(ch))
+		})
+	}
+	q.Wait()
+	return int(nFailed.Load()), cp.Finish(newest)
+}
+
+func logSaveErr(err error) {
+	if err != nil {
+		log.Printf("harvest: save checkpoint: %v\n", err)
+	}
+}
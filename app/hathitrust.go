@@ -3,16 +3,15 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/hathitrust"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
-	"strconv"
 )
 
 type Hathitrust struct {
@@ -39,7 +38,7 @@ func (r Hathitrust) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -55,22 +54,43 @@ func (r Hathitrust) getBookId(sUrl string) (bookId string) {
 
 func (r Hathitrust) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
-	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
+	layers, err := hathitrust.ParseLayers(config.Conf.Text)
 	if err != nil {
-		fmt.Println(err.Error())
-		return "requested URL was not found.", err
+		return "", err
+	}
+	params, err := r.getParams(r.dt.Url, r.dt.Jar)
+	if err != nil {
+		r.dt.LogErr(err)
+		var ae *hathitrust.AccessError
+		if errors.As(err, &ae) {
+			return err.Error(), err
+		}
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
-	msg, err = r.do(canvases)
+	if !config.Conf.TextOnly {
+		canvases := r.getCanvases(params)
+		recordPages(r.dt, canvases, config.Conf.FileExt)
+		msg, err = r.do(canvases, config.Conf.FileExt)
+	}
+	for _, layer := range layers {
+		urls := make([]string, params.TotalSeq)
+		for i := range urls {
+			urls[i] = hathitrust.LayerUrl(r.dt.BookId, i+1, layer)
+		}
+		if _, e := r.do(urls, hathitrust.LayerExt(layer)); e != nil {
+			err = e
+		}
+	}
 	return msg, err
 }
 
-func (r Hathitrust) do(imgUrls []string) (msg string, err error) {
+// do 按页下载到 0001{ext}；限速或网络错误时按 hathitrust.Backoff 等待后重试
+func (r Hathitrust) do(imgUrls []string, ext string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(imgUrls)
 	for i, uri := range imgUrls {
@@ -81,12 +101,12 @@ func (r Hathitrust) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
+		filename := sortId + ext
 		dest := r.dt.SavePath + filename
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d, URL: %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -99,18 +119,18 @@ func (r Hathitrust) do(imgUrls []string) (msg string, err error) {
 			},
 		}
 		ctx := context.Background()
-		for {
-			_, err := gohttp.FastGet(ctx, uri, opts)
-			if err != nil {
-				fmt.Println(err)
-				//log.Println("images (1 file per page, watermarked,  max. 20 MB / 1 min), image quality:Full")
-				util.PrintSleepTime(60)
-				continue
+		for attempt := 0; ; attempt++ {
+			_, err = gohttp.FastGet(ctx, uri, opts)
+			if err == nil {
+				break
 			}
-			break
+			r.dt.LogErr(err)
+			if errors.Is(err, gohttp.ErrNotFound) || errors.Is(err, gohttp.ErrAuthRequired) || attempt+1 >= hathitrust.MaxAttempts {
+				break
+			}
+			util.PrintSleepTime(int(hathitrust.Backoff(attempt).Seconds()))
 		}
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -119,23 +139,17 @@ func (r Hathitrust) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []strin
 	panic("implement me")
 }
 
-func (r Hathitrust) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string, err error) {
-	bs, err := r.getBody(r.dt.Url, r.dt.Jar)
-	if err != nil || bs == nil {
+// getParams 读取阅读页参数；只能检索或不允许下载时返回 *hathitrust.AccessError
+func (r Hathitrust) getParams(sUrl string, jar *cookiejar.Jar) (*hathitrust.Params, error) {
+	bs, err := r.getBody(sUrl, jar)
+	if err != nil {
 		return nil, err
 	}
-	//
-	if !bytes.Contains(bs, []byte("HT.params.allowSinglePageDownload = true;")) {
-		return nil, errors.New("This item is not available online —  Limited - search only")
-	}
-	// HT.params.totalSeq = 1220;
-	matches := regexp.MustCompile(`HT.params.totalSeq = ([0-9]+);`).FindStringSubmatch(string(bs))
-	if matches == nil {
-		return
-	}
-	size, _ := strconv.Atoi(matches[1])
+	return hathitrust.ParseParams(r.dt.BookId, bs)
+}
 
-	canvases = make([]string, 0, size)
+func (r Hathitrust) getCanvases(params *hathitrust.Params) (canvases []string) {
+	canvases = make([]string, 0, params.TotalSeq)
 	ext := config.Conf.FileExt
 	format := "jpeg"
 	if ext == ".png" {
@@ -143,11 +157,10 @@ func (r Hathitrust) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []str
 	} else if ext == ".tif" {
 		format = "tiff"
 	}
-	for i := 0; i < size; i++ {
-		imgurl := fmt.Sprintf("https://babel.hathitrust.org/cgi/imgsrv/image?id=%s&attachment=1&size=ppi%%3A300&format=image/%s&seq=%d", r.dt.BookId, format, i+1)
-		canvases = append(canvases, imgurl)
+	for i := 0; i < params.TotalSeq; i++ {
+		canvases = append(canvases, hathitrust.ImageUrl(r.dt.BookId, i+1, format))
 	}
-	return canvases, err
+	return canvases
 }
 
 func (r Hathitrust) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -44,7 +44,7 @@ func (r *Hkulib) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.apiUrl = r.dt.UrlParsed.Scheme + "://" + r.dt.UrlParsed.Host + "/service/api/iiif/manifest/"
@@ -61,11 +61,11 @@ func (r *Hkulib) getBookId(sUrl string) (bookId string) {
 
 func (r *Hkulib) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -76,10 +76,10 @@ func (r *Hkulib) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -89,21 +89,19 @@ func (r *Hkulib) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d page, URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d page, URL: %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -117,14 +115,12 @@ func (r *Hkulib) do(imgUrls []string) (msg string, err error) {
 		}
 		_, err := gohttp.FastGet(ctx, uri, opts)
 		if err != nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			util.PrintSleepTime(60)
 			continue
 		}
-		fmt.Println()
 		//util.PrintSleepTime(config.Conf.Speed)
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -152,23 +148,17 @@ func (r *Hkulib) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string
 		return nil, err
 	}
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			//JPEG URL
-			w := fmt.Sprintf("/full/%d,/", image.Resource.Width)
-			imgUrl := strings.Replace(image.Resource.Id, "/full/full/", w, -1)
-			canvases = append(canvases, imgUrl)
-		}
-	}
-	return canvases, nil
+	return canvasUrls(r.dt, manifest, func(image iiif.Image) string {
+		//JPEG URL
+		w := fmt.Sprintf("/full/%d,/", image.Resource.Width)
+		return strings.Replace(image.Resource.Id, "/full/full/", w, -1)
+	}), nil
 }
 
 func (r *Hkulib) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
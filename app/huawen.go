@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -43,7 +43,7 @@ func (r *Huawen) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -56,11 +56,11 @@ func (r *Huawen) getBookId(sUrl string) (bookId string) {
 
 func (r *Huawen) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -68,7 +68,7 @@ func (r *Huawen) download() (msg string, err error) {
 			continue
 		}
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
-		log.Printf(" %d/%d PDFs \n", i+1, len(respVolume))
+		r.dt.Logf(" %d/%d PDFs \n", i+1, len(respVolume))
 		r.do(vol)
 	}
 	return "", nil
@@ -95,10 +95,9 @@ func (r *Huawen) do(pdfUrl string) (msg string, err error) {
 	}
 	_, err = gohttp.FastGet(ctx, pdfUrl, opts)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 	}
 	util.PrintSleepTime(config.Conf.Speed)
-	fmt.Println()
 	return "", nil
 }
 
@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
-	"bookget/pkg/progressbar"
+	"bookget/pkg/i18n"
+	"bookget/pkg/progress"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -15,7 +15,7 @@ import (
 
 type Idp struct {
 	dt  *DownloadTask
-	bar *progressbar.ProgressBar
+	job *progress.Job
 }
 
 func NewIdp() *Idp {
@@ -38,7 +38,7 @@ func (r *Idp) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -54,19 +54,18 @@ func (r *Idp) getBookId(sUrl string) (bookId string) {
 
 func (r *Idp) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	canvases, err := r.getCanvases(r.dt.BookId, r.dt.Jar)
 	if err != nil || canvases == nil {
-		fmt.Println(err)
-		return "requested URL was not found.", err
+		r.dt.LogErr(err)
+		return i18n.T("app.url_not_found"), err
 	}
 	//不按卷下载，所有图片存一个目录
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	sizeCanvases := len(canvases)
-	fmt.Println()
 	ext := ".jpg"
-	r.bar = progressbar.Default(int64(sizeCanvases), "downloading")
+	r.job = progress.Default.Start(r.dt.BookId, progress.Pages, int64(sizeCanvases))
 	ctx := context.Background()
 	for i, imgUrl := range canvases {
 		if !config.PageRange(i, sizeCanvases) || imgUrl == "" {
@@ -84,11 +83,12 @@ func (r *Idp) download() (msg string, err error) {
 		})
 		_, err = cli.Get(imgUrl)
 		if err != nil {
-			log.Println(err)
+			r.dt.LogErr(err)
 			break
 		}
-		r.bar.Add(1)
+		r.job.Add(1)
 	}
+	r.job.Done()
 	return "", nil
 }
 
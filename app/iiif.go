@@ -4,12 +4,13 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/sniffer"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -45,7 +46,7 @@ func (i *IIIF) Run(sUrl string) (msg string, err error) {
 	i.dt.Jar, _ = cookiejar.New(nil)
 	i.dt.BookId = i.getBookId(i.dt.Url)
 	if i.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	return i.download()
 }
@@ -72,7 +73,7 @@ func (i *IIIF) getBookId(sUrl string) (bookId string) {
 func (i *IIIF) download() (msg string, err error) {
 	i.xmlContent, err = i.getBody(i.dt.Url, i.dt.Jar)
 	if err != nil || i.xmlContent == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	canvases, err := i.getCanvases(i.dt.Url, i.dt.Jar)
 	if err != nil || canvases == nil {
@@ -88,34 +89,20 @@ func (i *IIIF) do(imgUrls []string) (msg string, err error) {
 	} else {
 		i.doNormal(imgUrls)
 	}
-	return "", nil
+	return "", i.dt.Err()
 }
 
 func (i *IIIF) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string, err error) {
 	var manifest = new(iiif.ManifestResponse)
 	if err = json.Unmarshal(i.xmlContent, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		i.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			if config.Conf.UseDziRs {
-				//dezoomify-rs URL
-				iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-				canvases = append(canvases, iiiInfo)
-			} else {
-				//JPEG URL
-				imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-				canvases = append(canvases, imgUrl)
-			}
-		}
-	}
-	return canvases, nil
+	i.dt.Title = strings.TrimSpace(string(manifest.Label))
+	return canvasUrls(i.dt, manifest, nil), nil
 }
 
 func (i *IIIF) getBody(sUrl string, jar *cookiejar.Jar) ([]byte, error) {
@@ -160,19 +147,19 @@ func (i *IIIF) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(i.dt, iiifUrls, config.Conf.FileExt)
 	for k, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(k, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", k+1)
-
-		filename := sortId + config.Conf.FileExt
-		dest := i.dt.SavePath + filename
+		dest := pageDest(i.dt, k, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", k+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		jobLog(i.dt).Info(fmt.Sprintf("Get %d/%d  %s", k+1, size, uri), "page", k+1)
+		if !dezoomify(uri, dest, uri, args) {
+			i.dt.PageFailed(errDezoomify)
+		}
 	}
 	return true
 }
@@ -182,20 +169,18 @@ func (i *IIIF) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(i.dt, imgUrls, "")
 	ctx := context.Background()
 	for k, uri := range imgUrls {
 		if uri == "" || !config.PageRange(k, size) {
 			continue
 		}
 		ext := util.FileExt(uri)
-		sortId := fmt.Sprintf("%04d", k+1)
-		filename := sortId + ext
-		dest := i.dt.SavePath + filename
+		dest := pageDest(i.dt, k, ext)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", k+1, size, uri)
+		jobLog(i.dt).Info(fmt.Sprintf("Get %d/%d  %s", k+1, size, uri), "page", k+1)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -208,9 +193,8 @@ func (i *IIIF) doNormal(imgUrls []string) bool {
 		}
 		_, err := gohttp.FastGet(ctx, uri, opts)
 		if err != nil {
-			fmt.Println(err)
+			i.dt.PageFailed(err)
 		}
-		fmt.Println()
 	}
 	return true
 }
@@ -222,7 +206,7 @@ func (i *IIIF) doNormal(imgUrls []string) bool {
 // https://iiif.dl.itc.u-tokyo.ac.jp/repo/iiif/07956eb1-931c-74ff-61e9-e66d4c30817d/manifest
 func (i *IIIF) AutoDetectManifest(iTask int, sUrl string) (msg string, err error) {
 	name := fmt.Sprintf("%04d", iTask)
-	log.Printf("Auto Detect %s  %s\n", name, sUrl)
+	i.dt.Logf("Auto Detect %s  %s\n", name, sUrl)
 	bs, err := getBody(sUrl, nil)
 	if err != nil {
 		return "", err
@@ -230,25 +214,37 @@ func (i *IIIF) AutoDetectManifest(iTask int, sUrl string) (msg string, err error
 	ver, err := i.checkVersion(bs)
 	if err != nil {
 		jsonUrl := i.getManifestUrl(sUrl, string(bs))
+		if jsonUrl == "" {
+			//网页中没有 manifest，再找其他查看器
+			return NewSniffer().analyze(iTask, sUrl, bs)
+		}
 		//查找到新的 jsonUrl
-		if jsonUrl != sUrl && jsonUrl != "" {
-			bs, err = getBody(jsonUrl, nil)
-			if err != nil {
-				return "", err
-			}
-			ver, err = i.checkVersion(bs)
-			if err != nil {
-				return "", err
-			}
-			sUrl = jsonUrl
+		if jsonUrl != sUrl {
+			return i.runManifest(iTask, jsonUrl)
 		}
 	}
+	return i.runVersion(ver, sUrl, err)
+}
+
+// runManifest 下载 manifest，按版本交给 IIIF 或 IIIFv3
+func (i *IIIF) runManifest(iTask int, sUrl string) (msg string, err error) {
+	bs, err := getBody(sUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	ver, err := i.checkVersion(bs)
+	if err != nil {
+		return "", err
+	}
+	return i.runVersion(ver, sUrl, nil)
+}
+
+func (i *IIIF) runVersion(ver int, sUrl string, err error) (string, error) {
 	if ver == 3 {
-		var iiif3 IIIFv3
+		iiif3 := IIIFv3{dt: new(DownloadTask)}
 		return iiif3.Run(sUrl)
 	} else if ver == 2 {
-		var iiif2 IIIF
-		return iiif2.Run(sUrl)
+		return NewIiifRouter().Run(sUrl)
 	}
 	return "", err
 }
@@ -293,10 +289,14 @@ func (i *IIIF) getManifestUrl(pageUrl, text string) string {
 		return m[1] + "manifest" + m[2] + ".json"
 	}
 	m = regexp.MustCompile(`href=["'](\S+)/manifest.json["']`).FindStringSubmatch(text)
-	if m == nil {
-		return ""
+	if m != nil {
+		return i.padUri(host, m[1]+"/manifest.json")
+	}
+	//Mirador 的 manifestId、data-manifest 等写法
+	if manifests := sniffer.Of(sniffer.Analyze(pageUrl, []byte(text)), sniffer.Manifest); manifests != nil {
+		return manifests[0]
 	}
-	return i.padUri(host, m[1]+"/manifest.json")
+	return ""
 }
 
 func (i *IIIF) padUri(host, uri string) string {
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"bookget/pkg/iiifexport"
+	"bookget/pkg/progress"
+	"fmt"
+	"log"
+	"log/slog"
+	"path/filepath"
+)
+
+// ExportIIIF 把已下载的图书目录导出为 IIIF v3 manifest 与 level 0 切片
+func ExportIIIF(dir string, opts iiifexport.Options) error {
+	job := progress.Default.Start(filepath.Base(dir), progress.Pages, 0)
+	var pages int64
+	opts.OnPage = func(i, total int, file string) {
+		pages = int64(total)
+		job.SetTotal(pages)
+		job.Set(int64(i))
+		slog.Debug("iiif-export", "page", i+1, "file", file)
+	}
+	r, err := iiifexport.Export(dir, opts)
+	if err != nil {
+		job.Done()
+		return err
+	}
+	job.Set(pages)
+	job.Done()
+	for _, f := range r.Skipped {
+		log.Printf("iiif-export: %s skipped (only jpg/png can be tiled)\n", f)
+	}
+	fmt.Printf("%s  %d pages, %d ranges\n", r.Manifest, r.Pages, r.Ranges)
+	fmt.Printf("Serve %s with any static file server, e.g. cd %q && python3 -m http.server 8000\n",
+		filepath.Dir(r.Manifest), filepath.Dir(r.Manifest))
+	return nil
+}
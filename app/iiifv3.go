@@ -4,15 +4,16 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
+	"strings"
 )
 
 type IIIFv3 struct {
@@ -27,7 +28,7 @@ func (p *IIIFv3) Run(sUrl string) (msg string, err error) {
 	p.dt.Jar, _ = cookiejar.New(nil)
 	p.dt.BookId = p.getBookId(p.dt.Url)
 	if p.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	return p.download()
 }
@@ -54,7 +55,7 @@ func (p *IIIFv3) getBookId(sUrl string) (bookId string) {
 func (p *IIIFv3) download() (msg string, err error) {
 	p.xmlContent, err = p.getBody(p.dt.Url, p.dt.Jar)
 	if err != nil || p.xmlContent == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	canvases, err := p.getCanvases(p.dt.Url, p.dt.Jar)
 	if err != nil || canvases == nil {
@@ -70,20 +71,22 @@ func (p *IIIFv3) do(imgUrls []string) (msg string, err error) {
 	} else {
 		p.doNormal(imgUrls)
 	}
-	return "", nil
+	return "", p.dt.Err()
 }
 
 func (p *IIIFv3) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string, err error) {
 	var manifest = new(iiif.ManifestV3Response)
 	if err = json.Unmarshal(p.xmlContent, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		p.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Canvases) == 0 {
 		return
 	}
+	p.dt.Title = strings.TrimSpace(string(manifest.Label))
 	size := len(manifest.Canvases)
 	canvases = make([]string, 0, size)
+	p.dt.Labels = make([]string, 0, size)
 	//config.Conf.Format = strings.ReplaceAll(config.Conf.Format, "full/full", "full/max")
 	for _, canvase := range manifest.Canvases {
 		image := canvase.Items[0].Items[0]
@@ -95,10 +98,12 @@ func (p *IIIFv3) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string
 			//dezoomify-rs URL
 			iiiInfo := fmt.Sprintf("%s/info.json", id)
 			canvases = append(canvases, iiiInfo)
+			p.dt.Labels = append(p.dt.Labels, string(canvase.Label))
 		} else {
 			//JPEG URL
 			imgUrl := id + "/" + config.Conf.Format
 			canvases = append(canvases, imgUrl)
+			p.dt.Labels = append(p.dt.Labels, string(canvase.Label))
 		}
 	}
 	return canvases, nil
@@ -136,18 +141,19 @@ func (p *IIIFv3) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(p.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := p.dt.SavePath + filename
+		dest := pageDest(p.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		jobLog(p.dt).Info(fmt.Sprintf("Get %d/%d  %s", i+1, size, uri), "page", i+1)
+		if !dezoomify(uri, dest, uri, args) {
+			p.dt.PageFailed(errDezoomify)
+		}
 	}
 	return true
 }
@@ -157,20 +163,18 @@ func (p *IIIFv3) doNormal(imgUrls []string) bool {
 		return false
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(p.dt, imgUrls, "")
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
 		ext := util.FileExt(uri)
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + ext
-		dest := p.dt.SavePath + filename
+		dest := pageDest(p.dt, i, ext)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
+		jobLog(p.dt).Info(fmt.Sprintf("Get %d/%d  %s", i+1, size, uri), "page", i+1)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -183,9 +187,8 @@ func (p *IIIFv3) doNormal(imgUrls []string) bool {
 		}
 		_, err := gohttp.FastGet(ctx, uri, opts)
 		if err != nil {
-			fmt.Println(err)
+			p.dt.PageFailed(err)
 		}
-		fmt.Println()
 	}
 	return true
 }
@@ -2,6 +2,12 @@ package app
 
 import (
 	"bookget/config"
+	"bookget/pkg/events"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/metrics"
+	"bookget/pkg/progress"
+	"bookget/pkg/urltemplate"
 	"bufio"
 	"bytes"
 	"context"
@@ -9,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"os"
@@ -18,8 +25,6 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"bookget/pkg/progressbar"
 )
 
 const (
@@ -29,13 +34,9 @@ const (
 )
 
 type ImageDownloader struct {
-	client            *http.Client
-	reader            *bufio.Reader
-	hasVolPlaceholder bool   // 是否包含[VOL]或[PAGE]占位符
-	hasABPlaceholder  bool   // 是否包含[AB]或[ab]占位符
-	abPlaceholder     string // 存储实际的占位符形式（"[AB]"或"[ab]"）
-	abIsLowercase     bool   // 占位符是否为小写
-	maxConcurrent     int
+	client        *http.Client
+	reader        *bufio.Reader
+	maxConcurrent int
 
 	ctx context.Context
 }
@@ -56,11 +57,10 @@ func NewImageDownloader() *ImageDownloader {
 
 	return &ImageDownloader{
 		// 初始化字段
-		client:            &http.Client{Timeout: config.Conf.Timeout * time.Second, Jar: jar, Transport: tr},
-		reader:            bufio.NewReader(os.Stdin),
-		hasVolPlaceholder: false,
-		maxConcurrent:     maxConcurrent_,
-		ctx:               context.Background(),
+		client:        &http.Client{Timeout: config.Conf.Timeout * time.Second, Jar: jar, Transport: metrics.Transport(tr)},
+		reader:        bufio.NewReader(os.Stdin),
+		maxConcurrent: maxConcurrent_,
+		ctx:           context.Background(),
 	}
 }
 
@@ -74,103 +74,123 @@ func (i *ImageDownloader) GetRouterInit(rawUrl string) (map[string]interface{},
 	}, nil
 }
 
+// RunTemplates 无人值守下载：--template 指定的模板与 --template-file 中的每一行
+func (i *ImageDownloader) RunTemplates() error {
+	var specs []*urltemplate.Spec
+	if config.Conf.TemplateFile != "" {
+		f, err := os.Open(config.Conf.TemplateFile)
+		if err != nil {
+			return err
+		}
+		specs, err = urltemplate.ParseFile(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", config.Conf.TemplateFile, err)
+		}
+	}
+	if config.Conf.Template != "" {
+		s, err := urltemplate.NewSpec(config.Conf.Template, map[string]string{
+			"vol":   config.Conf.TemplateVols,
+			"pages": config.Conf.TemplatePages,
+			"start": config.Conf.TemplateStart,
+		})
+		if err != nil {
+			return err
+		}
+		specs = append(specs, s)
+	}
+	for _, s := range specs {
+		i.download(s)
+	}
+	return nil
+}
+
 func (i *ImageDownloader) Run(rawUrl string) {
 	for {
-		fmt.Println("\n=== 当前模式：图片批量下载 ===")
-		fmt.Println("输入 'exit' 退出程序")
+		fmt.Fprintln(os.Stderr, "\n"+i18n.T("img.mode"))
+		fmt.Fprintln(os.Stderr, i18n.T("img.exit_hint"))
 
-		// 1. 获取URL模板
-		urlTemplate, err := i.getInput("请输入图片URL模板（必须包含[PAGE]，可选[VOL]和[AB]）: ")
-		if err != nil || strings.ToLower(urlTemplate) == "exit" {
+		// 1. 获取URL模板，旧写法[PAGE]、[VOL]、[AB]需要再问页码位数
+		tpl, err := i.getInput(i18n.T("img.template"))
+		if err != nil || strings.ToLower(tpl) == "exit" {
 			break
 		}
-
-		// 检查必须包含[PAGE]占位符
-		if !strings.Contains(urlTemplate, "[PAGE]") {
-			fmt.Println("错误: URL模板必须包含[PAGE]占位符")
-			continue
-		}
-
-		// 检查占位符
-		i.hasVolPlaceholder = strings.Contains(urlTemplate, "[VOL]")
-		i.hasABPlaceholder = strings.Contains(urlTemplate, "[AB]") || strings.Contains(urlTemplate, "[ab]")
-
-		// 存储实际的占位符形式
-		if strings.Contains(urlTemplate, "[AB]") {
-			i.abPlaceholder = "[AB]"
-			i.abIsLowercase = false
-		} else if strings.Contains(urlTemplate, "[ab]") {
-			i.abPlaceholder = "[ab]"
-			i.abIsLowercase = true
+		if strings.Contains(tpl, "[PAGE]") {
+			width, err := i.getInputInt(i18n.T("img.page_format"))
+			if err != nil || width <= 0 {
+				fmt.Fprintln(os.Stderr, i18n.T("img.need_page_format"))
+				continue
+			}
+			tpl = urltemplate.Legacy(tpl, width)
 		}
-
-		// 2. 获取页码格式化位数
-		pageFormat, err := i.getInput("请输入页码格式化位数（如04表示0001，03表示001）: ")
-		if err != nil || pageFormat == "" {
-			fmt.Println("输入错误: 必须指定页码格式化位数")
+		t, err := urltemplate.Parse(tpl)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.T("img.input_error", err))
 			continue
 		}
 
-		// 3. 获取扩展名（从URL模板中提取或用户指定）
-		ext := filepath.Ext(urlTemplate)
+		// 2. 获取扩展名（从URL模板中提取或用户指定）
+		ext := templateExt(tpl)
 		if ext == "" {
-			ext, err = i.getInput("无法从URL中识别扩展名，请手动输入（如.jpg、.png）: ")
+			ext, err = i.getInput(i18n.T("img.ext"))
 			if err != nil || ext == "" {
-				fmt.Println("输入错误: 必须指定文件扩展名")
+				fmt.Fprintln(os.Stderr, i18n.T("img.need_ext"))
 				continue
 			}
-			if !strings.HasPrefix(ext, ".") {
-				ext = "." + ext
-			}
 		}
 
-		var startVol, endVol int
-		if i.hasVolPlaceholder {
-			// 4. 获取册数范围
-			startVol, endVol, err = i.getVolumeRange()
+		// 3. 获取册数范围与每册页数
+		opts := map[string]string{"ext": ext}
+		if t.HasVol() {
+			startVol, endVol, err := i.getVolumeRange()
 			if err != nil {
-				fmt.Println("输入错误:", err)
+				fmt.Fprintln(os.Stderr, i18n.T("img.input_error", err))
 				continue
 			}
+			opts["vol"] = fmt.Sprintf("%d:%d", startVol, endVol)
+			opts["pages"], err = i.getInput(i18n.T("img.vol_pages"))
 		} else {
-			startVol, endVol = 1, 1
+			opts["pages"], err = i.getInput(i18n.T("img.total_pages"))
 		}
-
-		// 5. 获取总页数
-		totalPages, err := i.getInputInt("请输入全部册数的总页数: ")
-		if err != nil || totalPages <= 0 {
-			fmt.Println("输入错误: 总页数必须大于0")
+		if err != nil || opts["pages"] == "" {
+			fmt.Fprintln(os.Stderr, i18n.T("img.need_total"))
+			continue
+		}
+		spec, err := urltemplate.NewSpec(tpl, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.T("img.input_error", err))
 			continue
 		}
 
-		// 6. 确认并开始下载
-		if i.hasVolPlaceholder {
-			fmt.Printf("\n即将开始下载:\nURL模板: %s\n册数范围: %04d-%04d\n总页数: %d\n页码格式: %%0%sd\n扩展名: %s\n",
-				urlTemplate, startVol, endVol, totalPages, pageFormat, ext)
+		// 4. 确认并开始下载，页数为 auto 时先探测
+		if spec.Probe {
+			i.probe(spec)
+		}
+		if t.HasVol() {
+			fmt.Fprintf(os.Stderr, "\n%s\n", i18n.T("img.summary_vol", tpl, spec.VolStart, spec.VolEnd, opts["pages"], spec.Total(), spec.Ext))
 		} else {
-			fmt.Printf("\n即将开始下载:\nURL模板: %s\n总页数: %d\n页码格式: %%0%sd\n扩展名: %s\n",
-				urlTemplate, totalPages, pageFormat, ext)
+			fmt.Fprintf(os.Stderr, "\n%s\n", i18n.T("img.summary", tpl, spec.Total(), spec.Ext))
 		}
-		confirm, _ := i.getInput("确认开始下载？(y/n): ")
+		confirm, _ := i.getInput(i18n.T("img.confirm"))
 		if strings.ToLower(confirm) != "y" {
 			continue
 		}
 
-		// 7. 执行下载
-		i.downloadAll(urlTemplate, startVol, endVol, totalPages, pageFormat, ext)
+		// 5. 执行下载
+		i.download(spec)
 
-		// 8. 询问是否继续
-		cont, _ := i.getInput("\n下载完成！是否继续下载其他URL模板？(y/n): ")
+		// 6. 询问是否继续
+		cont, _ := i.getInput("\n" + i18n.T("img.continue"))
 		if strings.ToLower(cont) != "y" {
 			break
 		}
 	}
 
-	fmt.Println("程序退出")
+	fmt.Fprintln(os.Stderr, i18n.T("img.bye"))
 }
 
 func (i *ImageDownloader) getInput(prompt string) (string, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(os.Stderr, prompt)
 	input, err := i.reader.ReadString('\n')
 	if err != nil {
 		return "", err
@@ -187,147 +207,216 @@ func (i *ImageDownloader) getInputInt(prompt string) (int, error) {
 }
 
 func (i *ImageDownloader) getVolumeRange() (int, int, error) {
-	startVol, err := i.getInputInt("请输入起始册号: ")
+	startVol, err := i.getInputInt(i18n.T("img.start_vol"))
 	if err != nil {
 		return 0, 0, err
 	}
 
-	endVol, err := i.getInputInt("请输入结束册号: ")
+	endVol, err := i.getInputInt(i18n.T("img.end_vol"))
 	if err != nil {
 		return 0, 0, err
 	}
 
 	if startVol > endVol {
-		return 0, 0, fmt.Errorf("起始册号不能大于结束册号")
+		return 0, 0, i18n.Error("img.vol_order")
 	}
 
 	return startVol, endVol, nil
 }
 
-func (i *ImageDownloader) downloadAll(urlTemplate string, startVol, endVol, totalPages int, pageFormat, ext string) {
-	totalVolumes := endVol - startVol + 1
-	//totalExpected := totalPages * 2 // 假设每页都有A/B两面
+// templateExt 模板 URL 路径的扩展名（不含查询参数）
+func templateExt(tpl string) string {
+	if k := strings.IndexByte(tpl, '?'); k >= 0 {
+		tpl = tpl[:k]
+	}
+	ext := filepath.Ext(tpl)
+	if strings.ContainsAny(ext, "{}/") {
+		return ""
+	}
+	return ext
+}
+
+// download 按册、页下载一个模板任务，保存到 downloads/<模板 URL 的哈希>。有 {vol} 时每册一个子目录，文件名为 0001.jpg 或 0001a.jpg
+func (i *ImageDownloader) download(s *urltemplate.Spec) {
+	ext := s.Ext
+	if ext == "" {
+		ext = templateExt(s.Template.String())
+	}
+	if ext == "" {
+		ext = config.Conf.FileExt
+	}
+	if s.Probe {
+		i.probe(s)
+	}
+	pages := s.List()
+	files := len(pages)
+	if n := len(s.Template.Sides); n > 1 {
+		files *= n
+	}
 
 	var totalDownloaded int64
-	globalBar := progressbar.NewOptions64(
-		int64(totalPages),
-		progressbar.OptionSetDescription("总下载进度"),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "=",
-			SaucerHead:    ">",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetWidth(50),
-		progressbar.OptionOnCompletion(func() {
-			fmt.Printf("\n下载完成！共成功下载 %d 个文件\n", atomic.LoadInt64(&totalDownloaded))
-		}),
-	)
+	job := progress.Default.Start(i18n.T("img.progress"), progress.Pages, int64(files))
 
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, i.maxConcurrent)
+	//每个模板一个目录，多个模板的文件名相同时不会互相跳过
+	root := filepath.Join(config.Conf.SaveFolder, "downloads", getBookId(s.Template.String()))
+	for _, p := range pages {
+		dirPath := root
+		if s.Template.HasVol() {
+			dirPath = filepath.Join(dirPath, fmt.Sprintf("%04d", p.Vol))
+		}
+		if err := os.MkdirAll(dirPath, 0755); err != nil {
+			progress.Printf("%s", i18n.T("img.mkdir", dirPath, err))
+			break
+		}
 
-	// 计算每册页数（简单平均分配）
-	pagesPerVol := totalPages / totalVolumes
-	remainingPages := totalPages % totalVolumes
-
-	for vol := startVol; vol <= endVol; vol++ {
 		wg.Add(1)
 		semaphore <- struct{}{}
-
-		// 当前册的页数
-		currentPages := pagesPerVol
-		if vol <= startVol+remainingPages-1 {
-			currentPages++
-		}
-
-		go func(volume, pagesThisVol int) {
+		go func(p urltemplate.Page) {
 			defer wg.Done()
 			defer func() { <-semaphore }()
-
-			volStr := fmt.Sprintf("%04d", volume)
-			var dirPath string
-			if i.hasVolPlaceholder {
-				dirPath = filepath.Join(config.Conf.SaveFolder, "downloads", volStr)
-			} else {
-				dirPath = filepath.Join(config.Conf.SaveFolder, "downloads")
-			}
-
-			if err := os.MkdirAll(dirPath, 0755); err != nil {
-				fmt.Printf("\n创建目录 %s 失败: %v\n", dirPath, err)
-				return
-			}
-
-			for page := 1; page <= pagesThisVol; page++ {
-				i.downloadPageSmart(urlTemplate, volStr, page, dirPath, pageFormat, ext, globalBar, &totalDownloaded)
-			}
-		}(vol, currentPages)
+			i.downloadPage(s, p, dirPath, ext, job, &totalDownloaded)
+		}(p)
 	}
 
 	wg.Wait()
-	globalBar.Finish()
+	job.Done()
+	log.Println(i18n.T("img.done", atomic.LoadInt64(&totalDownloaded)))
 }
 
-func (i *ImageDownloader) downloadPageSmart(urlTemplate, volStr string, page int, dirPath, pageFormat, ext string, globalBar *progressbar.ProgressBar, totalDownloaded *int64) {
-	// 构建页码格式
-	pageNum := fmt.Sprintf("%0"+pageFormat+"d", page)
+// probe pages=auto 时探测每册页数，结果写入 s.Pages
+func (i *ImageDownloader) probe(s *urltemplate.Spec) {
+	vols := s.Volumes()
+	s.Pages = make([]int, len(vols))
+	s.Probe = false
+	for k, v := range vols {
+		last := urltemplate.FindLast(s.Start, s.Gap, func(pg int) bool {
+			return i.pageExists(s, v, pg)
+		})
+		s.Pages[k] = last - s.Start + 1
+		if s.Template.HasVol() {
+			log.Println(i18n.T("img.probe_vol", v, s.Pages[k]))
+		} else {
+			log.Println(i18n.T("img.probe", s.Pages[k]))
+		}
+	}
+}
 
-	// 构建基础URL
-	url := urlTemplate
-	if i.hasVolPlaceholder {
-		url = strings.Replace(url, "[VOL]", volStr, 1)
+// pageExists 第 vol 册第 pg 页是否存在，有 {side} 时与下载一样先试第一面，再试不带面号
+func (i *ImageDownloader) pageExists(s *urltemplate.Spec, vol, pg int) bool {
+	sides := []string{""}
+	if len(s.Template.Sides) > 0 {
+		sides = []string{s.Template.Sides[0], ""}
+	}
+	for _, side := range sides {
+		if i.urlExists(s.Template.Expand(vol, pg, side)) {
+			return true
+		}
 	}
+	return false
+}
 
-	if i.hasABPlaceholder {
-		// 智能处理AB面（只检测一次）
-		abSuffix := "A" // 默认使用大写
-		if i.abIsLowercase {
-			abSuffix = "a"
+// urlExists 先发 HEAD 请求；服务器不支持 HEAD 或没有 Content-Length 时改为 Range 请求前 minFileSize 字节。
+// 小于 minFileSize 或返回网页（错误页、登录页）视为不存在，限流时等待后重试
+func (i *ImageDownloader) urlExists(u string) bool {
+	for retry := 0; ; retry++ {
+		ok, err := i.probeURL(u)
+		if errors.Is(err, gohttp.ErrRateLimited) && retry < config.Conf.Retry {
+			time.Sleep(time.Duration(retry+1) * 5 * time.Second)
+			continue
 		}
+		return ok
+	}
+}
 
-		// 构建A面URL
-		urlA := strings.Replace(url, "[PAGE]", pageNum, 1)
-		urlA = strings.Replace(urlA, i.abPlaceholder, abSuffix, 1)
-		err := i.downloadAndValidate(urlA, filepath.Join(dirPath, fmt.Sprintf("%s%s%s", pageNum, abSuffix, ext)), globalBar, totalDownloaded)
+func (i *ImageDownloader) probeURL(u string) (bool, error) {
+	req, err := http.NewRequestWithContext(i.ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if resp, err := i.client.Do(req); err == nil {
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+			mime := strings.ToLower(resp.Header.Get("Content-Type"))
+			if resp.ContentLength >= 0 {
+				textual := strings.HasPrefix(mime, "text/") || strings.Contains(mime, "json") || strings.Contains(mime, "xml")
+				return !textual && resp.ContentLength >= minFileSize, nil
+			}
+		case http.StatusNotFound, http.StatusGone:
+			return false, nil
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return false, gohttp.ErrRateLimited
+		}
+	}
 
-		if err == nil {
-			// 如果A面存在，下载B面
-			abSuffix = "B" // 默认使用大写
-			if i.abIsLowercase {
-				abSuffix = "b"
+	req.Method = http.MethodGet
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", minFileSize-1))
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, minFileSize))
+	if err = gohttp.CheckContent(resp.StatusCode, resp.Header.Get("Content-Type"), head, u); err != nil {
+		return false, err
+	}
+	size := int64(len(head))
+	if resp.StatusCode == http.StatusPartialContent {
+		//Content-Range: bytes 0-1023/48213
+		if _, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/"); ok {
+			if n, err := strconv.ParseInt(total, 10, 64); err == nil {
+				size = n
 			}
+		}
+	} else if resp.ContentLength > size {
+		size = resp.ContentLength
+	}
+	return size >= minFileSize, nil
+}
 
-			urlB := strings.Replace(url, "[PAGE]", pageNum, 1)
-			urlB = strings.Replace(urlB, i.abPlaceholder, abSuffix, 1)
-			err = i.downloadAndValidate(urlB, filepath.Join(dirPath, fmt.Sprintf("%s%s%s", pageNum, abSuffix, ext)), globalBar, totalDownloaded)
-			if err != nil {
-				fmt.Printf("[err=downloadAndValidate]+%v\n", err)
-				return
+// downloadPage 下载一页的各面。第一面不存在时视为该页不分面，改为不带面号下载
+func (i *ImageDownloader) downloadPage(s *urltemplate.Spec, p urltemplate.Page, dirPath, ext string, job *progress.Job, totalDownloaded *int64) {
+	dest := func(side string) string {
+		return filepath.Join(dirPath, fmt.Sprintf("%04d%s%s", p.Page, side, ext))
+	}
+	if len(p.Sides) == 0 {
+		if err := i.downloadAndValidate(s.Template.Expand(p.Vol, p.Page, ""), dest(""), job, totalDownloaded); err != nil {
+			progress.Printf("[err=downloadAndValidate]+%v", err)
+		}
+		return
+	}
+	for k, side := range p.Sides {
+		err := i.downloadAndValidate(s.Template.Expand(p.Vol, p.Page, side), dest(side), job, totalDownloaded)
+		if err != nil && k == 0 {
+			err = i.downloadAndValidate(s.Template.Expand(p.Vol, p.Page, ""), dest(""), job, totalDownloaded)
+			if err == nil {
+				//进度按每页 len(Sides) 个文件计算
+				job.Add(int64(len(p.Sides) - 1))
 			}
-		} else {
-			urlPlain := strings.Replace(url, "[PAGE]", pageNum, 1)
-			urlPlain = strings.Replace(urlPlain, i.abPlaceholder, "", 1)
-			s := filepath.Join(dirPath, fmt.Sprintf("%s%s", pageNum, ext))
-			err = i.downloadAndValidate(urlPlain, s, globalBar, totalDownloaded)
 			if err != nil {
-				fmt.Printf("[err=downloadAndValidate]+%v\n", err)
-				return
+				progress.Printf("[err=downloadAndValidate]+%v", err)
 			}
+			return
 		}
-
-	} else {
-		urlPlain := strings.Replace(url, "[PAGE]", pageNum, 1)
-		err := i.downloadAndValidate(urlPlain, filepath.Join(dirPath, fmt.Sprintf("%s%s", pageNum, ext)), globalBar, totalDownloaded)
 		if err != nil {
-			fmt.Printf("[err=downloadAndValidate]+%v\n", err)
-			return
+			progress.Printf("[err=downloadAndValidate]+%v", err)
 		}
 	}
 }
 
-func (i *ImageDownloader) downloadAndValidate(url, filePath string, globalBar *progressbar.ProgressBar, totalDownloaded *int64) error {
+func (i *ImageDownloader) downloadAndValidate(url, filePath string, job *progress.Job, totalDownloaded *int64) (err error) {
+	//已下载的跳过，便于中断后重新运行
+	if fi, err := os.Stat(filePath); err == nil && fi.Size() >= minFileSize {
+		job.Add(1)
+		return nil
+	}
+	start := time.Now()
+	events.Emit(events.Event{Type: events.PageQueued, Url: url, Path: filePath})
+	defer func() { events.Page(url, filePath, start, err) }()
+
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return err
@@ -340,16 +429,15 @@ func (i *ImageDownloader) downloadAndValidate(url, filePath string, globalBar *p
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return err
-	}
-
 	buf := bytes.NewBuffer(make([]byte, 0, 10*1024*1024))
 	if _, err := io.CopyBuffer(buf, resp.Body, make([]byte, 32*1024)); err != nil { // 32KB缓冲区
 		return err
 	}
+	if err := gohttp.CheckContent(resp.StatusCode, resp.Header.Get("Content-Type"), buf.Bytes(), filePath); err != nil {
+		return err
+	}
 	if buf.Len() < minFileSize {
-		return errors.New("发现0字节文件")
+		return i18n.Error("img.empty")
 	}
 
 	//file, err := os.Create(filePath)
@@ -365,11 +453,12 @@ func (i *ImageDownloader) downloadAndValidate(url, filePath string, globalBar *p
 	//}
 
 	if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("写入文件失败: %v", err)
+		return i18n.Errorf("img.write", err)
 	}
 
 	atomic.AddInt64(totalDownloaded, 1)
-	globalBar.Add(1)
+	job.AddBytes(int64(buf.Len()))
+	job.Add(1)
 
 	return nil
 }
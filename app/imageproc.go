@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/imageproc"
+	"bookget/pkg/verify"
+	"log"
+	"path/filepath"
+	"sync"
+)
+
+// bookDirs CreateDirectory 创建的图书目录 → 站点域名，用于按站点读取图像处理设置
+var bookDirs sync.Map
+
+// EnablePostProcess 每个文件下载完成后，先记入 bookget.json 与 pages.json（handler 没有调用 recordPages 时也有页面对照表），
+// 再检查重复页与占位图，最后按站点设置进行图像处理
+func EnablePostProcess() {
+	loadPlaceholders()
+	verify.SkipDir(config.Conf.ImageProc.Output)
+	gohttp.AfterSave = func(dest, src string) error {
+		if verify.IsPageFile(dest) {
+			if err := verify.RecordSaved(dest, src); err != nil {
+				log.Printf("pages.json: %v\n", err)
+			}
+		}
+		if err := checkDuplicate(dest); err != nil {
+			return err
+		}
+		processImage(dest)
+		return nil
+	}
+}
+
+func processImage(dest string) {
+	p := config.ImageProcFor(siteOfFile(dest))
+	opts := imageproc.Options{
+		Split:    p.Split,
+		Crop:     p.Crop,
+		Deskew:   p.Deskew,
+		Color:    p.Color,
+		MaxWidth: p.MaxWidth,
+		Quality:  p.Quality,
+		Output:   p.Output,
+	}
+	if !opts.Enabled() {
+		return
+	}
+	verify.SkipDir(opts.Output)
+	files, err := imageproc.File(dest, opts)
+	if err != nil {
+		log.Printf("imageproc: %v\n", err)
+		return
+	}
+	//覆盖模式下跨页拆分后原文件已删除，记下拆分后的文件，verify 与续传时不再当作缺页
+	if opts.Output == "" && len(files) > 1 {
+		if err = verify.SetProcessed(dest, files); err != nil {
+			log.Printf("imageproc: %v\n", err)
+		}
+	}
+}
+
+// siteOfFile 向上查找文件所在的图书目录，返回其站点域名
+func siteOfFile(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		if host, ok := bookDirs.Load(dir); ok {
+			return host.(string)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
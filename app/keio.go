@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -44,7 +44,7 @@ func (r *Keio) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId, r.dt.VolumeId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -81,11 +81,11 @@ func (r *Keio) getManifestUrl(sUrl string) (uri string, err error) {
 
 func (r *Keio) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -96,10 +96,10 @@ func (r *Keio) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -141,28 +141,13 @@ func (r *Keio) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 	}
 	var manifest = new(iiif.ManifestResponse)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			if config.Conf.UseDziRs {
-				//dezoomify-rs URL
-				iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-				canvases = append(canvases, iiiInfo)
-			} else {
-				//JPEG URL
-				imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-				canvases = append(canvases, imgUrl)
-			}
-		}
-	}
-	return canvases, nil
+	return canvasUrls(r.dt, manifest, nil), nil
 }
 
 func (r *Keio) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
@@ -204,8 +189,8 @@ func (r *Keio) doNormal(imgUrls []string) bool {
 	if imgUrls == nil {
 		return false
 	}
-	fmt.Println()
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, dUrl := range imgUrls {
@@ -213,14 +198,12 @@ func (r *Keio) doNormal(imgUrls []string) bool {
 			continue
 		}
 		ext := util.FileExt(dUrl)
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + ext
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, ext)
 		if FileExist(dest) {
 			continue
 		}
 		imgUrl := dUrl
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -253,18 +236,17 @@ func (r *Keio) doDezoomifyRs(iiifUrls []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i+1, size, uri)
-		util.StartProcess(uri, dest, args)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, uri)
+		dezoomify(uri, dest, uri, args)
 	}
 	return true
 }
@@ -4,12 +4,11 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
-	"bookget/pkg/util"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
@@ -45,7 +44,7 @@ func (r *Khirin) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.apiUrl = r.dt.UrlParsed.Scheme + "://" + r.dt.UrlParsed.Host
@@ -62,17 +61,17 @@ func (r *Khirin) getBookId(sUrl string) (bookId string) {
 
 func (r *Khirin) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.dt.SavePath = CreateDirectory(r.dt.Url, r.dt.BookId, "")
 	manifestUrl, err := r.getManifestUrl(r.dt.Url)
 	if err != nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	canvases, err := r.getCanvases(manifestUrl, r.dt.Jar)
 	if err != nil || canvases == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
-	log.Printf(" %d pages \n", len(canvases))
+	r.dt.Logf(" %d pages \n", len(canvases))
 	return r.do(canvases)
 }
 
@@ -96,12 +95,12 @@ func (r *Khirin) doDezoomifyRs(canvases []string) bool {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(canvases)
+	recordPages(r.dt, canvases, config.Conf.FileExt)
 	for i, uri := range canvases {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
 		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
 		inputUri := r.dt.SavePath + sortId + "_info.json"
 		bs, err := r.getBody(uri, r.dt.Jar)
 		if err != nil {
@@ -109,12 +108,12 @@ func (r *Khirin) doDezoomifyRs(canvases []string) bool {
 		}
 		bsNew := regexp.MustCompile(`profile":\[([^{]+)\{"formats":([^\]]+)\],`).ReplaceAll(bs, []byte(`profile":[{"formats":["jpg"],`))
 		os.WriteFile(inputUri, bsNew, os.ModePerm)
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %s  %s\n", sortId, uri)
-		if ret := util.StartProcess(inputUri, dest, args); ret == true {
+		r.dt.Logf("Get %s  %s\n", sortId, uri)
+		if dezoomify(inputUri, dest, uri, args) {
 			os.Remove(inputUri)
 		}
 	}
@@ -125,20 +124,18 @@ func (r *Khirin) doNormal(canvases []string) bool {
 	if canvases == nil {
 		return false
 	}
-	fmt.Println()
 	size := len(canvases)
+	recordPages(r.dt, canvases, config.Conf.FileExt)
 	ctx := context.Background()
 	for i, uri := range canvases {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d, URL: %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -150,10 +147,8 @@ func (r *Khirin) doNormal(canvases []string) bool {
 			},
 		}
 		gohttp.FastGet(ctx, uri, opts)
-		fmt.Println()
 		//util.PrintSleepTime(config.Conf.Speed)
 	}
-	fmt.Println()
 	return true
 }
 
@@ -169,27 +164,13 @@ func (r *Khirin) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string
 		return nil, err
 	}
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			if config.Conf.UseDziRs {
-				//dezoomify-rs URL
-				iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-				canvases = append(canvases, iiiInfo)
-			} else {
-				imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-				canvases = append(canvases, imgUrl)
-			}
-		}
-	}
-	return canvases, nil
+	return canvasUrls(r.dt, manifest, nil), nil
 }
 func (r *Khirin) getManifestUrl(sUrl string) (uri string, err error) {
 	bs, err := r.getBody(sUrl, r.dt.Jar)
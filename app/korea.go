@@ -4,11 +4,11 @@ import (
 	"bookget/config"
 	"bookget/model/korea"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -41,7 +41,7 @@ func (r *Korea) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -57,11 +57,11 @@ func (r *Korea) getBookId(sUrl string) (bookId string) {
 
 func (r *Korea) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -78,7 +78,7 @@ func (r *Korea) download() (msg string, err error) {
 		if err != nil || vol.Canvases == nil {
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(vol.Canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(vol.Canvases))
 		r.do(vol.Canvases)
 	}
 	return "", nil
@@ -88,8 +88,8 @@ func (r *Korea) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
@@ -104,7 +104,7 @@ func (r *Korea) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d, %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d, %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -121,11 +121,9 @@ func (r *Korea) do(imgUrls []string) (msg string, err error) {
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
 			util.PrintSleepTime(config.Conf.Speed)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -3,13 +3,13 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"path/filepath"
@@ -46,7 +46,7 @@ func (r *KyudbSnu) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.entry = r.getEntryPage(sUrl)
@@ -81,19 +81,19 @@ func (r *KyudbSnu) getBookId(sUrl string) (bookId string) {
 
 func (r *KyudbSnu) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	bs, err := r.getBody(r.dt.Url, r.dt.Jar)
 	if err != nil || bs == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	//PDF
 	if bytes.Contains(bs, []byte("name=\"mfpdf_link\"")) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 		canvases, err := r.getPdfUrls(r.dt.Url)
 		if err != nil || canvases == nil {
-			return "requested URL was not found.", err
+			return i18n.T("app.url_not_found"), err
 		}
-		log.Printf(" %d volumes \n", len(canvases))
+		r.dt.Logf(" %d volumes \n", len(canvases))
 		r.doPdf(canvases)
 		return "", nil
 	}
@@ -101,7 +101,7 @@ func (r *KyudbSnu) download() (msg string, err error) {
 	if r.itemId == "" && r.entry == "renderer" {
 		match := regexp.MustCompile(`item_cd=([A-z0-9_-]+)`).FindSubmatch(bs)
 		if match == nil {
-			return "requested URL was not found.", err
+			return i18n.T("app.url_not_found"), err
 		}
 		r.itemId = string(match[1])
 	}
@@ -118,7 +118,7 @@ func (r *KyudbSnu) download() (msg string, err error) {
 		if err != nil || canvases == nil {
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -128,9 +128,9 @@ func (r *KyudbSnu) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := fmt.Sprintf("%s://%s/pf01/rendererImg.do", r.dt.UrlParsed.Scheme, r.dt.UrlParsed.Host)
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, "")
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if !config.PageRange(i, size) {
@@ -141,7 +141,7 @@ func (r *KyudbSnu) do(imgUrls []string) (msg string, err error) {
 		}
 		ext := util.FileExt(uri)
 		sortId := fmt.Sprintf("%04d", i+1)
-		log.Printf("Get %d/%d page, URL: %s\n", i+1, len(imgUrls), uri)
+		r.dt.Logf("Get %d/%d page, URL: %s\n", i+1, len(imgUrls), uri)
 		filename := sortId + ext
 		dest := r.dt.SavePath + filename
 		opts := gohttp.Options{
@@ -157,11 +157,10 @@ func (r *KyudbSnu) do(imgUrls []string) (msg string, err error) {
 		}
 		_, err = gohttp.FastGet(ctx, uri, opts)
 		if err != nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			break
 		}
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -169,7 +168,6 @@ func (r *KyudbSnu) doPdf(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(imgUrls)
 	var wg sync.WaitGroup
@@ -185,7 +183,7 @@ func (r *KyudbSnu) doPdf(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d, URL: %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -203,11 +201,9 @@ func (r *KyudbSnu) doPdf(imgUrls []string) (msg string, err error) {
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
 			util.PrintSleepTime(config.Conf.Speed)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -240,7 +236,7 @@ func (r *KyudbSnu) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string
 	}
 	matches := regexp.MustCompile(`<option\s+value=["']([A-z0-9]+)["']`).FindAllSubmatch(bs, -1)
 	if matches == nil {
-		err = errors.New("requested URL was not found.")
+		err = i18n.Error("app.url_not_found")
 		return nil, err
 	}
 	for _, m := range matches {
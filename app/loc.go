@@ -2,26 +2,29 @@ package app
 
 import (
 	"bookget/config"
-	"bookget/model/loc"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/locgov"
 	"bookget/pkg/util"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 )
 
+// 例如：
+// 图书     https://www.loc.gov/item/2012402381/
+// 报纸一期 https://www.loc.gov/item/sn84026749/1905-01-01/ed-1/
+// 报纸刊名 https://chroniclingamerica.loc.gov/lccn/sn84026749/ （按 -date-from、-date-to 下载各期，必须指定其一）
+
 type Loc struct {
-	dt         *DownloadTask
-	xmlContent []byte
-	tmpFile    string
+	dt *DownloadTask
 }
 
 func NewLoc() *Loc {
@@ -44,60 +47,147 @@ func (r *Loc) Run(sUrl string) (msg string, err error) {
 	r.dt.UrlParsed, err = url.Parse(sUrl)
 	r.dt.Url = sUrl
 
-	r.dt.BookId = r.getBookId(r.dt.Url)
+	id, issue := locgov.ItemId(r.dt.Url)
+	r.dt.BookId = id
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
-	return r.download()
+	if locgov.IsNewspaper(id, issue) {
+		return r.downloadIssues(id)
+	}
+	apiUrl := locgov.ItemUrl(id, issue)
+	if strings.Contains(sUrl, "/resource/") {
+		apiUrl = locgov.JsonUrl(sUrl)
+	}
+	return r.download(apiUrl, strings.ReplaceAll(issue, "/", "_"))
 }
 
-func (r *Loc) getBookId(sUrl string) (bookId string) {
-	m := regexp.MustCompile(`item/([A-Za-z0-9]+)`).FindStringSubmatch(sUrl)
-	if m != nil {
-		bookId = m[1]
+// downloadIssues 报纸：列出日期范围内的各期，每期一个目录，如 vol.1905-01-01_ed-1
+func (r *Loc) downloadIssues(lccn string) (msg string, err error) {
+	//整份报纸往往有上万期，必须指定日期范围
+	if config.Conf.DateFrom == "" && config.Conf.DateTo == "" {
+		return "", i18n.Errorf("app.date_range_required", lccn)
+	}
+	year := func(d string) string {
+		if len(d) > 4 {
+			return d[:4]
+		}
+		return d
+	}
+	var issues []locgov.Issue
+	seen := map[string]bool{}
+	for page := 1; page <= locgov.MaxPages; page++ {
+		bs, err := r.getBody(locgov.IssuesUrl(lccn, year(config.Conf.DateFrom), year(config.Conf.DateTo), page), r.dt.Jar)
+		if err != nil {
+			return "getIssues", err
+		}
+		found, more, err := locgov.ParseIssues(bs)
+		if err != nil {
+			return "getIssues", err
+		}
+		for _, v := range found {
+			if config.DateRange(v.Date) && !seen[v.Dir()] {
+				seen[v.Dir()] = true
+				issues = append(issues, v)
+			}
+		}
+		if !more || len(found) == 0 {
+			break
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Dir() < issues[j].Dir()
+	})
+	r.dt.Logf("%s  %d issue(s)\n", lccn, len(issues))
+	for _, v := range issues {
+		if _, e := r.download(locgov.ItemUrl(lccn, v.Date+"/"+v.Edition), v.Dir()); e != nil {
+			err = e
+		}
 	}
-	return bookId
+	return "", err
 }
 
-func (r *Loc) download() (msg string, err error) {
-	apiUrl := fmt.Sprintf("https://www.loc.gov/item/%s/?fo=json", r.dt.BookId)
-	r.xmlContent, err = r.getBody(apiUrl, r.dt.Jar)
-	if err != nil || r.xmlContent == nil {
-		return "requested URL was not found.", err
+// download 条目的每一部分（分册、分段）为一个目录；prefix 不为空时用作目录名（报纸的某一期）
+func (r *Loc) download(apiUrl, prefix string) (msg string, err error) {
+	quality, err := locgov.ParseQuality(config.Conf.Quality, config.Conf.FileExt)
+	if err != nil {
+		return "", err
+	}
+	bs, err := r.getBody(apiUrl, r.dt.Jar)
+	if err != nil || bs == nil {
+		return i18n.T("app.url_not_found"), err
 	}
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, apiUrl)
 
-	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
+	parts, err := locgov.ParseItem(bs)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
-	for i, vol := range respVolume {
+	for i, part := range parts {
 		if !config.VolumeRange(i) {
 			continue
 		}
 		vid := fmt.Sprintf("%04d", i+1)
+		if prefix != "" {
+			vid = prefix
+			if len(parts) > 1 {
+				vid += fmt.Sprintf("_%04d", i+1)
+			}
+		}
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
-		canvases, err := r.getCanvases(vol, r.dt.Jar)
-		if err != nil || canvases == nil {
-			fmt.Println(err)
+		pages, err := r.getPages(part)
+		if err != nil {
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
-		r.do(canvases)
+		canvases, exts := r.getCanvases(pages, quality)
+		if canvases == nil {
+			continue
+		}
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(parts), len(canvases))
+		r.do(canvases, exts)
 	}
 	return "", nil
 }
 
-func (r *Loc) do(imgUrls []string) (msg string, err error) {
+// getPages 多册条目与分段的 files 常常不在条目 JSON 中，需要再请求该部分的地址
+func (r *Loc) getPages(part locgov.Part) ([][]locgov.File, error) {
+	if len(part.Pages) > 0 || part.Url == "" {
+		return part.Pages, nil
+	}
+	bs, err := r.getBody(locgov.JsonUrl(part.Url), r.dt.Jar)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := locgov.ParseItem(bs)
+	if err != nil {
+		return nil, err
+	}
+	var pages [][]locgov.File
+	for _, p := range sub {
+		pages = append(pages, p.Pages...)
+	}
+	return pages, nil
+}
+
+func (r *Loc) do(imgUrls, exts []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(imgUrls)
+	//各页类型相同时按该扩展名记录，否则按各页地址
+	ext := exts[0]
+	for _, e := range exts {
+		if e != ext {
+			ext = ""
+			break
+		}
+	}
+	recordPages(r.dt, imgUrls, ext)
 
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
@@ -106,13 +196,13 @@ func (r *Loc) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
+		filename := sortId + exts[i]
 		dest := r.dt.SavePath + filename
 		if FileExist(dest) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d, %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d, %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -135,48 +225,29 @@ func (r *Loc) do(imgUrls []string) (msg string, err error) {
 				}
 			}
 			util.PrintSleepTime(config.Conf.Speed)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
-func (r *Loc) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string, err error) {
-	var manifests = new(loc.ManifestsJson)
-	if err = json.Unmarshal(r.xmlContent, manifests); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
-		return
-	}
-	//一本书有N卷
-	for _, resource := range manifests.Resources {
-		volumes = append(volumes, resource.Url)
-	}
-	return volumes, nil
-}
-
-func (r *Loc) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string, err error) {
-	var manifests = new(loc.ManifestsJson)
-	if err = json.Unmarshal(r.xmlContent, manifests); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
-		return
-	}
-
-	for _, resource := range manifests.Resources {
-		if resource.Url != sUrl {
+// getCanvases 每页按 -quality 选取一个文件；IIIF 的 JPEG 按 -format 改为所需尺寸
+func (r *Loc) getCanvases(pages [][]locgov.File, quality locgov.Quality) (canvases, exts []string) {
+	for _, files := range pages {
+		f, ext, ok := quality.Pick(files)
+		if !ok {
 			continue
 		}
-		for _, file := range resource.Files {
-			//每页有6种下载方式
-			imgUrl, ok := r.getImagePage(file)
-			if ok {
-				canvases = append(canvases, imgUrl)
-			}
+		imgUrl := f.Url
+		if f.Mimetype == "image/jpeg" && config.Conf.Format != "" && strings.Contains(imgUrl, "full/pct:") {
+			imgUrl = regexp.MustCompile(`full/pct:(.+)`).ReplaceAllString(imgUrl, config.Conf.Format)
 		}
+		canvases = append(canvases, imgUrl)
+		exts = append(exts, ext)
 	}
-	return canvases, nil
+	return canvases, exts
 }
+
 func (r *Loc) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
 	referer := r.dt.Url
 	ctx := context.Background()
@@ -200,23 +271,3 @@ func (r *Loc) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
 	}
 	return bs, nil
 }
-func (r *Loc) getImagePage(fileUrls []loc.ImageFile) (downloadUrl string, ok bool) {
-	for _, f := range fileUrls {
-		if config.Conf.FileExt == ".jpg" && f.Mimetype == "image/jpeg" {
-			if strings.Contains(f.Url, "full/pct:100/") {
-				if config.Conf.Format != "" {
-					downloadUrl = regexp.MustCompile(`full/pct:(.+)`).ReplaceAllString(f.Url, config.Conf.Format)
-				} else {
-					downloadUrl = f.Url
-				}
-				ok = true
-				break
-			}
-		} else if f.Mimetype != "image/jpeg" {
-			downloadUrl = f.Url
-			ok = true
-			break
-		}
-	}
-	return
-}
@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
@@ -48,7 +48,7 @@ func (r *LodNLGoKr) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.apiUrl = "http://viewer.nl.go.kr:8080"
@@ -56,7 +56,9 @@ func (r *LodNLGoKr) Run(sUrl string) (msg string, err error) {
 	webUrl := r.apiUrl + "/nlmivs/viewWonmun_js.jsp?card_class=L&cno=" + r.dt.BookId
 	//AppData\Roaming\BookGet\bookget\User Data\
 	r.tmpFile = config.UserHomeDir() + "\\AppData\\Roaming\\BookGet\\bookget\\User Data\\tmp.html"
-	OpenWebBrowser(webUrl, []string{"-o", "tmp.html"})
+	if err = OpenWebBrowser(webUrl, []string{"-o", "tmp.html"}); err != nil {
+		return "", err
+	}
 	return r.download()
 }
 
@@ -84,13 +86,13 @@ func (r *LodNLGoKr) download() (msg string, err error) {
 		break
 	}
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	//PDF
 	if strings.Contains(r.PageBody, "extention = \"PDF\";") {
 		r.fileExt = ".pdf"
 		m := regexp.MustCompile(`DEFAULT_URL\s=\s["']([^;]+)["'];`).FindStringSubmatch(r.PageBody)
 		if m == nil {
-			return "requested URL was not found.", err
+			return i18n.T("app.url_not_found"), err
 		}
 		pdfUrl := r.apiUrl + m[1]
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
@@ -104,7 +106,7 @@ func (r *LodNLGoKr) download() (msg string, err error) {
 
 	respVolume, err := r.getVolumeUrls(r.dt.Url)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -115,10 +117,10 @@ func (r *LodNLGoKr) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvasesByUrl(i, vol.Url)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages, title=%s \n", i+1, len(respVolume), len(canvases), vol.Title)
+		r.dt.Logf(" %d/%d volume, %d pages, title=%s \n", i+1, len(respVolume), len(canvases), vol.Title)
 		r.do(canvases)
 	}
 	_ = os.Remove(r.tmpFile)
@@ -129,7 +131,6 @@ func (r *LodNLGoKr) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	size := len(imgUrls)
 	ctx := context.Background()
 	if r.fileExt != ".pdf" {
@@ -145,7 +146,7 @@ func (r *LodNLGoKr) do(imgUrls []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d, URL: %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -158,9 +159,7 @@ func (r *LodNLGoKr) do(imgUrls []string) (msg string, err error) {
 		}
 		gohttp.FastGet(ctx, uri, opts)
 		util.PrintSleepTime(config.Conf.Speed)
-		fmt.Println()
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -3,9 +3,9 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -36,7 +36,7 @@ func (r *Nationaljp) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.extId = "jp2"
@@ -53,11 +53,11 @@ func (r *Nationaljp) getBookId(sUrl string) (bookId string) {
 
 func (r *Nationaljp) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", (r.dt.Index))
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes()
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
@@ -71,9 +71,8 @@ func (r *Nationaljp) download() (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf(" %d/%d volume, %s\n", i+1, len(respVolume), r.extId)
+		r.dt.Logf(" %d/%d volume, %s\n", i+1, len(respVolume), r.extId)
 		r.do(i+1, vol, dest)
-		fmt.Println()
 	}
 	return msg, err
 }
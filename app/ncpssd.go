@@ -2,13 +2,14 @@ package app
 
 import (
 	"bookget/config"
+	"bookget/pkg/authstore"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -38,7 +39,9 @@ func (r *Ncpssd) Run(sUrl string) (msg string, err error) {
 	r.dt.UrlParsed, err = url.Parse(sUrl)
 	r.dt.Url = sUrl
 	r.dt.Jar, _ = cookiejar.New(nil)
-	WaitNewCookie()
+	if err = WaitNewCookie(); err != nil {
+		return "", err
+	}
 	return r.download()
 }
 
@@ -57,11 +60,11 @@ func (r *Ncpssd) getBookId(sUrl string) (bookId string) {
 func (r *Ncpssd) download() (msg string, err error) {
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if r.dt.BookId == "" || err != nil {
-		fmt.Println(err)
-		return "requested URL was not found.", err
+		r.dt.LogErr(err)
+		return i18n.T("app.url_not_found"), err
 	}
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	bookId := r.dt.UrlParsed.Query().Get("type")
 	if bookId == "" {
 		bookId = "ncpssd"
@@ -71,10 +74,9 @@ func (r *Ncpssd) download() (msg string, err error) {
 		if !config.VolumeRange(i) {
 			continue
 		}
-		log.Printf(" %d/%d volume, %s \n", i+1, len(respVolume), vol)
+		r.dt.Logf(" %d/%d volume, %s \n", i+1, len(respVolume), vol)
 		r.do(vol)
 		util.PrintSleepTime(config.Conf.Speed)
-		fmt.Println()
 	}
 	return msg, err
 }
@@ -111,7 +113,7 @@ func (r *Ncpssd) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string,
 	} else {
 		r.dt.BookId = r.getBookId(sUrl)
 		name := fmt.Sprintf("%04d", r.dt.Index)
-		log.Printf("Get %s  %s\n", name, sUrl)
+		r.dt.Logf("Get %s  %s\n", name, sUrl)
 		dUrl, err := r.getReadUrl(r.dt.BookId)
 		if err != nil {
 			return nil, err
@@ -196,6 +198,10 @@ func (r *Ncpssd) getPdfUrl(sUrl string) string {
 }
 
 func (r *Ncpssd) getToken() (string, error) {
+	//凭据文件中已有 sign 时直接使用
+	if sign, ok := authstore.Default.For(r.dt.UrlParsed.Host).Get("sign"); ok {
+		return sign, nil
+	}
 	apiUrl := "https://" + r.dt.UrlParsed.Host + "/common/getMinioSign"
 	bs, err := r.postBody(apiUrl, nil)
 	if err != nil {
@@ -4,11 +4,11 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -39,7 +39,7 @@ func (r *NdlJP) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -55,7 +55,7 @@ func (r *NdlJP) getBookId(sUrl string) (bookId string) {
 func (r *NdlJP) download() (msg string, err error) {
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -68,13 +68,13 @@ func (r *NdlJP) download() (msg string, err error) {
 		}
 		canvases, err := r.getCanvases(iiifUrl, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
 		vid := fmt.Sprintf("%04d", i+1)
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return msg, err
@@ -85,22 +85,19 @@ func (r *NdlJP) do(imgUrls []string) (msg string, err error) {
 		return "", nil
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
-		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -116,11 +113,9 @@ func (r *NdlJP) do(imgUrls []string) (msg string, err error) {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -145,7 +140,7 @@ func (r *NdlJP) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []string, e
 	}
 	var result = new(ResponseBody)
 	if err = json.Unmarshal(bs, result); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if result.Children == nil {
@@ -166,22 +161,16 @@ func (r *NdlJP) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 	}
 	var manifest = new(iiif.ManifestResponse)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			//JPEG URL
-			imgUrl := image.Resource.Service.Id + "/" + config.Conf.Format
-			canvases = append(canvases, imgUrl)
-		}
-	}
-	return canvases, nil
+	return canvasUrls(r.dt, manifest, func(image iiif.Image) string {
+		//JPEG URL
+		return image.Resource.Service.Id + "/" + config.Conf.Format
+	}), nil
 }
 
 func (r *NdlJP) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
@@ -219,7 +208,7 @@ func (r *NdlJP) getManifestUrl(id string) (iiifUrl string, err error) {
 	}
 	var result ResponseBody
 	if err = json.Unmarshal(bs, &result); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	return result.Item.IiifManifestUrl, nil
@@ -3,10 +3,10 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/njuedu"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
@@ -38,7 +38,7 @@ func (r *Njuedu) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -53,15 +53,15 @@ func (r *Njuedu) getBookId(sUrl string) (bookId string) {
 
 func (r *Njuedu) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.typeId, err = r.getDetail(r.dt.BookId, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getDetail", err
 	}
 	respVolume, err := r.getVolumes(r.dt.BookId, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -72,10 +72,10 @@ func (r *Njuedu) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return msg, err
@@ -102,7 +102,7 @@ func (r *Njuedu) do(dziUrls []string) (msg string, err error) {
 		if FileExist(outfile) {
 			continue
 		}
-		if ret := util.StartProcess(inputUri, outfile, args); ret == true {
+		if dezoomify(inputUri, outfile, r.dt.Url, args) {
 			os.Remove(inputUri)
 		}
 		util.PrintSleepTime(config.Conf.Speed)
@@ -118,7 +118,7 @@ func (r *Njuedu) getDetail(bookId string, jar *cookiejar.Jar) (typeId int, err e
 	}
 	var result njuedu.Detail
 	if err = json.Unmarshal(bs, &result); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	for _, v := range result.Data {
@@ -138,7 +138,7 @@ func (r *Njuedu) getVolumes(bookId string, jar *cookiejar.Jar) (volumes []string
 	}
 	var result njuedu.Catalog
 	if err = json.Unmarshal(bs, &result); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	for _, d := range result.Data {
@@ -156,7 +156,7 @@ func (r *Njuedu) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string
 	}
 	var result njuedu.Response
 	if err = json.Unmarshal(bs, &result); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	for _, id := range result.Data.Images {
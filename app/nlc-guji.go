@@ -4,6 +4,8 @@ import (
 	"bookget/config"
 	"bookget/model/nlc"
 	"bookget/pkg/downloader"
+	"bookget/pkg/i18n"
+	"bookget/pkg/metrics"
 	"bookget/pkg/util"
 	"bytes"
 	"context"
@@ -11,7 +13,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -51,12 +52,17 @@ func NewNlcGuji() *NlcGuji {
 	return &NlcGuji{
 		// 初始化字段
 		dm:     dm,
-		client: &http.Client{Timeout: config.Conf.Timeout * time.Second, Jar: jar, Transport: tr},
+		client: &http.Client{Timeout: config.Conf.Timeout * time.Second, Jar: jar, Transport: metrics.Transport(tr)},
 		ctx:    ctx,
 		cancel: cancel,
 	}
 }
 
+// task 日志用的任务信息
+func (s *NlcGuji) task() *DownloadTask {
+	return &DownloadTask{Url: s.rawUrl, UrlParsed: s.parsedUrl, BookId: s.bookId}
+}
+
 func (s *NlcGuji) GetRouterInit(sUrl string) (map[string]interface{}, error) {
 	s.rawUrl = sUrl
 	s.parsedUrl, _ = url.Parse(sUrl)
@@ -142,7 +148,7 @@ func (s *NlcGuji) letsGo(canvases []nlc.DataItem) (msg string, err error) {
 		}
 		encoded := url.QueryEscape(resp.Data.FilePath)
 		imgUrl := imgServer + encoded
-		fmt.Printf("准备中 %d/%d\r", i, sizeVol)
+		jobLog(s.task()).Debug(i18n.T("app.preparing", i, sizeVol))
 		// 添加GET下载任务
 		s.dm.AddTask(
 			imgUrl,
@@ -155,7 +161,6 @@ func (s *NlcGuji) letsGo(canvases []nlc.DataItem) (msg string, err error) {
 		)
 		counter++
 	}
-	fmt.Println()
 	s.dm.SetBar(counter)
 	s.dm.Start()
 	return "", nil
@@ -194,12 +199,12 @@ func (s *NlcGuji) getBody(sUrl string) ([]byte, error) {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("close body err=%v", err)
+			s.task().Logf("close body err=%v", err)
 		}
 	}()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
-		err = fmt.Errorf("服务器返回错误状态码: %d", resp.StatusCode)
+		err = i18n.Errorf("http.status", resp.StatusCode)
 		return nil, err
 	}
 	body, err := io.ReadAll(resp.Body)
@@ -223,12 +228,12 @@ func (s *NlcGuji) postBody(sUrl string, postData []byte) ([]byte, error) {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Printf("close body err=%v", err)
+			s.task().Logf("close body err=%v", err)
 		}
 	}()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
-		err = fmt.Errorf("服务器返回错误状态码: %d", resp.StatusCode)
+		err = i18n.Errorf("http.status", resp.StatusCode)
 		return nil, err
 	}
 	body, err := io.ReadAll(resp.Body)
@@ -240,35 +245,35 @@ func (s *NlcGuji) postBody(sUrl string, postData []byte) ([]byte, error) {
 
 func (s *NlcGuji) buildCatalog(outputPath string) {
 	// 1. 获取目录结构数据
-	fmt.Println("正在获取目录结构数据...")
+	s.task().Logf("%s", i18n.T("app.catalog_fetch"))
 
 	apiUrl := fmt.Sprintf("https://%s/api/anc/ancStructureAndCatalogList?metadataId=%s", s.parsedUrl.Host, s.bookId)
 	rawData := []byte("metadataId=" + s.bookId)
 
 	structureData, err := s.postBody(apiUrl, rawData)
 	if err != nil {
-		fmt.Printf("获取目录结构失败: %v\n", err)
+		s.task().LogErr(i18n.Errorf("app.catalog_fetch_failed", err))
 		return
 	}
 
 	var structureResp nlc.StructureResponse
 	if err := json.Unmarshal(structureData, &structureResp); err != nil {
-		fmt.Printf("解析目录结构失败: %v\n", err)
+		s.task().LogErr(i18n.Errorf("app.catalog_parse_failed", err))
 		return
 	}
 
 	// 2. 获取页码映射数据
-	fmt.Println("正在获取页码映射数据...")
+	s.task().Logf("%s", i18n.T("app.pagemap_fetch"))
 	apiUrl = fmt.Sprintf("https://%s/api/anc/ancImageIdListWithPageNum?metadataId=%s", s.parsedUrl.Host, s.bookId)
 	s.responseBody, err = s.postBody(apiUrl, rawData)
 	if err != nil {
-		fmt.Printf("获取页码映射失败: %v\n", err)
+		s.task().LogErr(i18n.Errorf("app.pagemap_fetch_failed", err))
 		return
 	}
 
 	var pageResp nlc.PageResponse
 	if err := json.Unmarshal(s.responseBody, &pageResp); err != nil {
-		fmt.Printf("解析页码映射失败: %v\n", err)
+		s.task().LogErr(i18n.Errorf("app.pagemap_parse_failed", err))
 		return
 	}
 
@@ -288,7 +293,7 @@ func (s *NlcGuji) buildCatalog(outputPath string) {
 		idToPage[imageID] = pageNum
 	}
 
-	fmt.Printf("获取到 %d 条页码映射数据\n", len(idToPage))
+	s.task().Logf("%s", i18n.T("app.pagemap_done", len(idToPage)))
 
 	// 生成目录
 	catalog := []string{config.CatalogVersionInfo}
@@ -301,12 +306,11 @@ func (s *NlcGuji) buildCatalog(outputPath string) {
 	// 保存到文件
 	content := strings.Join(catalog, "\n")
 	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
-		fmt.Printf("保存文件失败: %v\n", err)
+		s.task().LogErr(i18n.Errorf("app.catalog_save_failed", err))
 		return
 	}
 
-	fmt.Printf("目录已成功保存到 %s\n", outputPath)
-	fmt.Printf("共生成 %d 条目录项）\n", len(catalog)-1)
+	s.task().Logf("%s", i18n.T("app.catalog_saved", outputPath, len(catalog)-1))
 }
 
 func processItem(item *nlc.CatalogItem, idToPage map[int]string, catalog *[]string, prefix string) {
@@ -317,11 +321,11 @@ func processItem(item *nlc.CatalogItem, idToPage map[int]string, catalog *[]stri
 	// 获取imageID
 	imageID, err := util.ToInt(item.ImageIDs[0])
 	if err != nil {
-		*catalog = append(*catalog, fmt.Sprintf("%s%s ………… 未知", prefix, strings.TrimSpace(item.Title)))
+		*catalog = append(*catalog, fmt.Sprintf("%s%s ………… %s", prefix, strings.TrimSpace(item.Title), i18n.T("app.catalog_unknown_page")))
 	} else {
 		pageNum, exists := idToPage[imageID]
 		if !exists {
-			pageNum = "未知"
+			pageNum = i18n.T("app.catalog_unknown_page")
 		}
 		*catalog = append(*catalog, fmt.Sprintf("%s%s ………… %s", prefix, strings.TrimSpace(item.Title), pageNum))
 	}
@@ -4,12 +4,13 @@ import (
 	"bookget/config"
 	"bookget/pkg/downloader"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/metrics"
 	"bookget/pkg/util"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -53,13 +54,18 @@ func NewChinaNlc() *ChinaNlc {
 	return &ChinaNlc{
 		// 初始化字段
 		dm:     dm,
-		client: &http.Client{Timeout: config.Conf.Timeout * time.Second, Jar: jar, Transport: tr},
+		client: &http.Client{Timeout: config.Conf.Timeout * time.Second, Jar: jar, Transport: metrics.Transport(tr)},
 		ctx:    ctx,
 		cancel: cancel,
 		jar:    jar,
 	}
 }
 
+// task 日志用的任务信息
+func (r *ChinaNlc) task() *DownloadTask {
+	return &DownloadTask{Url: r.rawUrl, UrlParsed: r.parsedUrl, BookId: r.bookId}
+}
+
 func (r *ChinaNlc) GetRouterInit(sUrl string) (map[string]interface{}, error) {
 	r.rawUrl = sUrl
 	r.parsedUrl, _ = url.Parse(sUrl)
@@ -79,7 +85,7 @@ func (r *ChinaNlc) Run() (msg string, err error) {
 		r.bookId = r.getBookId(r.rawUrl)
 	}
 	if r.bookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	return r.download()
 }
@@ -122,7 +128,7 @@ func (r *ChinaNlc) download() (msg string, err error) {
 		if err != nil || canvases == nil {
 			return "", err
 		}
-		log.Printf("  %d pages \n", len(canvases))
+		r.task().Logf("  %d pages \n", len(canvases))
 		r.do(canvases)
 		return "", err
 	}
@@ -144,7 +150,7 @@ func (r *ChinaNlc) download() (msg string, err error) {
 	//多册/多图
 	err = r.downloadForPDFs()
 	if err != nil {
-		fmt.Println(err)
+		r.task().LogErr(err)
 		return "getVolumes", err
 	}
 	//矢量多册PDF
@@ -156,7 +162,6 @@ func (r *ChinaNlc) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := url.QueryEscape(r.rawUrl)
 	size := len(imgUrls)
 	var wg sync.WaitGroup
@@ -172,7 +177,7 @@ func (r *ChinaNlc) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, imgUrl)
+		r.task().Logf("Get %d/%d, URL: %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -189,11 +194,9 @@ func (r *ChinaNlc) do(imgUrls []string) (msg string, err error) {
 			}
 			gohttp.FastGet(r.ctx, imgUrl, opts)
 			util.PrintSleepTime(config.Conf.Speed)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -214,15 +217,15 @@ func (r *ChinaNlc) downloadForPDFs() error {
 			r.savePath = CreateDirectory(r.parsedUrl.Host, r.bookId, vid)
 			canvases, err := r.getCanvases()
 			if err != nil || canvases == nil {
-				fmt.Println(err)
+				r.task().LogErr(err)
 				continue
 			}
-			log.Printf(" %d/%d volume, %d pages \n", i+1, size, len(canvases))
+			r.task().Logf(" %d/%d volume, %d pages \n", i+1, size, len(canvases))
 			r.do(canvases)
 		} else {
 			//PDF
 			r.savePath = CreateDirectory(r.parsedUrl.Host, r.bookId, "")
-			log.Printf("Get %d/%d volume, URL: %s\n", i+1, size, vol)
+			r.task().Logf("Get %d/%d volume, URL: %s\n", i+1, size, vol)
 			filename := vid + ".pdf"
 			r.doPdfUrl(vol, filename)
 		}
@@ -240,7 +243,7 @@ func (r *ChinaNlc) downloadForOCR() {
 		}
 		vid := fmt.Sprintf("%04d", i+1)
 		r.savePath = CreateDirectory(r.parsedUrl.Host, r.bookId, "ocr")
-		log.Printf("Get %d/%d volume, URL: %s\n", i+1, len(r.vectorBooks), vol)
+		r.task().Logf("Get %d/%d volume, URL: %s\n", i+1, len(r.vectorBooks), vol)
 		filename := vid + ".pdf"
 		r.doPdfUrl(vol, filename)
 	}
@@ -311,10 +314,9 @@ func (r *ChinaNlc) doPdfUrl(sUrl, filename string) error {
 	}
 	resp, err := gohttp.FastGet(r.ctx, pdfUrl, opts)
 	if err != nil || resp.GetStatusCode() != 200 {
-		fmt.Println(err)
+		r.task().LogErr(err)
 	}
 	util.PrintSleepTime(config.Conf.Speed)
-	fmt.Println()
 	return err
 }
 
@@ -388,7 +390,7 @@ func (r *ChinaNlc) getBody(apiUrl string) ([]byte, error) {
 func (r *ChinaNlc) getToken(uri string) (tokenKey, timeKey, timeFlag string) {
 	body, err := r.getBody(uri)
 	if err != nil {
-		log.Printf("Server unavailable: %s", err.Error())
+		r.task().Logf("Server unavailable: %s", err.Error())
 		return
 	}
 	//<iframe id="myframe" name="myframe" src="" width="100%" height="100%" scrolling="no" frameborder="0" tokenKey="4ADAD4B379874C10864990817734A2BA" timeKey="1648363906519" timeFlag="1648363906519" sflag=""></iframe>
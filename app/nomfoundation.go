@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -43,7 +43,7 @@ func (r *Nomfoundation) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -62,13 +62,13 @@ func (r *Nomfoundation) getBookId(sUrl string) (bookId string) {
 
 func (r *Nomfoundation) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
 	if err != nil || canvases == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
-	log.Printf(" %d pages \n", len(canvases))
+	r.dt.Logf(" %d pages \n", len(canvases))
 	return r.do(canvases)
 }
 
@@ -76,9 +76,9 @@ func (r *Nomfoundation) do(canvases []string) (msg string, err error) {
 	if canvases == nil {
 		return
 	}
-	fmt.Println()
 	referer := r.dt.Url
 	size := len(canvases)
+	recordPages(r.dt, canvases, config.Conf.FileExt)
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range canvases {
@@ -92,7 +92,7 @@ func (r *Nomfoundation) do(canvases []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d, URL: %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -110,11 +110,9 @@ func (r *Nomfoundation) do(canvases []string) (msg string, err error) {
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
 			util.PrintSleepTime(config.Conf.Speed)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -3,15 +3,16 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/ouroots"
+	"bookget/pkg/events"
 	"bookget/pkg/gohttp"
-	"bookget/pkg/progressbar"
+	"bookget/pkg/i18n"
+	"bookget/pkg/progress"
 	"bookget/pkg/util"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
@@ -24,7 +25,7 @@ import (
 type Ouroots struct {
 	dt      *DownloadTask
 	Counter int
-	bar     *progressbar.ProgressBar
+	job     *progress.Job
 }
 
 func NewOuroots() *Ouroots {
@@ -48,7 +49,7 @@ func (r *Ouroots) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -64,11 +65,11 @@ func (r *Ouroots) getBookId(sUrl string) (bookId string) {
 
 func (r *Ouroots) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.BookId)
 	if err != nil || respVolume.StatusCode != "200" {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	//不按卷下载，所有图片存一个目录
@@ -80,21 +81,20 @@ func (r *Ouroots) download() (msg string, err error) {
 		}
 		macCounter += vol.Pages
 	}
-	fmt.Println()
-	r.bar = progressbar.Default(int64(macCounter), "downloading")
+	r.job = progress.Default.Start(r.dt.BookId, progress.Pages, int64(macCounter))
 	for i, vol := range respVolume.Volume {
 		if !config.VolumeRange(i) {
 			continue
 		}
 		r.do(vol.Pages, vol.VolumeId)
 	}
+	r.job.Done()
 	return "", nil
 }
 
 func (r *Ouroots) do(pageTotal int, volumeId int) (msg string, err error) {
 	token, err := r.getToken()
 	if err != nil {
-		r.bar.Clear()
 		return "token not found.", err
 	}
 	for i := 1; i <= pageTotal; i++ {
@@ -102,25 +102,38 @@ func (r *Ouroots) do(pageTotal int, volumeId int) (msg string, err error) {
 		dest := r.dt.SavePath + sortId
 		if util.FileExist(dest) {
 			r.Counter++
-			r.bar.Add(1)
+			r.job.Add(1)
 			time.Sleep(40 * time.Millisecond)
 			continue
 		}
+		start := time.Now()
+		events.Emit(events.Event{Type: events.PageQueued, Url: r.dt.Url, Path: dest})
 		respImage, err := r.getBase64Image(r.dt.BookId, volumeId, i, "", token)
-		if err != nil || respImage.StatusCode != "200" {
+		if err == nil && respImage.StatusCode != "200" {
+			err = fmt.Errorf("status %s", respImage.StatusCode)
+		}
+		if err != nil {
+			events.Page(r.dt.Url, dest, start, err)
 			continue
 		}
 		if pos := strings.Index(respImage.ImagePath, "data:image/jpeg;base64,"); pos != -1 {
 			data := respImage.ImagePath[pos+len("data:image/jpeg;base64,"):]
 			bs, err := base64.StdEncoding.DecodeString(data)
 			if err != nil || bs == nil {
-				//log.Println(err)
+				//r.dt.LogErr(err)
+				if err == nil {
+					err = errors.New("empty image")
+				}
+				events.Page(r.dt.Url, dest, start, err)
 				continue
 			}
-			_ = os.WriteFile(dest, bs, os.ModePerm)
+			err = os.WriteFile(dest, bs, os.ModePerm)
+			events.Page(r.dt.Url, dest, start, err)
 			r.Counter++
-			r.bar.Add(1)
+			r.job.Add(1)
 			time.Sleep(40 * time.Millisecond)
+		} else {
+			events.Page(r.dt.Url, dest, start, errors.New("unexpected image data"))
 		}
 	}
 	return "", nil
@@ -147,7 +160,7 @@ func (r *Ouroots) getVolumes(catalogKey string) (ouroots.ResponseVolume, error)
 
 	var respVolume ouroots.ResponseVolume
 	if err = json.Unmarshal(bs, &respVolume); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return respVolume, errors.New(resp.GetReasonPhrase())
 	}
 	return respVolume, nil
@@ -4,11 +4,11 @@ import (
 	"bookget/config"
 	"bookget/model/princeton"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -40,7 +40,7 @@ func (r *Princeton) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -56,11 +56,11 @@ func (r *Princeton) getBookId(sUrl string) (bookId string) {
 
 func (r *Princeton) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -71,10 +71,10 @@ func (r *Princeton) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -84,9 +84,9 @@ func (r *Princeton) do(canvases []string) (msg string, err error) {
 	if canvases == nil {
 		return
 	}
-	fmt.Println()
 	referer := r.dt.Url
 	size := len(canvases)
+	recordPages(r.dt, canvases, config.Conf.FileExt)
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range canvases {
@@ -100,7 +100,7 @@ func (r *Princeton) do(canvases []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d page, URL: %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d page, URL: %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -117,11 +117,9 @@ func (r *Princeton) do(canvases []string) (msg string, err error) {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -153,7 +151,7 @@ func (r *Princeton) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []strin
 
 		}
 		if err = json.Unmarshal(bs, phql); err != nil {
-			log.Printf("json.Unmarshal failed: %s\n", err)
+			r.dt.Logf("json.Unmarshal failed: %s\n", err)
 			return nil, err
 		}
 		for _, v := range phql.Data.ResourcesByOrangelightIds {
@@ -172,7 +170,7 @@ func (r *Princeton) getVolumes(sUrl string, jar *cookiejar.Jar) (volumes []strin
 		return
 	}
 	if err = json.Unmarshal(body, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 
@@ -194,7 +192,7 @@ func (r *Princeton) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []str
 		return
 	}
 	if err = json.Unmarshal(body, manifest2); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	i := len(manifest2.Sequences[0].Canvases)
@@ -1,10 +1,30 @@
 package app
 
+import (
+	"bookget/pkg/metrics"
+	"sync/atomic"
+)
+
 type QueueLimit struct {
 	n int
 	c chan interface{}
 }
 
+// pageQueues 所有 QueueLimit 中等待与执行中的任务数，以 queue="pages" 输出到 --metrics
+var pageQueues queueDepth
+
+type queueDepth struct {
+	pending atomic.Int64
+	running atomic.Int64
+}
+
+func (d *queueDepth) Pending() int      { return int(d.pending.Load()) }
+func (d *queueDepth) CurrentCount() int { return int(d.running.Load()) }
+
+func init() {
+	metrics.RegisterQueue("pages", &pageQueues)
+}
+
 func QueueNew(n int) *QueueLimit {
 	return &QueueLimit{
 		n: n,
@@ -12,9 +32,13 @@ func QueueNew(n int) *QueueLimit {
 	}
 }
 func (q *QueueLimit) Go(f func()) {
+	pageQueues.pending.Add(1)
 	q.c <- 0
+	pageQueues.pending.Add(-1)
+	pageQueues.running.Add(1)
 	go func() {
 		f()
+		pageQueues.running.Add(-1)
 		<-q.c
 	}()
 }
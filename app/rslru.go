@@ -3,12 +3,14 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/rslru"
+	"bookget/pkg/events"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -16,6 +18,7 @@ import (
 	"regexp"
 	"strconv"
 	"sync"
+	"time"
 )
 
 type RslRu struct {
@@ -45,7 +48,7 @@ func (r *RslRu) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -60,19 +63,19 @@ func (r *RslRu) getBookId(sUrl string) (bookId string) {
 }
 
 func (r *RslRu) download() (msg string, err error) {
-	log.Printf("Get  %s\n", r.dt.Url)
+	r.dt.Logf("Get  %s\n", r.dt.Url)
 
 	r.response, err = r.getJsonResponse()
 	if err != nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	vid := regexp.MustCompile(`([\\/:：；\s]+)`).ReplaceAllString(r.response.Description.Title, "")
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 	canvases, err := r.getCanvases(r.dt.Url, r.dt.Jar)
 	if err != nil || canvases == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
-	log.Printf(" %d pages \n", len(canvases))
+	r.dt.Logf(" %d pages \n", len(canvases))
 	return r.do(canvases)
 }
 
@@ -80,9 +83,9 @@ func (r *RslRu) do(canvases []string) (msg string, err error) {
 	if canvases == nil {
 		return
 	}
-	fmt.Println()
 	//referer := r.dt.Url
 	size := len(canvases)
+	recordPages(r.dt, canvases, config.Conf.FileExt)
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range canvases {
@@ -96,10 +99,14 @@ func (r *RslRu) do(canvases []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		log.Printf("Get %d/%d page, URL: %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d page, URL: %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
+			var err error
+			start := time.Now()
+			events.Emit(events.Event{Type: events.PageQueued, Url: imgUrl, Path: dest})
+			defer func() { events.Page(imgUrl, dest, start, err) }()
 			ctx := context.Background()
 			cli := gohttp.NewClient(ctx, gohttp.Options{
 				CookieFile: config.Conf.CookieFile,
@@ -115,13 +122,13 @@ func (r *RslRu) do(canvases []string) (msg string, err error) {
 			bs, _ := resp.GetBody()
 			length, _ := strconv.Atoi(resp.GetHeaderLine("Content-Length"))
 			if bs == nil || length != len(bs) {
+				err = io.ErrUnexpectedEOF
 				return
 			}
-			os.WriteFile(dest, bs, os.ModePerm)
+			err = os.WriteFile(dest, bs, os.ModePerm)
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -145,7 +152,7 @@ func (r *RslRu) getJsonResponse() (resp *rslru.Response, err error) {
 		return
 	}
 	if err = json.Unmarshal(bs, resp); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 	}
 	return resp, err
 }
@@ -1,8 +1,8 @@
 package app
 
 import (
+	"bookget/pkg/i18n"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -34,7 +34,7 @@ func (r *Sammlungen) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -50,7 +50,7 @@ func (r *Sammlungen) getBookId(sUrl string) (bookId string) {
 
 func (r *Sammlungen) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	manifestUrl := fmt.Sprintf("https://api.digitale-sammlungen.de/iiif/presentation/v2/%s/manifest", r.dt.BookId)
 	var iiif IIIF
 	return iiif.InitWithId(r.dt.Index, manifestUrl, r.dt.BookId)
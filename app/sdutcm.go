@@ -4,12 +4,14 @@ import (
 	"bookget/config"
 	"bookget/model/sdutcm"
 	"bookget/pkg/crypt"
+	"bookget/pkg/dedup"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -42,10 +44,12 @@ func (r *Sdutcm) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
-	WaitNewCookie()
+	if err = WaitNewCookie(); err != nil {
+		return "", err
+	}
 	return r.download()
 }
 
@@ -59,14 +63,14 @@ func (r *Sdutcm) getBookId(sUrl string) (bookId string) {
 
 func (r *Sdutcm) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.body, err = r.getPageContent(r.dt.Url)
 	if err != nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	config.Conf.FileExt = ".pdf"
@@ -78,19 +82,19 @@ func (r *Sdutcm) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
 }
 
 func (r *Sdutcm) do(imgUrls []string) (msg string, err error) {
-	fmt.Println()
 	referer := r.dt.Url
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if uri == "" || !config.PageRange(i, size) {
@@ -102,7 +106,7 @@ func (r *Sdutcm) do(imgUrls []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d,  URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d,  URL: %s\n", i+1, size, uri)
 
 		bs, err := getBody(uri, r.dt.Jar)
 		var respBody sdutcm.PagePicTxt
@@ -127,12 +131,16 @@ func (r *Sdutcm) do(imgUrls []string) (msg string, err error) {
 			if err == nil && resp.GetStatusCode() == 200 {
 				break
 			}
-			WaitNewCookieWithMsg(uri)
+			//重复页、占位图被拒收（--dedup requeue）不是 cookie 失效
+			if errors.Is(err, dedup.ErrSuspect) {
+				break
+			}
+			if err = WaitNewCookieWithMsg(uri); err != nil {
+				return "", err
+			}
 		}
 		util.PrintSleepTime(config.Conf.Speed)
-		fmt.Println()
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/search"
+	"context"
+	"fmt"
+)
+
+// Search 在站点 site 上检索 query，返回第 page 页（从 1 开始）的结果
+func Search(site, query string, page int) ([]search.Result, error) {
+	a, ok := search.Lookup(site)
+	if !ok {
+		return nil, i18n.Errorf("cmd.search_unsupported", site)
+	}
+	req := a.Request(query, page)
+	opts := gohttp.Options{
+		Retry:      config.Conf.Retry,
+		CookieFile: config.Conf.CookieFile,
+		Headers: map[string]interface{}{
+			"User-Agent": config.Conf.UserAgent,
+		},
+	}
+	var (
+		resp *gohttp.Response
+		err  error
+	)
+	if req.Body != nil {
+		opts.Body = req.Body
+		opts.Headers["Content-Type"] = req.ContentType
+		resp, err = gohttp.Post(context.Background(), req.Url, opts)
+	} else {
+		resp, err = gohttp.Get(context.Background(), req.Url, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bs, _ := resp.GetBody()
+	if resp.GetStatusCode() != 200 || bs == nil {
+		return nil, fmt.Errorf("%s: ErrCode:%d, %s", req.Url, resp.GetStatusCode(), resp.GetReasonPhrase())
+	}
+	return a.Parse(bs)
+}
@@ -4,12 +4,11 @@ import (
 	"bookget/config"
 	"bookget/model/iiif"
 	"bookget/pkg/gohttp"
-	"bookget/pkg/util"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -45,7 +44,7 @@ func (r *SiEdu) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -74,14 +73,14 @@ func (r *SiEdu) getBookId(sUrl string) (bookId string) {
 
 func (r *SiEdu) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	apiUrl := "https://ids.si.edu/ids/manifest/" + r.dt.BookId
 	canvases, err := r.getCanvases(apiUrl, r.dt.Jar)
 	if err != nil || canvases == nil {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
-	log.Printf(" %d images \n", len(canvases))
+	r.dt.Logf(" %d images \n", len(canvases))
 	return r.do(canvases)
 }
 
@@ -96,12 +95,12 @@ func (r *SiEdu) do(iiifUrls []string) (msg string, err error) {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(iiifUrls)
+	recordPages(r.dt, iiifUrls, config.Conf.FileExt)
 	for i, uri := range iiifUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
 		}
 		sortId := fmt.Sprintf("%04d", i+1)
-		filename := sortId + config.Conf.FileExt
 		inputUri := r.dt.SavePath + sortId + "_info.json"
 		bs, err := r.getBody(uri, r.dt.Jar)
 		if err != nil {
@@ -110,12 +109,12 @@ func (r *SiEdu) do(iiifUrls []string) (msg string, err error) {
 		body := strings.Replace(string(bs), `"http://iiif.io/api/image/2/level2.json",`, "", -1)
 		body = strings.Replace(body, `"sizeByH",`, "", -1)
 		os.WriteFile(inputUri, []byte(body), os.ModePerm)
-		dest := r.dt.SavePath + filename
+		dest := pageDest(r.dt, i, config.Conf.FileExt)
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %s  %s\n", sortId, uri)
-		if ret := util.StartProcess(inputUri, dest, args); ret == true {
+		r.dt.Logf("Get %s  %s\n", sortId, uri)
+		if dezoomify(inputUri, dest, uri, args) {
 			os.Remove(inputUri)
 		}
 	}
@@ -129,21 +128,15 @@ func (r *SiEdu) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 		return nil, err
 	}
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
 		return
 	}
-	size := len(manifest.Sequences[0].Canvases)
-	canvases = make([]string, 0, size)
-	for _, canvase := range manifest.Sequences[0].Canvases {
-		for _, image := range canvase.Images {
-			iiiInfo := fmt.Sprintf("%s/info.json", image.Resource.Service.Id)
-			canvases = append(canvases, iiiInfo)
-		}
-	}
-	return canvases, nil
+	return canvasUrls(r.dt, manifest, func(image iiif.Image) string {
+		return image.Resource.Service.Id + "/info.json"
+	}), nil
 }
 
 func (r *SiEdu) getBody(apiUrl string, jar *cookiejar.Jar) ([]byte, error) {
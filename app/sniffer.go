@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/sniffer"
+	"bookget/pkg/util"
+	"context"
+	"fmt"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// Sniffer 不认识的网站：分析网页中的查看器（OpenSeadragon、Zoomify、IIPImage、pdf.js、IIIF）或图片列表，
+// 把找到的资源交给对应的下载方式
+type Sniffer struct {
+	dt *DownloadTask
+}
+
+func NewSniffer() *Sniffer {
+	return &Sniffer{
+		// 初始化字段
+		dt: new(DownloadTask),
+	}
+}
+
+func (s *Sniffer) GetRouterInit(sUrl string) (map[string]interface{}, error) {
+	msg, err := s.Run(sUrl)
+	return map[string]interface{}{
+		"type": "sniffer",
+		"url":  sUrl,
+		"msg":  msg,
+	}, err
+}
+
+// Run 先按 IIIF manifest 检测，找不到 manifest 时再分析网页
+func (s *Sniffer) Run(sUrl string) (msg string, err error) {
+	var iiif IIIF
+	return iiif.AutoDetectManifest(s.dt.Index, sUrl)
+}
+
+// analyze 分析已取得的网页 bs，由 IIIF.AutoDetectManifest 在找不到 manifest 时调用
+func (s *Sniffer) analyze(iTask int, sUrl string, bs []byte) (msg string, err error) {
+	s.dt = new(DownloadTask)
+	s.dt.Index = iTask
+	s.dt.Url = sUrl
+	if s.dt.UrlParsed, err = url.Parse(sUrl); err != nil {
+		return "", err
+	}
+	s.dt.Jar, _ = cookiejar.New(nil)
+	s.dt.BookId = getBookId(sUrl)
+
+	finds := sniffer.Analyze(sUrl, bs)
+	if len(finds) == 0 {
+		return "", i18n.Errorf("sniff.none", sUrl)
+	}
+	for _, kind := range []sniffer.Kind{sniffer.Manifest, sniffer.IIIFImage, sniffer.DZI, sniffer.Zoomify, sniffer.IIP, sniffer.PDF, sniffer.Image} {
+		if n := len(sniffer.Of(finds, kind)); n > 0 {
+			s.dt.Logf("%s", i18n.T("sniff.found", n, kind))
+		}
+	}
+
+	//有 manifest 时只下载 manifest，页面上的其他查看器一般是同一本书
+	if manifests := sniffer.Of(finds, sniffer.Manifest); len(manifests) > 0 {
+		var iiif IIIF
+		var failed error
+		for k, m := range manifests {
+			if _, err = iiif.runManifest(iTask+k, m); err != nil {
+				s.dt.LogErr(err)
+				failed = err
+			}
+		}
+		return "", failed
+	}
+
+	//各类资源都从 0001 编号，找到多类时分别保存到子目录，避免互相覆盖
+	tiled := append(sniffer.Of(finds, sniffer.Zoomify), sniffer.Of(finds, sniffer.IIP)...)
+	groups := 0
+	for _, n := range []int{len(sniffer.Of(finds, sniffer.IIIFImage)), len(sniffer.Of(finds, sniffer.DZI)), len(tiled), len(sniffer.Of(finds, sniffer.Image))} {
+		if n > 0 {
+			groups++
+		}
+	}
+	s.dt.SavePath = CreateDirectory(s.dt.UrlParsed.Host, s.dt.BookId, "")
+	bookDir := s.dt.SavePath
+	saveTo := func(kind sniffer.Kind) {
+		s.dt.SavePath = bookDir
+		if groups > 1 {
+			s.dt.SavePath = CreateDirectory(s.dt.UrlParsed.Host, s.dt.BookId, string(kind))
+		}
+	}
+
+	if infos := sniffer.Of(finds, sniffer.IIIFImage); len(infos) > 0 {
+		saveTo(sniffer.IIIFImage)
+		i := IIIF{dt: s.dt}
+		if config.Conf.UseDziRs {
+			i.doDezoomifyRs(infos)
+		} else {
+			imgUrls := make([]string, 0, len(infos))
+			for _, info := range infos {
+				imgUrls = append(imgUrls, strings.TrimSuffix(info, "/info.json")+"/"+config.Conf.Format)
+			}
+			i.doNormal(imgUrls)
+		}
+	}
+	if dzis := sniffer.Of(finds, sniffer.DZI); len(dzis) > 0 {
+		saveTo(sniffer.DZI)
+		s.dezoomify(dzis, "deepzoom")
+	}
+	if len(tiled) > 0 {
+		saveTo(sniffer.Zoomify)
+		t := Tiles{dt: s.dt}
+		_ = t.do(tiled)
+	}
+	s.dt.SavePath = bookDir
+	for _, pdfUrl := range sniffer.Of(finds, sniffer.PDF) {
+		s.downloadPdf(pdfUrl)
+	}
+	if imgUrls := sniffer.Of(finds, sniffer.Image); len(imgUrls) > 0 {
+		saveTo(sniffer.Image)
+		i := IIIF{dt: s.dt}
+		i.doNormal(imgUrls)
+	}
+	return "", s.dt.Err()
+}
+
+// dezoomify 用 dezoomify-rs 下载并拼接切片图，dezoomer 如 deepzoom
+func (s *Sniffer) dezoomify(tileUrls []string, dezoomer string) {
+	if tileUrls == nil {
+		return
+	}
+	referer := url.QueryEscape(s.dt.Url)
+	args := []string{"--dezoomer=" + dezoomer,
+		"-H", "Origin:" + referer,
+		"-H", "Referer:" + referer,
+		"-H", "User-Agent:" + config.Conf.UserAgent,
+	}
+	size := len(tileUrls)
+	for k, uri := range tileUrls {
+		if !config.PageRange(k, size) {
+			continue
+		}
+		dest := pageDest(s.dt, k, config.Conf.FileExt)
+		if FileExist(dest) {
+			continue
+		}
+		jobLog(s.dt).Info(fmt.Sprintf("Get %d/%d  %s", k+1, size, uri), "page", k+1)
+		if !dezoomify(uri, dest, uri, args) {
+			s.dt.PageFailed(errDezoomify)
+		}
+		util.PrintSleepTime(config.Conf.Speed)
+	}
+}
+
+func (s *Sniffer) downloadPdf(pdfUrl string) {
+	dest := s.dt.SavePath + util.FileName(pdfUrl)
+	if FileExist(dest) {
+		return
+	}
+	jobLog(s.dt).Info(fmt.Sprintf("Get %s", pdfUrl))
+	opts := gohttp.Options{
+		DestFile:    dest,
+		Overwrite:   false,
+		Concurrency: 1,
+		CookieFile:  config.Conf.CookieFile,
+		CookieJar:   s.dt.Jar,
+		Headers: map[string]interface{}{
+			"User-Agent": config.Conf.UserAgent,
+			"Referer":    s.dt.Url,
+		},
+	}
+	if _, err := gohttp.FastGet(context.Background(), pdfUrl, opts); err != nil {
+		s.dt.PageFailed(err)
+	}
+	util.PrintSleepTime(config.Conf.Speed)
+}
@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/szLib"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -41,7 +41,7 @@ func (r *SzLib) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -57,11 +57,11 @@ func (r *SzLib) getBookId(sUrl string) (bookId string) {
 
 func (r *SzLib) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume.Volumes)
@@ -69,7 +69,7 @@ func (r *SzLib) download() (msg string, err error) {
 		if !config.VolumeRange(i) {
 			continue
 		}
-		fmt.Printf("\r Test volume %d ... ", i+1)
+		jobLog(r.dt).Debug("test volume", "volume", i+1)
 		if sizeVol == 1 {
 			r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 		} else {
@@ -79,11 +79,10 @@ func (r *SzLib) download() (msg string, err error) {
 
 		canvases, err := r.getCanvases(vol)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		fmt.Println()
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -91,10 +90,10 @@ func (r *SzLib) download() (msg string, err error) {
 
 func (r *SzLib) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
-		return "图片URLs为空", errors.New("imgUrls is nil")
+		return i18n.T("app.img_urls_empty"), errors.New("imgUrls is nil")
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -109,8 +108,7 @@ func (r *SzLib) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -126,11 +124,9 @@ func (r *SzLib) do(imgUrls []string) (msg string, err error) {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", nil
 }
 
@@ -142,7 +138,7 @@ func (r *SzLib) getVolumes(sUrl string) (*szLib.ResultVolumes, error) {
 	}
 	var rstVolumes = new(szLib.ResultVolumes)
 	if err = json.Unmarshal(bs, rstVolumes); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return nil, err
 	}
 	return rstVolumes, err
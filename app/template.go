@@ -2,20 +2,33 @@ package app
 
 import (
 	"bookget/config"
+	"bookget/model/iiif"
+	"bookget/pkg/authflow"
+	"bookget/pkg/authstore"
+	"bookget/pkg/cookies"
+	"bookget/pkg/events"
 	"bookget/pkg/gohttp"
 	xhash "bookget/pkg/hash"
+	"bookget/pkg/i18n"
+	"bookget/pkg/logging"
+	"bookget/pkg/metrics"
 	"bookget/pkg/util"
+	"bookget/pkg/verify"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"log/slog"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
-	"sync"
 	"time"
+	"unicode"
 )
 
 type Downloader interface {
@@ -39,6 +52,8 @@ type DownloadTask struct {
 	VolumeId  string
 	Param     map[string]interface{} //备用参数
 	Jar       *cookiejar.Jar
+	Labels    []string //页码标签，与图片URL一一对应（IIIF canvas label、叶码等），可为空
+	Failed    int      //下载失败的页数
 }
 
 type Volume struct {
@@ -133,6 +148,10 @@ func FileExist(path string) bool {
 	if err == nil && fi.Size() > 0 {
 		return true
 	}
+	//页面已被图像处理替换（如拆分为 0001_a.jpg、0001_b.jpg）
+	if errors.Is(err, os.ErrNotExist) && verify.IsPageFile(path) {
+		return verify.IsProcessed(path)
+	}
 	return false
 }
 
@@ -144,73 +163,246 @@ func CreateDirectory(domain, bookId, volumeId string) string {
 		dirPath += "vol." + volumeId + string(os.PathSeparator)
 	}
 	_ = os.MkdirAll(dirPath, os.ModePerm)
+	if _, loaded := bookDirs.LoadOrStore(filepath.Clean(dirPath), domain); !loaded {
+		events.Emit(events.Event{Type: events.VolumeResolved, Site: domain, BookId: bookId, Volume: volumeId, Dir: dirPath})
+	}
 	return dirPath
 }
 
-func OpenWebBrowser(sUrl string, args []string) {
-	var wg sync.WaitGroup
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		argv := []string{sUrl}
-		if args != nil {
-			argv = append(argv, args...)
+var errDezoomify = errors.New("dezoomify-rs failed")
+
+// dezoomify 用 dezoomify-rs 下载一页（input 为 IIIF info.json、DZI 等的地址或本地文件，src 为来源 URL），
+// 与 gohttp 下载的页面一样输出 page_* 事件并触发 AfterSave
+func dezoomify(input, dest, src string, args []string) bool {
+	start := time.Now()
+	events.Emit(events.Event{Type: events.PageQueued, Url: src, Path: dest})
+	err := errDezoomify
+	if util.StartProcess(input, dest, args) && FileExist(dest) {
+		err = gohttp.NotifySaved(dest, src)
+	}
+	events.Page(src, dest, start, err)
+	return err == nil
+}
+
+// jobLog 带 site、bookId、volume 字段的 logger
+func jobLog(dt *DownloadTask) *slog.Logger {
+	if dt == nil {
+		return slog.Default()
+	}
+	site := ""
+	if dt.UrlParsed != nil {
+		site = dt.UrlParsed.Host
+	}
+	l := logging.Job(site, dt.BookId)
+	if dt.VolumeId != "" {
+		l = l.With("volume", dt.VolumeId)
+	}
+	return l
+}
+
+// Logf 输出带任务字段的 INFO 日志，受 --log-level/--quiet 控制
+func (dt *DownloadTask) Logf(format string, a ...interface{}) {
+	jobLog(dt).Info(strings.TrimSpace(fmt.Sprintf(format, a...)))
+}
+
+// PageFailed 记录一页下载失败
+func (dt *DownloadTask) PageFailed(err error) {
+	dt.LogErr(err)
+	dt.Failed++
+}
+
+// Err 有页面下载失败时返回错误，批量任务据此保留为待重试
+func (dt *DownloadTask) Err() error {
+	if dt.Failed > 0 {
+		return i18n.Errorf("app.pages_failed", dt.Failed)
+	}
+	return nil
+}
+
+// LogErr 输出带任务字段的 ERROR 日志，err 为 nil 时不输出
+func (dt *DownloadTask) LogErr(err error) {
+	if err != nil {
+		jobLog(dt).Error(err.Error())
+	}
+}
+
+// pageLabel 第 i 页（从0开始）的页码标签
+func pageLabel(dt *DownloadTask, i int) string {
+	if i < 0 || i >= len(dt.Labels) {
+		return ""
+	}
+	return strings.TrimSpace(dt.Labels[i])
+}
+
+// pageFileName 第 i 页（从0开始）的文件名。--page-names=label 且有页码标签时为 0001_f001r.jpg，
+// 序号前缀保证文件仍按顺序排列
+func pageFileName(dt *DownloadTask, i int, ext string) string {
+	name := fmt.Sprintf("%04d", i+1)
+	if config.Conf.PageNames == "label" {
+		if label := sanitizeLabel(pageLabel(dt, i)); label != "" {
+			name += "_" + label
 		}
-		if ret := util.OpenGUI(argv); ret == true {
-			return
+	}
+	return name + ext
+}
+
+// sanitizeLabel 页码标签用作文件名：只保留字母、数字、点、横线和下划线，其余替换为 _
+func sanitizeLabel(label string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, c := range label {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '.' || c == '-' {
+			sb.WriteRune(c)
+			underscore = false
+		} else if !underscore {
+			sb.WriteByte('_')
+			underscore = true
 		}
-	}()
-
-	//WaitNewCookie
-	fmt.Println("请使用 bookget-gui 浏览器，打开图书网址，完成「真人验证 / 登录用户」，然后 「刷新」 网页.")
-	go func() {
-		defer wg.Done()
-		for i := 0; i < 3600*8; i++ {
-			if FileExist(config.Conf.CookieFile) {
-				break
-			}
-			time.Sleep(time.Second * 3)
+	}
+	s := strings.Trim(sb.String(), "_.")
+	if r := []rune(s); len(r) > 64 {
+		s = string(r[:64])
+	}
+	return s
+}
+
+// pageDest 第 i 页（从0开始）的保存路径，文件名见 pageFileName
+func pageDest(dt *DownloadTask, i int, ext string) string {
+	return dt.SavePath + pageFileName(dt, i, ext)
+}
+
+// canvasUrls IIIF v2 清单第一个序列中每张图的地址，并按同样顺序把画布标签记入 dt.Labels，供 pageFileName 命名。
+// imageUrl 为 nil 时用 iiifImageUrl
+func canvasUrls(dt *DownloadTask, manifest *iiif.ManifestResponse, imageUrl func(image iiif.Image) string) []string {
+	if len(manifest.Sequences) == 0 {
+		return nil
+	}
+	if imageUrl == nil {
+		imageUrl = iiifImageUrl
+	}
+	canvases := manifest.Sequences[0].Canvases
+	urls := make([]string, 0, len(canvases))
+	dt.Labels = make([]string, 0, len(canvases))
+	for _, canvas := range canvases {
+		for _, image := range canvas.Images {
+			urls = append(urls, imageUrl(image))
+			dt.Labels = append(dt.Labels, string(canvas.Label))
 		}
-	}()
+	}
+	return urls
+}
 
-	wg.Wait()
+// iiifImageUrl --dezoomify-rs 时为 info.json（dezoomify-rs URL），否则按 --format 取 JPEG
+func iiifImageUrl(image iiif.Image) string {
+	if config.Conf.UseDziRs {
+		return image.Resource.Service.Id + "/info.json"
+	}
+	return image.Resource.Service.Id + "/" + config.Conf.Format
 }
 
-func WaitNewCookie() {
-	if FileExist(config.Conf.CookieFile) {
+// recordPages 下载前把页面列表写入 bookget.json 与 pages.json，供 bookget verify 校验页数与修复
+// ext 为空时按URL扩展名命名（与 doNormal 保持一致）
+func recordPages(dt *DownloadTask, imgUrls []string, ext string) {
+	size := len(imgUrls)
+	pages := make([]verify.Page, 0, size)
+	for i, uri := range imgUrls {
+		if uri == "" || !config.PageRange(i, size) {
+			continue
+		}
+		fileExt := ext
+		if fileExt == "" {
+			fileExt = util.FileExt(uri)
+		}
+		pages = append(pages, verify.Page{
+			Seq:    i + 1,
+			Label:  pageLabel(dt, i),
+			Url:    uri,
+			File:   pageFileName(dt, i, fileExt),
+			Status: verify.StatusPending,
+		})
+	}
+	metrics.Claim(dt.Url, imgUrls)
+	if err := verify.Record(dt.SavePath, dt.Url, size, pages); err != nil {
+		dt.LogErr(err)
 		return
 	}
-	var wg sync.WaitGroup
-	wg.Add(1)
-	fmt.Println("请使用 bookget-gui 浏览器，打开图书网址，完成「真人验证 / 登录用户」，然后 「刷新」 网页.")
-	go func() {
-		defer wg.Done()
-		for i := 0; i < 3600*8; i++ {
-			if FileExist(config.Conf.CookieFile) {
-				break
-			}
-			util.PrintSleepTime(config.Conf.Speed)
-		}
-	}()
-	wg.Wait()
+	if dt.Title != "" {
+		_ = verify.SetTitle(dt.SavePath, dt.Title)
+	}
+}
+
+// OpenWebBrowser 如有 bookget-gui（Windows）则用它打开网址，同时开启本地回调页面，任一方式取得 cookie 即返回
+func OpenWebBrowser(sUrl string, args []string) error {
+	argv := []string{sUrl}
+	if args != nil {
+		argv = append(argv, args...)
+	}
+	util.OpenGUI(argv)
+	return waitAuth(sUrl)
 }
 
-func WaitNewCookieWithMsg(uri string) {
+// WaitNewCookie cookie.txt 不存在时，等待用户在浏览器中完成验证并回传 cookie
+func WaitNewCookie() error {
+	if FileExist(config.Conf.CookieFile) {
+		return nil
+	}
+	return waitAuth("")
+}
+
+// WaitNewCookieWithMsg cookie 已失效（如出现验证码），删除后等待用户打开 uri 重新验证
+func WaitNewCookieWithMsg(uri string) error {
 	_ = os.Remove(config.Conf.CookieFile)
-	var wg sync.WaitGroup
-	wg.Add(1)
-	fmt.Println("请使用 bookget-gui 浏览器打开下面 URL，完成「真人验证 / 登录用户」，然后 「刷新」 网页.")
-	fmt.Println(uri)
-	go func() {
-		defer wg.Done()
-		for i := 0; i < 3600*8; i++ {
-			if FileExist(config.Conf.CookieFile) {
-				break
-			}
-			util.PrintSleepTime(config.Conf.Speed)
-		}
-	}()
-	wg.Wait()
+	return waitAuth(uri)
+}
+
+// waitAuth 启动本地回调页面，等待书签小工具/扩展回传 cookie 与 localStorage。
+// 仍兼容 bookget-gui：cookie.txt 出现后同样视为完成。Ctrl+C 只取消本次等待。
+func waitAuth(targetUrl string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := authflow.Wait(ctx, authflow.Options{
+		Listen:    config.Conf.AuthListen,
+		Timeout:   config.Conf.AuthTimeout,
+		TargetUrl: targetUrl,
+		Done: func() bool {
+			return FileExist(config.Conf.CookieFile)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	creds := &authstore.Credentials{
+		LocalStorage:   result.LocalStorage,
+		SessionStorage: result.SessionStorage,
+	}
+	err = result.SaveCookies(config.Conf.CookieFile)
+	switch {
+	case errors.Is(err, cookies.ErrNotNetscape):
+		//不改写用户的 JSON、Cookie 头格式文件，cookie 保存到凭据文件
+		creds.Cookies = result.Cookies
+	case err != nil:
+		return err
+	case len(result.Cookies) > 0:
+		log.Println(i18n.T("app.cookies_saved", len(result.Cookies), config.Conf.CookieFile))
+	}
+	if len(creds.Cookies) == 0 && len(creds.LocalStorage) == 0 && len(creds.SessionStorage) == 0 {
+		return nil
+	}
+	authstore.Default.Merge(result.Host(), creds)
+	err = authstore.Default.Save(config.Conf.LocalStorage)
+	if errors.Is(err, authstore.ErrNotNative) {
+		//不改写用户导出的 storageState、localStorage.txt，凭据保存到缓存目录
+		err = authstore.Default.Save(AuthCacheFile())
+	}
+	return err
+}
+
+// AuthCacheFile --local-storage 不是 bookget 格式时，回传的凭据保存在这里，启动时一并读取
+func AuthCacheFile() string {
+	return filepath.Join(config.CacheDir(), "auth.json")
 }
 
 func IsChinaIP(jar *cookiejar.Jar) bool {
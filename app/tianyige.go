@@ -3,8 +3,10 @@ package app
 import (
 	"bookget/config"
 	"bookget/model/tianyige"
+	"bookget/pkg/authstore"
+	"bookget/pkg/dedup"
 	"bookget/pkg/gohttp"
-	xhash "bookget/pkg/hash"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"bytes"
 	"context"
@@ -18,7 +20,6 @@ import (
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
 	"io"
-	"log"
 	"math/rand"
 	"net/http/cookiejar"
 	"net/url"
@@ -64,7 +65,7 @@ func (r *Tianyige) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.localStorage.authorization, r.localStorage.authorizationu, err = r.getLocalStorage()
@@ -87,15 +88,15 @@ func (r *Tianyige) getBookId(sUrl string) (bookId string) {
 func (r *Tianyige) download() (msg string, err error) {
 	respVolume, err := r.getVolumes(r.dt.BookId, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	canvases, err := r.getCanvases(r.dt.BookId, r.dt.Jar)
 	if err != nil {
-		log.Println(err)
+		r.dt.LogErr(err)
 		return
 	}
-	log.Printf(" %d volumes,  %d pages.\n", len(respVolume), len(canvases))
+	r.dt.Logf(" %d volumes,  %d pages.\n", len(respVolume), len(canvases))
 	parts := make(tianyige.Parts)
 	for _, record := range canvases {
 		parts[record.FascicleId] = append(parts[record.FascicleId], record)
@@ -109,7 +110,7 @@ func (r *Tianyige) download() (msg string, err error) {
 		vid := fmt.Sprintf("%04d", i+1)
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		sizePage := len(parts[vol.FascicleId])
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, sizePage)
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, sizePage)
 		text, err := r.getCatalogById(vol.CatalogId, vol.FascicleId, r.index)
 		if err == nil {
 			bookmark += text
@@ -129,9 +130,7 @@ func (r *Tianyige) do(records []tianyige.ImageRecord) (msg string, err error) {
 		return "", nil
 	}
 	size := len(records)
-	fmt.Println()
 	var wg sync.WaitGroup
-	idDict := make(map[string]string, 1000)
 	i := 0
 	for _, record := range records {
 		uri, _, err := r.getImageById(record.ImageId)
@@ -146,7 +145,7 @@ func (r *Tianyige) do(records []tianyige.ImageRecord) (msg string, err error) {
 		if config.Conf.Bookmark || FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d  %s\n", i, size, uri)
+		r.dt.Logf("Get %d/%d  %s\n", i, size, uri)
 		//下载时有验证码
 		ctx := context.Background()
 		opts := gohttp.Options{
@@ -164,25 +163,22 @@ func (r *Tianyige) do(records []tianyige.ImageRecord) (msg string, err error) {
 			if err == nil && FileExist(dest) {
 				break
 			}
-			WaitNewCookieWithMsg(uri)
+			//重复页、占位图被拒收（--dedup requeue）不是 cookie 失效
+			if errors.Is(err, gohttp.ErrNotFound) || errors.Is(err, dedup.ErrSuspect) {
+				break
+			}
+			if errors.Is(err, gohttp.ErrRateLimited) {
+				util.PrintSleepTime(60)
+				continue
+			}
+			if err = WaitNewCookieWithMsg(uri); err != nil {
+				return "", err
+			}
 		}
 
-		bs, _ := os.ReadFile(dest)
-		mh := xhash.NewMultiHasher()
-		_, _ = io.Copy(mh, bytes.NewBuffer(bs))
-		kId, _ := mh.SumString(xhash.MD5, false)
-		_, ok := idDict[kId]
-		if ok {
-			fmt.Println()
-			continue
-		} else {
-			idDict[kId] = uri
-		}
 		util.PrintSleepTime(config.Conf.Speed)
-		fmt.Println()
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -237,7 +233,7 @@ func (r *Tianyige) getImageById(imageId string) (imgUrl, ocrUrl string, err erro
 	}
 	var resObj tianyige.ResponseFile
 	if err = json.Unmarshal(bs, &resObj); err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return
 	}
 
@@ -259,7 +255,7 @@ func (r *Tianyige) getCatalogById(catalogId, fascicleId string, indexStart int)
 	}
 	var resp tianyige.Catalog
 	if err = json.Unmarshal(bs, &resp); err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "", err
 	}
 	var bookmark string
@@ -300,7 +296,6 @@ func (r *Tianyige) getBody(sUrl string, jar *cookiejar.Jar) ([]byte, error) {
 	bs, _ := resp.GetBody()
 	if bs == nil || resp.GetStatusCode() != 200 {
 		msg := fmt.Sprintf("Please try again later.[%d %s]\n", resp.GetStatusCode(), resp.GetReasonPhrase())
-		fmt.Println(msg)
 		return nil, errors.New(msg)
 	}
 	return bs, err
@@ -329,7 +324,6 @@ func (r *Tianyige) postBody(sUrl string, d []byte, jar *cookiejar.Jar) ([]byte,
 	bs, _ := resp.GetBody()
 	if bs == nil || resp.GetStatusCode() != 200 {
 		msg := fmt.Sprintf("Please try again later.[%d %s]\n", resp.GetStatusCode(), resp.GetReasonPhrase())
-		fmt.Println(msg)
 		return nil, errors.New(msg)
 	}
 	return bs, err
@@ -360,45 +354,12 @@ func (r *Tianyige) getToken() string {
 	return r.encrypt(pt, key)
 }
 
-// // 假设 LocalStorage 中已经有 'authorization' 和 'authorizationu' 这两个键
-// const authorization = localStorage.getItem('authorization');
-// const authorizationu = localStorage.getItem('authorizationu');
+// getLocalStorage 网页 localStorage 中的 'authorization' 和 'authorizationu'，
+// 从 --local-storage 指定的凭据文件或真人验证回传的数据中读取
 func (r *Tianyige) getLocalStorage() (string, string, error) {
-	bs, err := os.ReadFile(config.Conf.LocalStorage)
-	if bs == nil || err != nil {
-		return "", "", err
-	}
-
-	// 分割输入字符串为多个部分，以换行符为分隔符
-	lines := strings.Split(string(bs), "\n")
-
-	authTokens := make(map[string]string)
-	for _, line := range lines {
-		// 去除行首和行尾的空格
-		line = strings.TrimSpace(line)
-
-		// 如果行是空的，则跳过
-		if line == "" {
-			continue
-		}
-
-		// 分割键和值，以冒号为分隔符，并去除键和值两侧的空格
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			return "", "", fmt.Errorf("invalid line format: %s", line)
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.Trim(strings.Trim(strings.Trim(parts[1], "\""), " "), "'")
-
-		// 将键值对存储到 map 中
-		authTokens[key] = value
-	}
-
-	// 从 map 中提取 authorization 和 authorizationu 的值
-	authorization, ok1 := authTokens["authorization"]
-	authorizationu, ok2 := authTokens["authorizationu"]
-
-	// 检查是否成功提取到所有需要的值
+	creds := authstore.Default.For(r.dt.UrlParsed.Host)
+	authorization, ok1 := creds.Get("authorization")
+	authorizationu, ok2 := creds.Get("authorizationu")
 	if !ok1 || !ok2 {
 		return "", "", fmt.Errorf("missing required token")
 	}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/progress"
+	"bookget/pkg/tiles"
+	"bookget/pkg/util"
+	"context"
+	"fmt"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// 例如：
+// Zoomify  https://example.org/zoomify/0001/ImageProperties.xml
+// IIPImage https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/0001.tif
+
+// Tiles Zoomify 与 IIPImage 切片图：读取切片描述，下载最高分辨率一级的全部切片并拼成整图
+type Tiles struct {
+	dt *DownloadTask
+}
+
+func NewTiles() *Tiles {
+	return &Tiles{
+		// 初始化字段
+		dt: new(DownloadTask),
+	}
+}
+
+func (t *Tiles) GetRouterInit(sUrl string) (map[string]interface{}, error) {
+	msg, err := t.Run(sUrl)
+	return map[string]interface{}{
+		"type": "tiles",
+		"url":  sUrl,
+		"msg":  msg,
+	}, err
+}
+
+func (t *Tiles) Run(sUrl string) (msg string, err error) {
+	t.dt = new(DownloadTask)
+	t.dt.Url = sUrl
+	if t.dt.UrlParsed, err = url.Parse(sUrl); err != nil {
+		return "", err
+	}
+	t.dt.Jar, _ = cookiejar.New(nil)
+	t.dt.BookId = getBookId(sUrl)
+	t.dt.SavePath = CreateDirectory(t.dt.UrlParsed.Host, t.dt.BookId, "")
+	return "", t.do([]string{sUrl})
+}
+
+// do 依次拼接每张图，按页序命名；返回最后一个错误
+func (t *Tiles) do(imgUrls []string) (err error) {
+	//拼接图只能保存为 PNG 或 JPEG
+	ext := tiles.Ext(config.Conf.FileExt)
+	size := len(imgUrls)
+	recordPages(t.dt, imgUrls, ext)
+	job := progress.Default.Start(t.dt.BookId, progress.Pages, int64(size))
+	defer job.Done()
+	for k, uri := range imgUrls {
+		if !config.PageRange(k, size) {
+			continue
+		}
+		dest := pageDest(t.dt, k, ext)
+		if FileExist(dest) {
+			continue
+		}
+		jobLog(t.dt).Info(fmt.Sprintf("Get %d/%d  %s", k+1, size, uri), "page", k+1)
+		if e := t.stitch(uri, dest, ext); e != nil {
+			t.dt.PageFailed(e)
+			err = e
+		} else {
+			job.Add(1)
+		}
+		util.PrintSleepTime(config.Conf.Speed)
+	}
+	return err
+}
+
+func (t *Tiles) stitch(uri, dest, ext string) error {
+	g, err := t.grid(uri)
+	if err != nil {
+		return err
+	}
+	jobLog(t.dt).Debug(fmt.Sprintf("%dx%d  %d tiles", g.Width, g.Height, len(g.Tiles)))
+	img, err := tiles.Stitch(g, t.getTile, config.Conf.Threads, nil)
+	if err != nil {
+		return err
+	}
+	if err = tiles.Save(dest, img, ext, config.Conf.ImageProc.Quality); err != nil {
+		return err
+	}
+	return gohttp.NotifySaved(dest, uri)
+}
+
+// grid FIF= 为 IIPImage，其余按 Zoomify 处理（可以是 ImageProperties.xml 或其所在目录）
+func (t *Tiles) grid(uri string) (*tiles.Grid, error) {
+	if strings.Contains(uri, "FIF=") {
+		p, err := tiles.ParseIIP(uri)
+		if err != nil {
+			return nil, err
+		}
+		bs, err := t.getTile(p.InfoUrl())
+		if err != nil {
+			return nil, err
+		}
+		return p.Grid(bs)
+	}
+	bs, err := t.getTile(tiles.ZoomifyBase(uri) + "ImageProperties.xml")
+	if err != nil {
+		return nil, err
+	}
+	return tiles.ParseZoomify(uri, bs)
+}
+
+func (t *Tiles) getTile(uri string) ([]byte, error) {
+	resp, err := gohttp.Get(context.Background(), uri, gohttp.Options{
+		Retry:      config.Conf.Retry,
+		CookieFile: config.Conf.CookieFile,
+		CookieJar:  t.dt.Jar,
+		Headers: map[string]interface{}{
+			"User-Agent": config.Conf.UserAgent,
+			"Referer":    t.dt.Url,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	bs, _ := resp.GetBody()
+	if resp.GetStatusCode() != 200 || bs == nil {
+		return nil, fmt.Errorf("%s: ErrCode:%d, %s", uri, resp.GetStatusCode(), resp.GetReasonPhrase())
+	}
+	return bs, nil
+}
@@ -3,12 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
-	"bookget/pkg/util"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -40,7 +39,7 @@ func (r *Tnm) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -56,16 +55,16 @@ func (r *Tnm) getBookId(sUrl string) (bookId string) {
 
 func (r *Tnm) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
 	apiUrl := fmt.Sprintf("%s://%s/dlib/pages/%s", r.dt.UrlParsed.Scheme, r.dt.UrlParsed.Host, r.dt.BookId)
 	canvases, err := r.getCanvases(apiUrl, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err.Error())
+		r.dt.LogErr(err)
 		return
 	}
-	log.Printf(" %d pages \n", len(canvases))
+	r.dt.Logf(" %d pages \n", len(canvases))
 	return r.do(canvases)
 }
 
@@ -80,6 +79,7 @@ func (r *Tnm) do(dziUrls []string) (msg string, err error) {
 		"-H", "User-Agent:" + config.Conf.UserAgent,
 	}
 	size := len(dziUrls)
+	recordPages(r.dt, dziUrls, config.Conf.FileExt)
 	for i, uri := range dziUrls {
 		if uri == "" || !config.PageRange(i, size) {
 			continue
@@ -90,8 +90,8 @@ func (r *Tnm) do(dziUrls []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %s  %s\n", sortId, uri)
-		util.StartProcess(uri, dest, args)
+		r.dt.Logf("Get %s  %s\n", sortId, uri)
+		dezoomify(uri, dest, uri, args)
 	}
 	return "", err
 }
@@ -4,12 +4,12 @@ import (
 	"bookget/config"
 	"bookget/model/usthk"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -40,7 +40,7 @@ func (r *Usthk) Run(sUrl string) (msg string, err error) {
 	r.dt.Url = sUrl
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -56,11 +56,11 @@ func (r *Usthk) getBookId(sUrl string) (bookId string) {
 
 func (r *Usthk) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	sizeVol := len(respVolume)
@@ -77,11 +77,10 @@ func (r *Usthk) download() (msg string, err error) {
 
 		canvases, err := r.getCanvases(vol)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		fmt.Println()
-		log.Printf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, sizeVol, len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -89,10 +88,10 @@ func (r *Usthk) download() (msg string, err error) {
 
 func (r *Usthk) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
-		return "图片URLs为空", errors.New("imgUrls is nil")
+		return i18n.T("app.img_urls_empty"), errors.New("imgUrls is nil")
 	}
 	size := len(imgUrls)
-	fmt.Println()
+	recordPages(r.dt, imgUrls, "")
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -107,8 +106,7 @@ func (r *Usthk) do(imgUrls []string) (msg string, err error) {
 			continue
 		}
 		imgUrl := uri
-		fmt.Println()
-		log.Printf("Get %d/%d  %s\n", i+1, size, imgUrl)
+		r.dt.Logf("Get %d/%d  %s\n", i+1, size, imgUrl)
 		wg.Add(1)
 		q.Go(func() {
 			defer wg.Done()
@@ -124,11 +122,9 @@ func (r *Usthk) do(imgUrls []string) (msg string, err error) {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", nil
 }
 
@@ -158,7 +154,7 @@ func (r *Usthk) getCanvases(sUrl string) ([]string, error) {
 		}
 		respFiles := new(usthk.Response)
 		if err = json.Unmarshal(bs, respFiles); err != nil {
-			log.Printf("json.Unmarshal failed: %s\n", err)
+			r.dt.Logf("json.Unmarshal failed: %s\n", err)
 			break
 		}
 		//imgUrls := make([]string, 0, len(result.FileList))
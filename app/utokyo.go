@@ -3,11 +3,11 @@ package app
 import (
 	"bookget/config"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -37,7 +37,7 @@ func (p *Utokyo) Run(sUrl string) (msg string, err error) {
 	p.dt.Url = sUrl
 	p.dt.BookId = p.getBookId(p.dt.Url)
 	if p.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	p.dt.Jar, _ = cookiejar.New(nil)
 	return p.download()
@@ -52,10 +52,10 @@ func (p *Utokyo) getBookId(sUrl string) (bookId string) {
 
 func (p *Utokyo) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", p.dt.Index)
-	log.Printf("Get %s  %s\n", name, p.dt.Url)
+	p.dt.Logf("Get %s  %s\n", name, p.dt.Url)
 	respVolume, err := p.getVolumes(p.dt.Url, p.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		p.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	p.dt.SavePath = CreateDirectory(p.dt.UrlParsed.Host, p.dt.BookId, "")
@@ -63,7 +63,7 @@ func (p *Utokyo) download() (msg string, err error) {
 		if !config.VolumeRange(i) {
 			continue
 		}
-		log.Printf(" %d/%d volume, %s \n", i+1, len(respVolume), vol)
+		p.dt.Logf(" %d/%d volume, %s \n", i+1, len(respVolume), vol)
 		fName := util.FileName(vol)
 		sortId := fmt.Sprintf("%04d", i+1)
 		dest := p.dt.SavePath + sortId + fName
@@ -87,7 +87,7 @@ func (p *Utokyo) do(dest, pdfUrl string) (msg string, err error) {
 	}
 	resp, err := gohttp.FastGet(ctx, pdfUrl, opts)
 	if err != nil || resp.GetStatusCode() != 200 {
-		fmt.Println(err)
+		p.dt.LogErr(err)
 	}
 	return "", err
 }
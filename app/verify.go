@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bookget/config"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/verify"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VerifyBooks 校验 dir 下所有已下载的图书，repair=true 时重新下载缺页和坏页
+func VerifyBooks(dir string, repair bool) (bad int, err error) {
+	var results []*verify.Result
+	err = verify.Walk(dir, repair, func(r *verify.Result, err error) {
+		if err != nil {
+			log.Printf("verify: %v\n", err)
+			return
+		}
+		printVerifyResult(r)
+		if !r.OK() {
+			results = append(results, r)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !repair {
+		return len(results), nil
+	}
+
+	for _, r := range results {
+		repairBook(r)
+		r2, err := verify.Dir(r.Dir, true)
+		if err != nil {
+			log.Printf("verify: %v\n", err)
+			bad++
+			continue
+		}
+		printVerifyResult(r2)
+		if !r2.OK() {
+			bad++
+		}
+	}
+	return bad, nil
+}
+
+func printVerifyResult(r *verify.Result) {
+	if r.OK() {
+		fmt.Printf("[OK]   %s  %d/%d\n", r.Dir, r.Found, r.Expected)
+		return
+	}
+	fmt.Printf("[FAIL] %s  %d/%d, missing: %d, bad: %d\n", r.Dir, r.Found, r.Expected, len(r.Missing), len(r.Bad))
+	for _, p := range r.Bad {
+		fmt.Printf("       %s: %s\n", p.File, p.Error)
+	}
+	for _, p := range r.Missing {
+		fmt.Printf("       %s: missing\n", p.File)
+	}
+}
+
+// repairBook 只重新下载有问题的页面
+func repairBook(r *verify.Result) {
+	pages := append(r.Bad, r.Missing...)
+	referer := url.QueryEscape(r.Url)
+	for _, p := range pages {
+		if p.Url == "" {
+			log.Printf("repair: %s has no source URL, skipped\n", p.File)
+			continue
+		}
+		dest := filepath.Join(r.Dir, p.File)
+		_ = os.Remove(dest)
+		log.Printf("Repair %s  %s\n", p.File, p.Url)
+		if strings.HasSuffix(p.Url, "info.json") || strings.HasSuffix(p.Url, ".dzi") || strings.HasSuffix(p.Url, ".xml") {
+			args := []string{
+				"-H", "Origin:" + referer,
+				"-H", "Referer:" + referer,
+				"-H", "User-Agent:" + config.Conf.UserAgent,
+			}
+			dezoomify(p.Url, dest, p.Url, args)
+			continue
+		}
+		opts := gohttp.Options{
+			DestFile:    dest,
+			Overwrite:   true,
+			Concurrency: 1,
+			CookieFile:  config.Conf.CookieFile,
+			Headers: map[string]interface{}{
+				"User-Agent": config.Conf.UserAgent,
+				"Referer":    referer,
+			},
+		}
+		for k := 0; k < config.Conf.Retry; k++ {
+			if _, err := gohttp.FastGet(context.Background(), p.Url, opts); err == nil {
+				break
+			}
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"bookget/pkg/i18n"
+	"bookget/pkg/viewer"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ServeBooks 启动本地阅读服务，浏览 dir 下已下载的图书，ctx 取消时退出
+func ServeBooks(ctx context.Context, listen, dir string) error {
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: viewer.Handler(dir), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	fmt.Println(i18n.T("app.view_shelf", dir))
+	fmt.Println(i18n.T("app.view_open", "http://"+ln.Addr().String()+"/"))
+	if err = srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
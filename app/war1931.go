@@ -4,16 +4,18 @@ import (
 	"bookget/config"
 	"bookget/model/war"
 	"bookget/pkg/gohttp"
-	"bookget/pkg/util"
+	"bookget/pkg/i18n"
+	"bookget/pkg/serial"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 )
 
 type War1931 struct {
@@ -21,6 +23,8 @@ type War1931 struct {
 	docType         string
 	fileCode        string
 	jsonUrlTemplate string
+	info            war.Info
+	issues          serial.Range
 }
 
 func NewWar1931() *War1931 {
@@ -44,7 +48,10 @@ func (r *War1931) Run(sUrl string) (msg string, err error) {
 	r.dt.Jar, _ = cookiejar.New(nil)
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
+	}
+	if r.issues, err = serial.ParseRange(config.Conf.Issue); err != nil {
+		return "ParseRange", err
 	}
 	return r.download()
 }
@@ -66,7 +73,7 @@ func (r *War1931) mkdirAll(directory, vid string) (dirPath string) {
 		r.dt.VolumeId = r.dt.UrlParsed.Host + "_" + r.dt.BookId + string(os.PathSeparator) + directory
 		break
 	case "qk":
-		r.dt.VolumeId = r.dt.UrlParsed.Host + "_" + r.dt.BookId + string(os.PathSeparator) + directory + "_vol." + vid
+		r.dt.VolumeId = r.dt.UrlParsed.Host + "_" + r.dt.BookId + string(os.PathSeparator) + directory
 		break
 	default:
 	}
@@ -77,33 +84,56 @@ func (r *War1931) mkdirAll(directory, vid string) (dirPath string) {
 
 func (r *War1931) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 	apiUrl := "https://" + r.dt.UrlParsed.Host + "/backend-prod/esBook/findDetailsInfo/" + r.dt.BookId
 	partialVolumes, err := r.getVolumes(apiUrl, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for k, parts := range partialVolumes {
 		if !config.VolumeRange(k) {
 			continue
 		}
-		log.Printf(" %d/%d, %d volumes \n", k+1, len(partialVolumes), len(parts.Volumes))
+		r.dt.Logf(" %d/%d, %d volumes \n", k+1, len(partialVolumes), len(parts.Volumes))
 		for i, vol := range parts.Volumes {
 			vid := fmt.Sprintf("%04d", i+1)
 			r.mkdirAll(parts.Directory, vid)
+			if r.docType == "bz" || r.docType == "qk" {
+				if err := r.writeInfo(parts, vol); err != nil {
+					r.dt.LogErr(err)
+				}
+			}
 			canvases, err := r.getCanvases(vol, r.dt.Jar)
 			if err != nil || canvases == nil {
-				fmt.Println(err)
+				r.dt.LogErr(err)
 				continue
 			}
-			log.Printf(" %d/%d volume, %d pages \n", i+1, len(parts.Volumes), len(canvases))
+			r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(parts.Volumes), len(canvases))
 			r.do(canvases)
 		}
 	}
 	return "", err
 }
 
+// writeInfo 报刊每期目录中保存 info.json（书目信息与该期的日期、期名）
+func (r *War1931) writeInfo(parts war.PartialVolumes, jsonUrl string) error {
+	bs, err := json.MarshalIndent(war.IssueInfo{
+		Info:    r.info,
+		Title:   parts.Title,
+		Date:    parts.Date,
+		Issue:   parts.Issue,
+		JsonUrl: jsonUrl,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(r.dt.SavePath+string(os.PathSeparator)+"info.json", bs, 0644); err != nil {
+		return i18n.Errorf("app.catalog_save_failed", err)
+	}
+	return nil
+}
+
 func (r *War1931) do(canvases []string) (msg string, err error) {
 	if canvases == nil {
 		return "", nil
@@ -135,8 +165,8 @@ func (r *War1931) do(canvases []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %s  %s\n", sortId, uri)
-		if ret := util.StartProcess(inputUri, dest, args); ret == true {
+		r.dt.Logf("Get %s  %s\n", sortId, uri)
+		if dezoomify(inputUri, dest, uri, args) {
 			os.Remove(inputUri)
 		}
 	}
@@ -152,6 +182,7 @@ func (r *War1931) getVolumes(apiUrl string, jar *cookiejar.Jar) (volumes []war.P
 	if err = json.Unmarshal(bs, &resp); err != nil {
 		return nil, err
 	}
+	r.info = resp.Result.Info
 	r.docType = resp.Result.Info.DocType
 	r.fileCode = resp.Result.Info.FileCode
 	jsonUrl := resp.Result.Info.IiifObj.JsonUrl
@@ -197,18 +228,21 @@ func (r *War1931) getVolumesForBz(sUrl string, jar *cookiejar.Jar) (volumes []wa
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println()
+	seen := make(map[string]int)
 	for _, year := range years {
+		//不在 --date-from、--date-to 范围内的年、月不再请求
+		if !config.DateRange(serial.Year(year)) {
+			continue
+		}
 		months, err := r.findBzMonth(year)
 		if err != nil {
 			continue
 		}
 		for _, month := range months {
-			if len(month) == 1 {
-				fmt.Printf("Test %s-0%s\r", year, month)
-			} else {
-				fmt.Printf("Test %s-%s\r", year, month)
+			if !config.DateRange(serial.Year(year) + "-" + serial.Month(month)) {
+				continue
 			}
+			jobLog(r.dt).Debug("test month", "year", year, "month", serial.Month(month))
 			apiUrl := "https://" + r.dt.UrlParsed.Host + "/backend-prod/esBook/findDirectoryByMonth?fileCode=" + r.fileCode + "&year=" + year + "&month=" + month
 			bs, err := r.getBody(apiUrl, jar)
 			if err != nil {
@@ -216,20 +250,31 @@ func (r *War1931) getVolumesForBz(sUrl string, jar *cookiejar.Jar) (volumes []wa
 			}
 			var resp = new(war.FindDirectoryByMonth)
 			if err := json.Unmarshal(bs, resp); err != nil {
-				log.Printf("json.Unmarshal failed: %s\n", err)
+				r.dt.Logf("json.Unmarshal failed: %s\n", err)
 				break
 			}
 			for _, item := range resp.Result {
+				//按 YYYY/MM/DD 保存，同一天有多份时加 _2、_3
+				date := serial.Date(year, month, item.Date)
+				if date == "" {
+					date = year + "/" + item.Date
+				} else if !config.DateRange(date) {
+					continue
+				}
+				directory := filepath.FromSlash(strings.ReplaceAll(date, "-", "/"))
+				if seen[directory]++; seen[directory] > 1 {
+					directory += fmt.Sprintf("_%d", seen[directory])
+				}
 				partVol := war.PartialVolumes{
-					Directory: year + "/" + item.Date,
+					Directory: directory,
 					Title:     item.Date,
+					Date:      date,
 					Volumes:   []string{item.IiifObj.JsonUrl},
 				}
 				volumes = append(volumes, partVol)
 			}
 		}
 	}
-	fmt.Println()
 	return volumes, err
 }
 
@@ -246,7 +291,7 @@ func (r *War1931) findBzYear(fileCode string) (years []string, err error) {
 	}
 	var resp = new(Response)
 	if err = json.Unmarshal(bs, resp); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	return resp.Result, err
@@ -265,7 +310,7 @@ func (r *War1931) findBzMonth(year string) (years []string, err error) {
 	}
 	var resp = new(Response)
 	if err = json.Unmarshal(bs, resp); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	return resp.Result, err
@@ -279,22 +324,33 @@ func (r *War1931) getVolumesForQk(sUrl string, jar *cookiejar.Jar) (volumes []wa
 	}
 	var resp = new(war.Qk)
 	if err = json.Unmarshal(bs, resp); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
+	//每期一个目录：年/期号（同年期号重复时加 _2、_3），按 --date-from、--date-to 筛选年份，按 --issue 筛选期号
+	seen := make(map[string]int)
 	for _, items := range resp.Result {
 		for _, item := range items.List {
-			partVol := war.PartialVolumes{
-				Directory: item.Year,
-				Title:     items.Title,
-				Volumes:   nil,
+			if !config.DateRange(serial.Year(item.Year)) {
+				continue
 			}
-			partVol.Volumes = make([]string, 0, len(item.DataList))
-			for _, v := range item.DataList {
-				jsonUrl := fmt.Sprintf(r.jsonUrlTemplate, v.Id, v.Id)
-				partVol.Volumes = append(partVol.Volumes, jsonUrl)
+			for k, v := range item.DataList {
+				n := serial.IssueNumber(v.Directory, k+1)
+				if !r.issues.Contains(n) {
+					continue
+				}
+				directory := item.Year + string(os.PathSeparator) + fmt.Sprintf("%03d", n)
+				if seen[directory]++; seen[directory] > 1 {
+					directory += fmt.Sprintf("_%d", seen[directory])
+				}
+				partVol := war.PartialVolumes{
+					Directory: directory,
+					Title:     items.Title,
+					Issue:     v.Directory,
+					Volumes:   []string{fmt.Sprintf(r.jsonUrlTemplate, v.Id, v.Id)},
+				}
+				volumes = append(volumes, partVol)
 			}
-			volumes = append(volumes, partVol)
 		}
 	}
 	return volumes, err
@@ -307,7 +363,7 @@ func (r *War1931) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []strin
 	}
 	var manifest = new(war.Manifest)
 	if err = json.Unmarshal(bs, manifest); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		r.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	if len(manifest.Sequences) == 0 {
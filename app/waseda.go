@@ -7,7 +7,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"path"
@@ -47,7 +46,7 @@ func (r Waseda) Run(sUrl string) (msg string, err error) {
 func (r Waseda) download() (msg string, err error) {
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	if config.Conf.FileExt == ".pdf" {
@@ -57,7 +56,7 @@ func (r Waseda) download() (msg string, err error) {
 			}
 			sortId := fmt.Sprintf("%04d", i+1)
 			r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, "")
-			log.Printf(" %d/%d volume, URL:%s \n", i+1, len(respVolume), vol)
+			r.dt.Logf(" %d/%d volume, URL:%s \n", i+1, len(respVolume), vol)
 			filename := sortId + config.Conf.FileExt
 			dest := r.dt.SavePath + filename
 			r.doDownload(vol, dest)
@@ -75,11 +74,11 @@ func (r Waseda) download() (msg string, err error) {
 			}
 			canvases, err := r.getCanvases(vol, r.dt.Jar)
 			if err != nil || canvases == nil {
-				fmt.Println(err)
+				r.dt.LogErr(err)
 				continue
 			}
 
-			log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+			r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 			r.do(canvases)
 		}
 	}
@@ -91,8 +90,8 @@ func (r Waseda) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	var wg sync.WaitGroup
 	q := QueueNew(int(config.Conf.Threads))
 	for i, uri := range imgUrls {
@@ -105,7 +104,7 @@ func (r Waseda) do(imgUrls []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d page, URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d page, URL: %s\n", i+1, size, uri)
 		imgUrl := uri
 		wg.Add(1)
 		q.Go(func() {
@@ -122,11 +121,9 @@ func (r Waseda) do(imgUrls []string) (msg string, err error) {
 				},
 			}
 			gohttp.FastGet(ctx, imgUrl, opts)
-			fmt.Println()
 		})
 	}
 	wg.Wait()
-	fmt.Println()
 	return "", err
 }
 
@@ -225,10 +222,8 @@ func (r Waseda) doDownload(dUrl, dest string) bool {
 	ctx := context.Background()
 	_, err := gohttp.FastGet(ctx, dUrl, opts)
 	if err == nil {
-		fmt.Println()
 		return true
 	}
-	fmt.Println(err)
-	fmt.Println()
+	r.dt.LogErr(err)
 	return false
 }
@@ -4,10 +4,10 @@ import (
 	"bookget/config"
 	"bookget/model/wzlib"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -37,7 +37,7 @@ func (p *Wzlib) Run(sUrl string) (msg string, err error) {
 	p.dt.Url = sUrl
 	p.dt.BookId = p.getBookId(p.dt.Url)
 	if p.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	p.dt.Jar, _ = cookiejar.New(nil)
 	return p.download()
@@ -54,21 +54,21 @@ func (p *Wzlib) getBookId(sUrl string) (bookId string) {
 
 func (p *Wzlib) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", p.dt.Index)
-	log.Printf("Get %s  %s\n", name, p.dt.Url)
+	p.dt.Logf("Get %s  %s\n", name, p.dt.Url)
 	p.dt.SavePath = CreateDirectory(p.dt.UrlParsed.Host, p.dt.BookId, "")
 
 	//旧版：瓯越记忆
 	if p.dt.UrlParsed.Host == "oyjy.wzlib.cn" {
 		canvases, err := p.OyjyGetCanvases(p.dt.BookId)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			p.dt.LogErr(err)
 		}
 		return p.do(canvases)
 	}
 	//新版温州图书馆
 	canvases, err := p.getCanvases(p.dt.Url, p.dt.Jar)
 	if err != nil || canvases == nil {
-		fmt.Println(err)
+		p.dt.LogErr(err)
 	}
 	return p.do(canvases)
 }
@@ -77,9 +77,8 @@ func (p *Wzlib) do(dUrls []string) (msg string, err error) {
 	if dUrls == nil {
 		return
 	}
-	fmt.Println()
 	size := len(dUrls)
-	log.Printf(" %d PDFs.\n", size)
+	p.dt.Logf(" %d PDFs.\n", size)
 	ctx := context.Background()
 	for i, uri := range dUrls {
 		if !config.PageRange(i, size) {
@@ -88,7 +87,7 @@ func (p *Wzlib) do(dUrls []string) (msg string, err error) {
 		if uri == "" {
 			continue
 		}
-		log.Printf("Get %d/%d, URL: %s\n", i+1, size, uri)
+		p.dt.Logf("Get %d/%d, URL: %s\n", i+1, size, uri)
 		sortId := fmt.Sprintf("%04d", i+1)
 		filename := sortId + ".pdf"
 		dest := p.dt.SavePath + filename
@@ -104,12 +103,10 @@ func (p *Wzlib) do(dUrls []string) (msg string, err error) {
 		}
 		_, err = gohttp.FastGet(ctx, uri, opts)
 		if err != nil {
-			fmt.Println(err)
+			p.dt.LogErr(err)
 			continue
 		}
-		fmt.Println()
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -127,7 +124,7 @@ func (p *Wzlib) getCanvases(sUrl string, jar *cookiejar.Jar) (canvases []string,
 
 	var resT = new(wzlib.Digital)
 	if err = json.Unmarshal(bs, &resT); err != nil {
-		log.Printf("json.Unmarshal failed: %s\n", err)
+		p.dt.Logf("json.Unmarshal failed: %s\n", err)
 		return
 	}
 	for _, ret := range resT.DigitalResourceData {
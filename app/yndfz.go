@@ -4,11 +4,11 @@ import (
 	"bookget/config"
 	"bookget/model/yndfz"
 	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
@@ -40,7 +40,7 @@ func (r *Yndfz) Run(sUrl string) (msg string, err error) {
 
 	r.dt.BookId = r.getBookId(r.dt.Url)
 	if r.dt.BookId == "" {
-		return "requested URL was not found.", err
+		return i18n.T("app.url_not_found"), err
 	}
 	r.dt.Jar, _ = cookiejar.New(nil)
 	return r.download()
@@ -56,11 +56,11 @@ func (r *Yndfz) getBookId(sUrl string) (bookId string) {
 
 func (r *Yndfz) download() (msg string, err error) {
 	name := fmt.Sprintf("%04d", r.dt.Index)
-	log.Printf("Get %s  %s\n", name, r.dt.Url)
+	r.dt.Logf("Get %s  %s\n", name, r.dt.Url)
 
 	respVolume, err := r.getVolumes(r.dt.Url, r.dt.Jar)
 	if err != nil {
-		fmt.Println(err)
+		r.dt.LogErr(err)
 		return "getVolumes", err
 	}
 	for i, vol := range respVolume {
@@ -71,10 +71,10 @@ func (r *Yndfz) download() (msg string, err error) {
 		r.dt.SavePath = CreateDirectory(r.dt.UrlParsed.Host, r.dt.BookId, vid)
 		canvases, err := r.getCanvases(vol, r.dt.Jar)
 		if err != nil || canvases == nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			continue
 		}
-		log.Printf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
+		r.dt.Logf(" %d/%d volume, %d pages \n", i+1, len(respVolume), len(canvases))
 		r.do(canvases)
 	}
 	return "", nil
@@ -84,9 +84,9 @@ func (r *Yndfz) do(imgUrls []string) (msg string, err error) {
 	if imgUrls == nil {
 		return
 	}
-	fmt.Println()
 	referer := url.QueryEscape(r.dt.Url)
 	size := len(imgUrls)
+	recordPages(r.dt, imgUrls, config.Conf.FileExt)
 	ctx := context.Background()
 	for i, uri := range imgUrls {
 		if !config.PageRange(i, size) {
@@ -101,7 +101,7 @@ func (r *Yndfz) do(imgUrls []string) (msg string, err error) {
 		if FileExist(dest) {
 			continue
 		}
-		log.Printf("Get %d/%d page, URL: %s\n", i+1, size, uri)
+		r.dt.Logf("Get %d/%d page, URL: %s\n", i+1, size, uri)
 		opts := gohttp.Options{
 			DestFile:    dest,
 			Overwrite:   false,
@@ -116,17 +116,15 @@ func (r *Yndfz) do(imgUrls []string) (msg string, err error) {
 
 		imgUrl, err := r.getDownloadUrl(uri)
 		if err != nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			break
 		}
 		_, err = gohttp.FastGet(ctx, imgUrl, opts)
 		if err != nil {
-			fmt.Println(err)
+			r.dt.LogErr(err)
 			util.PrintSleepTime(config.Conf.Speed)
 		}
-		fmt.Println()
 	}
-	fmt.Println()
 	return "", err
 }
 
@@ -3,15 +3,26 @@ package main
 import (
 	"bookget/app"
 	"bookget/config"
+	"bookget/pkg/authstore"
+	"bookget/pkg/cookies"
+	"bookget/pkg/events"
+	"bookget/pkg/i18n"
+	"bookget/pkg/logging"
+	"bookget/pkg/metrics"
+	"bookget/pkg/progress"
 	"bookget/pkg/queue"
+	"bookget/pkg/verify"
 	"bookget/pkg/version"
 	"bookget/router"
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 )
@@ -28,6 +39,15 @@ var (
 func main() {
 	ctx := context.Background()
 
+	// 界面语言：--lang 或 LANG，需在注册参数说明之前确定
+	_ = i18n.SetLang(i18n.Detect(os.Args[1:]))
+
+	// 子命令
+	if c, ok := lookupCommand(); ok {
+		runCommand(ctx, c)
+		return
+	}
+
 	// 初始化配置
 	if !initializeConfig(ctx) {
 		return
@@ -38,17 +58,109 @@ func main() {
 
 	// 根据运行模式执行相应操作
 	executeByRunMode(ctx)
+	if err := verify.Flush(); err != nil {
+		log.Printf("pages.json: %v\n", err)
+	}
 }
 
 // initializeConfig 处理配置初始化
 func initializeConfig(ctx context.Context) bool {
 	if !config.Init(ctx) {
-		log.Println("配置初始化失败")
+		log.Println(i18n.T("cmd.init_failed"))
+		return false
+	}
+	if !openEvents() {
 		return false
 	}
+	if config.Conf.Metrics != "" {
+		addr, err := metrics.Serve(config.Conf.Metrics)
+		if err != nil {
+			log.Println(i18n.T("cmd.metrics_failed", err))
+			return false
+		}
+		log.Printf("metrics: http://%s/metrics\n", addr)
+	}
+	if !setupLogging() {
+		return false
+	}
+	importBrowserCookies()
+	loadAuthStore()
+	app.EnablePostProcess()
 	return true
 }
 
+// setupLogging 按 --log-level/--log-file 设置日志。终端中日志经 progress 输出，以免打乱进度行；
+// --quiet 时不显示进度，日志只输出警告与错误
+func setupLogging() bool {
+	var console io.Writer = os.Stderr
+	level := config.Conf.LogLevel
+	if config.Conf.Quiet {
+		progress.Silence()
+		if l, err := logging.ParseLevel(level); err == nil && l < slog.LevelWarn {
+			level = "warn"
+		}
+	} else if progress.Default.Terminal() {
+		console = progress.Default
+	}
+	if _, err := logging.Setup(console, level, config.Conf.LogFile); err != nil {
+		log.Println(i18n.T("cmd.log_failed", err))
+		return false
+	}
+	return true
+}
+
+// openEvents --events json 时打开事件流。写入 stdout 时进度改到 stderr，保证 stdout 只有 NDJSON
+// （日志、dezoomify-rs 与验证提示本来就输出到 stderr）
+func openEvents() bool {
+	switch config.Conf.Events {
+	case "":
+		return true
+	case "json":
+	default:
+		log.Println(i18n.T("cmd.events_format", config.Conf.Events))
+		return false
+	}
+	if dest := config.Conf.EventsFile; dest != "" && dest != "-" {
+		if err := events.Default.OpenFile(dest); err != nil {
+			log.Println(i18n.T("cmd.events_open", err))
+			return false
+		}
+		return true
+	}
+	events.Default.SetOutput(os.Stdout)
+	progress.SetOutput(os.Stderr)
+	return true
+}
+
+// loadAuthStore 读取各站点的 localStorage / token / 请求头（--local-storage 与缓存目录中回传的凭据），cookie 仍以 cookie 文件为准
+func loadAuthStore() {
+	authstore.Default.CookieFile = config.Conf.CookieFile
+	for _, path := range []string{config.Conf.LocalStorage, app.AuthCacheFile()} {
+		if err := authstore.Default.Load(path); err != nil {
+			log.Println(i18n.T("cmd.read_failed", path, err))
+		}
+	}
+}
+
+// importBrowserCookies 从本地浏览器读取cookie，保存到缓存目录并作为 cookie 文件使用
+func importBrowserCookies() {
+	if config.Conf.CookieBrowser == "" {
+		return
+	}
+	list, err := cookies.ReadBrowser(config.Conf.CookieBrowser, "")
+	if err != nil {
+		log.Println(i18n.T("cmd.browser_cookie_read", err))
+		return
+	}
+	dest := filepath.Join(config.CacheDir(), "browser-cookies.txt")
+	if err = cookies.Save(dest, list); err != nil {
+		log.Println(i18n.T("cmd.browser_cookie_save", err))
+		return
+	}
+	config.Conf.CookieFile = dest
+	log.Println(i18n.T("cmd.browser_cookie_done", config.Conf.CookieBrowser, len(list)))
+}
+
 // executeByRunMode 根据运行模式执行相应操作
 func executeByRunMode(ctx context.Context) {
 	switch determineRunMode() {
@@ -60,9 +172,11 @@ func executeByRunMode(ctx context.Context) {
 		runInteractiveMode(ctx)
 	case RunModeInteractiveImage:
 		runInteractiveModeImage(ctx)
+	case RunModeTemplate:
+		executeTemplates()
 	}
 
-	log.Println("Download complete.")
+	log.Println(i18n.T("cmd.download_complete"))
 }
 
 type RunMode int
@@ -72,10 +186,14 @@ const (
 	RunModeBatchURLs
 	RunModeInteractive
 	RunModeInteractiveImage
+	RunModeTemplate
 )
 
 // determineRunMode 确定运行模式
 func determineRunMode() RunMode {
+	if config.Conf.Template != "" || config.Conf.TemplateFile != "" {
+		return RunModeTemplate
+	}
 	if config.Conf.AutoDetect == 1 {
 		return RunModeInteractiveImage
 	}
@@ -108,8 +226,13 @@ func executeBatchURLs() {
 		log.Println(err)
 		return
 	}
+	runBatch(app.ExpandUrls(allUrls))
+}
 
+// runBatch 并发下载一批URLs
+func runBatch(allUrls []string) {
 	q := queue.NewConcurrentQueue(int(config.Conf.Threads))
+	metrics.RegisterQueue("urls", q)
 	if config.Conf.AutoDetect == 1 {
 		processURLsAutoDetect(q, allUrls)
 	} else {
@@ -139,11 +262,18 @@ func runInteractiveModeImage(ctx context.Context) {
 	app.NewImageDownloader().Run("")
 }
 
+// executeTemplates 按 --template / --template-file 无人值守下载
+func executeTemplates() {
+	if err := app.NewImageDownloader().RunTemplates(); err != nil {
+		log.Println(err)
+	}
+}
+
 // loadAndFilterURLs 加载并过滤URLs
 func loadAndFilterURLs(filename string) ([]string, error) {
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("无法读取URL文件: %w", err)
+		return nil, i18n.Errorf("cmd.urls_read", err)
 	}
 
 	lines := strings.Split(string(content), "\n")
@@ -156,7 +286,7 @@ func loadAndFilterURLs(filename string) ([]string, error) {
 	}
 
 	if len(urls) == 0 {
-		return nil, fmt.Errorf("URL文件中没有有效的URL")
+		return nil, i18n.Error("cmd.urls_empty")
 	}
 
 	return urls, nil
@@ -184,7 +314,7 @@ func processURLsManual(q *queue.ConcurrentQueue, allUrls []string) {
 	for _, v := range allUrls {
 		u, err := url.Parse(v)
 		if err != nil {
-			log.Printf("URL解析失败: %s, 错误: %v\n", v, err)
+			log.Println(i18n.T("cmd.url_parse", v, err))
 			continue
 		}
 
@@ -211,11 +341,11 @@ func processURLSet(siteID string, rawUrl string) {
 // readURLFromInput 从用户输入读取URL
 func readURLFromInput() (string, error) {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Println("Enter an URL:")
-	fmt.Print("-> ")
+	fmt.Fprintln(os.Stderr, i18n.T("cmd.enter_url"))
+	fmt.Fprint(os.Stderr, "-> ")
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		return "", fmt.Errorf("读取输入失败: %w", err)
+		return "", i18n.Errorf("cmd.input_failed", err)
 	}
 	return strings.TrimSpace(input), nil
 }
@@ -224,12 +354,18 @@ func readURLFromInput() (string, error) {
 func processURL(ctx context.Context, rawUrl string) error {
 	rawURL := strings.TrimSpace(rawUrl)
 	if !isValidURL(rawURL) {
-		return fmt.Errorf("无效的URL: %s", rawUrl)
+		return i18n.Errorf("cmd.invalid_url", rawUrl)
 	}
 
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("URL解析失败: %w", err)
+		return i18n.Errorf("cmd.url_parse_err", err)
+	}
+
+	//书单、合集、检索结果：展开后批量下载
+	if urls := app.ExpandUrls([]string{rawURL}); len(urls) != 1 || urls[0] != rawURL {
+		runBatch(urls)
+		return nil
 	}
 
 	result, err := router.FactoryRouter(u.Host, rawURL)
@@ -245,8 +381,11 @@ func processURL(ctx context.Context, rawUrl string) error {
 
 // cleanupCookieFile 清理cookie文件
 func cleanupCookieFile() {
+	if config.Conf.CookieBrowser != "" {
+		return
+	}
 	if err := os.Remove(config.Conf.CookieFile); err != nil && !os.IsNotExist(err) {
-		log.Printf("清理cookie文件失败: %v\n", err)
+		log.Println(i18n.T("cmd.cookie_cleanup", err))
 	}
 }
 
@@ -254,14 +393,14 @@ func cleanupCookieFile() {
 func checkForUpdates() {
 	latestVersion, updateAvailable, err := versionChecker.CheckForUpdate()
 	if err != nil {
-		log.Printf("版本检查失败: %v\n", err)
+		log.Println(i18n.T("cmd.version_check", err))
 		return
 	}
 
 	if updateAvailable {
-		fmt.Printf("\n新版本可用: %s (当前版本: %s)\n", latestVersion, versionChecker.CurrentVersion)
-		fmt.Printf("请访问 https://github.com/deweizhu/bookget/releases/latest 升级。\n\n")
+		log.Println(i18n.T("cmd.new_version", latestVersion, versionChecker.CurrentVersion))
+		log.Println(i18n.T("cmd.upgrade", "https://github.com/deweizhu/bookget/releases/latest"))
 	} else if latestVersion != "" {
-		fmt.Printf("当前已是最新版本: %s\n", versionChecker.CurrentVersion)
+		log.Println(i18n.T("cmd.latest", versionChecker.CurrentVersion))
 	}
 }
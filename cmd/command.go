@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bookget/pkg/i18n"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Command 子命令，例如 bookget verify <dir>
+type Command struct {
+	Name  string
+	Usage string
+	Flags func()                                         //注册子命令专用参数（在 config.Init 之前调用）
+	Run   func(ctx context.Context, args []string) error //args 为去掉参数后的位置参数
+}
+
+var commands = map[string]*Command{}
+
+// errUsage 子命令参数错误时返回，会打印用法
+var errUsage = errors.New("invalid arguments")
+
+func registerCommand(c *Command) {
+	commands[c.Name] = c
+}
+
+// lookupCommand 第一个参数是已注册的子命令时返回该命令
+func lookupCommand() (*Command, bool) {
+	if len(os.Args) < 2 {
+		return nil, false
+	}
+	c, ok := commands[os.Args[1]]
+	return c, ok
+}
+
+// runCommand 执行子命令，全局参数（-output、-cookie 等）仍然有效
+func runCommand(ctx context.Context, c *Command) {
+	os.Args = append(os.Args[:1], os.Args[2:]...)
+	if c.Flags != nil {
+		c.Flags()
+	}
+	if !initializeConfig(ctx) {
+		return
+	}
+	if err := c.Run(ctx, interspersedArgs(flag.CommandLine)); err != nil {
+		fmt.Fprintf(os.Stderr, "bookget %s: %v\n", c.Name, err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, i18n.T("cmd.usage", c.Usage))
+		}
+		os.Exit(1)
+	}
+}
+
+// interspersedArgs 允许参数写在位置参数之后，如 bookget verify ./book --repair
+func interspersedArgs(fs *flag.FlagSet) []string {
+	var args []string
+	rest := fs.Args()
+	for len(rest) > 0 {
+		args = append(args, rest[0])
+		if err := fs.Parse(rest[1:]); err != nil {
+			break
+		}
+		rest = fs.Args()
+	}
+	return args
+}
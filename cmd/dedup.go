@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bookget/app"
+	"bookget/config"
+	"bookget/pkg/i18n"
+	"context"
+	"flag"
+	"fmt"
+)
+
+var dedupRequeue bool
+
+func init() {
+	registerCommand(&Command{
+		Name:  "dedup",
+		Usage: "dedup [--requeue] [dir]",
+		Flags: func() {
+			flag.BoolVar(&dedupRequeue, "requeue", false, i18n.T("flag.dedup.requeue"))
+		},
+		Run: runDedup,
+	})
+}
+
+// runDedup 检查已下载图书中的重复页与占位图
+func runDedup(ctx context.Context, args []string) error {
+	dir := config.Conf.SaveFolder
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	suspects, err := app.DedupBooks(dir, dedupRequeue)
+	if err != nil {
+		return err
+	}
+	if suspects > 0 && !dedupRequeue {
+		return fmt.Errorf("%d suspect page(s) found, run with --requeue to download them again", suspects)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bookget/app"
+	"bookget/pkg/i18n"
+	"context"
+	"flag"
+)
+
+var harvestFull bool
+
+func init() {
+	registerCommand(&Command{
+		Name:  "harvest",
+		Usage: "harvest [--full] <activity-stream-url>",
+		Flags: func() {
+			flag.BoolVar(&harvestFull, "full", false, i18n.T("flag.harvest.full"))
+		},
+		Run: runHarvest,
+	})
+}
+
+// runHarvest 按 IIIF Change Discovery 活动流增量下载整个馆藏
+func runHarvest(ctx context.Context, args []string) error {
+	if len(args) != 1 || !isValidURL(args[0]) {
+		return errUsage
+	}
+	failed, err := app.Harvest(args[0], harvestFull)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return i18n.Errorf("cmd.harvest_failed", failed)
+	}
+	return nil
+}
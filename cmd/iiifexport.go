@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bookget/app"
+	"bookget/pkg/i18n"
+	"bookget/pkg/iiifexport"
+	"context"
+	"flag"
+)
+
+var iiifExportOpts iiifexport.Options
+
+func init() {
+	registerCommand(&Command{
+		Name:  "iiif-export",
+		Usage: "iiif-export [--base-url URL] [--direction auto|rtl|ltr] [--tile-size N] <dir>",
+		Flags: func() {
+			flag.StringVar(&iiifExportOpts.BaseUrl, "base-url", iiifexport.DefaultBaseUrl, i18n.T("flag.iiif-export.base-url"))
+			flag.StringVar(&iiifExportOpts.Direction, "direction", iiifexport.DirectionAuto, i18n.T("flag.iiif-export.direction"))
+			flag.StringVar(&iiifExportOpts.Label, "label", "", i18n.T("flag.iiif-export.label"))
+			flag.IntVar(&iiifExportOpts.TileSize, "tile-size", 512, i18n.T("flag.iiif-export.tile-size"))
+		},
+		Run: runIIIFExport,
+	})
+}
+
+// runIIIFExport 导出 IIIF v3 manifest，用 Mirador / Universal Viewer 阅读本地副本
+func runIIIFExport(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return errUsage
+	}
+	return app.ExportIIIF(args[0], iiifExportOpts)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bookget/app"
+	"bookget/pkg/i18n"
+	"bookget/pkg/search"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	searchSite     string
+	searchPage     int
+	searchUrlsOnly bool
+)
+
+func init() {
+	registerCommand(&Command{
+		Name:  "search",
+		Usage: "search --site <host> [--page N] [--urls] \"query\"",
+		Flags: func() {
+			flag.StringVar(&searchSite, "site", "", i18n.T("flag.search.site", strings.Join(search.Sites(), ", ")))
+			flag.IntVar(&searchPage, "page", 1, i18n.T("flag.search.page"))
+			flag.BoolVar(&searchUrlsOnly, "urls", false, i18n.T("flag.search.urls"))
+		},
+		Run: runSearch,
+	})
+}
+
+// runSearch 检索站点，结果可重定向为 urls.txt：bookget search --site www.loc.gov "tao te ching" > urls.txt
+func runSearch(ctx context.Context, args []string) error {
+	if searchSite == "" || len(args) == 0 || searchPage < 1 {
+		return errUsage
+	}
+	results, err := app.Search(searchSite, strings.Join(args, " "), searchPage)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("cmd.search_none"))
+		return nil
+	}
+	search.Write(os.Stdout, results, searchUrlsOnly)
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bookget/app"
+	"bookget/config"
+	"bookget/pkg/i18n"
+	"context"
+	"flag"
+)
+
+var verifyRepair bool
+
+func init() {
+	registerCommand(&Command{
+		Name:  "verify",
+		Usage: "verify [--repair] [dir]",
+		Flags: func() {
+			flag.BoolVar(&verifyRepair, "repair", false, i18n.T("flag.verify.repair"))
+		},
+		Run: runVerify,
+	})
+}
+
+// runVerify 校验已下载图书的完整性
+func runVerify(ctx context.Context, args []string) error {
+	dir := config.Conf.SaveFolder
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	bad, err := app.VerifyBooks(dir, verifyRepair)
+	if err != nil {
+		return err
+	}
+	if bad > 0 {
+		return i18n.Errorf("cmd.verify_failed", bad)
+	}
+	return nil
+}
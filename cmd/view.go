@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bookget/app"
+	"bookget/config"
+	"bookget/pkg/i18n"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+)
+
+var viewListen string
+
+func init() {
+	registerCommand(&Command{
+		Name:  "view",
+		Usage: "view [--listen 127.0.0.1:8000] [dir]",
+		Flags: func() {
+			flag.StringVar(&viewListen, "listen", "127.0.0.1:8000", i18n.T("flag.view.listen"))
+		},
+		Run: runView,
+	})
+}
+
+// runView 在浏览器中校对已下载的图书
+func runView(ctx context.Context, args []string) error {
+	dir := config.Conf.SaveFolder
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	return app.ServeBooks(ctx, viewListen, dir)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bookget/pkg/i18n"
 	"context"
 	"flag"
 	"fmt"
@@ -14,16 +15,19 @@ import (
 )
 
 type Input struct {
-	DUrl         string //单个输入URL
-	UrlsFile     string //输入urls.txt
-	CookieFile   string //输入cookie.txt
-	LocalStorage string //localStorage.txt
-	Seq          string //页面范围 4:434
-	SeqStart     int
-	SeqEnd       int
-	Volume       string //册范围 4:434
-	VolStart     int
-	VolEnd       int
+	DUrl          string        //单个输入URL
+	UrlsFile      string        //输入urls.txt
+	CookieFile    string        //输入cookie.txt
+	CookieBrowser string        //从本地浏览器读取cookie，如 firefox、chrome、chromium:/path/to/profile
+	LocalStorage  string        //localStorage.txt
+	AuthListen    string        //真人验证/登录回调服务监听地址
+	AuthTimeout   time.Duration //等待浏览器回传 cookie 的超时
+	Seq           string        //页面范围 4:434
+	SeqStart      int
+	SeqEnd        int
+	Volume        string //册范围 4:434
+	VolStart      int
+	VolEnd        int
 
 	Speed      int    //限速
 	SaveFolder string //下载文件存放目录，默认为当前文件夹下 Downloads 目录下
@@ -31,7 +35,7 @@ type Input struct {
 	// ;0 = 禁用，1=启用 （只对支持的图书馆有效）
 	Format        string //;全高清图下载时，指定宽度像素（16开纸185mm*260mm，像素2185*3071）
 	UserAgent     string //自定义UserAgent
-	AutoDetect    int    //自动检测下载URL。可选值[0|1|2]，;0=默认;1=通用批量下载（类似IDM、迅雷）;2= IIIF manifest.json 自动检测下载图片
+	AutoDetect    int    //自动检测下载URL。可选值[0|1|2]，;0=默认;1=通用批量下载（类似IDM、迅雷）;2= IIIF manifest.json 自动检测下载图片，网页中没有 manifest 时分析查看器
 	DezoomifyPath string //dezoomify-rs 本地目录位置
 	DezoomifyRs   string //dezoomify-rs 参数
 	UseDziRs      bool   //启用DezoomifyRs下载IIIF
@@ -41,11 +45,57 @@ type Input struct {
 	Retry         int           //重试次数
 	Timeout       time.Duration //超时秒数
 	Bookmark      bool          //只下載書簽目錄（浙江寧波天一閣）
+	Text          string        //全文层 off | plain=纯文本 | ocr=带版面的 OCR | both（目前只有 HathiTrust）
+	TextOnly      bool          //只下载全文层，不下载图像
+	Quality       string        //多种文件可选时的偏好：best | tiff,jp2,jpeg,pdf 依次尝试（目前只有 loc.gov）
+	DateFrom      string        //报刊的日期范围 1905、1905-03 或 1905-03-01
+	DateTo        string        //
+	Issue         string        //期刊的期号范围 5、3:10 或 3:（目前只有 war1931）
+	PageNames     string        //文件命名方式 seq=按顺序 0001.jpg，label=附加网站页码 0001_f001r.jpg
+	ImageProc     ImageProc     //下载后的图像处理，可在 config.ini 中按站点配置
+	Dedup         Dedup         //重复页与占位图检查
+	Events        string        //机器可读的事件流格式，目前只有 json
+	EventsFile    string        //事件流写入的文件，空或 - 为 stdout
+	Metrics       string        //Prometheus 指标监听地址，如 :9090
+	LogLevel      string        //日志级别 debug | info | warn | error
+	LogFile       string        //日志文件
+	Quiet         bool          //只输出警告与错误，不显示进度
+	Template      string        //图片URL模板，如 https://example.org/{vol:03}/{page:04}.jpg（见 pkg/urltemplate）
+	TemplateFile  string        //模板文件，每行一个模板及其选项
+	TemplateVols  string        //--template 的册范围 1:3
+	TemplatePages string        //--template 的每册页数 120,98,143
+	TemplateStart string        //--template 的首页页码
+	Lang          string        //界面语言 zh-Hans | zh-Hant | en | ja，启动时已由 i18n.Detect 选择
 
 	Help    bool
 	Version bool
 }
 
+// ImageProc 下载后的图像处理（见 pkg/imageproc）
+type ImageProc struct {
+	Split    string //拆分跨页 rtl=右页在前 | ltr=左页在前
+	Crop     bool   //裁掉扫描黑边
+	Deskew   bool   //纠偏
+	Color    string //gray=灰度 | bitonal=黑白
+	MaxWidth int    //最大宽度
+	Quality  int    //JPEG 质量
+	Output   string //输出子目录，空为覆盖原文件
+}
+
+// Dedup 重复页与占位图检查（见 pkg/dedup）
+type Dedup struct {
+	Mode         string //off=不检查 | report=写入 dedup.json | requeue=同时拒收占位图以便重新下载
+	Placeholders string //已知占位图列表（MD5 或 pHash，每行一个）
+	Distance     int    //感知哈希汉明距离阈值
+	Repeat       int    //同一张图出现 N 次视为占位图
+	Duplicates   bool   //requeue 时完全重复的页面也重新下载，默认只重新下载占位图
+}
+
+var (
+	iniCfg       *ini.File       //config.ini，按站点读取 [imageproc.域名]
+	explicitFlag map[string]bool //命令行显式指定的参数
+)
+
 func Init(ctx context.Context) bool {
 
 	dir, _ := os.Getwd()
@@ -54,8 +104,8 @@ func Init(ctx context.Context) bool {
 	if os.PathSeparator == '\\' {
 		matched, _ := regexp.MatchString(`([^A-z0-9_\\/\-:.]+)`, dir)
 		if matched {
-			fmt.Println("本软件存放目录，不能包含空格、中文等特殊符号。推荐：D:\\bookget")
-			fmt.Println("按回车键终止程序。Press Enter to exit ...")
+			fmt.Fprintln(os.Stderr, i18n.T("config.bad_dir"))
+			fmt.Fprintln(os.Stderr, i18n.T("config.press_enter"))
 			endKey := make([]byte, 1)
 			os.Stdin.Read(endKey)
 			os.Exit(0)
@@ -63,28 +113,77 @@ func Init(ctx context.Context) bool {
 	}
 	iniConf, _ := initINI()
 
-	flag.StringVar(&Conf.UrlsFile, "input", iniConf.UrlsFile, "下载的URLs，指定任意本地文件，例如：urls.txt")
-	flag.StringVar(&Conf.SaveFolder, "output", iniConf.SaveFolder, "下载保存到目录")
-	flag.StringVar(&Conf.Seq, "sequence", iniConf.Seq, "页面范围，如4:434")
-	flag.StringVar(&Conf.Volume, "volume", iniConf.Volume, "多册图书，如10:20册，只下载10至20册")
-	flag.StringVar(&Conf.Format, "format", iniConf.Format, "IIIF 图像请求URI: full/full/0/default.jpg")
-	flag.StringVar(&Conf.UserAgent, "user-agent", iniConf.UserAgent, "user-agent")
-	flag.BoolVar(&Conf.Bookmark, "bookmark", iniConf.Bookmark, "只下载书签目录，可选值[0|1]。0=否，1=是。仅对 gj.tianyige.com.cn 有效。")
-	flag.BoolVar(&Conf.UseDziRs, "dezoomify-rs", iniConf.UseDziRs, "使用dezoomify-rs下载，仅对支持iiif的网站生效。")
-	flag.StringVar(&Conf.CookieFile, "cookie", iniConf.CookieFile, "指定cookie.txt文件路径")
-	flag.StringVar(&Conf.LocalStorage, "local-storage", iniConf.LocalStorage, "指定localStorage.txt文件路径")
-	flag.StringVar(&Conf.FileExt, "extension", iniConf.FileExt, "指定文件扩展名[.jpg|.tif|.png]等")
-	flag.IntVar(&Conf.Threads, "threads", iniConf.Threads, "最大线程数")
-	flag.IntVar(&Conf.MaxConcurrent, "concurrent", iniConf.MaxConcurrent, "最大并发任务数")
-	flag.IntVar(&Conf.Speed, "speed", iniConf.Speed, "下载限速 N 秒/任务，cuhk推荐5-60")
-	flag.IntVar(&Conf.Retry, "retry", iniConf.Retry, "下载重试次数")
-	flag.DurationVar(&Conf.Timeout, "timeout", iniConf.Timeout, "下载重试次数")
-	flag.IntVar(&Conf.AutoDetect, "auto-detect", iniConf.AutoDetect, "自动检测下载URL。可选值[0|1|2]，;0=默认;\n1=通用批量下载（类似IDM、迅雷）;\n2= IIIF manifest.json 自动检测下载图片")
-	flag.BoolVar(&Conf.Help, "help", false, "显示帮助")
-	flag.BoolVar(&Conf.Version, "version", false, "显示版本 -v")
-	flag.StringVar(&Conf.DezoomifyRs, "dezoomify-rs-args", iniConf.DezoomifyRs, "dezoomify-rs 参数")
+	flag.StringVar(&Conf.UrlsFile, "input", iniConf.UrlsFile, i18n.T("flag.input"))
+	flag.StringVar(&Conf.SaveFolder, "output", iniConf.SaveFolder, i18n.T("flag.output"))
+	flag.StringVar(&Conf.Seq, "sequence", iniConf.Seq, i18n.T("flag.sequence"))
+	flag.StringVar(&Conf.Volume, "volume", iniConf.Volume, i18n.T("flag.volume"))
+	flag.StringVar(&Conf.Format, "format", iniConf.Format, i18n.T("flag.format"))
+	flag.StringVar(&Conf.UserAgent, "user-agent", iniConf.UserAgent, i18n.T("flag.user-agent"))
+	flag.BoolVar(&Conf.Bookmark, "bookmark", iniConf.Bookmark, i18n.T("flag.bookmark"))
+	flag.StringVar(&Conf.Text, "text", "off", i18n.T("flag.text"))
+	flag.BoolVar(&Conf.TextOnly, "text-only", false, i18n.T("flag.text-only"))
+	flag.StringVar(&Conf.Quality, "quality", "", i18n.T("flag.quality"))
+	flag.StringVar(&Conf.DateFrom, "date-from", "", i18n.T("flag.date-from"))
+	flag.StringVar(&Conf.DateTo, "date-to", "", i18n.T("flag.date-to"))
+	flag.StringVar(&Conf.Issue, "issue", "", i18n.T("flag.issue"))
+	flag.BoolVar(&Conf.UseDziRs, "dezoomify-rs", iniConf.UseDziRs, i18n.T("flag.dezoomify-rs"))
+	flag.StringVar(&Conf.CookieFile, "cookie", iniConf.CookieFile, i18n.T("flag.cookie"))
+	flag.StringVar(&Conf.CookieBrowser, "cookie-from-browser", iniConf.CookieBrowser, i18n.T("flag.cookie-from-browser"))
+	flag.StringVar(&Conf.LocalStorage, "local-storage", iniConf.LocalStorage, i18n.T("flag.local-storage"))
+	flag.StringVar(&Conf.AuthListen, "auth-listen", iniConf.AuthListen, i18n.T("flag.auth-listen"))
+	flag.DurationVar(&Conf.AuthTimeout, "auth-timeout", iniConf.AuthTimeout, i18n.T("flag.auth-timeout"))
+	flag.StringVar(&Conf.PageNames, "page-names", iniConf.PageNames, i18n.T("flag.page-names"))
+	flag.StringVar(&Conf.ImageProc.Split, "split", iniConf.ImageProc.Split, i18n.T("flag.split"))
+	flag.BoolVar(&Conf.ImageProc.Crop, "crop", iniConf.ImageProc.Crop, i18n.T("flag.crop"))
+	flag.BoolVar(&Conf.ImageProc.Deskew, "deskew", iniConf.ImageProc.Deskew, i18n.T("flag.deskew"))
+	flag.StringVar(&Conf.ImageProc.Color, "color", iniConf.ImageProc.Color, i18n.T("flag.color"))
+	flag.IntVar(&Conf.ImageProc.MaxWidth, "max-width", iniConf.ImageProc.MaxWidth, i18n.T("flag.max-width"))
+	flag.StringVar(&Conf.ImageProc.Output, "imageproc-output", iniConf.ImageProc.Output, i18n.T("flag.imageproc-output"))
+	flag.StringVar(&Conf.Dedup.Mode, "dedup", iniConf.Dedup.Mode, i18n.T("flag.dedup"))
+	flag.StringVar(&Conf.Events, "events", "", i18n.T("flag.events"))
+	flag.StringVar(&Conf.EventsFile, "events-file", "", i18n.T("flag.events-file"))
+	flag.StringVar(&Conf.Metrics, "metrics", "", i18n.T("flag.metrics"))
+	flag.StringVar(&Conf.LogLevel, "log-level", iniConf.LogLevel, i18n.T("flag.log-level"))
+	flag.StringVar(&Conf.LogFile, "log-file", iniConf.LogFile, i18n.T("flag.log-file"))
+	flag.BoolVar(&Conf.Quiet, "quiet", false, i18n.T("flag.quiet"))
+	flag.StringVar(&Conf.Template, "template", "", i18n.T("flag.template"))
+	flag.StringVar(&Conf.TemplateFile, "template-file", "", i18n.T("flag.template-file"))
+	flag.StringVar(&Conf.TemplateVols, "template-vols", "", i18n.T("flag.template-vols"))
+	flag.StringVar(&Conf.TemplatePages, "template-pages", "", i18n.T("flag.template-pages"))
+	flag.StringVar(&Conf.TemplateStart, "template-start", "", i18n.T("flag.template-start"))
+	flag.StringVar(&Conf.Lang, "lang", i18n.Lang(), i18n.T("flag.lang"))
+	flag.StringVar(&Conf.FileExt, "extension", iniConf.FileExt, i18n.T("flag.extension"))
+	flag.IntVar(&Conf.Threads, "threads", iniConf.Threads, i18n.T("flag.threads"))
+	flag.IntVar(&Conf.MaxConcurrent, "concurrent", iniConf.MaxConcurrent, i18n.T("flag.concurrent"))
+	flag.IntVar(&Conf.Speed, "speed", iniConf.Speed, i18n.T("flag.speed"))
+	flag.IntVar(&Conf.Retry, "retry", iniConf.Retry, i18n.T("flag.retry"))
+	flag.DurationVar(&Conf.Timeout, "timeout", iniConf.Timeout, i18n.T("flag.timeout"))
+	flag.IntVar(&Conf.AutoDetect, "auto-detect", iniConf.AutoDetect, i18n.T("flag.auto-detect"))
+	flag.BoolVar(&Conf.Help, "help", false, i18n.T("flag.help"))
+	flag.BoolVar(&Conf.Version, "version", false, i18n.T("flag.version"))
+	flag.StringVar(&Conf.DezoomifyRs, "dezoomify-rs-args", iniConf.DezoomifyRs, i18n.T("flag.dezoomify-rs-args"))
 	Conf.DezoomifyPath = iniConf.DezoomifyPath
 	flag.Parse()
+	if err := i18n.SetLang(Conf.Lang); err == nil {
+		Conf.Lang = i18n.Lang()
+	}
+	explicitFlag = make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicitFlag[f.Name] = true
+	})
+	//只下载全文层时 -text 默认为 plain，显式写 off 则无事可做
+	if Conf.TextOnly && (Conf.Text == "" || strings.EqualFold(Conf.Text, "off")) {
+		if explicitFlag["text"] {
+			fmt.Fprintln(os.Stderr, i18n.T("config.text_only_off"))
+			return false
+		}
+		Conf.Text = "plain"
+	}
+	Conf.ImageProc.Quality = iniConf.ImageProc.Quality
+	Conf.Dedup.Placeholders = iniConf.Dedup.Placeholders
+	Conf.Dedup.Distance = iniConf.Dedup.Distance
+	Conf.Dedup.Repeat = iniConf.Dedup.Repeat
+	Conf.Dedup.Duplicates = iniConf.Dedup.Duplicates
 
 	k := len(os.Args)
 	if k == 2 {
@@ -97,6 +196,10 @@ func Init(ctx context.Context) bool {
 			return false
 		}
 	}
+	if Conf.Help {
+		printHelp()
+		return false
+	}
 	v := flag.Arg(0)
 	if strings.HasPrefix(v, "http") {
 		Conf.DUrl = v
@@ -110,6 +213,10 @@ func Init(ctx context.Context) bool {
 	}
 	initSeqRange()
 	initVolumeRange()
+	if err := initDateRange(); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("config.error", err))
+		return false
+	}
 	//保存目录处理
 	_ = os.Mkdir(Conf.SaveFolder, os.ModePerm)
 	_ = os.Mkdir(CacheDir(), os.ModePerm)
@@ -129,7 +236,7 @@ func initINI() (io Input, err error) {
 	}
 
 	if err := CreateConfigIfNotExists(configPath); err != nil {
-		fmt.Printf("错误: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("config.error", err))
 		os.Exit(1)
 	}
 
@@ -145,6 +252,8 @@ func initINI() (io Input, err error) {
 		UrlsFile:      urls,
 		CookieFile:    cFile,
 		LocalStorage:  localStorage,
+		AuthListen:    "127.0.0.1:0",
+		AuthTimeout:   30 * time.Minute,
 		Seq:           "",
 		SeqStart:      0,
 		SeqEnd:        0,
@@ -164,6 +273,10 @@ func initINI() (io Input, err error) {
 		MaxConcurrent: c,
 		Retry:         3,
 		Bookmark:      false,
+		PageNames:     "seq",
+		ImageProc:     ImageProc{Quality: 90, Output: "processed"},
+		Dedup:         Dedup{Mode: "off", Distance: 4, Repeat: 3},
+		LogLevel:      "info",
 		Help:          false,
 		Version:       false,
 	}
@@ -195,8 +308,19 @@ func initINI() (io Input, err error) {
 	}
 
 	// 读取cookie和localStorage路径
-	io.CookieFile = cfg.Section("paths").Key("cookie").String()
-	io.LocalStorage = cfg.Section("paths").Key("local-storage").String()
+	//留空时使用当前目录下的 cookie.txt / localStorage.txt
+	if v := cfg.Section("paths").Key("cookie").String(); v != "" {
+		io.CookieFile = v
+	}
+	if v := cfg.Section("paths").Key("local-storage").String(); v != "" {
+		io.LocalStorage = v
+	}
+	io.CookieBrowser = cfg.Section("paths").Key("cookie-from-browser").String()
+
+	// 读取真人验证/登录回调设置
+	secAuth := cfg.Section("auth")
+	io.AuthListen = secAuth.Key("listen").MustString("127.0.0.1:0")
+	io.AuthTimeout = secAuth.Key("timeout").MustDuration(30 * time.Minute)
 
 	// 读取下载相关设置
 	secDown := cfg.Section("download")
@@ -218,9 +342,27 @@ func initINI() (io Input, err error) {
 	io.Seq = secCus.Key("sequence").String()
 	io.Volume = secCus.Key("volume").String()
 	io.Bookmark = secCus.Key("bookmark").MustBool(false)
+	io.PageNames = secCus.Key("page-names").MustString("seq")
 	io.UserAgent = secCus.Key("user-agent").MustString(ua)
 	io.UrlsFile = secCus.Key("input").String() // 读取URLs文件路径
 
+	// 读取图像处理设置
+	io.ImageProc = readImageProc(cfg.Section("imageproc"), io.ImageProc)
+	iniCfg = cfg
+
+	// 读取重复页检查设置
+	secDedup := cfg.Section("dedup")
+	io.Dedup.Mode = secDedup.Key("mode").In("off", []string{"off", "report", "requeue"})
+	io.Dedup.Placeholders = secDedup.Key("placeholders").String()
+	io.Dedup.Distance = secDedup.Key("distance").MustInt(4)
+	io.Dedup.Repeat = secDedup.Key("repeat").MustInt(3)
+	io.Dedup.Duplicates = secDedup.Key("duplicates").MustBool(false)
+
+	// 读取日志设置
+	secLog := cfg.Section("log")
+	io.LogLevel = secLog.Key("level").In("info", []string{"debug", "info", "warn", "error"})
+	io.LogFile = secLog.Key("file").String()
+
 	// 读取dzi相关设置
 	secDzi := cfg.Section("dzi")
 	io.UseDziRs = secDzi.Key("dezoomify-rs").MustBool(false)
@@ -233,9 +375,58 @@ func initINI() (io Input, err error) {
 	return io, nil
 }
 
+func readImageProc(sec *ini.Section, def ImageProc) ImageProc {
+	//字符串允许设为空值（如 output = "" 表示覆盖原图），不能用 MustString
+	str := func(key, def string) string {
+		if sec.HasKey(key) {
+			return sec.Key(key).String()
+		}
+		return def
+	}
+	return ImageProc{
+		Split:    str("split", def.Split),
+		Crop:     sec.Key("crop").MustBool(def.Crop),
+		Deskew:   sec.Key("deskew").MustBool(def.Deskew),
+		Color:    str("color", def.Color),
+		MaxWidth: sec.Key("max-width").MustInt(def.MaxWidth),
+		Quality:  sec.Key("quality").MustInt(def.Quality),
+		Output:   str("output", def.Output),
+	}
+}
+
+// ImageProcFor 站点 host 的图像处理设置：config.ini 中 [imageproc.域名] 覆盖 [imageproc]，命令行参数优先
+func ImageProcFor(host string) ImageProc {
+	p := Conf.ImageProc
+	if iniCfg == nil || host == "" {
+		return p
+	}
+	sec, err := iniCfg.GetSection("imageproc." + host)
+	if err != nil {
+		return p
+	}
+	site := readImageProc(sec, p)
+	for name := range explicitFlag {
+		switch name {
+		case "split":
+			site.Split = p.Split
+		case "crop":
+			site.Crop = p.Crop
+		case "deskew":
+			site.Deskew = p.Deskew
+		case "color":
+			site.Color = p.Color
+		case "max-width":
+			site.MaxWidth = p.MaxWidth
+		case "imageproc-output":
+			site.Output = p.Output
+		}
+	}
+	return site
+}
+
 func printHelp() {
 	printVersion()
-	fmt.Println(`Usage: bookget [OPTION]... [URL]...`)
+	fmt.Println(i18n.T("help.usage"))
 	flag.PrintDefaults()
 	fmt.Println("Originally written by zhudw <zhudwi@outlook.com>.")
 	fmt.Println("https://github.com/deweizhu/bookget/")
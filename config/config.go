@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bookget/pkg/i18n"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,9 +20,19 @@ output = ""
 # 指定cookie.txt文件路径
 cookie = ""
 
-# 指定localStorage.txt文件路径
+# 指定localStorage.txt文件路径，也可以是 JSON 导出（按站点保存 localStorage、sessionStorage、token、请求头）
 local-storage = ""
 
+# 从本地浏览器读取cookie（仅Linux），可选值[firefox|chrome|chromium|edge|brave]
+cookie-from-browser = ""
+
+[auth]
+# 真人验证/登录回调服务监听地址，0 端口表示随机；SSH 远程可用 ssh -L 转发，或设为 0.0.0.0:8765
+listen = "127.0.0.1:0"
+
+# 等待浏览器回传cookie的超时
+timeout = 30m
+
 [download]
 # 指定文件扩展名[.jpg|.tif|.png|.pdf]等
 extension = ".jpg"
@@ -52,9 +63,65 @@ user-agent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTM
 # 只下载书签目录，可选值[0|1]。0=否，1=是。仅对 gj.tianyige.com.cn 有效。
 bookmark = 0
 
+# 文件命名方式，可选值[seq|label]。seq=0001.jpg，label=按网站页码/叶码命名，如 0001_f001r.jpg（仍按序号排序）
+page-names = "seq"
+
 # 下载的URLs，指定任意本地文件，例如：urls.txt
 input = ""
 
+[imageproc]
+# 下载后的图像处理，只处理 JPEG/PNG。
+# 拆分跨页，可选值[rtl|ltr]。rtl=右页在前（古籍），ltr=左页在前，空值=不拆分
+split = ""
+
+# 裁掉扫描黑边，可选值[0|1]
+crop = 0
+
+# 纠偏，可选值[0|1]
+deskew = 0
+
+# 颜色转换，可选值[gray|bitonal]，空值=保持原色
+color = ""
+
+# 图片最大宽度（像素），0=不限制
+max-width = 0
+
+# JPEG 质量
+quality = 90
+
+# 处理结果保存到图书目录下的子目录，空值=覆盖原图
+output = "processed"
+
+# 按站点单独设置，未设置的项沿用 [imageproc]，例如：
+# [imageproc.gj.tianyige.com.cn]
+# split = "rtl"
+# crop = 1
+
+[dedup]
+# 重复页与占位图检查，可选值[off|report|requeue]，默认 off（检查需要解码每一页并计算哈希）
+# report=把重复页、占位图（如限流时返回的同一张图、“图像不可用”卡片）写入图书目录下的 dedup.json
+# requeue=同时拒收占位图（不保存），重新运行或 bookget verify --repair 即可重新下载
+mode = "off"
+
+# 已知占位图列表文件，每行一个 MD5 或 pHash（见 dedup.json / bookget dedup 的输出）
+placeholders = ""
+
+# 感知哈希（dHash/pHash）汉明距离不超过此值视为近似重复
+distance = 4
+
+# 同一张图出现 N 次及以上视为占位图
+repeat = 3
+
+# requeue 时与前面某页完全相同的页面也重新下载，默认只报告（古籍的空白页、重复的图版也会完全相同）
+duplicates = 0
+
+[log]
+# 日志级别，可选值[debug|info|warn|error]。debug 会输出请求详情（Cookie、Authorization、sign/token 参数已隐去）
+level = "info"
+
+# 同时写入日志文件，空值=只输出到控制台
+file = ""
+
 [dzi]
 # 使用dezoomify-rs下载，仅对支持iiif的网站生效。
 # 0 = 禁用，1=启用
@@ -76,18 +143,18 @@ func CreateConfigIfNotExists(configPath string) error {
 		dir := filepath.Dir(configPath)
 		if dir != "" {
 			if err := os.MkdirAll(dir, 0755); err != nil {
-				return fmt.Errorf("创建目录失败: %w", err)
+				return i18n.Errorf("config.mkdir_failed", err)
 			}
 		}
 
 		// 文件不存在，创建并写入内容
 		if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
-			return fmt.Errorf("创建配置文件失败: %w", err)
+			return i18n.Errorf("config.write_failed", err)
 		}
-		fmt.Printf("配置文件已创建: %s\n", configPath)
+		fmt.Fprintln(os.Stderr, i18n.T("config.created", configPath))
 	} else if err != nil {
 		// 其他错误
-		return fmt.Errorf("检查配置文件失败: %w", err)
+		return i18n.Errorf("config.stat_failed", err)
 	}
 	//else {
 	//	fmt.Printf("配置文件在: %s\n", configPath)
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"bookget/pkg/i18n"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var Conf Input
@@ -42,6 +44,42 @@ func initVolumeRange() {
 	return
 }
 
+// initDateRange 日期范围统一为 2006-01-02 的写法，可只写年或年月；月、日补零，其它写法报错
+func initDateRange() (err error) {
+	if Conf.DateFrom, err = normalizeDate(Conf.DateFrom); err != nil {
+		return err
+	}
+	Conf.DateTo, err = normalizeDate(Conf.DateTo)
+	return err
+}
+
+// normalizeDate 1905/3/1、1905.3 等写法改为 1905-03-01、1905-03
+func normalizeDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	m := strings.Split(strings.NewReplacer("/", "-", ".", "-").Replace(s), "-")
+	if len(m) > 3 || len(m[0]) != 4 {
+		return "", i18n.Errorf("config.bad_date", s)
+	}
+	n := make([]int, 3)
+	n[1], n[2] = 1, 1
+	for i, v := range m {
+		d, err := strconv.Atoi(v)
+		if err != nil || strings.Trim(v, "0123456789") != "" || (i > 0 && len(v) > 2) {
+			return "", i18n.Errorf("config.bad_date", s)
+		}
+		n[i] = d
+	}
+	t := time.Date(n[0], time.Month(n[1]), n[2], 0, 0, 0, 0, time.UTC)
+	if t.Year() != n[0] || int(t.Month()) != n[1] || t.Day() != n[2] {
+		return "", i18n.Errorf("config.bad_date", s)
+	}
+	layout := []string{"2006", "2006-01", "2006-01-02"}[len(m)-1]
+	return t.Format(layout), nil
+}
+
 func UserHomeDir() string {
 	if os.PathSeparator == '\\' {
 		home := os.Getenv("HOMEDRIVE") + os.Getenv("HOMEPATH")
@@ -51,3 +51,21 @@ func VolumeRange(index int) bool {
 	}
 	return false
 }
+
+// DateRange 日期 2006-01-02 是否在 --date-from、--date-to 范围内；--date-to 只写年或年月时包含整年、整月。
+// date 也可以只有年（2006）或年月（2006-01），此时与范围有交集即为 true，用于先按年、月筛选
+func DateRange(date string) bool {
+	if from := Conf.DateFrom; from != "" {
+		n := min(len(date), len(from))
+		if date[:n] < from[:n] {
+			return false
+		}
+	}
+	if to := Conf.DateTo; to != "" {
+		n := min(len(date), len(to))
+		if date[:n] > to[:n] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,47 +1,59 @@
 package iiif
 
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
 // ManifestResponse by view-source:https://iiif.lib.harvard.edu/manifests/drs:53262215
 type ManifestResponse struct {
+	Label     Label `json:"label"`
 	Sequences []struct {
-		Canvases []struct {
-			Id   string `json:"@id"`
-			Type string `json:"@type"`
-			//兼容某些不正规的网站竟然用了string类型，见https://digitalarchive.npm.gov.tw/Antique/setJsonU?uid=58102&Dept=U
-			//Height int    `json:"height"`
-			Images []struct {
-				Id       string `json:"@id"`
-				Type     string `json:"@type"`
-				On       string `json:"on"`
-				Resource struct {
-					Id     string `json:"@id"`
-					Type   string `json:"@type"`
-					Format string `json:"format"`
-					//兼容digitalarchive.npm.gov.tw
-					//Height  int    `json:"height"`
-					Service struct {
-						Id string `json:"@id"`
-					} `json:"service"`
-					Width int `json:"width"`
-				} `json:"resource"`
-			} `json:"images"`
-			Label string `json:"label"`
-			//Width int    `json:"width"`
-		} `json:"canvases"`
+		Canvases []Canvas `json:"canvases"`
 	} `json:"sequences"`
 }
 
+// Canvas IIIF v2 清单中的一页
+type Canvas struct {
+	Id   string `json:"@id"`
+	Type string `json:"@type"`
+	//兼容某些不正规的网站竟然用了string类型，见https://digitalarchive.npm.gov.tw/Antique/setJsonU?uid=58102&Dept=U
+	//Height int    `json:"height"`
+	Images []Image `json:"images"`
+	Label  Label   `json:"label"`
+	//Width int    `json:"width"`
+}
+
+// Image 画布上的一张图
+type Image struct {
+	Id       string `json:"@id"`
+	Type     string `json:"@type"`
+	On       string `json:"on"`
+	Resource struct {
+		Id     string `json:"@id"`
+		Type   string `json:"@type"`
+		Format string `json:"format"`
+		//兼容digitalarchive.npm.gov.tw
+		//Height  int    `json:"height"`
+		Service struct {
+			Id string `json:"@id"`
+		} `json:"service"`
+		Width int `json:"width"`
+	} `json:"resource"`
+}
+
 // ManifestV3Response  https://iiif.io/api/presentation/3.0/#52-manifest
 type ManifestV3Response struct {
-	Id    string `json:"id"`
-	Type  string `json:"type"`
-	Label struct {
-		None []string `json:"none"`
-	} `json:"label"`
-	Height   int `json:"height"`
-	Width    int `json:"width"`
+	Id       string `json:"id"`
+	Type     string `json:"type"`
+	Label    Label  `json:"label"`
+	Height   int    `json:"height"`
+	Width    int    `json:"width"`
 	Canvases []struct {
 		Id     string `json:"id"`
 		Type   string `json:"type"`
+		Label  Label  `json:"label"`
 		Height int    `json:"height"`
 		Width  int    `json:"width"`
 		Items  []struct {
@@ -82,3 +94,54 @@ type ManifestPresentation struct {
 	Context string `json:"@context"`
 	Id      string `json:"id"`
 }
+
+// Label 兼容各种写法的 label：字符串、数字、数组、{"@value":"…"}，以及 v3 的语言映射 {"none":["…"]}
+type Label string
+
+func (l *Label) UnmarshalJSON(b []byte) error {
+	*l = Label(labelString(b))
+	return nil
+}
+
+func labelString(b []byte) string {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(b, &n) == nil {
+		return n.String()
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(b, &list) == nil {
+		parts := make([]string, 0, len(list))
+		for _, v := range list {
+			if s = labelString(v); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(b, &obj) != nil {
+		return ""
+	}
+	if v, ok := obj["@value"]; ok {
+		return labelString(v)
+	}
+	//语言映射：优先 none，其次按语言代码顺序取第一个
+	if v, ok := obj["none"]; ok {
+		return labelString(v)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if s = labelString(obj[k]); s != "" {
+			return s
+		}
+	}
+	return ""
+}
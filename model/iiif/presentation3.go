@@ -0,0 +1,118 @@
+package iiif
+
+// 生成 IIIF Presentation 3.0 / Image API 3.0 文档用的类型（bookget iiif-export）
+
+const (
+	PresentationContext3 = "http://iiif.io/api/presentation/3/context.json"
+	ImageContext3        = "http://iiif.io/api/image/3/context.json"
+)
+
+// LanguageMap {"zh": ["…"]}，未知语言用 "none"
+type LanguageMap map[string][]string
+
+func NewLanguageMap(lang, value string) LanguageMap {
+	if lang == "" {
+		lang = "none"
+	}
+	return LanguageMap{lang: {value}}
+}
+
+type Manifest3 struct {
+	Context          string        `json:"@context"`
+	Id               string        `json:"id"`
+	Type             string        `json:"type"`
+	Label            LanguageMap   `json:"label"`
+	Metadata         []MetadataKV3 `json:"metadata,omitempty"`
+	ViewingDirection string        `json:"viewingDirection,omitempty"`
+	Behavior         []string      `json:"behavior,omitempty"`
+	Homepage         []Resource3   `json:"homepage,omitempty"`
+	Items            []*Canvas3    `json:"items"`
+	Structures       []*Range3     `json:"structures,omitempty"`
+}
+
+type MetadataKV3 struct {
+	Label LanguageMap `json:"label"`
+	Value LanguageMap `json:"value"`
+}
+
+type Canvas3 struct {
+	Id        string            `json:"id"`
+	Type      string            `json:"type"`
+	Label     LanguageMap       `json:"label,omitempty"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Thumbnail []Resource3       `json:"thumbnail,omitempty"`
+	Items     []*AnnotationPage `json:"items"`
+}
+
+type AnnotationPage struct {
+	Id    string        `json:"id"`
+	Type  string        `json:"type"`
+	Items []*Annotation `json:"items"`
+}
+
+type Annotation struct {
+	Id         string    `json:"id"`
+	Type       string    `json:"type"`
+	Motivation string    `json:"motivation"`
+	Body       Resource3 `json:"body"`
+	Target     string    `json:"target"`
+}
+
+// Resource3 图片、网页等外部资源
+type Resource3 struct {
+	Id      string      `json:"id"`
+	Type    string      `json:"type"`
+	Format  string      `json:"format,omitempty"`
+	Label   LanguageMap `json:"label,omitempty"`
+	Width   int         `json:"width,omitempty"`
+	Height  int         `json:"height,omitempty"`
+	Service []Service3  `json:"service,omitempty"`
+}
+
+type Service3 struct {
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Profile string `json:"profile"`
+}
+
+// Range3 目录（structures）
+type Range3 struct {
+	Id    string      `json:"id"`
+	Type  string      `json:"type"`
+	Label LanguageMap `json:"label"`
+	Items []RangeItem `json:"items"`
+}
+
+// RangeItem 为 Canvas 引用（Id/Type）或嵌套的 *Range3
+type RangeItem interface{}
+
+// CanvasRef Range 中引用的 Canvas
+type CanvasRef struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// ImageInfo3 Image API 3.0 info.json（level0 静态切片）
+type ImageInfo3 struct {
+	Context  string      `json:"@context"`
+	Id       string      `json:"id"`
+	Type     string      `json:"type"`
+	Protocol string      `json:"protocol"`
+	Profile  string      `json:"profile"`
+	Width    int         `json:"width"`
+	Height   int         `json:"height"`
+	Sizes    []ImageSize `json:"sizes,omitempty"`
+	Tiles    []ImageTile `json:"tiles,omitempty"`
+}
+
+type ImageSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type ImageTile struct {
+	Width        int   `json:"width"`
+	Height       int   `json:"height,omitempty"`
+	ScaleFactors []int `json:"scaleFactors"`
+}
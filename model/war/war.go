@@ -3,9 +3,20 @@ package war
 type PartialVolumes struct {
 	Directory string
 	Title     string
+	Date      string //报纸的日期 2006-01-02
+	Issue     string //期刊的期名
 	Volumes   []string
 }
 
+// IssueInfo 报刊每期目录中的 info.json
+type IssueInfo struct {
+	Info    Info   `json:"info"`
+	Title   string `json:"title"`
+	Date    string `json:"date,omitempty"`
+	Issue   string `json:"issue,omitempty"`
+	JsonUrl string `json:"jsonUrl"`
+}
+
 type DetailsInfo struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
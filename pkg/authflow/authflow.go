@@ -0,0 +1,317 @@
+// Package authflow 跨平台的「真人验证 / 登录」流程：在本地启动一个回调网页，
+// 用户在自己的浏览器里完成验证后，通过书签小工具（bookmarklet）或浏览器扩展把 cookie 与 localStorage
+// POST 回 bookget。无需 bookget-gui，SSH 远程时可用端口转发打开打印出的 URL。
+package authflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bookget/pkg/cookies"
+	"bookget/pkg/i18n"
+)
+
+var (
+	ErrTimeout  = errors.New("auth: timed out waiting for browser callback")
+	ErrCanceled = errors.New("auth: canceled")
+)
+
+// DefaultTimeout 未指定超时时的等待时间
+const DefaultTimeout = 30 * time.Minute
+
+type Options struct {
+	Listen    string        //监听地址，默认 127.0.0.1:0（随机端口）
+	Timeout   time.Duration //等待超时
+	TargetUrl string        //需要验证/登录的网址，显示在回调页面
+	Message   string        //额外提示
+	// Done 不为 nil 时定期调用，返回 true 表示已通过其它途径（如 bookget-gui 写入 cookie.txt）完成
+	Done func() bool
+}
+
+// Result 浏览器回传的数据
+type Result struct {
+	Url            string            //回传时所在页面
+	Cookies        []*http.Cookie    //已补全域名
+	LocalStorage   map[string]string //页面 localStorage
+	SessionStorage map[string]string //页面 sessionStorage
+}
+
+// payload 书签小工具 / 扩展 POST 的 JSON。
+// cookie 为 document.cookie 字符串；cookies 为扩展通过 chrome.cookies.getAll 取得的数组（含 HttpOnly）
+type payload struct {
+	Url            string            `json:"url"`
+	Cookie         string            `json:"cookie"`
+	Cookies        json.RawMessage   `json:"cookies"`
+	LocalStorage   map[string]string `json:"localStorage"`
+	SessionStorage map[string]string `json:"sessionStorage"`
+}
+
+type server struct {
+	token   string
+	opts    Options
+	baseUrl string
+	result  chan *Result
+	cancel  chan struct{}
+}
+
+// Wait 启动回调服务并等待浏览器回传，直到收到数据、超时、ctx 取消或用户在页面上点击取消
+func Wait(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Listen == "" {
+		opts.Listen = "127.0.0.1:0"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	ln, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		token:  newToken(),
+		opts:   opts,
+		result: make(chan *Result, 1),
+		cancel: make(chan struct{}, 1),
+	}
+	s.baseUrl = "http://" + displayAddr(ln.Addr().(*net.TCPAddr))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/callback/"+s.token, s.handleCallback)
+	mux.HandleFunc("/cancel/"+s.token, s.handleCancel)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	s.printHelp()
+
+	ctx, stop := context.WithTimeout(ctx, opts.Timeout)
+	defer stop()
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-s.result:
+			return r, nil
+		case <-s.cancel:
+			return nil, ErrCanceled
+		case <-ticker.C:
+			if opts.Done != nil && opts.Done() {
+				return nil, nil
+			}
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrTimeout
+			}
+			return nil, ErrCanceled
+		}
+	}
+}
+
+// printHelp 提示写到 stderr，stdout 可能是 --events 事件流
+func (s *server) printHelp() {
+	fmt.Fprintln(os.Stderr, i18n.T("auth.open"))
+	fmt.Fprintln(os.Stderr, s.baseUrl+"/")
+	if s.opts.TargetUrl != "" {
+		fmt.Fprintln(os.Stderr, i18n.T("auth.target", s.opts.TargetUrl))
+	}
+	if s.opts.Message != "" {
+		fmt.Fprintln(os.Stderr, s.opts.Message)
+	}
+	if host, port, err := net.SplitHostPort(strings.TrimPrefix(s.baseUrl, "http://")); err == nil && isLoopback(host) {
+		fmt.Fprintln(os.Stderr, i18n.T("auth.ssh", port, port))
+	}
+	fmt.Fprintln(os.Stderr, i18n.T("auth.wait", s.opts.Timeout))
+}
+
+func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = indexTpl.Execute(w, map[string]interface{}{
+		"TargetUrl":   s.opts.TargetUrl,
+		"Message":     s.opts.Message,
+		"CallbackUrl": s.baseUrl + "/callback/" + s.token,
+		"CancelUrl":   s.baseUrl + "/cancel/" + s.token,
+		"Bookmarklet": template.URL(bookmarklet(s.baseUrl + "/callback/" + s.token)),
+	})
+}
+
+func (s *server) handleCallback(w http.ResponseWriter, r *http.Request) {
+	//书签小工具运行在图书馆网页中，跨域请求本地服务
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Private-Network", "true")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 8<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var p payload
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		//回调页面上的手动粘贴表单
+		form, _ := url.ParseQuery(string(body))
+		p.Url = form.Get("url")
+		p.Cookie = form.Get("cookie")
+	} else if err = json.Unmarshal(body, &p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := p.toResult(s.opts.TargetUrl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	select {
+	case s.result <- result:
+	default:
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<p>%s</p>", html.EscapeString(i18n.T("auth.received", len(result.Cookies), len(result.LocalStorage), len(result.SessionStorage))))
+}
+
+func (s *server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	select {
+	case s.cancel <- struct{}{}:
+	default:
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<p>%s</p>", html.EscapeString(i18n.T("auth.canceled")))
+}
+
+func (p *payload) toResult(targetUrl string) (*Result, error) {
+	pageUrl := p.Url
+	if pageUrl == "" {
+		pageUrl = targetUrl
+	}
+	u, err := url.Parse(pageUrl)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("auth: missing page url")
+	}
+	r := &Result{Url: pageUrl, LocalStorage: p.LocalStorage, SessionStorage: p.SessionStorage}
+	if len(p.Cookies) > 0 && string(p.Cookies) != "null" {
+		list, err := cookies.Parse(p.Cookies, u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		r.Cookies = append(r.Cookies, list...)
+	}
+	if p.Cookie != "" {
+		//document.cookie 没有域名信息，按页面所在站点补全，便于同站的图片服务器使用
+		list, _ := cookies.Parse([]byte(p.Cookie), CookieDomain(u.Hostname()))
+		r.Cookies = append(r.Cookies, list...)
+	}
+	if len(r.Cookies) == 0 && len(r.LocalStorage) == 0 && len(r.SessionStorage) == 0 {
+		return nil, errors.New("auth: no cookies or localStorage received")
+	}
+	return r, nil
+}
+
+// CookieDomain document.cookie 的默认作用域：去掉 www. 后对子域名也有效
+func CookieDomain(host string) string {
+	return "." + strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// SaveCookies 把 cookie 合并写入 cookieFile（Netscape 格式）；cookieFile 是其他格式时不改写，返回 cookies.ErrNotNetscape
+func (r *Result) SaveCookies(cookieFile string) error {
+	if cookieFile == "" || len(r.Cookies) == 0 {
+		return nil
+	}
+	u, _ := url.Parse(r.Url)
+	err := cookies.Update(cookieFile, u, r.Cookies)
+	if errors.Is(err, os.ErrNotExist) {
+		err = cookies.Save(cookieFile, r.Cookies)
+	}
+	return err
+}
+
+// Host 回传页面的主机名
+func (r *Result) Host() string {
+	u, err := url.Parse(r.Url)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// bookmarklet 在当前网页中收集 document.cookie、localStorage 与 sessionStorage 并发送到 callback
+func bookmarklet(callback string) string {
+	js := `(function(){function d(t){var s={};try{for(var i=0;i<t.length;i++){var k=t.key(i);s[k]=t.getItem(k);}}catch(e){}return s;}` +
+		`fetch(` + strconv.Quote(callback) + `,{method:'POST',mode:'no-cors',headers:{'Content-Type':'text/plain'},` +
+		`body:JSON.stringify({url:location.href,cookie:document.cookie,localStorage:d(localStorage),sessionStorage:d(sessionStorage)})})` +
+		`.then(function(){alert('bookget: OK')},function(e){alert('bookget: '+e)});})();`
+	return "javascript:" + url.PathEscape(js)
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// displayAddr 监听 0.0.0.0 时打印本机地址，便于在局域网其它设备上打开
+func displayAddr(addr *net.TCPAddr) string {
+	if addr.IP.IsUnspecified() {
+		return net.JoinHostPort("127.0.0.1", fmt.Sprint(addr.Port))
+	}
+	return addr.String()
+}
+
+func isLoopback(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// indexTpl 回调页面，文字随 --lang
+var indexTpl = template.Must(template.New("index").Funcs(template.FuncMap{"t": i18n.T, "lang": i18n.Lang}).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}">
+<head><meta charset="utf-8"><title>{{t "auth.page.title"}}</title>
+<style>body{font-family:sans-serif;max-width:760px;margin:2em auto;line-height:1.6}code,textarea{width:100%}a.bm{display:inline-block;padding:.4em 1em;border:1px solid #888;border-radius:4px;text-decoration:none}</style>
+</head>
+<body>
+<h2>{{t "auth.page.heading"}}</h2>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+<ol>
+<li>{{t "auth.page.drag"}}<a class="bm" href="{{.Bookmarklet}}">{{t "auth.page.bookmarklet"}}</a></li>
+<li>{{t "auth.page.verify"}}{{if .TargetUrl}}<br><a href="{{.TargetUrl}}" target="_blank" rel="noreferrer">{{.TargetUrl}}</a>{{end}}</li>
+<li>{{t "auth.page.click"}}</li>
+</ol>
+<p>{{t "auth.page.extension"}}<br><code>{"url":"…","cookies":[…],"localStorage":{…},"sessionStorage":{…}}</code><br><code>{{.CallbackUrl}}</code></p>
+<h3>{{t "auth.page.manual"}}</h3>
+<p>{{t "auth.page.manual_hint"}}</p>
+<form method="post" action="{{.CallbackUrl}}">
+<p>{{t "auth.page.url"}}<input name="url" size="80" value="{{.TargetUrl}}"></p>
+<textarea name="cookie" rows="6" placeholder="a=1; b=2"></textarea>
+<p><button type="submit">{{t "auth.page.submit"}}</button></p>
+</form>
+<form method="post" action="{{.CancelUrl}}"><button type="submit">{{t "auth.page.cancel"}}</button></form>
+</body>
+</html>
+`))
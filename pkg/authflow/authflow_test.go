@@ -0,0 +1,108 @@
+package authflow_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"bookget/pkg/authflow"
+	"bookget/pkg/cookies"
+	"bookget/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// page 等待回调服务启动并取回页面
+func page(addr string) []byte {
+	for i := 0; i < 50; i++ {
+		resp, err := http.Get("http://" + addr + "/")
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return body
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return nil
+}
+
+// pageLink 从回调页面中取出 callback / cancel 地址
+func pageLink(t *testing.T, addr, kind string) string {
+	body := page(addr)
+	m := regexp.MustCompile(`action="(http://[^"]+/` + kind + `/[0-9a-f]+)"`).FindSubmatch(body)
+	require.NotNil(t, m, string(body))
+	return string(m[1])
+}
+
+func TestWaitCallback(t *testing.T) {
+	addr := freeAddr(t)
+	go func() {
+		callback := pageLink(t, addr, "callback")
+		body := `{"url":"https://www.example.com/book/1","cookie":"sid=abc; lang=zh","localStorage":{"authorization":"t1"}}`
+		resp, err := http.Post(callback, "text/plain", strings.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	result, err := authflow.Wait(context.Background(), authflow.Options{Listen: addr, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, result.Cookies, 2)
+	assert.Equal(t, ".example.com", result.Cookies[0].Domain)
+	assert.Equal(t, "t1", result.LocalStorage["authorization"])
+	assert.Equal(t, "www.example.com", result.Host())
+
+	cookieFile := filepath.Join(t.TempDir(), "cookie.txt")
+	require.NoError(t, result.SaveCookies(cookieFile))
+	saved, err := cookies.Load(cookieFile, "")
+	require.NoError(t, err)
+	assert.Len(t, saved, 2)
+}
+
+func TestWaitCancelAndTimeout(t *testing.T) {
+	addr := freeAddr(t)
+	go func() {
+		resp, err := http.Post(pageLink(t, addr, "cancel"), "application/x-www-form-urlencoded", nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	_, err := authflow.Wait(context.Background(), authflow.Options{Listen: addr, Timeout: 5 * time.Second})
+	assert.ErrorIs(t, err, authflow.ErrCanceled)
+
+	_, err = authflow.Wait(context.Background(), authflow.Options{Timeout: 50 * time.Millisecond})
+	assert.ErrorIs(t, err, authflow.ErrTimeout)
+}
+
+func TestIndexLang(t *testing.T) {
+	prev := i18n.Lang()
+	t.Cleanup(func() { _ = i18n.SetLang(prev) })
+	require.NoError(t, i18n.SetLang("en"))
+
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = authflow.Wait(ctx, authflow.Options{Listen: addr, Timeout: 5 * time.Second})
+	}()
+	body := page(addr)
+	cancel()
+	<-done
+	assert.Contains(t, string(body), `<html lang="en">`)
+	assert.Contains(t, string(body), "Send to bookget")
+	assert.NotContains(t, string(body), "发送到 bookget")
+}
@@ -0,0 +1,435 @@
+// Package authstore 按站点保存登录凭据：cookie、localStorage、sessionStorage、Bearer token 与自定义请求头。
+// 可从 JSON 导出（bookget 自身格式、Playwright storageState、JSON.stringify(localStorage)）
+// 或旧版 localStorage.txt（每行 key: value）读取。
+package authstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bookget/pkg/cookies"
+)
+
+// Default 全局凭据，启动时从 --local-storage 指定的文件读取
+var Default = New()
+
+// ErrNotNative 目标文件是 storageState、localStorage.txt 等用户导出的文件，Save 不改写
+var ErrNotNative = errors.New("credentials file is not in bookget format, not overwritten")
+
+// Credentials 一个站点的凭据
+type Credentials struct {
+	Cookies        []*http.Cookie
+	LocalStorage   map[string]string
+	SessionStorage map[string]string
+	BearerToken    string
+	Headers        map[string]string
+}
+
+type Store struct {
+	// CookieFile 不为空时，For 会从该 cookie 文件读取匹配站点的 cookie（cookie.txt 为 cookie 的主要来源）
+	CookieFile string
+
+	mu    sync.RWMutex
+	sites map[string]*Credentials
+}
+
+func New() *Store {
+	return &Store{sites: make(map[string]*Credentials)}
+}
+
+// siteKey 统一站点键：小写、去掉端口与前导点。空字符串表示适用于所有站点
+func siteKey(host string) string {
+	host = strings.ToLower(strings.TrimPrefix(host, "."))
+	if i := strings.LastIndex(host, ":"); i > 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return host
+}
+
+// Merge 合并凭据到 site，同名键覆盖
+func (s *Store) Merge(site string, c *Credentials) {
+	if c == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := siteKey(site)
+	dst, ok := s.sites[key]
+	if !ok {
+		dst = &Credentials{}
+		s.sites[key] = dst
+	}
+	dst.merge(c)
+}
+
+// For 返回 host 可用的凭据：Stored 的凭据加上 cookie 文件中匹配的 cookie
+func (s *Store) For(host string) *Credentials {
+	host = siteKey(host)
+	out := s.Stored(host)
+	s.mu.RLock()
+	cookieFile := s.CookieFile
+	s.mu.RUnlock()
+
+	if cookieFile != "" {
+		if list, err := cookies.Load(cookieFile, host); err == nil {
+			for _, c := range list {
+				if domainMatch(host, c.Domain) {
+					out.Cookies = append(out.Cookies, c)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Stored 返回凭据文件中 host 可用的凭据，不含 cookie 文件：依次合并通用凭据、上级域名与 host 本身（越具体越优先）
+func (s *Store) Stored(host string) *Credentials {
+	host = siteKey(host)
+	keys := []string{""}
+	parts := strings.Split(host, ".")
+	for i := len(parts) - 2; i >= 0; i-- {
+		keys = append(keys, strings.Join(parts[i:], "."))
+	}
+
+	out := &Credentials{}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range keys {
+		if c, ok := s.sites[k]; ok {
+			out.merge(c)
+		}
+	}
+	return out
+}
+
+// Sites 已保存凭据的站点
+func (s *Store) Sites() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sites := make([]string, 0, len(s.sites))
+	for k := range s.sites {
+		sites = append(sites, k)
+	}
+	sort.Strings(sites)
+	return sites
+}
+
+func domainMatch(host, domain string) bool {
+	if domain == "" {
+		return true
+	}
+	domain = strings.ToLower(domain)
+	if strings.HasPrefix(domain, ".") {
+		return host == domain[1:] || strings.HasSuffix(host, domain)
+	}
+	return host == domain
+}
+
+func (c *Credentials) merge(src *Credentials) {
+	c.Cookies = mergeCookies(c.Cookies, src.Cookies)
+	c.LocalStorage = mergeMap(c.LocalStorage, src.LocalStorage)
+	c.SessionStorage = mergeMap(c.SessionStorage, src.SessionStorage)
+	c.Headers = mergeMap(c.Headers, src.Headers)
+	if src.BearerToken != "" {
+		c.BearerToken = src.BearerToken
+	}
+}
+
+func mergeMap(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func mergeCookies(dst, src []*http.Cookie) []*http.Cookie {
+	for _, sc := range src {
+		replaced := false
+		for i, c := range dst {
+			if c.Name == sc.Name && c.Domain == sc.Domain && c.Path == sc.Path {
+				dst[i] = sc
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			dst = append(dst, sc)
+		}
+	}
+	return dst
+}
+
+// Get 按 key 依次查找自定义请求头（不区分大小写）、sessionStorage、localStorage 与 cookie
+func (c *Credentials) Get(key string) (string, bool) {
+	for k, v := range c.Headers {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	if v, ok := c.SessionStorage[key]; ok {
+		return v, true
+	}
+	if v, ok := c.LocalStorage[key]; ok {
+		return v, true
+	}
+	return c.Cookie(key)
+}
+
+// Cookie 取未过期的同名 cookie 值
+func (c *Credentials) Cookie(name string) (string, bool) {
+	for i := len(c.Cookies) - 1; i >= 0; i-- {
+		v := c.Cookies[i]
+		if v.Name == name && (v.Expires.IsZero() || v.Expires.After(time.Now())) {
+			return v.Value, true
+		}
+	}
+	return "", false
+}
+
+// Authorization Bearer token 对应的请求头值，已带认证方案时原样返回
+func (c *Credentials) Authorization() string {
+	if c.BearerToken == "" {
+		return ""
+	}
+	if strings.Contains(c.BearerToken, " ") {
+		return c.BearerToken
+	}
+	return "Bearer " + c.BearerToken
+}
+
+// ApplyHeaders 把自定义请求头与 Authorization 写入 gohttp.Options.Headers
+func (c *Credentials) ApplyHeaders(headers map[string]interface{}) {
+	for k, v := range c.Headers {
+		headers[k] = v
+	}
+	if auth := c.Authorization(); auth != "" {
+		headers["Authorization"] = auth
+	}
+}
+
+// Load 读取凭据文件，不存在时忽略
+func (s *Store) Load(path string) error {
+	if path == "" {
+		return nil
+	}
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.Parse(bs)
+}
+
+// Parse 自动识别 JSON 导出或旧版「key: value」文本
+func (s *Store) Parse(data []byte) error {
+	text := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(text) == 0 {
+		return nil
+	}
+	if text[0] != '{' {
+		c, err := parseLines(string(text))
+		if err != nil {
+			return err
+		}
+		s.Merge("", c)
+		return nil
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(text, &probe); err != nil {
+		return err
+	}
+	if _, ok := probe["sites"]; ok {
+		return s.parseNative(text)
+	}
+	if _, ok := probe["origins"]; ok {
+		return s.parseStorageState(text)
+	}
+	if _, ok := probe["cookies"]; ok {
+		return s.parseStorageState(text)
+	}
+	//JSON.stringify(localStorage)
+	flat := make(map[string]string, len(probe))
+	for k, raw := range probe {
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			v = string(raw)
+		}
+		flat[k] = v
+	}
+	s.Merge("", &Credentials{LocalStorage: flat})
+	return nil
+}
+
+// parseLines 旧版 localStorage.txt：每行 key: value，值两侧的引号会被去掉
+func parseLines(text string) (*Credentials, error) {
+	c := &Credentials{LocalStorage: make(map[string]string)}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid line format: %s", line)
+		}
+		v = strings.Trim(strings.Trim(strings.TrimSpace(v), `"`), "'")
+		c.LocalStorage[strings.TrimSpace(k)] = v
+	}
+	return c, nil
+}
+
+// fileSite 保存格式中的一个站点
+type fileSite struct {
+	Cookies        json.RawMessage   `json:"cookies,omitempty"`
+	LocalStorage   map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]string `json:"sessionStorage,omitempty"`
+	Bearer         string            `json:"bearer,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+}
+
+type fileStore struct {
+	Sites map[string]fileSite `json:"sites"`
+}
+
+// fileCookie 与 EditThisCookie 导出字段一致，可由 cookies.Parse 读取
+type fileCookie struct {
+	Domain         string  `json:"domain"`
+	HostOnly       bool    `json:"hostOnly,omitempty"`
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Path           string  `json:"path,omitempty"`
+	Secure         bool    `json:"secure,omitempty"`
+	HttpOnly       bool    `json:"httpOnly,omitempty"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"`
+}
+
+// isNative 文件为空或为 bookget 格式（顶层有 sites）
+func isNative(data []byte) bool {
+	text := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(text) == 0 {
+		return true
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(text, &probe); err != nil {
+		return false
+	}
+	_, ok := probe["sites"]
+	return ok
+}
+
+func (s *Store) parseNative(data []byte) error {
+	var f fileStore
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	for site, v := range f.Sites {
+		c := &Credentials{
+			LocalStorage:   v.LocalStorage,
+			SessionStorage: v.SessionStorage,
+			BearerToken:    v.Bearer,
+			Headers:        v.Headers,
+		}
+		if len(v.Cookies) > 0 {
+			list, err := cookies.Parse(v.Cookies, site)
+			if err != nil {
+				return fmt.Errorf("%s: %w", site, err)
+			}
+			c.Cookies = list
+		}
+		s.Merge(site, c)
+	}
+	return nil
+}
+
+// parseStorageState Playwright/Puppeteer storageState.json：cookie 按各自域名、localStorage 按 origin 归档
+func (s *Store) parseStorageState(data []byte) error {
+	var state cookies.StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	list, err := cookies.Parse(data, "")
+	if err != nil {
+		return err
+	}
+	for _, c := range list {
+		s.Merge(c.Domain, &Credentials{Cookies: []*http.Cookie{c}})
+	}
+	for _, o := range state.Origins {
+		m := make(map[string]string, len(o.LocalStorage))
+		for _, kv := range o.LocalStorage {
+			m[kv.Name] = kv.Value
+		}
+		s.Merge(originHost(o.Origin), &Credentials{LocalStorage: m})
+	}
+	return nil
+}
+
+func originHost(origin string) string {
+	if _, rest, ok := strings.Cut(origin, "://"); ok {
+		origin = rest
+	}
+	host, _, _ := strings.Cut(origin, "/")
+	return host
+}
+
+// Save 以 bookget 格式保存（不含 CookieFile 中的 cookie）。
+// 只改写不存在、为空或已是 bookget 格式的文件，其它格式保持原样并返回 ErrNotNative
+func (s *Store) Save(path string) error {
+	if bs, err := os.ReadFile(path); err == nil && !isNative(bs) {
+		return ErrNotNative
+	}
+	s.mu.RLock()
+	f := fileStore{Sites: make(map[string]fileSite, len(s.sites))}
+	for site, c := range s.sites {
+		v := fileSite{
+			LocalStorage:   c.LocalStorage,
+			SessionStorage: c.SessionStorage,
+			Bearer:         c.BearerToken,
+			Headers:        c.Headers,
+		}
+		if len(c.Cookies) > 0 {
+			list := make([]fileCookie, 0, len(c.Cookies))
+			for _, ck := range c.Cookies {
+				fc := fileCookie{
+					Domain:   ck.Domain,
+					HostOnly: !strings.HasPrefix(ck.Domain, "."),
+					Name:     ck.Name,
+					Value:    ck.Value,
+					Path:     ck.Path,
+					Secure:   ck.Secure,
+					HttpOnly: ck.HttpOnly,
+				}
+				if !ck.Expires.IsZero() {
+					fc.ExpirationDate = float64(ck.Expires.Unix())
+				}
+				list = append(list, fc)
+			}
+			v.Cookies, _ = json.Marshal(list)
+		}
+		f.Sites[site] = v
+	}
+	s.mu.RUnlock()
+
+	bs, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, 0600)
+}
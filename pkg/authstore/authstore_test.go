@@ -0,0 +1,122 @@
+package authstore_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/authstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegacyLines(t *testing.T) {
+	s := authstore.New()
+	require.NoError(t, s.Parse([]byte("authorization: \"abc\"\nauthorizationu: 'def'\n")))
+	c := s.For("gj.tianyige.com.cn")
+	v, ok := c.Get("authorization")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", v)
+	v, _ = c.Get("authorizationu")
+	assert.Equal(t, "def", v)
+
+	assert.Error(t, authstore.New().Parse([]byte("no separator")))
+}
+
+func TestParseNativeBySite(t *testing.T) {
+	s := authstore.New()
+	require.NoError(t, s.Parse([]byte(`{"sites": {
+		"": {"headers": {"X-Common": "1"}},
+		"example.com": {"localStorage": {"token": "parent"}, "bearer": "t0"},
+		"www.example.com": {"localStorage": {"token": "child"}, "sessionStorage": {"sid": "s1"},
+			"cookies": [{"domain": ".example.com", "name": "fssessionid", "value": "x"}]}
+	}}`)))
+
+	c := s.For("www.example.com:443")
+	v, _ := c.Get("token")
+	assert.Equal(t, "child", v)
+	v, _ = c.Get("sid")
+	assert.Equal(t, "s1", v)
+	v, _ = c.Get("x-common")
+	assert.Equal(t, "1", v)
+	v, _ = c.Cookie("fssessionid")
+	assert.Equal(t, "x", v)
+	assert.Equal(t, "Bearer t0", c.Authorization())
+
+	c = s.For("img.example.com")
+	v, _ = c.Get("token")
+	assert.Equal(t, "parent", v)
+	_, ok := c.Get("sid")
+	assert.False(t, ok)
+
+	headers := map[string]interface{}{}
+	c.ApplyHeaders(headers)
+	assert.Equal(t, map[string]interface{}{"X-Common": "1", "Authorization": "Bearer t0"}, headers)
+}
+
+func TestParseStorageStateAndFlat(t *testing.T) {
+	s := authstore.New()
+	require.NoError(t, s.Parse([]byte(`{
+		"cookies": [{"name": "JSESSIONID", "value": "j", "domain": "lib.example.net", "path": "/", "expires": -1}],
+		"origins": [{"origin": "https://lib.example.net", "localStorage": [{"name": "sign", "value": "abc"}]}]
+	}`)))
+	require.NoError(t, s.Parse([]byte(`{"authorization": "flat", "count": 3}`)))
+
+	c := s.For("lib.example.net")
+	v, _ := c.Get("sign")
+	assert.Equal(t, "abc", v)
+	v, _ = c.Cookie("JSESSIONID")
+	assert.Equal(t, "j", v)
+	v, _ = c.Get("authorization")
+	assert.Equal(t, "flat", v)
+	v, _ = c.Get("count")
+	assert.Equal(t, "3", v)
+	assert.Empty(t, s.For("other.example.net").Cookies)
+}
+
+func TestSaveLoadAndCookieFile(t *testing.T) {
+	dir := t.TempDir()
+	cookieFile := filepath.Join(dir, "cookie.txt")
+	require.NoError(t, os.WriteFile(cookieFile, []byte(".example.org\tTRUE\t/\tFALSE\t0\tsid\tfromfile\n"), 0600))
+
+	s := authstore.New()
+	require.NoError(t, s.Parse([]byte(`{"sites": {"www.example.org": {"bearer": "Basic abc", "cookies": [{"domain": "www.example.org", "hostOnly": true, "name": "a", "value": "1"}]}}}`)))
+	path := filepath.Join(dir, "auth.json")
+	require.NoError(t, s.Save(path))
+
+	loaded := authstore.New()
+	loaded.CookieFile = cookieFile
+	require.NoError(t, loaded.Load(path))
+	require.NoError(t, loaded.Load(filepath.Join(dir, "missing.json")))
+	assert.Equal(t, []string{"www.example.org"}, loaded.Sites())
+
+	c := loaded.For("www.example.org")
+	assert.Equal(t, "Basic abc", c.Authorization())
+	v, _ := c.Cookie("a")
+	assert.Equal(t, "1", v)
+	v, _ = c.Cookie("sid")
+	assert.Equal(t, "fromfile", v)
+	_, ok := loaded.For("www.example.com").Cookie("sid")
+	assert.False(t, ok)
+}
+
+func TestSaveKeepsForeignFormat(t *testing.T) {
+	dir := t.TempDir()
+	s := authstore.New()
+	s.Merge("example.org", &authstore.Credentials{LocalStorage: map[string]string{"token": "t"}})
+
+	for _, data := range []string{"token: abc\n", `{"cookies": [], "origins": []}`, `{"token": "abc"}`} {
+		path := filepath.Join(dir, "localStorage.txt")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+		assert.ErrorIs(t, s.Save(path), authstore.ErrNotNative)
+		bs, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, data, string(bs))
+	}
+
+	//已是 bookget 格式或为空时可以改写
+	path := filepath.Join(dir, "auth.json")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	require.NoError(t, s.Save(path))
+	require.NoError(t, s.Save(path))
+}
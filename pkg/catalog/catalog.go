@@ -0,0 +1,58 @@
+// Package catalog 读取下载时生成的目录文件：nlc-guji 的 catalog.txt（制表符缩进表示层级）、天一阁的 bookmark.txt。
+package catalog
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Files 按优先顺序查找的目录文件名
+var Files = []string{"catalog.txt", "bookmark.txt"}
+
+// 标题 ………… 页码 / 标题......页码
+var lineRe = regexp.MustCompile(`^(\t*)(.*?)\s*(?:\.{3,}|…+)\s*(\S*)\s*$`)
+
+// Entry 一条目录。Page 通常为全书顺序号（从1开始），也可能是“未知”或页码标签
+type Entry struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	Page  string `json:"page"`
+}
+
+// Read 读取图书目录下的目录文件，没有时返回 nil
+func Read(dir string) []Entry {
+	for _, name := range Files {
+		fp, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		entries := Parse(fp)
+		fp.Close()
+		if len(entries) > 0 {
+			return entries
+		}
+	}
+	return nil
+}
+
+// Parse 解析目录文件，跳过 #版本=1.0 等注释行
+func Parse(r io.Reader) []Entry {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := lineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		entries = append(entries, Entry{Level: len(m[1]), Title: strings.TrimSpace(m[2]), Page: m[3]})
+	}
+	return entries
+}
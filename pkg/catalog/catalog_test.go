@@ -0,0 +1,18 @@
+package catalog_test
+
+import (
+	"strings"
+	"testing"
+
+	"bookget/pkg/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	entries := catalog.Parse(strings.NewReader("#版本=1.0\r\n卷一......1\r\n\r\n序 ………… 未知\n\t第一回 ………… 12\nno page\n"))
+	assert.Equal(t, []catalog.Entry{
+		{Level: 0, Title: "卷一", Page: "1"},
+		{Level: 0, Title: "序", Page: "未知"},
+		{Level: 1, Title: "第一回", Page: "12"},
+	}, entries)
+}
@@ -0,0 +1,274 @@
+//go:build linux
+
+package cookies
+
+import (
+	"bookget/pkg/crypt"
+	"bookget/pkg/i18n"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// chromiumEpoch Chromium 的时间戳从 1601-01-01 起，单位微秒
+const chromiumEpoch = 11644473600
+
+// ReadBrowser 从本地浏览器配置目录读取 cookie。
+// browser: firefox | chrome | chromium | edge | brave，可写成 firefox:/path/to/profile 指定配置目录。
+// host 非空时只返回该域名（及其上级域）的 cookie。需要系统安装 sqlite3 命令行工具，Chromium 系还需要 secret-tool。
+func ReadBrowser(browser, host string) ([]*http.Cookie, error) {
+	name, profile, _ := strings.Cut(browser, ":")
+	switch strings.ToLower(name) {
+	case "firefox":
+		return readFirefox(profile, host)
+	case "chrome", "chromium", "edge", "brave":
+		return readChromium(strings.ToLower(name), profile, host)
+	}
+	return nil, fmt.Errorf("unsupported browser: %s", browser)
+}
+
+func readFirefox(profile, host string) ([]*http.Cookie, error) {
+	db := filepath.Join(profile, "cookies.sqlite")
+	if profile == "" {
+		matches, _ := filepath.Glob(filepath.Join(os.Getenv("HOME"), ".mozilla", "firefox", "*", "cookies.sqlite"))
+		if len(matches) == 0 {
+			return nil, errors.New("firefox cookies.sqlite not found")
+		}
+		db = newest(matches)
+	}
+	query := "SELECT host, path, isSecure, expiry, name, value, isHttpOnly FROM moz_cookies"
+	if host != "" {
+		query += " WHERE " + hostFilter("host", host)
+	}
+	rows, err := sqlite(db, query)
+	if err != nil {
+		return nil, err
+	}
+	cookies := make([]*http.Cookie, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			continue
+		}
+		c := &http.Cookie{
+			Domain:   row[0],
+			Path:     row[1],
+			Secure:   row[2] == "1",
+			Name:     row[4],
+			Value:    row[5],
+			HttpOnly: row[6] == "1",
+		}
+		if expiry, _ := strconv.ParseInt(row[3], 10, 64); expiry > 0 {
+			//新版 Firefox 以毫秒保存
+			if expiry > 1e12 {
+				expiry /= 1000
+			}
+			c.Expires = time.Unix(expiry, 0)
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, nil
+}
+
+func readChromium(name, profile, host string) ([]*http.Cookie, error) {
+	if profile == "" {
+		dirs := map[string]string{
+			"chrome":   "google-chrome",
+			"chromium": "chromium",
+			"edge":     "microsoft-edge",
+			"brave":    "BraveSoftware/Brave-Browser",
+		}
+		profile = filepath.Join(os.Getenv("HOME"), ".config", dirs[name], "Default")
+	}
+	db := filepath.Join(profile, "Network", "Cookies")
+	if _, err := os.Stat(db); err != nil {
+		db = filepath.Join(profile, "Cookies")
+	}
+	query := "SELECT host_key, path, is_secure, expires_utc, name, value, is_httponly, hex(encrypted_value) FROM cookies"
+	if host != "" {
+		query += " WHERE " + hostFilter("host_key", host)
+	}
+	rows, err := sqlite(db, query)
+	if err != nil {
+		return nil, err
+	}
+	//数据库版本 >= 24 时明文前有 32 字节的域名 SHA256
+	var dbVersion int
+	if meta, err := sqlite(db, "SELECT value FROM meta WHERE key='version'"); err == nil && len(meta) > 0 && len(meta[0]) > 0 {
+		dbVersion, _ = strconv.Atoi(meta[0][0])
+	}
+	keys, keyring := chromiumKeys(name)
+
+	failed := 0
+	cookies := make([]*http.Cookie, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 8 {
+			continue
+		}
+		value := row[5]
+		if value == "" && row[7] != "" {
+			enc, _ := hex.DecodeString(row[7])
+			plain, err := decryptChromium(enc, keys)
+			if err != nil {
+				failed++
+				continue
+			}
+			if dbVersion >= 24 && len(plain) >= 32 {
+				plain = plain[32:]
+			}
+			value = string(plain)
+		}
+		c := &http.Cookie{
+			Domain:   row[0],
+			Path:     row[1],
+			Secure:   row[2] == "1",
+			Name:     row[4],
+			Value:    value,
+			HttpOnly: row[6] == "1",
+		}
+		if expires, _ := strconv.ParseInt(row[3], 10, 64); expires > 0 {
+			c.Expires = time.Unix(expires/1e6-chromiumEpoch, 0)
+		}
+		cookies = append(cookies, c)
+	}
+	//没有 secret-tool 时取不到系统密钥环中的密码，v11 cookie 无法解密
+	if failed > 0 && !keyring {
+		return nil, i18n.Errorf("cookies.no_secret_tool", failed)
+	}
+	return cookies, nil
+}
+
+// chromiumKeys v10 使用固定密码 peanuts；v11 的密码保存在系统密钥环（需要 secret-tool），keyring=false 表示没有 secret-tool
+func chromiumKeys(name string) (keys map[string][]byte, keyring bool) {
+	keys = map[string][]byte{"v10": pbkdf2SHA1([]byte("peanuts"), []byte("saltysalt"), 1, 16)}
+	//没有密钥环时 v11 使用空密码
+	keys["v11"] = pbkdf2SHA1([]byte(""), []byte("saltysalt"), 1, 16)
+	bin, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return keys, false
+	}
+	app := map[string]string{"chrome": "chrome", "chromium": "chromium", "edge": "Microsoft Edge", "brave": "brave"}[name]
+	out, err := exec.Command(bin, "lookup", "application", app).Output()
+	if err == nil && len(bytes.TrimSpace(out)) > 0 {
+		keys["v11"] = pbkdf2SHA1(bytes.TrimSpace(out), []byte("saltysalt"), 1, 16)
+	}
+	return keys, true
+}
+
+func decryptChromium(enc []byte, keys map[string][]byte) ([]byte, error) {
+	if len(enc) < 3 {
+		return nil, errors.New("encrypted value too short")
+	}
+	key, ok := keys[string(enc[:3])]
+	if !ok {
+		return nil, fmt.Errorf("unsupported cookie encryption %q", enc[:3])
+	}
+	data := enc[3:]
+	if len(data)%16 != 0 {
+		return nil, errors.New("invalid encrypted value")
+	}
+	return crypt.AesDecrypt(data, key, bytes.Repeat([]byte{' '}, 16))
+}
+
+// pbkdf2SHA1 RFC 8018 PBKDF2-HMAC-SHA1
+func pbkdf2SHA1(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha1.New, password)
+	var dk []byte
+	for block := 1; len(dk) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for n := 1; n < iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range t {
+				t[i] ^= u[i]
+			}
+		}
+		dk = append(dk, t...)
+	}
+	return dk[:keyLen]
+}
+
+// sqlite 浏览器运行时数据库被锁定，先复制到临时目录再用 sqlite3 查询。
+// 最近写入的 cookie 可能还在 -wal 文件中，一并复制
+func sqlite(db, query string) ([][]string, error) {
+	bin, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return nil, i18n.Error("cookies.no_sqlite3")
+	}
+	dir, err := os.MkdirTemp("", "bookget-cookies-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "cookies.sqlite")
+	if err = copyFile(db, tmp); err != nil {
+		return nil, err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err = copyFile(db+suffix, tmp+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	out, err := exec.Command(bin, "-csv", tmp, query).Output()
+	if err != nil {
+		return nil, fmt.Errorf("sqlite3 %s: %w", db, err)
+	}
+	return csv.NewReader(bytes.NewReader(out)).ReadAll()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// hostFilter host 及其上级域的条件；单段域名（如 localhost）只匹配它本身
+func hostFilter(column, host string) string {
+	host = strings.ReplaceAll(strings.TrimPrefix(host, "."), "'", "")
+	parts := strings.Split(host, ".")
+	conds := make([]string, 0, len(parts)*2)
+	for i := 0; i < max(len(parts)-1, 1); i++ {
+		d := strings.Join(parts[i:], ".")
+		conds = append(conds, fmt.Sprintf("%s='%s'", column, d), fmt.Sprintf("%s='.%s'", column, d))
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+func newest(paths []string) string {
+	var best string
+	var mod time.Time
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil && fi.ModTime().After(mod) {
+			best, mod = p, fi.ModTime()
+		}
+	}
+	return best
+}
@@ -0,0 +1,33 @@
+package cookies_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/cookies"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFirefox(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	dir := t.TempDir()
+	sql := `CREATE TABLE moz_cookies (host TEXT, path TEXT, isSecure INTEGER, expiry INTEGER, name TEXT, value TEXT, isHttpOnly INTEGER);
+INSERT INTO moz_cookies VALUES ('localhost', '/', 0, 4102444800, 'a', '1', 0);
+INSERT INTO moz_cookies VALUES ('.example.com', '/', 1, 4102444800, 'b', '2', 1);`
+	require.NoError(t, exec.Command("sqlite3", filepath.Join(dir, "cookies.sqlite"), sql).Run())
+
+	list, err := cookies.ReadBrowser("firefox:"+dir, "localhost")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "a", list[0].Name)
+
+	list, err = cookies.ReadBrowser("firefox:"+dir, "www.example.com")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "2", list[0].Value)
+	assert.True(t, list[0].HttpOnly)
+}
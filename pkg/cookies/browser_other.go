@@ -0,0 +1,13 @@
+//go:build !linux
+
+package cookies
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ReadBrowser 目前只支持 Linux，其它系统请使用 bookget-gui 或导出 cookie.txt
+func ReadBrowser(browser, host string) ([]*http.Cookie, error) {
+	return nil, errors.New("reading browser cookie stores is only supported on Linux")
+}
@@ -0,0 +1,384 @@
+// Package cookies 读取/保存 cookie：Netscape cookie.txt、JSON（EditThisCookie、Playwright storageState）、
+// 原始 Cookie 请求头，以及 Linux 下 Firefox/Chromium 的本地 cookie 数据库。
+package cookies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrFormat      = errors.New("unrecognized cookie format")
+	ErrNotNetscape = errors.New("cookie file is not in Netscape format, not overwritten")
+)
+
+// fileLock 同一进程内多个任务同时回写 cookie 文件
+var fileLock sync.Mutex
+
+// Load 读取 cookie 文件，自动识别格式。
+// defaultHost 用于没有域名信息的原始 Cookie 头，可为空（匹配所有域名）
+func Load(path, defaultHost string) ([]*http.Cookie, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bs, defaultHost)
+}
+
+// cookie 文件格式
+const (
+	FormatNetscape = "netscape"
+	FormatJSON     = "json"
+	FormatHeader   = "header"
+)
+
+// Format 识别 cookie 内容的格式，无法识别时返回空
+func Format(data []byte) string {
+	text := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case len(text) == 0:
+		return ""
+	case text[0] == '[' || text[0] == '{':
+		return FormatJSON
+	case bytes.Contains(text, []byte("\t")):
+		return FormatNetscape
+	case bytes.Contains(text, []byte("=")):
+		return FormatHeader
+	}
+	return ""
+}
+
+// Parse 解析 cookie 内容，自动识别 JSON / Netscape / Cookie 头格式
+func Parse(data []byte, defaultHost string) ([]*http.Cookie, error) {
+	text := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(text) == 0 {
+		return nil, nil
+	}
+	switch Format(text) {
+	case FormatJSON:
+		return parseJSON(text)
+	case FormatNetscape:
+		return parseNetscape(text), nil
+	case FormatHeader:
+		return parseHeader(string(text), defaultHost), nil
+	}
+	return nil, ErrFormat
+}
+
+// parseNetscape 每行: domain includeSubdomains path secure expiry name value
+func parseNetscape(data []byte) []*http.Cookie {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		} else if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		//bookget-gui 导出的值带有转义的双引号
+		line = strings.ReplaceAll(line, `\"`, `"`)
+		row := strings.Split(line, "\t")
+		if len(row) < 7 {
+			continue
+		}
+		c := &http.Cookie{
+			Domain:   row[0],
+			Path:     row[2],
+			Secure:   strings.EqualFold(row[3], "TRUE"),
+			Name:     strings.Trim(row[5], `"`),
+			Value:    strings.Trim(row[6], `"`),
+			HttpOnly: httpOnly,
+		}
+		//includeSubdomains=FALSE 表示只对该主机有效
+		if !strings.EqualFold(row[1], "TRUE") {
+			c.Domain = strings.TrimPrefix(c.Domain, ".")
+		} else if !strings.HasPrefix(c.Domain, ".") {
+			c.Domain = "." + c.Domain
+		}
+		if expiry, err := strconv.ParseFloat(row[4], 64); err == nil && expiry > 0 {
+			c.Expires = time.Unix(int64(expiry), 0)
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies
+}
+
+// jsonCookie 兼容 EditThisCookie、Cookie-Editor 与 Playwright 的字段
+type jsonCookie struct {
+	Domain         string  `json:"domain"`
+	HostOnly       bool    `json:"hostOnly"`
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Path           string  `json:"path"`
+	Secure         bool    `json:"secure"`
+	HttpOnly       bool    `json:"httpOnly"`
+	ExpirationDate float64 `json:"expirationDate"` //EditThisCookie
+	Expires        float64 `json:"expires"`        //Playwright, -1 为会话 cookie
+}
+
+// StorageState Playwright/Puppeteer 导出的 storageState.json
+type StorageState struct {
+	Cookies []jsonCookie `json:"cookies"`
+	Origins []struct {
+		Origin       string `json:"origin"`
+		LocalStorage []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"localStorage"`
+	} `json:"origins"`
+}
+
+func parseJSON(data []byte) ([]*http.Cookie, error) {
+	var list []jsonCookie
+	if data[0] == '{' {
+		var state StorageState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		list = state.Cookies
+	} else if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	cookies := make([]*http.Cookie, 0, len(list))
+	for _, v := range list {
+		c := &http.Cookie{
+			Name:     v.Name,
+			Value:    v.Value,
+			Domain:   v.Domain,
+			Path:     v.Path,
+			Secure:   v.Secure,
+			HttpOnly: v.HttpOnly,
+		}
+		if v.HostOnly {
+			c.Domain = strings.TrimPrefix(c.Domain, ".")
+		}
+		expires := v.ExpirationDate
+		if expires == 0 {
+			expires = v.Expires
+		}
+		if expires > 0 {
+			c.Expires = time.Unix(int64(expires), 0)
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, nil
+}
+
+// parseHeader 解析 "Cookie: a=1; b=2" 或 "a=1; b=2"
+func parseHeader(text, defaultHost string) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 7 && strings.EqualFold(line[:7], "cookie:") {
+			line = line[7:]
+		}
+		for _, pair := range strings.Split(line, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || k == "" {
+				continue
+			}
+			cookies = append(cookies, &http.Cookie{
+				Name:   k,
+				Value:  v,
+				Domain: defaultHost,
+				Path:   "/",
+			})
+		}
+	}
+	return cookies
+}
+
+// Match cookie 是否应随 u 发送（域名、路径、过期时间、secure）
+func Match(c *http.Cookie, u *url.URL) bool {
+	if !c.Expires.IsZero() && c.Expires.Before(time.Now()) {
+		return false
+	}
+	if c.Secure && u.Scheme != "https" {
+		return false
+	}
+	if c.Domain != "" {
+		host := strings.ToLower(u.Hostname())
+		domain := strings.ToLower(c.Domain)
+		if strings.HasPrefix(domain, ".") {
+			if host != domain[1:] && !strings.HasSuffix(host, domain) {
+				return false
+			}
+		} else if host != domain {
+			return false
+		}
+	}
+	if c.Path != "" && c.Path != "/" {
+		p := u.Path
+		if p == "" {
+			p = "/"
+		}
+		if !strings.HasPrefix(p, c.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+// ForURL 过滤出发送到 u 的 cookie
+func ForURL(cookies []*http.Cookie, u *url.URL) []*http.Cookie {
+	var out []*http.Cookie
+	for _, c := range cookies {
+		if Match(c, u) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// SetJar 按各自的域名把 cookie 写入 jar；没有域名的 cookie 无法放入 jar，会被忽略
+func SetJar(jar *cookiejar.Jar, cookies []*http.Cookie) {
+	if jar == nil {
+		return
+	}
+	for _, c := range cookies {
+		if c.Domain == "" {
+			continue
+		}
+		host := strings.TrimPrefix(c.Domain, ".")
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		u := &url.URL{Scheme: scheme, Host: host, Path: path}
+		nc := *c
+		//cookiejar 中 Domain 为空表示 host-only
+		if !strings.HasPrefix(c.Domain, ".") {
+			nc.Domain = ""
+		}
+		jar.SetCookies(u, []*http.Cookie{&nc})
+	}
+}
+
+// LoadJar 读取 cookie 文件并写入 jar
+func LoadJar(jar *cookiejar.Jar, path, defaultHost string) error {
+	cookies, err := Load(path, defaultHost)
+	if err != nil {
+		return err
+	}
+	SetJar(jar, cookies)
+	return nil
+}
+
+// Save 以 Netscape cookie.txt 格式保存
+func Save(path string, cookies []*http.Cookie) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Netscape HTTP Cookie File\n# Generated by bookget\n\n")
+	for _, c := range cookies {
+		if c.Domain == "" || c.Name == "" {
+			continue
+		}
+		domain := c.Domain
+		if c.HttpOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, boolString(strings.HasPrefix(c.Domain, ".")),
+			path, boolString(c.Secure), expires, c.Name, c.Value)
+	}
+	//先写临时文件再改名，其他任务读取时不会读到写了一半的文件
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Update 把响应中的 Set-Cookie 合并回 cookie 文件。
+// 只回写已存在的 Netscape 格式文件；JSON、storageState、Cookie 头格式的文件保持原样并返回 ErrNotNetscape
+func Update(path string, u *url.URL, refreshed []*http.Cookie) error {
+	if path == "" || len(refreshed) == 0 {
+		return nil
+	}
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if Format(bs) != FormatNetscape {
+		return ErrNotNetscape
+	}
+	cookies := parseNetscape(bs)
+	for _, rc := range refreshed {
+		nc := *rc
+		if nc.Domain == "" {
+			nc.Domain = u.Hostname()
+		} else if !strings.HasPrefix(nc.Domain, ".") {
+			nc.Domain = "." + nc.Domain
+		}
+		if nc.Path == "" {
+			nc.Path = "/"
+		}
+		if nc.MaxAge > 0 {
+			nc.Expires = time.Now().Add(time.Duration(nc.MaxAge) * time.Second)
+		}
+		replaced := false
+		for i, c := range cookies {
+			if c.Name == nc.Name && strings.TrimPrefix(c.Domain, ".") == strings.TrimPrefix(nc.Domain, ".") && c.Path == nc.Path {
+				cookies[i] = &nc
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cookies = append(cookies, &nc)
+		}
+	}
+	//删除已过期的 cookie
+	live := cookies[:0]
+	for _, c := range cookies {
+		if c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(time.Now())) {
+			continue
+		}
+		live = append(live, c)
+	}
+	return Save(path, live)
+}
+
+// HeaderString 拼接为 Cookie 请求头
+func HeaderString(cookies []*http.Cookie) string {
+	var s string
+	for _, c := range cookies {
+		s += c.Name + "=" + c.Value + "; "
+	}
+	return s
+}
+
+func boolString(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
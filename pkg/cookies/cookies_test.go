@@ -0,0 +1,120 @@
+package cookies_test
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/cookies"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const netscape = `# Netscape HTTP Cookie File
+.example.com	TRUE	/	FALSE	4102444800	sid	abc
+#HttpOnly_www.example.com	FALSE	/viewer	TRUE	4102444800	token	xyz
+.example.com	TRUE	/	FALSE	946684800	old	expired
+`
+
+const editThisCookie = `[
+  {"domain": ".example.org", "hostOnly": false, "name": "a", "value": "1", "path": "/", "expirationDate": 4102444800},
+  {"domain": "img.example.org", "hostOnly": true, "name": "b", "value": "2", "path": "/"}
+]`
+
+const storageState = `{
+  "cookies": [{"name": "JSESSIONID", "value": "s1", "domain": "lib.example.net", "path": "/", "expires": -1, "httpOnly": true, "secure": true}],
+  "origins": [{"origin": "https://lib.example.net", "localStorage": [{"name": "authorization", "value": "t"}]}]
+}`
+
+func mustURL(t *testing.T, s string) *url.URL {
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	return u
+}
+
+func names(list []*http.Cookie) []string {
+	var out []string
+	for _, c := range list {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+func TestParseNetscape(t *testing.T) {
+	list, err := cookies.Parse([]byte(netscape), "")
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, ".example.com", list[0].Domain)
+	assert.Equal(t, "www.example.com", list[1].Domain)
+	assert.True(t, list[1].HttpOnly)
+
+	assert.Equal(t, []string{"sid"}, names(cookies.ForURL(list, mustURL(t, "http://img.example.com/a.jpg"))))
+	assert.Equal(t, []string{"sid", "token"}, names(cookies.ForURL(list, mustURL(t, "https://www.example.com/viewer/1"))))
+	assert.Equal(t, []string{"sid"}, names(cookies.ForURL(list, mustURL(t, "http://www.example.com/viewer/1"))))
+}
+
+func TestParseJSON(t *testing.T) {
+	list, err := cookies.Parse([]byte(editThisCookie), "")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "img.example.org", list[1].Domain)
+	assert.Equal(t, []string{"a"}, names(cookies.ForURL(list, mustURL(t, "https://www.example.org/"))))
+
+	list, err = cookies.Parse([]byte(storageState), "")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].Expires.IsZero())
+	assert.True(t, list[0].Secure)
+}
+
+func TestParseHeader(t *testing.T) {
+	list, err := cookies.Parse([]byte("Cookie: a=1; b=2=3"), "www.example.com")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "2=3", list[1].Value)
+	assert.Empty(t, cookies.ForURL(list, mustURL(t, "https://other.example.com/")))
+}
+
+func TestSetJarAndUpdate(t *testing.T) {
+	list, err := cookies.Parse([]byte(netscape), "")
+	require.NoError(t, err)
+	jar, _ := cookiejar.New(nil)
+	cookies.SetJar(jar, list)
+	assert.Equal(t, []string{"sid"}, names(jar.Cookies(mustURL(t, "http://img.example.com/"))))
+	assert.Empty(t, jar.Cookies(mustURL(t, "http://example.net/")))
+
+	path := filepath.Join(t.TempDir(), "cookie.txt")
+	require.NoError(t, cookies.Save(path, list))
+	u := mustURL(t, "https://www.example.com/")
+	require.NoError(t, cookies.Update(path, u, []*http.Cookie{{Name: "sid", Value: "new", Domain: "example.com", Path: "/"}, {Name: "fresh", Value: "1"}}))
+
+	saved, err := cookies.Load(path, "")
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, c := range saved {
+		values[c.Name] = c.Value
+	}
+	assert.Equal(t, "new", values["sid"])
+	assert.Equal(t, "1", values["fresh"])
+	assert.NotContains(t, values, "old")
+}
+
+func TestUpdateKeepsFormat(t *testing.T) {
+	u := mustURL(t, "https://www.example.com/")
+	refreshed := []*http.Cookie{{Name: "sid", Value: "new"}}
+	for name, data := range map[string]string{
+		"cookies.json": `[{"name":"sid","value":"old","domain":".example.com","path":"/"}]`,
+		"header.txt":   "sid=old; uid=1",
+	} {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		assert.ErrorIs(t, cookies.Update(path, u, refreshed), cookies.ErrNotNetscape, name)
+		bs, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, data, string(bs), name)
+	}
+	assert.Equal(t, cookies.FormatNetscape, cookies.Format([]byte(netscape)))
+}
@@ -0,0 +1,307 @@
+// Package dedup 检查同一本书中的重复页与占位图：限流时返回的同一张占位图、“图像不可用”卡片、误重复下载的页面。
+// 精确重复用 MD5，近似重复用 dHash/pHash。
+package dedup
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReportName 每个图书目录下的检查报告
+const ReportName = "dedup.json"
+
+// 问题类型
+const (
+	KindPlaceholder   = "placeholder"    //占位图，应重新下载
+	KindDuplicate     = "duplicate"      //与前面某页完全相同，默认仅报告（古籍空白页、重复的图版也会完全相同）
+	KindNearDuplicate = "near-duplicate" //与前面某页几乎相同，仅报告（古籍空白页也会相似）
+)
+
+type Options struct {
+	Distance int    //dHash 与 pHash 的汉明距离都不超过此值视为近似重复，默认 4
+	Repeat   int    //同一张图出现 N 次及以上视为占位图，默认 3
+	Known    *Known //已知占位图
+}
+
+func (o Options) withDefaults() Options {
+	if o.Distance <= 0 {
+		o.Distance = 4
+	}
+	if o.Repeat <= 1 {
+		o.Repeat = 3
+	}
+	return o
+}
+
+type Finding struct {
+	File     string `json:"file"`
+	Kind     string `json:"kind"`
+	Of       string `json:"of,omitempty"` //相同/相似的页面
+	Distance int    `json:"distance,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	MD5      string `json:"md5,omitempty"` //占位图的 MD5/pHash，可加入已知占位图列表
+	PHash    Hash   `json:"phash,omitempty"`
+}
+
+// ErrSuspect 刚保存的页面是重复页或占位图，--dedup requeue 时拒收
+var ErrSuspect = errors.New("suspect page")
+
+// SuspectError 被拒收的页面，errors.Is(err, ErrSuspect) 为 true
+type SuspectError struct {
+	Finding
+}
+
+func (e *SuspectError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.File, e.Kind)
+	if e.Of != "" {
+		msg += " of " + e.Of
+	}
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg
+}
+
+func (e *SuspectError) Unwrap() error {
+	return ErrSuspect
+}
+
+// Class 事件流中的错误分类：placeholder | duplicate
+func (e *SuspectError) Class() string {
+	return e.Kind
+}
+
+func placeholder(fp *Fingerprint, reason string) Finding {
+	return Finding{File: fp.File, Kind: KindPlaceholder, Reason: reason, MD5: fp.MD5, PHash: fp.PHash}
+}
+
+// Suspect 需要重新下载的页面：占位图；duplicates=true 时也包括完全重复的页面
+func (f Finding) Suspect(duplicates bool) bool {
+	return f.Kind == KindPlaceholder || (duplicates && f.Kind == KindDuplicate)
+}
+
+// Book 逐页检查一本书，下载过程中每保存一页调用一次 Add
+type Book struct {
+	opts  Options
+	pages []*Fingerprint
+	byMD5 map[string][]int
+}
+
+func NewBook(o Options) *Book {
+	return &Book{opts: o.withDefaults(), byMD5: make(map[string][]int)}
+}
+
+// Add 检查新的一页。同一张图第 Repeat 次出现时，前面几次也一并报告为占位图
+func (b *Book) Add(fp *Fingerprint) []Finding {
+	idx := len(b.pages)
+	b.pages = append(b.pages, fp)
+	same := append(b.byMD5[fp.MD5], idx)
+	b.byMD5[fp.MD5] = same
+
+	if b.opts.Known.Match(fp, b.opts.Distance) {
+		return []Finding{placeholder(fp, "known placeholder")}
+	}
+	if n := len(same); n >= b.opts.Repeat {
+		reason := "same image repeated " + strconv.Itoa(n) + " times"
+		if n > b.opts.Repeat {
+			return []Finding{placeholder(fp, reason)}
+		}
+		findings := make([]Finding, 0, n)
+		for _, i := range same {
+			findings = append(findings, placeholder(b.pages[i], reason))
+		}
+		return findings
+	} else if n > 1 {
+		return []Finding{{File: fp.File, Kind: KindDuplicate, Of: b.pages[same[0]].File}}
+	}
+	if !fp.HasImage() {
+		return nil
+	}
+	if b.tooSmall(fp) {
+		return []Finding{placeholder(fp, "much smaller than other pages")}
+	}
+	for _, p := range b.pages[:idx] {
+		if !p.HasImage() {
+			continue
+		}
+		d1, d2 := Distance(fp.DHash, p.DHash), Distance(fp.PHash, p.PHash)
+		if d1 <= b.opts.Distance && d2 <= b.opts.Distance {
+			return []Finding{{File: fp.File, Kind: KindNearDuplicate, Of: p.File, Distance: max(d1, d2)}}
+		}
+	}
+	return nil
+}
+
+// tooSmall “图像不可用”之类的卡片：宽高都不到本书中位数的 40%
+func (b *Book) tooSmall(fp *Fingerprint) bool {
+	var ws, hs []int
+	for _, p := range b.pages {
+		if p.HasImage() {
+			ws = append(ws, p.Width)
+			hs = append(hs, p.Height)
+		}
+	}
+	if len(ws) < 5 {
+		return false
+	}
+	sort.Ints(ws)
+	sort.Ints(hs)
+	w, h := ws[len(ws)/2], hs[len(hs)/2]
+	return fp.Width*5 < w*2 && fp.Height*5 < h*2
+}
+
+// Analyze 按页序检查整本书，每个文件只保留最严重的一条
+func Analyze(pages []*Fingerprint, o Options) []Finding {
+	b := NewBook(o)
+	byFile := make(map[string]int)
+	var findings []Finding
+	for _, fp := range pages {
+		for _, f := range b.Add(fp) {
+			if i, ok := byFile[f.File]; ok {
+				if rank(f.Kind) > rank(findings[i].Kind) {
+					findings[i] = f
+				}
+				continue
+			}
+			byFile[f.File] = len(findings)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func rank(kind string) int {
+	switch kind {
+	case KindPlaceholder:
+		return 3
+	case KindDuplicate:
+		return 2
+	}
+	return 1
+}
+
+// Report dedup.json
+type Report struct {
+	Updated  time.Time `json:"updated"`
+	Findings []Finding `json:"findings"`
+}
+
+var mu sync.Mutex
+
+// LoadReport 读取目录下的 dedup.json，不存在时返回空报告
+func LoadReport(dir string) (*Report, error) {
+	r := new(Report)
+	bs, err := os.ReadFile(filepath.Join(dir, ReportName))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, json.Unmarshal(bs, r)
+}
+
+// Save 写入 dedup.json；没有问题时删除旧报告
+func (r *Report) Save(dir string) error {
+	name := filepath.Join(dir, ReportName)
+	if len(r.Findings) == 0 {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	r.Updated = time.Now()
+	bs, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, bs, 0644)
+}
+
+// Append 下载过程中把新发现的问题追加到 dir 的报告中，同一文件以新的为准
+func Append(dir string, findings ...Finding) error {
+	mu.Lock()
+	defer mu.Unlock()
+	r, err := LoadReport(dir)
+	if err != nil {
+		r = new(Report)
+	}
+	for _, f := range findings {
+		replaced := false
+		for i := range r.Findings {
+			if r.Findings[i].File == f.File {
+				r.Findings[i], replaced = f, true
+				break
+			}
+		}
+		if !replaced {
+			r.Findings = append(r.Findings, f)
+		}
+	}
+	return r.Save(dir)
+}
+
+// Known 已知占位图：每行一个 MD5（32位）或 pHash（16位十六进制），# 开头为注释
+type Known struct {
+	md5   map[string]bool
+	phash []Hash
+}
+
+// LoadKnown 读取已知占位图列表，path 为空时返回 nil
+func LoadKnown(path string) (*Known, error) {
+	if path == "" {
+		return nil, nil
+	}
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	k := &Known{md5: make(map[string]bool)}
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if i := strings.IndexAny(line, " \t"); i > 0 {
+			line = line[:i]
+		}
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case len(line) == 32:
+			k.md5[line] = true
+		case len(line) == 16:
+			var h Hash
+			if err := h.UnmarshalText([]byte(line)); err == nil {
+				k.phash = append(k.phash, h)
+			}
+		}
+	}
+	return k, scanner.Err()
+}
+
+// Match 是否为已知占位图
+func (k *Known) Match(fp *Fingerprint, distance int) bool {
+	if k == nil {
+		return false
+	}
+	if k.md5[fp.MD5] {
+		return true
+	}
+	if !fp.HasImage() {
+		return false
+	}
+	for _, h := range k.phash {
+		if Distance(h, fp.PHash) <= distance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package dedup_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/dedup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// page 白底页面，seed 决定黑块的位置，模拟不同的版面
+func page(w, h, seed int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i := 0; i < 6; i++ {
+		x := (seed*37 + i*53) % 80 * w / 100
+		y := (seed*91 + i*29) % 80 * h / 100
+		draw.Draw(img, image.Rect(x, y, x+w/5, y+h/7), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, img))
+	require.NoError(t, f.Close())
+}
+
+func TestHashes(t *testing.T) {
+	a, b := page(200, 300, 1), page(200, 300, 7)
+	assert.Equal(t, dedup.DHash(a), dedup.DHash(page(400, 600, 1)), "scale invariant")
+	assert.LessOrEqual(t, dedup.Distance(dedup.PHash(a), dedup.PHash(page(400, 600, 1))), 2)
+	assert.Greater(t, dedup.Distance(dedup.PHash(a), dedup.PHash(b)), 8)
+	assert.Greater(t, dedup.Distance(dedup.DHash(a), dedup.DHash(b)), 8)
+}
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]image.Image{
+		"0001.png": page(200, 300, 1),
+		"0002.png": page(200, 300, 2),
+		"0003.png": page(200, 300, 3),
+		"0004.png": page(200, 300, 4),
+		"0005.png": page(200, 300, 5),
+		"0006.png": page(60, 40, 9), //“图像不可用”卡片
+		"0007.png": page(200, 300, 6),
+		"0008.png": page(400, 600, 6), //近似重复
+	}
+	for name, img := range files {
+		writePNG(t, filepath.Join(dir, name), img)
+	}
+	var fps []*dedup.Fingerprint
+	add := func(name, file string) {
+		fp, err := dedup.Compute(filepath.Join(dir, file), name)
+		require.NoError(t, err)
+		fps = append(fps, fp)
+	}
+	for _, name := range []string{"0001.png", "0002.png", "0003.png", "0004.png", "0005.png", "0006.png", "0007.png", "0008.png"} {
+		add(name, name)
+	}
+	add("0009.png", "0002.png") //精确重复
+	add("0010.png", "0005.png") //限流占位图：同一张图出现 3 次
+	add("0011.png", "0005.png")
+
+	findings := dedup.Analyze(fps, dedup.Options{})
+	kinds := map[string]string{}
+	for _, f := range findings {
+		kinds[f.File] = f.Kind
+	}
+	assert.Equal(t, map[string]string{
+		"0005.png": dedup.KindPlaceholder,
+		"0006.png": dedup.KindPlaceholder,
+		"0008.png": dedup.KindNearDuplicate,
+		"0009.png": dedup.KindDuplicate,
+		"0010.png": dedup.KindPlaceholder,
+		"0011.png": dedup.KindPlaceholder,
+	}, kinds)
+}
+
+func TestKnownAndReport(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "0001.png"), page(200, 300, 1))
+	fp, err := dedup.Compute(filepath.Join(dir, "0001.png"), "0001.png")
+	require.NoError(t, err)
+
+	list := filepath.Join(dir, "placeholders.txt")
+	require.NoError(t, os.WriteFile(list, []byte("# 占位图\n"+fp.PHash.String()+"  not available\n"), 0644))
+	known, err := dedup.LoadKnown(list)
+	require.NoError(t, err)
+	findings := dedup.NewBook(dedup.Options{Known: known}).Add(fp)
+	require.Len(t, findings, 1)
+	assert.True(t, findings[0].Suspect(false))
+	//完全重复的页面默认只报告
+	dup := dedup.Finding{File: "0002.png", Kind: dedup.KindDuplicate, Of: "0001.png"}
+	assert.False(t, dup.Suspect(false))
+	assert.True(t, dup.Suspect(true))
+
+	require.NoError(t, dedup.Append(dir, findings...))
+	require.NoError(t, dedup.Append(dir, dedup.Finding{File: "0001.png", Kind: dedup.KindDuplicate, Of: "0000.png"}))
+	r, err := dedup.LoadReport(dir)
+	require.NoError(t, err)
+	require.Len(t, r.Findings, 1)
+	assert.Equal(t, dedup.KindDuplicate, r.Findings[0].Kind)
+
+	r.Findings = nil
+	require.NoError(t, r.Save(dir))
+	assert.NoFileExists(t, filepath.Join(dir, dedup.ReportName))
+}
+
+func TestSuspectError(t *testing.T) {
+	var err error = &dedup.SuspectError{Finding: dedup.Finding{File: "0005.jpg", Kind: dedup.KindDuplicate, Of: "0004.jpg"}}
+	assert.ErrorIs(t, err, dedup.ErrSuspect)
+	assert.Equal(t, "0005.jpg: duplicate of 0004.jpg", err.Error())
+	assert.Equal(t, dedup.KindDuplicate, err.(*dedup.SuspectError).Class())
+}
@@ -0,0 +1,170 @@
+package dedup
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
+	"strconv"
+
+	xhash "bookget/pkg/hash"
+)
+
+// Hash 64 位感知哈希，JSON 中为 16 位十六进制字符串
+type Hash uint64
+
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(b []byte) error {
+	v, err := strconv.ParseUint(string(b), 16, 64)
+	*h = Hash(v)
+	return err
+}
+
+// Distance 汉明距离
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Fingerprint 一页的指纹。tif/jp2 等无法解码的格式只有 MD5
+type Fingerprint struct {
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	MD5    string `json:"md5"`
+	DHash  Hash   `json:"dhash"`
+	PHash  Hash   `json:"phash"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// HasImage 是否计算了感知哈希
+func (f *Fingerprint) HasImage() bool {
+	return f.Width > 0 && f.Height > 0
+}
+
+// Compute 计算文件的 MD5 与 dHash/pHash，name 为报告中显示的文件名
+func Compute(path, name string) (*Fingerprint, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := xhash.StreamTypes(bytes.NewReader(bs), xhash.NewHashSet(xhash.MD5))
+	if err != nil {
+		return nil, err
+	}
+	fp := &Fingerprint{File: name, Size: int64(len(bs)), MD5: sums[xhash.MD5]}
+	img, _, err := image.Decode(bytes.NewReader(bs))
+	if err != nil {
+		return fp, nil
+	}
+	fp.Width, fp.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	fp.DHash = DHash(img)
+	fp.PHash = PHash(img)
+	return fp, nil
+}
+
+// DHash 差值哈希：缩成 9×8 灰度，比较相邻像素
+func DHash(img image.Image) Hash {
+	g := shrink(img, 9, 8)
+	var h Hash
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if g[y*9+x] > g[y*9+x+1] {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// PHash 感知哈希：缩成 32×32 灰度做 DCT，取左上 8×8 低频与中位数比较
+func PHash(img image.Image) Hash {
+	const n = 32
+	g := shrink(img, n, n)
+	var low [64]float64
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for y := 0; y < n; y++ {
+				for x := 0; x < n; x++ {
+					sum += g[y*n+x] * dctCos[u][x] * dctCos[v][y]
+				}
+			}
+			low[v*8+u] = sum
+		}
+	}
+	sorted := append([]float64(nil), low[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[31] + sorted[32]) / 2
+	var h Hash
+	for _, c := range low {
+		h <<= 1
+		if c > median {
+			h |= 1
+		}
+	}
+	return h
+}
+
+var dctCos = func() (t [8][32]float64) {
+	for u := 0; u < 8; u++ {
+		for x := 0; x < 32; x++ {
+			t[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / 64)
+		}
+	}
+	return
+}()
+
+// shrink 按区域平均缩成 w×h 的灰度值
+func shrink(img image.Image, w, h int) []float64 {
+	b := img.Bounds()
+	sum := make([]float64, w*h)
+	cnt := make([]float64, w*h)
+	luma := lumaFunc(img)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		ty := (y - b.Min.Y) * h / b.Dy()
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := ty*w + (x-b.Min.X)*w/b.Dx()
+			sum[i] += luma(x, y)
+			cnt[i]++
+		}
+	}
+	for i := range sum {
+		if cnt[i] > 0 {
+			sum[i] /= cnt[i]
+		}
+	}
+	return sum
+}
+
+// lumaFunc 常见格式直接读亮度，避免逐像素 At 转换
+func lumaFunc(img image.Image) func(x, y int) float64 {
+	switch m := img.(type) {
+	case *image.YCbCr:
+		return func(x, y int) float64 { return float64(m.Y[m.YOffset(x, y)]) }
+	case *image.Gray:
+		return func(x, y int) float64 { return float64(m.Pix[m.PixOffset(x, y)]) }
+	case *image.RGBA:
+		return func(x, y int) float64 {
+			i := m.PixOffset(x, y)
+			return 0.299*float64(m.Pix[i]) + 0.587*float64(m.Pix[i+1]) + 0.114*float64(m.Pix[i+2])
+		}
+	}
+	return func(x, y int) float64 {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 257
+	}
+}
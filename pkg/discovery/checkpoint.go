@@ -0,0 +1,130 @@
+package discovery
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint 一个活动流的抓取进度，保存为 JSON
+type Checkpoint struct {
+	Stream    string               `json:"stream"`
+	LastCrawl time.Time            `json:"lastCrawl"`         //上次抓取时流中最新活动的时间
+	Items     map[string]time.Time `json:"items"`             //已下载的 manifest 及其活动时间
+	Pending   map[string]time.Time `json:"pending,omitempty"` //下载失败、下次重试的 manifest
+
+	path string
+	mu   sync.Mutex
+}
+
+// CheckpointPath 活动流 stream 在 dir 下的进度文件
+func CheckpointPath(dir, stream string) string {
+	sum := sha1.Sum([]byte(stream))
+	return filepath.Join(dir, "harvest", hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load 读取进度文件，不存在时返回空的进度
+func Load(path, stream string) (*Checkpoint, error) {
+	cp := &Checkpoint{Stream: stream, Items: map[string]time.Time{}, Pending: map[string]time.Time{}, path: path}
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bs, cp); err != nil {
+		return nil, err
+	}
+	if cp.Items == nil {
+		cp.Items = map[string]time.Time{}
+	}
+	if cp.Pending == nil {
+		cp.Pending = map[string]time.Time{}
+	}
+	return cp, nil
+}
+
+// changed manifest 从未下载过，或活动时间晚于上次下载
+func (c *Checkpoint) changed(id string, t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	done, ok := c.Items[id]
+	return !ok || t.After(done)
+}
+
+// Reset 忽略已有进度，重新处理整个流
+func (c *Checkpoint) Reset() {
+	c.mu.Lock()
+	c.LastCrawl = time.Time{}
+	c.Items = map[string]time.Time{}
+	c.mu.Unlock()
+}
+
+// known 下载过或等待重试的 manifest，删除活动只对这些有意义
+func (c *Checkpoint) known(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, done := c.Items[id]
+	_, pending := c.Pending[id]
+	return done || pending
+}
+
+// Done 记录下载成功
+func (c *Checkpoint) Done(ch Change) error {
+	c.mu.Lock()
+	c.Items[ch.Id] = ch.EndTime
+	delete(c.Pending, ch.Id)
+	c.mu.Unlock()
+	return c.Save()
+}
+
+// Deleted 记录 manifest 已从流中删除
+func (c *Checkpoint) Deleted(ch Change) error {
+	c.mu.Lock()
+	delete(c.Items, ch.Id)
+	delete(c.Pending, ch.Id)
+	c.mu.Unlock()
+	return c.Save()
+}
+
+// Failed 记录下载失败，下次重试
+func (c *Checkpoint) Failed(ch Change) error {
+	c.mu.Lock()
+	c.Pending[ch.Id] = ch.EndTime
+	c.mu.Unlock()
+	return c.Save()
+}
+
+// Finish 全部处理完毕，记下流中最新活动的时间
+func (c *Checkpoint) Finish(newest time.Time) error {
+	c.mu.Lock()
+	if newest.After(c.LastCrawl) {
+		c.LastCrawl = newest
+	}
+	c.mu.Unlock()
+	return c.Save()
+}
+
+// Save 先写临时文件再改名，中途退出不会留下损坏的进度文件
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bs, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err = os.WriteFile(tmp, bs, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,206 @@
+// Package discovery 读取 IIIF Change Discovery API 1.0 的活动流（Activity Streams OrderedCollection），
+// 找出上次抓取以来新增、更新或删除的 manifest
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fetcher 取回 url 的内容
+type Fetcher func(url string) ([]byte, error)
+
+// Ref 活动流中的引用，兼容 {"id": ...}、{"@id": ...} 与纯字符串三种写法
+type Ref struct {
+	Id   string
+	Type string
+}
+
+func (r *Ref) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		r.Id = s
+		return nil
+	}
+	var v struct {
+		Id     string `json:"id"`
+		AtId   string `json:"@id"`
+		Type   string `json:"type"`
+		AtType string `json:"@type"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	r.Id, r.Type = v.Id, v.Type
+	if r.Id == "" {
+		r.Id = v.AtId
+	}
+	if r.Type == "" {
+		r.Type = v.AtType
+	}
+	return nil
+}
+
+// Activity 一条活动：Create、Update、Delete、Remove 等
+type Activity struct {
+	Type    string
+	Object  Ref
+	EndTime time.Time //缺少或无法解析时为零值
+}
+
+// Change 需要处理的一个 manifest
+type Change struct {
+	Id      string
+	Deleted bool //Delete 或 Remove
+	EndTime time.Time
+}
+
+type page struct {
+	Type         string     `json:"type"`
+	First        *Ref       `json:"first"`
+	Last         *Ref       `json:"last"`
+	Prev         *Ref       `json:"prev"`
+	Next         *Ref       `json:"next"`
+	OrderedItems []activity `json:"orderedItems"`
+}
+
+type activity struct {
+	Type    string `json:"type"`
+	Object  Ref    `json:"object"`
+	EndTime string `json:"endTime"`
+}
+
+// MaxPages 最多读取的页数，防止 prev/next 成环
+const MaxPages = 100000
+
+// Walk 从最新的一页开始沿 prev 向前读取，每页的活动按从新到旧交给 fn，fn 返回 false 时停止。
+// 流没有 last 时从 first 沿 next 读完全部页面，再倒序处理
+func Walk(stream string, fetch Fetcher, fn func(Activity) bool) error {
+	c, err := getPage(stream, fetch)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(c.Type, "OrderedCollectionPage") {
+		return walkBack(stream, c, fetch, fn)
+	}
+	if c.Last != nil && c.Last.Id != "" {
+		p, err := getPage(c.Last.Id, fetch)
+		if err != nil {
+			return err
+		}
+		return walkBack(c.Last.Id, p, fetch, fn)
+	}
+	if c.First == nil || c.First.Id == "" {
+		//整个流只有内联的 orderedItems
+		each(c.OrderedItems, fn)
+		return nil
+	}
+	var pages []*page
+	seen := map[string]bool{}
+	for u := c.First.Id; u != "" && !seen[u] && len(pages) < MaxPages; {
+		seen[u] = true
+		p, err := getPage(u, fetch)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, p)
+		u = ""
+		if p.Next != nil {
+			u = p.Next.Id
+		}
+	}
+	for i := len(pages) - 1; i >= 0; i-- {
+		if !each(pages[i].OrderedItems, fn) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func walkBack(u string, p *page, fetch Fetcher, fn func(Activity) bool) (err error) {
+	seen := map[string]bool{u: true}
+	for n := 0; n < MaxPages; n++ {
+		if !each(p.OrderedItems, fn) || p.Prev == nil || p.Prev.Id == "" || seen[p.Prev.Id] {
+			return nil
+		}
+		u = p.Prev.Id
+		seen[u] = true
+		if p, err = getPage(u, fetch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// each 一页内的活动按时间先后排列，倒序交给 fn
+func each(items []activity, fn func(Activity) bool) bool {
+	for i := len(items) - 1; i >= 0; i-- {
+		it := items[i]
+		a := Activity{Type: it.Type, Object: it.Object}
+		a.EndTime, _ = time.Parse(time.RFC3339, strings.TrimSpace(it.EndTime))
+		if !fn(a) {
+			return false
+		}
+	}
+	return true
+}
+
+func getPage(u string, fetch Fetcher) (*page, error) {
+	bs, err := fetch(u)
+	if err != nil {
+		return nil, err
+	}
+	p := new(page)
+	if err = json.Unmarshal(bs, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+	return p, nil
+}
+
+// IsManifest 活动对象是否为 manifest（没有写 type 的也当作 manifest）
+func IsManifest(r Ref) bool {
+	return r.Type == "" || strings.HasSuffix(r.Type, "Manifest")
+}
+
+// Changes 列出 cp 记录的上次抓取以来需要处理的 manifest，按时间先后排列；上次下载失败的排在最前。
+// 遇到早于 cp.LastCrawl 的活动即停止读取。newest 为流中最新活动的时间，全部处理完后写入 cp.LastCrawl
+func Changes(stream string, cp *Checkpoint, fetch Fetcher) (changes []Change, newest time.Time, err error) {
+	seen := map[string]bool{}
+	var found []Change
+	err = Walk(stream, fetch, func(a Activity) bool {
+		if !cp.LastCrawl.IsZero() && !a.EndTime.IsZero() && a.EndTime.Before(cp.LastCrawl) {
+			return false
+		}
+		if a.EndTime.After(newest) {
+			newest = a.EndTime
+		}
+		id := a.Object.Id
+		if id == "" || seen[id] || !IsManifest(a.Object) {
+			return true
+		}
+		//同一 manifest 只取最新的一次活动
+		seen[id] = true
+		deleted := a.Type == "Delete" || a.Type == "Remove"
+		if deleted && !cp.known(id) || !deleted && !cp.changed(id, a.EndTime) {
+			return true
+		}
+		found = append(found, Change{Id: id, Deleted: deleted, EndTime: a.EndTime})
+		return true
+	})
+	if err != nil {
+		return nil, newest, err
+	}
+	for id, t := range cp.Pending {
+		if !seen[id] {
+			changes = append(changes, Change{Id: id, EndTime: t})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].EndTime.Before(changes[j].EndTime) })
+	for i := len(found) - 1; i >= 0; i-- {
+		changes = append(changes, found[i])
+	}
+	return changes, newest, nil
+}
@@ -0,0 +1,118 @@
+package discovery_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"bookget/pkg/discovery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stream = "https://example.org/activity/all-changes"
+
+// feed 模拟一个活动流：collection 与两页活动
+type feed map[string]string
+
+func (f feed) fetch(u string) ([]byte, error) {
+	s, ok := f[u]
+	if !ok {
+		return nil, fmt.Errorf("404 %s", u)
+	}
+	return []byte(s), nil
+}
+
+func activity(typ, id, t string) string {
+	return fmt.Sprintf(`{"type": %q, "object": {"id": %q, "type": "Manifest"}, "endTime": %q}`, typ, id, t)
+}
+
+func newFeed() feed {
+	return feed{
+		stream: `{"@context": "http://iiif.io/api/discovery/1/context.json", "id": "` + stream + `", "type": "OrderedCollection",
+			"first": {"id": "` + stream + `/page-0", "type": "OrderedCollectionPage"},
+			"last": {"id": "` + stream + `/page-1", "type": "OrderedCollectionPage"}}`,
+		stream + "/page-0": `{"type": "OrderedCollectionPage", "next": {"id": "` + stream + `/page-1"}, "orderedItems": [` +
+			activity("Create", "https://example.org/iiif/1/manifest", "2024-01-01T00:00:00Z") + "," +
+			activity("Create", "https://example.org/iiif/2/manifest", "2024-01-02T00:00:00Z") + "," +
+			`{"type": "Create", "object": {"id": "https://example.org/iiif/top", "type": "Collection"}, "endTime": "2024-01-02T00:00:00Z"}` + `]}`,
+		stream + "/page-1": `{"type": "OrderedCollectionPage", "prev": {"id": "` + stream + `/page-0"}, "orderedItems": [` +
+			activity("Update", "https://example.org/iiif/1/manifest", "2024-02-01T00:00:00Z") + "," +
+			activity("Create", "https://example.org/iiif/3/manifest", "2024-02-02T00:00:00Z") + `]}`,
+	}
+}
+
+func ids(changes []discovery.Change) []string {
+	var out []string
+	for _, c := range changes {
+		s := strings.TrimPrefix(c.Id, "https://example.org/iiif/")
+		if c.Deleted {
+			s = "-" + s
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestChanges(t *testing.T) {
+	f := newFeed()
+	path := discovery.CheckpointPath(t.TempDir(), stream)
+	cp, err := discovery.Load(path, stream)
+	require.NoError(t, err)
+
+	changes, newest, err := discovery.Changes(stream, cp, f.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2/manifest", "1/manifest", "3/manifest"}, ids(changes), "oldest first, one per manifest, no collections")
+	assert.Equal(t, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), newest)
+
+	require.NoError(t, cp.Done(changes[0]))
+	require.NoError(t, cp.Done(changes[1]))
+	require.NoError(t, cp.Failed(changes[2]))
+	require.NoError(t, cp.Finish(newest))
+
+	//再次运行：只有上次失败的与新的活动
+	f[stream+"/page-1"] = strings.TrimSuffix(f[stream+"/page-1"], "]}") + "," +
+		activity("Update", "https://example.org/iiif/2/manifest", "2024-03-01T00:00:00Z") + "," +
+		activity("Delete", "https://example.org/iiif/1/manifest", "2024-03-02T00:00:00Z") + "]}"
+	delete(f, stream+"/page-0") //早于上次抓取的页面不应再读取
+	cp, err = discovery.Load(path, stream)
+	require.NoError(t, err)
+	changes, newest, err = discovery.Changes(stream, cp, f.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3/manifest", "2/manifest", "-1/manifest"}, ids(changes))
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), newest)
+
+	for _, c := range changes {
+		if c.Deleted {
+			require.NoError(t, cp.Deleted(c))
+		} else {
+			require.NoError(t, cp.Done(c))
+		}
+	}
+	require.NoError(t, cp.Finish(newest))
+	cp, err = discovery.Load(path, stream)
+	require.NoError(t, err)
+	assert.Empty(t, cp.Pending)
+	assert.Len(t, cp.Items, 2)
+	changes, _, err = discovery.Changes(stream, cp, f.fetch)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	cp.Reset()
+	_, _, err = discovery.Changes(stream, cp, f.fetch)
+	assert.Error(t, err, "a full re-run reads page-0 again")
+}
+
+func TestWalkForward(t *testing.T) {
+	//没有 last 的流：沿 next 读完再倒序
+	f := newFeed()
+	f[stream] = `{"type": "OrderedCollection", "first": "` + stream + `/page-0"}`
+	var got []string
+	err := discovery.Walk(stream, f.fetch, func(a discovery.Activity) bool {
+		got = append(got, a.Type+" "+strings.TrimPrefix(a.Object.Id, "https://example.org/iiif/"))
+		return len(got) < 3
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Create 3/manifest", "Update 1/manifest", "Create top"}, got)
+}
@@ -1,7 +1,11 @@
 package downloader
 
 import (
-	"bookget/pkg/progressbar"
+	"bookget/pkg/events"
+	"bookget/pkg/gohttp"
+	"bookget/pkg/i18n"
+	"bookget/pkg/metrics"
+	"bookget/pkg/progress"
 	"bytes"
 	"context"
 	"fmt"
@@ -66,9 +70,10 @@ type DownloadManager struct {
 	allDone    bool      // 标记所有任务是否已完成
 	startTime  time.Time // 记录开始时间
 
-	bar        *progressbar.ProgressBar // 总进度条(基于任务数)
-	totalSize  int64                    // 总文件大小
-	downloaded int64                    // 已下载字节数
+	job        *progress.Job // 总进度(基于任务数)
+	barTotal   int           // SetBar 指定的总任务数
+	totalSize  int64         // 总文件大小
+	downloaded int64         // 已下载字节数
 }
 
 // NewDownloadManager 创建下载管理器
@@ -109,14 +114,15 @@ func (dm *DownloadManager) AddTask(url, method string, headers map[string]string
 	}
 
 	dm.tasks = append(dm.tasks, task)
+	events.Emit(events.Event{Type: events.PageQueued, Url: url, Path: filepath.Join(saveDir, filename)})
 }
 
-// SetBar 设置进度条
+// SetBar 设置进度显示的总任务数，默认为已添加的任务数
 func (dm *DownloadManager) SetBar(maxTasks int) {
 	dm.mu.Lock()
 	defer dm.mu.Unlock()
 
-	dm.bar = progressbar.Default(int64(maxTasks), "downloading")
+	dm.barTotal = maxTasks
 }
 
 // Start 开始下载
@@ -124,17 +130,18 @@ func (dm *DownloadManager) Start() {
 	dm.mu.Lock()
 	dm.startTime = time.Now()
 
-	// 初始化进度条
-	if dm.bar == nil {
-		dm.bar = progressbar.Default(int64(len(dm.tasks)), "downloading")
-	}
-
 	if dm.showPrompt {
-		fmt.Printf("\n开始下载任务 (最大并发数: %d)...\n", dm.maxConcurrent)
-		fmt.Printf("总任务数: %d\n", len(dm.tasks))
+		log.Println(i18n.T("downloader.start", dm.maxConcurrent, len(dm.tasks)))
 		dm.showPrompt = false
 	}
 
+	// 初始化进度
+	total := dm.barTotal
+	if total <= 0 {
+		total = len(dm.tasks)
+	}
+	dm.job = progress.Default.Start(dm.jobName(), progress.Pages, int64(total))
+
 	dm.mu.Unlock()
 
 	for _, task := range dm.tasks {
@@ -146,18 +153,20 @@ func (dm *DownloadManager) Start() {
 				dm.wg.Done()
 			}()
 
+			start := time.Now()
 			err := t.Download(dm.ctx, dm) // 传入dm以更新总进度
+			events.Page(t.URL, filepath.Join(t.SaveDir, t.FileName), start, err)
 
 			dm.mu.Lock()
 			if err != nil {
 				atomic.AddInt32(&dm.failCount, 1)
 				t.Success = false
 				t.ErrorMessage = err.Error()
-				fmt.Printf("下载失败: %s (%s)\n", t.FileName, err)
+				progress.Printf("%s", i18n.T("downloader.failed", t.FileName, err))
 			} else {
 				atomic.AddInt32(&dm.successCount, 1)
 				t.Success = true
-				dm.bar.Add(1) // 每个任务完成时进度条+1
+				dm.job.Add(1) // 每个任务完成时进度+1
 			}
 			dm.mu.Unlock()
 		}(task)
@@ -167,15 +176,25 @@ func (dm *DownloadManager) Start() {
 
 	elapsed := time.Since(dm.startTime)
 	dm.mu.Lock()
-	if dm.bar != nil {
-		_ = dm.bar.Finish()
-	}
-	fmt.Printf("\n下载完成! 成功: %d, 失败: %d, 耗时: %v\n",
-		dm.successCount, dm.failCount, elapsed.Round(time.Millisecond))
+	dm.job.Done()
+	log.Println(i18n.T("downloader.done", dm.successCount, dm.failCount, elapsed.Round(time.Millisecond)))
 	dm.allDone = true
 	dm.mu.Unlock()
 }
 
+// jobName 进度中显示的名称：任务所在的图书目录，分卷时带上卷名
+func (dm *DownloadManager) jobName() string {
+	if len(dm.tasks) == 0 {
+		return "downloading"
+	}
+	dir := filepath.Clean(dm.tasks[0].SaveDir)
+	name := filepath.Base(dir)
+	if strings.HasPrefix(name, "vol.") {
+		name = filepath.Base(filepath.Dir(dir)) + "/" + name
+	}
+	return name
+}
+
 // getTasksToProcess 获取待处理的任务
 func (dm *DownloadManager) getTasksToProcess() []*DownloadTask {
 	dm.mu.Lock()
@@ -214,7 +233,7 @@ func (dm *DownloadManager) Stop() {
 func (task *DownloadTask) Download(ctx context.Context, dm *DownloadManager) error {
 	// 1. 获取文件信息
 	if err := task.getFileInfo(ctx); err != nil {
-		log.Printf("警告: %v", err)
+		log.Println(i18n.T("downloader.warning", err))
 		if task.FileName == "" {
 			task.FileName = getFileNameFromURL(task.URL)
 		}
@@ -247,13 +266,19 @@ func (task *DownloadTask) Download(ctx context.Context, dm *DownloadManager) err
 
 	// 4. 保存文件
 	if task.buffer.Len() > 0 {
+		filePath := filepath.Join(task.SaveDir, task.FileName)
+		if err := gohttp.CheckContent(http.StatusOK, task.ContentType, task.buffer.Bytes(), filePath); err != nil {
+			return err
+		}
 		if err := os.MkdirAll(task.SaveDir, 0755); err != nil {
-			return fmt.Errorf("创建目录失败: %v", err)
+			return i18n.Errorf("downloader.mkdir", err)
 		}
 
-		filePath := filepath.Join(task.SaveDir, task.FileName)
 		if err := os.WriteFile(filePath, task.buffer.Bytes(), 0644); err != nil {
-			return fmt.Errorf("写入文件失败: %v", err)
+			return i18n.Errorf("downloader.write", err)
+		}
+		if err := gohttp.NotifySaved(filePath, task.URL); err != nil {
+			return err
 		}
 	}
 
@@ -264,7 +289,7 @@ func (task *DownloadTask) Download(ctx context.Context, dm *DownloadManager) err
 func (task *DownloadTask) multiThreadDownload(ctx context.Context, dm *DownloadManager) error {
 	// 确保文件大小已知且有效
 	if task.ContentSize <= 0 {
-		return fmt.Errorf("无法使用多线程下载: 文件大小未知")
+		return i18n.Error("downloader.no_size_multi")
 	}
 
 	chunkSize := task.ContentSize / int64(task.Threads)
@@ -304,7 +329,7 @@ func (task *DownloadTask) multiThreadDownload(ctx context.Context, dm *DownloadM
 			// 设置Range头
 			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
 
-			client := &http.Client{}
+			client := &http.Client{Transport: metrics.Transport(nil)}
 			resp, err := client.Do(req.WithContext(ctx))
 			if err != nil {
 				errOnce.Do(func() { firstErr = err })
@@ -314,7 +339,7 @@ func (task *DownloadTask) multiThreadDownload(ctx context.Context, dm *DownloadM
 
 			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
 				errOnce.Do(func() {
-					firstErr = fmt.Errorf("服务器返回错误状态码: %d", resp.StatusCode)
+					firstErr = i18n.Errorf("http.status", resp.StatusCode)
 				})
 				return
 			}
@@ -333,6 +358,7 @@ func (task *DownloadTask) multiThreadDownload(ctx context.Context, dm *DownloadM
 						task.mu.Unlock()
 
 						atomic.AddInt64(&dm.downloaded, int64(n))
+						dm.job.AddBytes(int64(n))
 					}
 
 					if readErr != nil {
@@ -365,7 +391,7 @@ func (task *DownloadTask) singleThreadDownload(ctx context.Context, dm *Download
 		req.Header.Set("User-Agent", userAgent)
 	}
 
-	client := &http.Client{}
+	client := &http.Client{Transport: metrics.Transport(nil)}
 	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return err
@@ -373,7 +399,7 @@ func (task *DownloadTask) singleThreadDownload(ctx context.Context, dm *Download
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("服务器返回错误状态码: %d", resp.StatusCode)
+		return i18n.Errorf("http.status", resp.StatusCode)
 	}
 
 	buf := make([]byte, 32*1024)
@@ -389,6 +415,7 @@ func (task *DownloadTask) singleThreadDownload(ctx context.Context, dm *Download
 				task.mu.Unlock()
 
 				atomic.AddInt64(&dm.downloaded, int64(n))
+				dm.job.AddBytes(int64(n))
 			}
 
 			if readErr != nil {
@@ -433,7 +460,7 @@ func (task *DownloadTask) getFileInfo(ctx context.Context) error {
 		req.Header.Set("User-Agent", userAgent)
 	}
 
-	client := &http.Client{}
+	client := &http.Client{Transport: metrics.Transport(nil)}
 	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return err
@@ -447,7 +474,7 @@ func (task *DownloadTask) getFileInfo(ctx context.Context) error {
 	}
 
 	if resp.StatusCode != expectedStatus {
-		return fmt.Errorf("服务器返回错误状态码: %d", resp.StatusCode)
+		return i18n.Errorf("http.status", resp.StatusCode)
 	}
 
 	// 处理分块传输的情况
@@ -468,7 +495,7 @@ func (task *DownloadTask) getFileInfo(ctx context.Context) error {
 	} else if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
 		size, err := strconv.ParseInt(contentLength, 10, 64)
 		if err != nil {
-			return fmt.Errorf("解析文件大小失败: %v", err)
+			return i18n.Errorf("downloader.parse_size", err)
 		}
 		task.ContentSize = size
 	} else if isChunked {
@@ -476,7 +503,7 @@ func (task *DownloadTask) getFileInfo(ctx context.Context) error {
 		task.ContentSize = 0
 	} else {
 		// 既不是分块传输也没有Content-Length，可能是服务器错误
-		return fmt.Errorf("无法确定文件大小: 没有Content-Length头且不是分块传输")
+		return i18n.Error("downloader.no_size")
 	}
 
 	// 获取内容类型
@@ -512,7 +539,7 @@ func (task *DownloadTask) detectSupportedMethods(ctx context.Context) error {
 		headReq.Header.Set("User-Agent", userAgent)
 	}
 
-	client := &http.Client{}
+	client := &http.Client{Transport: metrics.Transport(nil)}
 	resp, err := client.Do(headReq.WithContext(ctx))
 
 	if err == nil && resp.StatusCode == http.StatusOK {
@@ -0,0 +1,183 @@
+// Package events 机器可读的事件流。--events json 时每个事件输出一行 JSON（NDJSON），
+// 供外部脚本使用，不必再用正则解析日志。各 handler 与两个下载器（gohttp、downloader）都经 Default 输出。
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	JobStarted     = "job_started"     //开始处理一个 URL
+	VolumeResolved = "volume_resolved" //解析出一册（或整本）的保存目录
+	PageQueued     = "page_queued"     //开始下载一页
+	PageDone       = "page_done"       //一页下载完成
+	PageFailed     = "page_failed"     //一页下载失败
+	JobFinished    = "job_finished"    //URL 处理结束
+)
+
+// 错误分类，见 Classify
+const (
+	ClassTimeout  = "timeout"
+	ClassCanceled = "canceled"
+	ClassNetwork  = "network"
+	ClassTruncate = "truncated"
+	ClassIO       = "io"
+	ClassOther    = "other"
+)
+
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Url     string    `json:"url,omitempty"`
+	Site    string    `json:"site,omitempty"`
+	Handler string    `json:"handler,omitempty"`
+	BookId  string    `json:"book_id,omitempty"`
+	Volume  string    `json:"volume,omitempty"`
+	Dir     string    `json:"dir,omitempty"`
+	Path    string    `json:"path,omitempty"`
+	Bytes   int64     `json:"bytes,omitempty"`
+	Ms      int64     `json:"ms,omitempty"`
+	Status  string    `json:"status,omitempty"` //job_finished：ok / failed
+	Class   string    `json:"class,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Bus 事件总线，未设置输出也没有订阅者时 Emit 不做任何事
+type Bus struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	subs   []func(Event)
+}
+
+var Default = new(Bus)
+
+// Emit 通过 Default 输出事件
+func Emit(e Event) {
+	Default.Emit(e)
+}
+
+// Enabled Default 是否已设置输出
+func Enabled() bool {
+	return Default.Enabled()
+}
+
+// SetOutput 设置输出，w 为 nil 时关闭事件流。w 实现 io.Closer 时由 Close 关闭
+func (b *Bus) SetOutput(w io.Writer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.enc, b.closer = nil, nil
+	if w == nil {
+		return
+	}
+	b.enc = json.NewEncoder(w)
+	b.enc.SetEscapeHTML(false)
+	if c, ok := w.(io.Closer); ok && w != os.Stdout && w != os.Stderr {
+		b.closer = c
+	}
+}
+
+// OpenFile 追加写入文件
+func (b *Bus) OpenFile(path string) error {
+	fp, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	b.SetOutput(fp)
+	return nil
+}
+
+// Subscribe 进程内订阅所有事件（如 metrics），fn 在 Emit 的协程中同步调用
+func (b *Bus) Subscribe(fn func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, fn)
+}
+
+func (b *Bus) Enabled() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.enc != nil || len(b.subs) > 0
+}
+
+func (b *Bus) Emit(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.enc == nil && len(b.subs) == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if b.enc != nil {
+		_ = b.enc.Encode(e)
+	}
+	for _, fn := range b.subs {
+		fn(e)
+	}
+}
+
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var err error
+	if b.closer != nil {
+		err = b.closer.Close()
+	}
+	b.enc, b.closer = nil, nil
+	return err
+}
+
+// Page 一页下载结束：err 为 nil 时输出 page_done（字节数取自文件），否则输出 page_failed
+func Page(url, path string, start time.Time, err error) {
+	if !Enabled() {
+		return
+	}
+	e := Event{Url: url, Path: path, Ms: time.Since(start).Milliseconds()}
+	if err != nil {
+		e.Type, e.Class, e.Error = PageFailed, Classify(err), err.Error()
+	} else {
+		e.Type = PageDone
+		if fi, err := os.Stat(path); err == nil {
+			e.Bytes = fi.Size()
+		}
+	}
+	Emit(e)
+}
+
+// Classifier 错误自带分类，如 gohttp.ContentError 的 auth / captcha / rate_limited
+type Classifier interface {
+	Class() string
+}
+
+// Classify 错误分类，便于脚本决定重试、等待或放弃
+func Classify(err error) string {
+	var c Classifier
+	var ne net.Error
+	var pe *os.PathError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &c):
+		return c.Class()
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return ClassTimeout
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return ClassTruncate
+	case errors.As(err, &pe): //*os.PathError 也实现了 net.Error
+		return ClassIO
+	case errors.As(err, &ne):
+		return ClassNetwork
+	}
+	return ClassOther
+}
@@ -0,0 +1,92 @@
+package events_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bookget/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rateLimited struct{}
+
+func (rateLimited) Error() string { return "rate limited" }
+func (rateLimited) Class() string { return "rate_limited" }
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, "", events.Classify(nil))
+	assert.Equal(t, "rate_limited", events.Classify(fmt.Errorf("page 3: %w", rateLimited{})))
+	assert.Equal(t, events.ClassCanceled, events.Classify(context.Canceled))
+	assert.Equal(t, events.ClassTimeout, events.Classify(context.DeadlineExceeded))
+	assert.Equal(t, events.ClassTruncate, events.Classify(fmt.Errorf("0001.jpg: %w", io.ErrUnexpectedEOF)))
+	assert.Equal(t, events.ClassNetwork, events.Classify(&net.OpError{Op: "dial", Err: errors.New("refused")}))
+	_, err := os.Open(filepath.Join(t.TempDir(), "missing"))
+	assert.Equal(t, events.ClassIO, events.Classify(err))
+	assert.Equal(t, events.ClassOther, events.Classify(errors.New("x")))
+}
+
+func TestBus(t *testing.T) {
+	var buf bytes.Buffer
+	bus := new(events.Bus)
+	bus.Emit(events.Event{Type: events.JobStarted})
+	assert.False(t, bus.Enabled())
+	assert.Zero(t, buf.Len())
+
+	bus.SetOutput(&buf)
+	bus.Emit(events.Event{Type: events.JobStarted, Url: "https://example.org/a?b=1&c=2"})
+	bus.Emit(events.Event{Type: events.JobFinished, Status: "ok", Ms: 12})
+	require.NoError(t, bus.Close())
+	bus.Emit(events.Event{Type: events.JobStarted})
+
+	var got []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+		got = append(got, m)
+	}
+	require.Len(t, got, 2)
+	assert.Equal(t, "job_started", got[0]["type"])
+	assert.Equal(t, "https://example.org/a?b=1&c=2", got[0]["url"])
+	assert.NotEmpty(t, got[0]["time"])
+	assert.NotContains(t, got[0], "bytes")
+	assert.Equal(t, "ok", got[1]["status"])
+}
+
+func TestPage(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "events.ndjson")
+	require.NoError(t, events.Default.OpenFile(out))
+	defer events.Default.Close()
+
+	page := filepath.Join(dir, "0001.jpg")
+	require.NoError(t, os.WriteFile(page, make([]byte, 2048), 0644))
+	events.Page("https://example.org/1.jpg", page, time.Now().Add(-time.Second), nil)
+	events.Page("https://example.org/2.jpg", filepath.Join(dir, "0002.jpg"), time.Now(), io.ErrUnexpectedEOF)
+	require.NoError(t, events.Default.Close())
+
+	bs, err := os.ReadFile(out)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(bs), []byte("\n"))
+	require.Len(t, lines, 2)
+	var done, failed events.Event
+	require.NoError(t, json.Unmarshal(lines[0], &done))
+	require.NoError(t, json.Unmarshal(lines[1], &failed))
+	assert.Equal(t, events.PageDone, done.Type)
+	assert.Equal(t, int64(2048), done.Bytes)
+	assert.GreaterOrEqual(t, done.Ms, int64(1000))
+	assert.Equal(t, page, done.Path)
+	assert.Equal(t, events.PageFailed, failed.Type)
+	assert.Equal(t, events.ClassTruncate, failed.Class)
+}
@@ -0,0 +1,107 @@
+// Package expand 把书单、合集、检索结果等“容器”网址展开为其中每一部书的网址，再交给 router 逐一下载
+package expand
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Fetcher 取回 url 的内容
+type Fetcher func(url string) ([]byte, error)
+
+// Expander 一类容器网址：page 从 1 开始，Parse 返回该页的图书地址，more 表示还有下一页
+type Expander interface {
+	Match(u *url.URL) bool
+	PageUrl(u *url.URL, page int) string
+	Parse(body []byte, page int) (urls []string, more bool, err error)
+}
+
+// MaxPages 每个容器最多读取的页数，防止分页参数无效的网站无限翻页
+const MaxPages = 500
+
+var expanders = map[string][]Expander{}
+
+// Register 为站点（与 router 中的 host 相同）注册容器类型
+func Register(e Expander, hosts ...string) {
+	for _, h := range hosts {
+		expanders[h] = append(expanders[h], e)
+	}
+}
+
+// Lookup sUrl 是容器时返回对应的 Expander
+func Lookup(sUrl string) (Expander, *url.URL, bool) {
+	u, err := url.Parse(sUrl)
+	if err != nil {
+		return nil, nil, false
+	}
+	for _, e := range expanders[strings.ToLower(u.Host)] {
+		if e.Match(u) {
+			return e, u, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Expand 逐页读取容器，返回去重后的图书地址；不是容器时返回 ok=false。
+// 某页读取失败时返回已取得的地址和错误
+func Expand(sUrl string, fetch Fetcher) (urls []string, ok bool, err error) {
+	e, u, ok := Lookup(sUrl)
+	if !ok {
+		return nil, false, nil
+	}
+	seen := map[string]bool{}
+	for page := 1; page <= MaxPages; page++ {
+		body, err := fetch(e.PageUrl(u, page))
+		if err != nil {
+			return urls, true, err
+		}
+		found, more, err := e.Parse(body, page)
+		if err != nil {
+			return urls, true, err
+		}
+		n := len(urls)
+		for _, v := range found {
+			if !seen[key(v)] {
+				seen[key(v)] = true
+				urls = append(urls, v)
+			}
+		}
+		//没有新地址说明分页参数被忽略，不再翻页
+		if !more || len(urls) == n {
+			break
+		}
+	}
+	return urls, true, nil
+}
+
+// Dedup 去掉重复的地址，保留第一次出现的顺序
+func Dedup(urls []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(urls))
+	for _, v := range urls {
+		if k := key(v); !seen[k] {
+			seen[k] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// key 比较地址时忽略协议、末尾的 / 和 #片段
+func key(u string) string {
+	u, _, _ = strings.Cut(u, "#")
+	u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	return strings.TrimSuffix(u, "/")
+}
+
+// setQuery 复制 u 并设置查询参数
+func setQuery(u *url.URL, kv ...string) string {
+	c := *u
+	q := c.Query()
+	for i := 0; i+1 < len(kv); i += 2 {
+		q.Set(kv[i], kv[i+1])
+	}
+	c.RawQuery = q.Encode()
+	c.Fragment = ""
+	return c.String()
+}
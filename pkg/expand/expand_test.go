@@ -0,0 +1,159 @@
+package expand_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"bookget/pkg/expand"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pages 按地址返回内容，记录请求过的地址
+type pages struct {
+	body map[string]string
+	got  []string
+}
+
+func (p *pages) fetch(u string) ([]byte, error) {
+	p.got = append(p.got, u)
+	b, ok := p.body[u]
+	if !ok {
+		return nil, fmt.Errorf("404 %s", u)
+	}
+	return []byte(b), nil
+}
+
+func TestNotContainer(t *testing.T) {
+	for _, u := range []string{
+		"https://www.loc.gov/item/2012402381/",
+		"https://www.loc.gov/item/2012402381/?fa=partof:x",
+		"https://www.loc.gov/about/",
+		"https://www.loc.gov/rr/asian/",
+		"https://www.loc.gov/collections/",
+		"https://www.loc.gov/",
+		"https://babel.hathitrust.org/cgi/pt?id=hvd.32044",
+		"https://iiif.lib.harvard.edu/manifests/view/drs:12345",
+		"https://oyjy.wzlib.cn/detail?id=5f1e0a",
+	} {
+		_, ok, err := expand.Expand(u, func(string) ([]byte, error) { panic(u) })
+		assert.False(t, ok, u)
+		assert.NoError(t, err)
+	}
+}
+
+func TestHarvardList(t *testing.T) {
+	p := &pages{body: map[string]string{
+		"https://listview.lib.harvard.edu/lists/drs-54194370": `
+<a target="_blank" href="https://nrs.harvard.edu//urn-3:FHCL:1234567">1</a>
+<a href="https://iiif.lib.harvard.edu/manifests/view/drs:428501920">2</a>
+<a href="https://iiif.lib.harvard.edu/manifests/drs:428501920">2</a>`,
+	}}
+	urls, ok, err := expand.Expand("https://listview.lib.harvard.edu/lists/drs-54194370", p.fetch)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"https://iiif.lib.harvard.edu/manifests/view/drs:428501920",
+		"https://nrs.harvard.edu/urn-3:FHCL:1234567",
+	}, urls)
+}
+
+func TestLocPaginated(t *testing.T) {
+	p := &pages{body: map[string]string{
+		"https://www.loc.gov/collections/chinese-rare-books/?c=100&fo=json&q=sutra&sp=1": `{"results":[
+{"url":"https://www.loc.gov/item/1/"},{"url":"https://www.loc.gov/collections/x/"}],"pagination":{"next":"https://www.loc.gov/...sp=2"}}`,
+		"https://www.loc.gov/collections/chinese-rare-books/?c=100&fo=json&q=sutra&sp=2": `{"results":[
+{"url":"https://www.loc.gov/item/1/"},{"url":"https://www.loc.gov/item/2/"}],"pagination":{"next":null}}`,
+	}}
+	urls, ok, err := expand.Expand("https://www.loc.gov/collections/chinese-rare-books/?q=sutra", p.fetch)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"https://www.loc.gov/item/1/", "https://www.loc.gov/item/2/"}, urls)
+	assert.Len(t, p.got, 2)
+}
+
+func TestIIIFCollection(t *testing.T) {
+	p := &pages{body: map[string]string{
+		"https://figgy.princeton.edu/collections/abc/manifest": `{"@type":"sc:Collection",
+"manifests":[{"@id":"https://figgy.princeton.edu/concern/scanned_resources/1/manifest"}],
+"items":[{"id":"https://figgy.princeton.edu/concern/scanned_resources/2/manifest","type":"Manifest"},
+{"id":"https://figgy.princeton.edu/collections/sub/manifest","type":"Collection"}]}`,
+	}}
+	urls, _, err := expand.Expand("https://figgy.princeton.edu/collections/abc/manifest", p.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://figgy.princeton.edu/concern/scanned_resources/1/manifest",
+		"https://figgy.princeton.edu/concern/scanned_resources/2/manifest",
+	}, urls)
+}
+
+func TestHathitrustCollection(t *testing.T) {
+	page := func(ids string, next int) string {
+		var b strings.Builder
+		for _, id := range strings.Fields(ids) {
+			fmt.Fprintf(&b, `<a href="https://babel.hathitrust.org/cgi/pt?id=%s">Full view</a>`, id)
+		}
+		if next > 0 {
+			fmt.Fprintf(&b, `<a href="/cgi/mb?a=listis&amp;c=123&amp;pn=%d">Next</a>`, next)
+		}
+		return b.String()
+	}
+	p := &pages{body: map[string]string{
+		"https://babel.hathitrust.org/cgi/mb?a=listis&c=123&sz=100&pn=1": page("mdp.1 mdp.2", 2),
+		"https://babel.hathitrust.org/cgi/mb?a=listis&c=123&sz=100&pn=2": page("mdp.2 uc1.3", 0),
+	}}
+	urls, ok, err := expand.Expand("https://babel.hathitrust.org/cgi/mb?a=listis;c=123", p.fetch)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"https://babel.hathitrust.org/cgi/pt?id=mdp.1",
+		"https://babel.hathitrust.org/cgi/pt?id=mdp.2",
+		"https://babel.hathitrust.org/cgi/pt?id=uc1.3",
+	}, urls)
+}
+
+func TestPageError(t *testing.T) {
+	p := &pages{body: map[string]string{
+		"https://babel.hathitrust.org/cgi/mb?a=listis&c=9&sz=100&pn=1": `<a href="/cgi/pt?id=a.1">1</a> <a href="?pn=2">2</a>`,
+	}}
+	urls, ok, err := expand.Expand("https://babel.hathitrust.org/cgi/mb?c=9", p.fetch)
+	assert.True(t, ok)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"https://babel.hathitrust.org/cgi/pt?id=a.1"}, urls)
+}
+
+func TestDedup(t *testing.T) {
+	assert.Equal(t, []string{"https://a.org/1/", "https://a.org/2"}, expand.Dedup([]string{
+		"https://a.org/1/", "http://a.org/1", "https://a.org/2", "https://a.org/1#p3",
+	}))
+}
+
+func TestHathitrustRecord(t *testing.T) {
+	p := &pages{body: map[string]string{
+		"https://catalog.hathitrust.org/api/volumes/brief/recordnumber/001234567.json": `{"items":[
+{"htid":"mdp.1","enumcron":"v.1","usRightsString":"Full view"},{"htid":"mdp.2","enumcron":"v.2","usRightsString":"Full view"}]}`,
+	}}
+	urls, ok, err := expand.Expand("https://catalog.hathitrust.org/Record/001234567", p.fetch)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"https://babel.hathitrust.org/cgi/pt?id=mdp.1",
+		"https://babel.hathitrust.org/cgi/pt?id=mdp.2",
+	}, urls)
+}
+
+func TestLocListMatch(t *testing.T) {
+	for _, u := range []string{
+		"https://www.loc.gov/collections/chinese-rare-books/",
+		"https://www.loc.gov/collections/chinese-rare-books/?q=sutra",
+		"https://www.loc.gov/search/?q=sutra",
+		"https://www.loc.gov/photos/?q=peking",
+		"https://www.loc.gov/books/?q=sutra",
+		"https://www.loc.gov/?fa=subject:china",
+	} {
+		p := &pages{body: map[string]string{}}
+		_, ok, _ := expand.Expand(u, p.fetch)
+		assert.True(t, ok, u)
+	}
+}
@@ -0,0 +1,227 @@
+package expand
+
+import (
+	"bookget/pkg/hathitrust"
+	"bookget/pkg/search"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(HarvardList{}, "listview.lib.harvard.edu")
+	Register(LocList{}, "www.loc.gov")
+	Register(IIIFCollection{}, "figgy.princeton.edu")
+	Register(HathitrustCollection{}, "babel.hathitrust.org")
+	Register(HathitrustRecord{}, "catalog.hathitrust.org")
+	Register(WzlibSearch{}, "oyjy.wzlib.cn")
+}
+
+// HarvardList 哈佛书单 https://listview.lib.harvard.edu/lists/drs-54194370
+type HarvardList struct{}
+
+var (
+	harvardDrsRe = regexp.MustCompile(`https?://iiif\.lib\.harvard\.edu/manifests/(?:view/)?(drs:\d+)`)
+	harvardNrsRe = regexp.MustCompile(`https?://nrs\.harvard\.edu/+(urn-3:[^"'\s<>]+)`)
+)
+
+func (HarvardList) Match(u *url.URL) bool {
+	return strings.HasPrefix(u.Path, "/lists/")
+}
+
+func (HarvardList) PageUrl(u *url.URL, page int) string {
+	return u.String()
+}
+
+// Parse 书单只有一页，条目链接为 manifests/view/drs:... 或 nrs.harvard.edu/urn-3:...
+func (HarvardList) Parse(body []byte, page int) (urls []string, more bool, err error) {
+	for _, m := range harvardDrsRe.FindAllSubmatch(body, -1) {
+		urls = append(urls, "https://iiif.lib.harvard.edu/manifests/view/"+string(m[1]))
+	}
+	for _, m := range harvardNrsRe.FindAllSubmatch(body, -1) {
+		urls = append(urls, "https://nrs.harvard.edu/"+string(m[1]))
+	}
+	return urls, false, nil
+}
+
+// LocList 美国国会图书馆的检索、合集、分类列表，如 https://www.loc.gov/collections/chinese-rare-books/?q=...
+// 只展开 /collections/{名称}/、/search/、/photos/、/books/ 与带 fa= 筛选的列表，这些页面都支持 fo=json；
+// /item/ 与 /resource/ 是单部书，其它页面（如 /about/、/rr/）不展开
+type LocList struct{}
+
+var locListPaths = []string{"search/", "photos/", "books/"}
+
+func (LocList) Match(u *url.URL) bool {
+	p := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	if strings.HasPrefix(p, "item/") || strings.HasPrefix(p, "resource/") {
+		return false
+	}
+	if u.Query().Has("fa") {
+		return true
+	}
+	if name, ok := strings.CutPrefix(p, "collections/"); ok {
+		return name != ""
+	}
+	for _, prefix := range locListPaths {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (LocList) PageUrl(u *url.URL, page int) string {
+	return setQuery(u, "fo", "json", "sp", strconv.Itoa(page), "c", "100")
+}
+
+func (LocList) Parse(body []byte, page int) (urls []string, more bool, err error) {
+	results, err := search.Loc{}.Parse(body)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, r := range results {
+		urls = append(urls, r.Url)
+	}
+	var p struct {
+		Pagination struct {
+			Next *string `json:"next"`
+		} `json:"pagination"`
+	}
+	_ = json.Unmarshal(body, &p)
+	return urls, p.Pagination.Next != nil && *p.Pagination.Next != "", nil
+}
+
+// IIIFCollection IIIF collection（v2 的 manifests 或 v3 的 items），如普林斯顿
+// https://figgy.princeton.edu/collections/{id}/manifest
+type IIIFCollection struct{}
+
+func (IIIFCollection) Match(u *url.URL) bool {
+	return strings.Contains(u.Path, "/collections/")
+}
+
+func (IIIFCollection) PageUrl(u *url.URL, page int) string {
+	return u.String()
+}
+
+func (IIIFCollection) Parse(body []byte, page int) (urls []string, more bool, err error) {
+	type ref struct {
+		Id     string `json:"id"`
+		AtId   string `json:"@id"`
+		Type   string `json:"type"`
+		AtType string `json:"@type"`
+	}
+	var c struct {
+		Manifests []ref `json:"manifests"`
+		Items     []ref `json:"items"`
+	}
+	if err = json.Unmarshal(body, &c); err != nil {
+		return nil, false, err
+	}
+	for _, r := range c.Manifests {
+		urls = append(urls, r.AtId)
+	}
+	for _, r := range c.Items {
+		if r.Type != "Manifest" && r.AtType != "sc:Manifest" {
+			continue
+		}
+		if r.Id != "" {
+			urls = append(urls, r.Id)
+		} else {
+			urls = append(urls, r.AtId)
+		}
+	}
+	return urls, false, nil
+}
+
+// HathitrustCollection HathiTrust 合集 https://babel.hathitrust.org/cgi/mb?a=listis;c=1234567890
+type HathitrustCollection struct{}
+
+var (
+	htCollRe = regexp.MustCompile(`[?;&]c=(\d+)`)
+	htPtRe   = regexp.MustCompile(`/cgi/pt\?id=([^&;"'#\s]+)`)
+	htPnRe   = regexp.MustCompile(`[?;&](?:amp;)?pn=(\d+)`)
+)
+
+func (HathitrustCollection) Match(u *url.URL) bool {
+	return u.Path == "/cgi/mb" && htCollRe.MatchString("?"+u.RawQuery)
+}
+
+// PageUrl 合集地址常用 ; 分隔参数，重新拼接
+func (HathitrustCollection) PageUrl(u *url.URL, page int) string {
+	c := htCollRe.FindStringSubmatch("?" + u.RawQuery)[1]
+	return fmt.Sprintf("https://babel.hathitrust.org/cgi/mb?a=listis&c=%s&sz=100&pn=%d", c, page)
+}
+
+// Parse 页面中有更大页码的翻页链接时还有下一页
+func (HathitrustCollection) Parse(body []byte, page int) (urls []string, more bool, err error) {
+	for _, m := range htPtRe.FindAllSubmatch(body, -1) {
+		urls = append(urls, hathitrust.VolumeUrl(string(m[1])))
+	}
+	for _, m := range htPnRe.FindAllSubmatch(body, -1) {
+		if n, _ := strconv.Atoi(string(m[1])); n > page {
+			more = true
+		}
+	}
+	return urls, more, nil
+}
+
+// HathitrustRecord HathiTrust 书目记录 https://catalog.hathitrust.org/Record/001234567，展开为其中每一册
+type HathitrustRecord struct{}
+
+func (HathitrustRecord) Match(u *url.URL) bool {
+	return hathitrust.RecordId(u.Path) != ""
+}
+
+func (HathitrustRecord) PageUrl(u *url.URL, page int) string {
+	return hathitrust.VolumesApi(hathitrust.RecordId(u.Path))
+}
+
+// Parse 只能检索的分册也列出，下载时报告 hathitrust.ErrLimitedView
+func (HathitrustRecord) Parse(body []byte, page int) (urls []string, more bool, err error) {
+	volumes, err := hathitrust.ParseVolumes(body)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, v := range volumes {
+		urls = append(urls, hathitrust.VolumeUrl(v.Id))
+	}
+	return urls, false, nil
+}
+
+// WzlibSearch 瓯越记忆的检索结果页，检索词在 keyword、kw 或 q 参数中
+type WzlibSearch struct{}
+
+func wzlibKeyword(u *url.URL) string {
+	q := u.Query()
+	for _, k := range []string{"keyword", "kw", "q"} {
+		if v := q.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (WzlibSearch) Match(u *url.URL) bool {
+	return wzlibKeyword(u) != ""
+}
+
+func (WzlibSearch) PageUrl(u *url.URL, page int) string {
+	return search.Wzlib{}.Request(wzlibKeyword(u), page).Url
+}
+
+func (WzlibSearch) Parse(body []byte, page int) (urls []string, more bool, err error) {
+	results, err := search.Wzlib{}.Parse(body)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, r := range results {
+		urls = append(urls, r.Url)
+	}
+	return urls, len(results) == search.PageSize, nil
+}
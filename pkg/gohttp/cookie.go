@@ -1,33 +1,17 @@
 package gohttp
 
 import (
-	"os"
-	"regexp"
-	"strings"
+	"bookget/pkg/cookies"
 )
 
-func ReadCookieFile(cfile string) (cookies string) {
+// ReadCookieFile 读取 cookie 文件（Netscape/JSON/Cookie 头格式），拼接为 Cookie 请求头
+func ReadCookieFile(cfile string) string {
 	if cfile == "" {
-		return
+		return ""
 	}
-	bs, err := os.ReadFile(cfile)
+	list, err := cookies.Load(cfile, "")
 	if err != nil {
 		return ""
 	}
-	mCookie := strings.Split(string(bs), "\n")
-	for _, line := range mCookie {
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
-		text := regexp.MustCompile(`\\"`).ReplaceAllString(line, "\"")
-		row := strings.Split(text, "\t")
-		if len(row) < 8 {
-			continue
-		}
-		k := strings.ReplaceAll(row[5], "\"", "")
-		v := strings.ReplaceAll(row[6], "\"", "")
-		s := k + "=" + v + "; "
-		cookies += s
-	}
-	return cookies
+	return cookies.HeaderString(list)
 }
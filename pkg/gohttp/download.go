@@ -6,10 +6,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"bookget/pkg/progress"
 )
 
 var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
@@ -103,16 +106,28 @@ func (r *Response) dlFile(d *Download) (size int64, err error) {
 	//}
 	var destTemp = fmt.Sprintf("%s.downloading", d.Dest)
 	file, err := os.Create(destTemp)
-	defer os.Rename(destTemp, d.Dest)
-	defer file.Close()
 	if err != nil {
 		return
 	}
 	// Allocate the file completely so that we can write concurrently
 	file.Truncate(r.resp.ContentLength)
 	size, err = io.Copy(file, io.TeeReader(r.resp.Body, d))
+	file.Close()
+	// 中断或长度不符时不覆盖目标文件，避免留下截断的图片
+	if err == nil && r.resp.ContentLength > 0 && size != r.resp.ContentLength {
+		err = fmt.Errorf("%s: %w (%d of %d bytes)", d.Dest, io.ErrUnexpectedEOF, size, r.resp.ContentLength)
+	}
+	if err != nil {
+		_ = os.Remove(destTemp)
+		return
+	}
+	if err = os.Rename(destTemp, d.Dest); err == nil {
+		err = NotifySaved(d.Dest, r.req.URL.String())
+	}
 	return
 }
+
+// dlProgressBar 把单个文件的下载进度交给 progress.Default 统一显示
 func dlProgressBar(wg *sync.WaitGroup, d *Download) {
 	defer wg.Done()
 	// Set default interval.
@@ -120,29 +135,17 @@ func dlProgressBar(wg *sync.WaitGroup, d *Download) {
 		d.Interval = uint64(400 / runtime.NumCPU())
 	}
 	sleepd := time.Duration(d.Interval) * time.Millisecond
+	if d.TotalSize() <= 0 {
+		return
+	}
+	job := progress.Default.Start(filepath.Base(d.Dest), progress.Bytes, int64(d.TotalSize()))
+	defer job.Done()
 	for {
-		// Run progress func.
-		if d.TotalSize() <= 0 {
-			return
-		}
-		pd := d.Size() * 100 / d.TotalSize()
-		speed := "="
-		max := int(pd)
-		for k := 0; k < max; k += 2 {
-			speed += "="
-		}
-		speed += ">"
-		after := 50 - len(speed)
-		for k := 0; k < after; k++ {
-			speed += " "
-		}
-		fmt.Fprintf(os.Stdout, "\r%d%%[%s]  %s/%s  %s/s    in %s", pd, speed, ByteUnitString(int64(d.Size())),
-			ByteUnitString(int64(d.TotalSize())), ByteUnitString(int64(d.AvgSpeed())), d.TotalCost())
-
+		job.Set(int64(d.Size()))
 		// Update last size
 		atomic.StoreUint64(&d.lastSize, atomic.LoadUint64(&d.size))
 		//stop
-		if pd == 100 || d.StopProgress {
+		if d.Size() >= d.TotalSize() || d.StopProgress {
 			d.StopProgress = true
 			break
 		}
@@ -1,6 +1,9 @@
 package gohttp
 
-import "context"
+import (
+	"context"
+	"os"
+)
 
 // NewClient new request object
 func NewClient(c context.Context, opts ...Options) *Request {
@@ -48,3 +51,19 @@ func FastGet(c context.Context, uri string, opts ...Options) (*Response, error)
 	r := NewClient(c)
 	return r.FastGet(uri, opts...)
 }
+
+// AfterSave 文件完整下载并改名为目标文件后调用（如记录 pages.json、图像后处理），src 为来源 URL，为 nil 时不处理。
+// 返回错误表示拒收该文件（如 --dedup requeue 发现占位图），由保存文件的一方删除 dest 并把错误返回给调用者
+var AfterSave func(dest, src string) error
+
+// NotifySaved 供其它下载器在保存文件后触发 AfterSave；拒收时删除 dest 并返回 AfterSave 的错误
+func NotifySaved(dest, src string) error {
+	if AfterSave == nil {
+		return nil
+	}
+	err := AfterSave(dest, src)
+	if err != nil {
+		_ = os.Remove(dest)
+	}
+	return err
+}
@@ -1,9 +1,12 @@
 package gohttp
 
 import (
+	"bookget/pkg/events"
+	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -33,11 +36,15 @@ func (r *Request) FastGet(uri string, opts ...Options) (resp *Response, err erro
 		Concurrency: r.opts.Concurrency,
 	}
 	d.mutex = new(sync.RWMutex)
+	start := time.Now()
+	events.Emit(events.Event{Type: events.PageQueued, Url: uri, Path: d.Dest})
 	//多线程下载
 	if err = d.ChunkInit(); err != nil {
+		events.Page(uri, d.Dest, start, err)
 		return nil, err
 	}
 	err = d.ChunkStart()
+	events.Page(uri, d.Dest, start, err)
 	resp = &Response{
 		resp: nil,
 		req:  r.req,
@@ -108,16 +115,36 @@ func (d *Download) GetInfoOrDownload() (*Info, error) {
 	d.opts.Headers["Range"] = "bytes=0-0"
 	r := NewClient(d.ctx)
 	r.Request("GET", d.URL, d.opts)
+	info := &Info{}
 	_resp, err := r.cli.Do(r.req)
+	if err != nil {
+		return info, err
+	}
 	defer _resp.Body.Close()
 
-	info := &Info{}
 	if _resp.ContentLength > 0 {
 		atomic.StoreUint64(&info.Size, uint64(_resp.ContentLength))
 	}
 
 	if _resp.StatusCode >= 300 {
-		return info, fmt.Errorf("Response status code is not ok: %d", _resp.StatusCode)
+		return info, CheckContent(_resp.StatusCode, _resp.Header.Get("Content-Type"), nil, d.Dest)
+	}
+	// Range 响应只有 1 字节，无法判断魔数，只校验 Content-Type
+	if _resp.StatusCode == http.StatusPartialContent {
+		mime := _resp.Header.Get("Content-Type")
+		if strings.Contains(mime, "text/html") || strings.Contains(mime, "json") {
+			return info, &ContentError{Kind: ErrUnexpectedContent, StatusCode: _resp.StatusCode, ContentType: mime}
+		}
+	} else {
+		br := bufio.NewReaderSize(_resp.Body, sniffSize)
+		head, _ := br.Peek(sniffSize)
+		if err = CheckContent(_resp.StatusCode, _resp.Header.Get("Content-Type"), head, d.Dest); err != nil {
+			return info, err
+		}
+		_resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{br, _resp.Body}
 	}
 
 	// Set content disposition non trusted name
@@ -131,6 +158,8 @@ func (d *Download) GetInfoOrDownload() (*Info, error) {
 	defer dest.Close()
 
 	if _, err = io.Copy(dest, io.TeeReader(_resp.Body, d)); err != nil {
+		_ = dest.Close()
+		_ = os.Remove(destTemp)
 		return info, err
 	}
 
@@ -148,10 +177,17 @@ func (d *Download) GetInfoOrDownload() (*Info, error) {
 			}
 		}
 		// Make sure the caller knows about the problem and we don't just silently fail
+		_ = dest.Close()
+		_ = os.Remove(destTemp)
 		return info, fmt.Errorf("Response includes content-range header which is invalid: %s", cr)
 	}
 
-	return info, nil
+	//不支持分块下载时文件已完整下载
+	_ = dest.Close()
+	if err = os.Rename(destTemp, d.Path()); err != nil {
+		return info, err
+	}
+	return info, NotifySaved(d.Path(), d.URL)
 }
 
 // Start downloads the file chunks, and merges them.
@@ -174,9 +210,17 @@ func (d *Download) ChunkStart() (err error) {
 		return err
 	}
 	defer func() {
-		err = file.Close()
+		cerr := file.Close()
 		if err == nil {
-			os.Rename(destTemp, d.Path())
+			err = cerr
+		}
+		//分块下载失败时不覆盖目标文件
+		if err != nil {
+			_ = os.Remove(destTemp)
+			return
+		}
+		if err = os.Rename(destTemp, d.Path()); err == nil {
+			err = NotifySaved(d.Path(), d.URL)
 		}
 	}()
 	size := d.TotalSize()
@@ -242,10 +286,10 @@ func (d *Download) DownloadChunk(c *Chunk, dest io.Writer) error {
 	r.Request("GET", d.URL, d.opts)
 	d.mutex.Unlock()
 	resp, err := r.cli.Do(r.req)
-	defer resp.Body.Close()
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 	// Verify the length
 	if resp.ContentLength != int64(c.End-c.Start+1) {
 		return fmt.Errorf(
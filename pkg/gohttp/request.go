@@ -1,19 +1,21 @@
 package gohttp
 
 import (
+	"bookget/pkg/authstore"
+	"bookget/pkg/cookies"
+	"bookget/pkg/events"
+	"bookget/pkg/metrics"
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
-	"os"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -104,6 +106,9 @@ func (r *Request) Request(method, uri string, opts ...Options) (*Request, error)
 	// parse query
 	r.parseQuery()
 
+	// 站点凭据：自定义请求头、Bearer token 与凭据文件中的 cookie
+	r.parseAuthStore()
+
 	// parse headers
 	r.parseHeaders()
 
@@ -111,17 +116,45 @@ func (r *Request) Request(method, uri string, opts ...Options) (*Request, error)
 	r.parseCookieFile()
 	r.parseCookies()
 
-	if r.opts.Debug {
+	if r.debugEnabled() {
 		// print request object
 		dump, err := httputil.DumpRequest(r.req, true)
 		if err == nil {
-			log.Printf("\n%s\n\n", dump)
+			slog.Log(r.ctx, r.debugLevel(), "request", "dump", string(dump))
 		}
 	}
 	return r, nil
 }
 
+// debugLevel Options.Debug 时请求详情以 INFO 输出，否则只在 --log-level debug 时输出。
+// Cookie、Authorization 与 sign/token 参数由 logging 隐去
+func (r *Request) debugLevel() slog.Level {
+	if r.opts.Debug {
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+func (r *Request) debugEnabled() bool {
+	return slog.Default().Enabled(r.ctx, r.debugLevel())
+}
+
+// do 保存到文件的请求输出 page_queued 与 page_done/page_failed 事件
 func (r *Request) do() (*Response, error) {
+	if r.opts.DestFile == "" {
+		return r.send()
+	}
+	start := time.Now()
+	events.Emit(events.Event{Type: events.PageQueued, Url: r.req.URL.String(), Path: r.opts.DestFile})
+	resp, err := r.send()
+	if err == nil && resp != nil {
+		err = resp.err
+	}
+	events.Page(r.req.URL.String(), r.opts.DestFile, start, err)
+	return resp, err
+}
+
+func (r *Request) send() (*Response, error) {
 	var _resp = new(http.Response)
 	var err error
 	for i := 0; i < r.opts.Retry; i++ {
@@ -134,15 +167,36 @@ func (r *Request) do() (*Response, error) {
 		return nil, err
 	}
 	defer _resp.Body.Close()
+	// 服务器刷新的 cookie 写回 cookie 文件（只回写 Netscape 格式，其他格式不改动用户的文件，刷新的 cookie 只留在 CookieJar 中）
+	if refreshed := _resp.Cookies(); len(refreshed) > 0 && r.opts.CookieFile != "" {
+		_ = cookies.Update(r.opts.CookieFile, r.req.URL, refreshed)
+	}
 	resp := &Response{
 		resp: _resp,
 		req:  r.req,
 		err:  err,
 	}
+	// 保存到文件前校验状态码、Content-Type 和文件头，避免把登录页、验证码页存成 0001.jpg
+	if err == nil && r.opts.DestFile != "" {
+		br := bufio.NewReaderSize(_resp.Body, sniffSize)
+		head, _ := br.Peek(sniffSize)
+		_resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{br, _resp.Body}
+		if err = CheckContent(_resp.StatusCode, _resp.Header.Get("Content-Type"), head, r.opts.DestFile); err != nil {
+			resp.err = err
+			return resp, err
+		}
+	}
 	if err != nil || _resp.StatusCode != http.StatusOK {
-		if r.opts.Debug {
+		if r.debugEnabled() {
 			// print response err
-			fmt.Println(err)
+			status := 0
+			if _resp != nil {
+				status = _resp.StatusCode
+			}
+			slog.Log(r.ctx, r.debugLevel(), "response", "url", r.req.URL.String(), "status", status, "err", err)
 		}
 		return resp, err
 	}
@@ -163,6 +217,8 @@ func (r *Request) do() (*Response, error) {
 		wg.Add(1)
 		go dlProgressBar(&wg, dl)
 		_, err := resp.dlFile(dl)
+		// 下载失败时大小达不到总长，需通知进度协程结束
+		dl.StopProgress = true
 		wg.Wait()
 		resp.err = err
 	} else {
@@ -171,12 +227,16 @@ func (r *Request) do() (*Response, error) {
 		resp.err = err
 	}
 
-	if r.opts.Debug {
+	if r.debugEnabled() {
 		// print response data
 		body, _ := resp.GetBody()
-		fmt.Println(string(body))
+		if r.opts.Debug {
+			slog.Log(r.ctx, r.debugLevel(), "response", "url", r.req.URL.String(), "status", _resp.StatusCode, "body", string(body))
+		} else {
+			slog.Log(r.ctx, r.debugLevel(), "response", "url", r.req.URL.String(), "status", _resp.StatusCode, "bytes", len(body), "dest", r.opts.DestFile)
+		}
 	}
-	return resp, nil
+	return resp, resp.err
 }
 
 func (r *Request) parseOptions() {
@@ -201,7 +261,7 @@ func (r *Request) parseClient() {
 	}
 	r.cli = &http.Client{
 		Timeout:   r.opts.timeout,
-		Transport: tr,
+		Transport: metrics.Transport(tr),
 	}
 	if r.opts.CookieJar != nil {
 		r.cli.Jar = r.opts.CookieJar
@@ -261,32 +321,43 @@ func (r *Request) parseCookieFile() {
 	if r.opts.CookieFile == "" {
 		return
 	}
-	fp, err := os.Open(r.opts.CookieFile)
+	list, err := cookies.Load(r.opts.CookieFile, r.req.URL.Hostname())
 	if err != nil {
 		return
 	}
-	defer fp.Close()
+	r.addCookies(list)
+}
 
-	bsHeader, err := io.ReadAll(fp)
-	if err != nil {
+// parseAuthStore 把 authstore.Default 中该站点的请求头与 Authorization 合并到 Headers（凭据优先），cookie 与 cookie 文件一样发送
+func (r *Request) parseAuthStore() {
+	creds := authstore.Default.Stored(r.req.URL.Hostname())
+	if len(creds.Headers) > 0 || creds.BearerToken != "" {
+		//Headers 可能在多个请求间共用，复制后再写入
+		headers := make(map[string]interface{}, len(r.opts.Headers)+len(creds.Headers)+1)
+		for k, v := range r.opts.Headers {
+			headers[k] = v
+		}
+		creds.ApplyHeaders(headers)
+		r.opts.Headers = headers
+	}
+	r.addCookies(creds.Cookies)
+}
+
+// addCookies 有 cookiejar 时按域名写入 jar，由 jar 决定发送哪些 cookie；否则把匹配的 cookie 加到请求
+func (r *Request) addCookies(list []*http.Cookie) {
+	if len(list) == 0 {
 		return
 	}
-	mHeader := strings.Split(string(bsHeader), "\n")
-	for _, line := range mHeader {
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
-		text := regexp.MustCompile(`\\"`).ReplaceAllString(line, "\"")
-		row := strings.Split(text, "\t")
-		if len(row) < 8 {
+	if r.opts.CookieJar != nil {
+		cookies.SetJar(r.opts.CookieJar, list)
+	}
+	for _, c := range cookies.ForURL(list, r.req.URL) {
+		if r.opts.CookieJar != nil && c.Domain != "" {
 			continue
 		}
-		k := strings.ReplaceAll(row[5], "\"", "")
-		v := strings.ReplaceAll(row[6], "\"", "")
-		//expires := strings.ReplaceAll(row[4], "#HttpOnly_", "")
 		r.req.AddCookie(&http.Cookie{
-			Name:  k,
-			Value: v,
+			Name:  c.Name,
+			Value: c.Value,
 		})
 	}
 }
@@ -0,0 +1,82 @@
+package gohttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/authstore"
+	"bookget/pkg/gohttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthStoreHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Clone()
+	}))
+	defer srv.Close()
+
+	saved := authstore.Default
+	t.Cleanup(func() { authstore.Default = saved })
+	authstore.Default = authstore.New()
+	authstore.Default.Merge("127.0.0.1", &authstore.Credentials{
+		BearerToken: "abc",
+		Headers:     map[string]string{"X-Api-Key": "k1"},
+		Cookies:     []*http.Cookie{{Name: "sid", Value: "s1", Domain: "127.0.0.1", Path: "/"}},
+	})
+	authstore.Default.Merge("example.org", &authstore.Credentials{Headers: map[string]string{"X-Other": "no"}})
+
+	headers := map[string]interface{}{"User-Agent": "bookget-test"}
+	_, err := gohttp.NewClient(context.Background(), gohttp.Options{Headers: headers}).Get(srv.URL + "/a")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc", got.Get("Authorization"))
+	assert.Equal(t, "k1", got.Get("X-Api-Key"))
+	assert.Equal(t, "bookget-test", got.Get("User-Agent"))
+	assert.Empty(t, got.Get("X-Other"))
+	assert.Contains(t, got.Get("Cookie"), "sid=s1")
+	//调用方的 Headers 不被改动
+	assert.Len(t, headers, 1)
+}
+
+func TestAfterSaveReject(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"))
+	}))
+	defer srv.Close()
+
+	rejected := errors.New("rejected")
+	saved := gohttp.AfterSave
+	t.Cleanup(func() { gohttp.AfterSave = saved })
+	var src string
+	gohttp.AfterSave = func(dest, u string) error {
+		src = u
+		return rejected
+	}
+	dest := filepath.Join(t.TempDir(), "0001.png")
+	_, err := gohttp.Get(context.Background(), srv.URL+"/1.png", gohttp.Options{DestFile: dest, Overwrite: true})
+	assert.ErrorIs(t, err, rejected)
+	assert.Equal(t, srv.URL+"/1.png", src)
+	assert.NoFileExists(t, dest)
+}
+
+func TestFastGetInvalidRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("Content-Range", "bytes 0-0/abc")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte{0xFF})
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "0001.jpg")
+	_, err := gohttp.FastGet(context.Background(), srv.URL+"/1.jpg", gohttp.Options{DestFile: dest, Overwrite: true, Concurrency: 2})
+	assert.Error(t, err)
+	assert.NoFileExists(t, dest)
+	assert.NoFileExists(t, dest+".downloading")
+}
@@ -0,0 +1,160 @@
+package gohttp
+
+import (
+	"bookget/pkg/verify"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// 下载内容校验失败的分类，handler 可以用 errors.Is 判断后决定重试、等待或放弃
+var (
+	ErrAuthRequired      = errors.New("authentication required")
+	ErrCaptcha           = errors.New("captcha required")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrNotFound          = errors.New("not found")
+	ErrUnexpectedContent = errors.New("unexpected content")
+)
+
+// sniffSize 校验时读取的响应头部字节数
+const sniffSize = 1024
+
+// ContentError 服务器返回了非预期的内容（登录页、验证码页、错误页等）
+type ContentError struct {
+	Kind        error
+	StatusCode  int
+	ContentType string
+	Expected    string
+	Detected    string
+}
+
+func (e *ContentError) Error() string {
+	msg := fmt.Sprintf("%s (HTTP %d, Content-Type: %s", e.Kind, e.StatusCode, e.ContentType)
+	if e.Expected != "" {
+		msg += fmt.Sprintf(", expected %s, got %s", e.Expected, e.Detected)
+	}
+	return msg + ")"
+}
+
+func (e *ContentError) Unwrap() error {
+	return e.Kind
+}
+
+// Class 事件流中的错误分类
+func (e *ContentError) Class() string {
+	switch e.Kind {
+	case ErrAuthRequired:
+		return "auth"
+	case ErrCaptcha:
+		return "captcha"
+	case ErrRateLimited:
+		return "rate_limited"
+	case ErrNotFound:
+		return "not_found"
+	}
+	return "unexpected_content"
+}
+
+var (
+	captchaKeywords = []string{"captcha", "验证码", "驗證碼", "geetest", "hcaptcha", "recaptcha", "cf-challenge", "challenge-platform", "真人验证"}
+	authKeywords    = []string{"login", "log in", "sign in", "signin", "password", "登录", "登錄", "請登入", "ログイン", "unauthorized", "access denied", "forbidden"}
+	rateKeywords    = []string{"too many requests", "rate limit", "访问过于频繁", "請求過於頻繁", "请求过于频繁", "try again later", "temporarily unavailable"}
+	notFoundKeyword = []string{"not found", "不存在", "找不到", "no longer available"}
+
+	// 以下关键词在图片、JSON 数据中也常出现，只在 HTML 的标题与正文文字中查找
+	captchaTextKeywords  = []string{"slider"}
+	notFoundTextKeywords = []string{"404"}
+
+	htmlRe       = regexp.MustCompile(`<(?:!doctype\s+html|html|head|title|body)[\s>]`)
+	htmlScriptRe = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?(?:</(?:script|style)>|$)`)
+	htmlTagRe    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// CheckContent 按状态码、Content-Type 和文件头部魔数校验下载内容是否为期望的文件类型
+// dest 的扩展名决定期望类型（jpg/png/tif/jp2/pdf 等），未知扩展名只校验状态码
+func CheckContent(statusCode int, contentType string, head []byte, dest string) error {
+	if len(head) > sniffSize {
+		head = head[:sniffSize]
+	}
+	ce := &ContentError{
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Expected:    verify.TypeByExt(dest),
+	}
+	switch statusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusProxyAuthRequired:
+		ce.Kind = classifyBody(head, ErrAuthRequired)
+		return ce
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		ce.Kind = ErrRateLimited
+		return ce
+	case http.StatusNotFound, http.StatusGone:
+		ce.Kind = ErrNotFound
+		return ce
+	default:
+		ce.Kind = classifyBody(head, ErrUnexpectedContent)
+		return ce
+	}
+
+	if ce.Expected == verify.TypeUnknown {
+		return nil
+	}
+	ce.Detected = verify.Detect(head)
+	if ce.Detected == ce.Expected {
+		return nil
+	}
+	// 服务器转换格式（例如请求 .jpg 返回 PNG）仍是有效图片，交给保存后的流程处理
+	mime := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if isBinaryType(ce.Detected) && (strings.HasPrefix(mime, "image/") || mime == "application/pdf" || mime == "" || mime == "application/octet-stream") {
+		return nil
+	}
+	ce.Kind = classifyBody(head, ErrUnexpectedContent)
+	return ce
+}
+
+func isBinaryType(t string) bool {
+	switch t {
+	case verify.TypeJPEG, verify.TypePNG, verify.TypeGIF, verify.TypeTIFF, verify.TypeJP2, verify.TypeWebP, verify.TypePDF:
+		return true
+	}
+	return false
+}
+
+// classifyBody 根据 HTML/JSON 页面内容判断是验证码、登录、限流还是不存在
+func classifyBody(head []byte, fallback error) error {
+	text := string(bytes.ToLower(head))
+	words := htmlText(text)
+	switch {
+	case containsAny(text, captchaKeywords), containsAny(words, captchaTextKeywords):
+		return ErrCaptcha
+	case containsAny(text, rateKeywords):
+		return ErrRateLimited
+	case containsAny(text, authKeywords):
+		return ErrAuthRequired
+	case fallback == ErrUnexpectedContent && (containsAny(text, notFoundKeyword) || containsAny(words, notFoundTextKeywords)):
+		return ErrNotFound
+	}
+	return fallback
+}
+
+// htmlText HTML 页面的标题与正文文字（去掉标签、属性、脚本与样式）；不是 HTML 时返回空
+func htmlText(text string) string {
+	if !htmlRe.MatchString(text) {
+		return ""
+	}
+	text = htmlScriptRe.ReplaceAllString(text, " ")
+	return htmlTagRe.ReplaceAllString(text, " ")
+}
+
+func containsAny(s string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package gohttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"bookget/pkg/gohttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckContent(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+	for _, test := range []struct {
+		name        string
+		status      int
+		contentType string
+		head        []byte
+		dest        string
+		want        error
+	}{
+		{"jpeg", http.StatusOK, "image/jpeg", jpeg, "0001.jpg", nil},
+		{"png served for jpg", http.StatusOK, "image/png", png, "0001.jpg", nil},
+		{"unknown extension", http.StatusOK, "text/html", []byte("<html>"), "info.json", nil},
+		{"captcha page", http.StatusOK, "text/html", []byte("<html><div id=\"captcha\">请输入验证码</div></html>"), "0001.jpg", gohttp.ErrCaptcha},
+		{"login page", http.StatusOK, "text/html; charset=utf-8", []byte("<html><form action=\"/login\"></form></html>"), "0001.jpg", gohttp.ErrAuthRequired},
+		{"rate limit page", http.StatusOK, "text/html", []byte("<html>Too Many Requests</html>"), "0001.tif", gohttp.ErrRateLimited},
+		{"html error page", http.StatusOK, "text/html", []byte("<html>oops</html>"), "0001.pdf", gohttp.ErrUnexpectedContent},
+		{"forbidden", http.StatusForbidden, "text/html", []byte("<html>region blocked</html>"), "0001.jpg", gohttp.ErrAuthRequired},
+		{"throttled", http.StatusTooManyRequests, "", nil, "0001.jpg", gohttp.ErrRateLimited},
+		{"not found", http.StatusNotFound, "text/html", nil, "0001.jpg", gohttp.ErrNotFound},
+		{"404 page", http.StatusOK, "text/html", []byte("<html><head><title>404</title></head><body>oops</body></html>"), "0001.jpg", gohttp.ErrNotFound},
+		{"slider captcha page", http.StatusOK, "text/html", []byte("<html><body><p>Drag the slider to continue</p></body></html>"), "0001.jpg", gohttp.ErrCaptcha},
+		{"slider in script", http.StatusOK, "text/html", []byte("<html><script>var slider = 404;</script><div class=\"slider\">oops</div></html>"), "0001.jpg", gohttp.ErrUnexpectedContent},
+		{"404 in json", http.StatusOK, "application/json", []byte(`{"id":404,"layout":"slider"}`), "0001.jpg", gohttp.ErrUnexpectedContent},
+	} {
+		err := gohttp.CheckContent(test.status, test.contentType, test.head, test.dest)
+		if test.want == nil {
+			assert.NoError(t, err, test.name)
+			continue
+		}
+		assert.ErrorIs(t, err, test.want, test.name)
+	}
+}
@@ -0,0 +1,183 @@
+// Package hathitrust HathiTrust 书目记录的分册、阅读页参数、逐页图像与全文层地址
+package hathitrust
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 阅读页不允许下载的原因，handler 用 errors.Is 判断
+var (
+	ErrLimitedView = errors.New("limited view (search only)")
+	ErrNoDownload  = errors.New("single page download is not allowed")
+)
+
+// AccessError 某册无法下载
+type AccessError struct {
+	Id   string
+	Kind error
+}
+
+func (e *AccessError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Id, e.Kind)
+}
+
+func (e *AccessError) Unwrap() error {
+	return e.Kind
+}
+
+// Class 事件流中的错误分类
+func (e *AccessError) Class() string {
+	if e.Kind == ErrLimitedView {
+		return "limited_view"
+	}
+	return "no_download"
+}
+
+// Volume 书目记录中的一册
+type Volume struct {
+	Id       string //htid，如 mdp.39015012345678
+	Enumcron string //册次，如 v.1
+	FullView bool
+}
+
+var (
+	recordRe  = regexp.MustCompile(`/Record/(\d+)`)
+	totalRe   = regexp.MustCompile(`totalSeq["']?\s*[:=]\s*["']?(\d+)`)
+	allowRe   = regexp.MustCompile(`allowSinglePageDownload["']?\s*[:=]\s*(true|false)`)
+	limitedRe = regexp.MustCompile(`(?i)limited\s*\(search[- ]only\)|search only`)
+)
+
+// RecordId https://catalog.hathitrust.org/Record/001234567 的记录号
+func RecordId(u string) string {
+	if m := recordRe.FindStringSubmatch(u); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// VolumesApi 书目 API：按记录号列出全部分册
+func VolumesApi(record string) string {
+	return fmt.Sprintf("https://catalog.hathitrust.org/api/volumes/brief/recordnumber/%s.json", record)
+}
+
+// VolumeUrl 一册的阅读页
+func VolumeUrl(id string) string {
+	return "https://babel.hathitrust.org/cgi/pt?id=" + id
+}
+
+// ParseVolumes 解析书目 API 的响应：
+//
+//	{"records":{...},"items":[{"htid":"mdp.39015...","enumcron":"v.1","usRightsString":"Full view"}]}
+func ParseVolumes(body []byte) ([]Volume, error) {
+	var resp struct {
+		Items []struct {
+			Htid           string `json:"htid"`
+			Enumcron       any    `json:"enumcron"` //没有册次时为 false
+			UsRightsString string `json:"usRightsString"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	volumes := make([]Volume, 0, len(resp.Items))
+	for _, v := range resp.Items {
+		if v.Htid == "" {
+			continue
+		}
+		enum, _ := v.Enumcron.(string)
+		volumes = append(volumes, Volume{
+			Id:       v.Htid,
+			Enumcron: enum,
+			FullView: strings.EqualFold(v.UsRightsString, "Full view"),
+		})
+	}
+	return volumes, nil
+}
+
+// Params 阅读页 HT.params 中与下载有关的参数
+type Params struct {
+	TotalSeq int
+}
+
+// ParseParams 解析阅读页：HT.params.totalSeq = 1220; HT.params.allowSinglePageDownload = true;
+// 只能检索（limited view）或不允许单页下载时返回 *AccessError
+func ParseParams(id string, body []byte) (*Params, error) {
+	m := allowRe.FindSubmatch(body)
+	if m == nil || string(m[1]) != "true" {
+		if limitedRe.Match(body) {
+			return nil, &AccessError{Id: id, Kind: ErrLimitedView}
+		}
+		return nil, &AccessError{Id: id, Kind: ErrNoDownload}
+	}
+	m = totalRe.FindSubmatch(body)
+	if m == nil {
+		return nil, fmt.Errorf("%s: totalSeq not found", id)
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return &Params{TotalSeq: n}, nil
+}
+
+// 全文层，见 ParseLayers
+const (
+	Plain = "plain" //纯文本，保存为 .txt
+	OCR   = "ocr"   //带版面信息的 OCR，保存为 .html
+)
+
+// ParseLayers 解析 --text：off | plain | ocr | both
+func ParseLayers(s string) ([]string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off":
+		return nil, nil
+	case Plain:
+		return []string{Plain}, nil
+	case OCR:
+		return []string{OCR}, nil
+	case "both":
+		return []string{Plain, OCR}, nil
+	}
+	return nil, fmt.Errorf("invalid text layer %q, want off|plain|ocr|both", s)
+}
+
+// LayerExt 全文层保存的扩展名
+func LayerExt(layer string) string {
+	if layer == OCR {
+		return ".html"
+	}
+	return ".txt"
+}
+
+// ImageUrl 第 seq 页（从 1 开始）的图像，format 为 jpeg、png 或 tiff
+func ImageUrl(id string, seq int, format string) string {
+	return fmt.Sprintf("https://babel.hathitrust.org/cgi/imgsrv/image?id=%s&attachment=1&size=ppi%%3A300&format=image/%s&seq=%d", url.QueryEscape(id), format, seq)
+}
+
+// LayerUrl 第 seq 页的全文层
+func LayerUrl(id string, seq int, layer string) string {
+	if layer == OCR {
+		return fmt.Sprintf("https://babel.hathitrust.org/cgi/imgsrv/html?id=%s&seq=%d", url.QueryEscape(id), seq)
+	}
+	return fmt.Sprintf("https://babel.hathitrust.org/cgi/imgsrv/download/plaintext?id=%s&seq=%d&attachment=1", url.QueryEscape(id), seq)
+}
+
+// MaxAttempts 每页最多请求次数
+const MaxAttempts = 8
+
+// Backoff 第 attempt 次（从 0 开始）失败后的等待时间：10 秒起按倍数增加，最多 1 分钟。
+// HathiTrust 对整页下载限速（约每分钟 20 MB），偶尔失败时不必每次都等满 1 分钟
+func Backoff(attempt int) time.Duration {
+	d := 10 * time.Second
+	for i := 0; i < attempt && d < time.Minute; i++ {
+		d *= 2
+	}
+	if d > time.Minute {
+		d = time.Minute
+	}
+	return d
+}
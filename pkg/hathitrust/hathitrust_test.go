@@ -0,0 +1,68 @@
+package hathitrust_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookget/pkg/events"
+	"bookget/pkg/hathitrust"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumes(t *testing.T) {
+	assert.Equal(t, "001234567", hathitrust.RecordId("https://catalog.hathitrust.org/Record/001234567?type=all"))
+	assert.Equal(t, "", hathitrust.RecordId("https://babel.hathitrust.org/cgi/pt?id=mdp.1"))
+	assert.Equal(t, "https://catalog.hathitrust.org/api/volumes/brief/recordnumber/001234567.json", hathitrust.VolumesApi("001234567"))
+
+	volumes, err := hathitrust.ParseVolumes([]byte(`{"records":{"001234567":{"recordURL":"https://catalog.hathitrust.org/Record/001234567"}},
+"items":[
+{"orig":"University of Michigan","htid":"mdp.39015001","enumcron":"v.1","usRightsString":"Full view"},
+{"orig":"Harvard University","htid":"hvd.32044002","enumcron":false,"usRightsString":"Limited (search-only)"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []hathitrust.Volume{
+		{Id: "mdp.39015001", Enumcron: "v.1", FullView: true},
+		{Id: "hvd.32044002", FullView: false},
+	}, volumes)
+}
+
+func TestParams(t *testing.T) {
+	p, err := hathitrust.ParseParams("mdp.1", []byte(`<script>HT.params.totalSeq = 1220;
+HT.params.allowSinglePageDownload = true;</script>`))
+	require.NoError(t, err)
+	assert.Equal(t, 1220, p.TotalSeq)
+
+	_, err = hathitrust.ParseParams("hvd.2", []byte(`<script>HT.params.allowSinglePageDownload = false;</script>
+<p>This item is not available online (<i class="icomoon"></i> Limited - search only) due to copyright restrictions.</p>`))
+	assert.True(t, errors.Is(err, hathitrust.ErrLimitedView))
+	assert.Equal(t, "limited_view", events.Classify(err))
+	var ae *hathitrust.AccessError
+	require.True(t, errors.As(err, &ae))
+	assert.Equal(t, "hvd.2", ae.Id)
+
+	_, err = hathitrust.ParseParams("uc1.3", []byte(`<script>HT.params.totalSeq = 12;</script>`))
+	assert.True(t, errors.Is(err, hathitrust.ErrNoDownload))
+	assert.Equal(t, "no_download", events.Classify(err))
+}
+
+func TestLayers(t *testing.T) {
+	layers, err := hathitrust.ParseLayers("both")
+	require.NoError(t, err)
+	assert.Equal(t, []string{hathitrust.Plain, hathitrust.OCR}, layers)
+	layers, err = hathitrust.ParseLayers("off")
+	require.NoError(t, err)
+	assert.Empty(t, layers)
+	_, err = hathitrust.ParseLayers("pdf")
+	assert.Error(t, err)
+
+	assert.Equal(t, "https://babel.hathitrust.org/cgi/imgsrv/download/plaintext?id=mdp.1&seq=3&attachment=1", hathitrust.LayerUrl("mdp.1", 3, hathitrust.Plain))
+	assert.Equal(t, "https://babel.hathitrust.org/cgi/imgsrv/html?id=mdp.1&seq=3", hathitrust.LayerUrl("mdp.1", 3, hathitrust.OCR))
+	assert.Equal(t, ".html", hathitrust.LayerExt(hathitrust.OCR))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, hathitrust.Backoff(0))
+	assert.Equal(t, 40*time.Second, hathitrust.Backoff(2))
+	assert.Equal(t, time.Minute, hathitrust.Backoff(10))
+}
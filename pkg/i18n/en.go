@@ -0,0 +1,209 @@
+package i18n
+
+// en English，LANG 为中文、日文以外的语言时使用
+var en = map[string]string{
+	//config
+	"config.bad_dir":       "The program directory must not contain spaces, Chinese or other special characters. Recommended: D:\\bookget",
+	"config.press_enter":   "Press Enter to exit ...",
+	"config.created":       "Config file created: %s",
+	"config.mkdir_failed":  "failed to create directory: %w",
+	"config.write_failed":  "failed to create config file: %w",
+	"config.stat_failed":   "failed to check config file: %w",
+	"config.error":         "Error: %v",
+	"config.bad_date":      "invalid date %q, want YYYY, YYYY-MM or YYYY-MM-DD",
+	"config.text_only_off": "-text-only cannot be combined with -text off",
+	"help.usage":           "Usage: bookget [OPTION]... [URL]...",
+
+	//global flags
+	"flag.input":               "file with URLs to download, e.g. urls.txt",
+	"flag.output":              "directory to save downloads to",
+	"flag.sequence":            "page range, e.g. 4:434",
+	"flag.volume":              "volume range of a multi-volume book, e.g. 10:20 downloads volumes 10 to 20; for modernhistory.org.cn periodicals it counts the selected issues",
+	"flag.format":              "IIIF image request URI: full/full/0/default.jpg",
+	"flag.user-agent":          "user-agent",
+	"flag.bookmark":            "download only the table of contents [0|1]. Only for gj.tianyige.com.cn.",
+	"flag.text":                "download per-page text layers where available: off|plain|ocr|both (HathiTrust)",
+	"flag.text-only":           "download only the text layers selected by -text (plain if unset), no images",
+	"flag.quality":             "file preference when several are offered: best = highest resolution, or a list such as tiff,jp2,jpeg,pdf (loc.gov). Default follows -extension",
+	"flag.date-from":           "first date to download for newspapers and periodicals, e.g. 1905, 1905-03 or 1905-03-01",
+	"flag.date-to":             "last date to download for newspapers and periodicals, e.g. 1906 or 1906-12-31",
+	"flag.issue":               "issue numbers to download for periodicals: 5, 3:10 or 3:",
+	"flag.dezoomify-rs":        "download with dezoomify-rs, only for IIIF sites.",
+	"flag.cookie":              "path to cookie.txt",
+	"flag.cookie-from-browser": "read cookies from a local browser (Linux only) [firefox|chrome|chromium|edge|brave], optionally firefox:profile-dir",
+	"flag.local-storage":       "path to localStorage.txt, or a JSON export (per-site localStorage, sessionStorage, tokens and headers)",
+	"flag.auth-listen":         "listen address of the verification/login callback server, e.g. 127.0.0.1:8765; use 0.0.0.0:8765 for remote use",
+	"flag.auth-timeout":        "how long to wait for the browser to send cookies back, e.g. 30m",
+	"flag.page-names":          "file naming [seq|label]. seq=0001.jpg, label=site page/folio label, e.g. 0001_f001r.jpg",
+	"flag.split":               "split two-page spreads [rtl|ltr]. rtl=right page first (East Asian books), ltr=left page first",
+	"flag.crop":                "crop black scan borders",
+	"flag.deskew":              "deskew pages",
+	"flag.color":               "color conversion [gray|bitonal]",
+	"flag.max-width":           "maximum image width in pixels, 0=unlimited",
+	"flag.imageproc-output":    "subdirectory of the book directory for processed images, empty=overwrite originals",
+	"flag.dedup":               "duplicate page and placeholder check [off|report|requeue]. report=write dedup.json, requeue=also reject placeholders so they are downloaded again (exact duplicates only with duplicates set in config.ini)",
+	"flag.events":              "write a machine-readable event stream [json], one JSON object per line (NDJSON)",
+	"flag.events-file":         "file for the event stream, default stdout (other output then goes to stderr)",
+	"flag.metrics":             "serve Prometheus metrics at /metrics on this address, e.g. :9090",
+	"flag.log-level":           "log level [debug|info|warn|error]",
+	"flag.quiet":               "only print warnings and errors, no progress",
+	"flag.log-file":            "also write logs to this file (Cookie, Authorization and sign/token parameters are redacted)",
+	"flag.lang":                "interface language [zh-Hans|zh-Hant|en|ja], defaults to the LANG environment variable",
+	"flag.extension":           "file extension [.jpg|.tif|.png] etc.",
+	"flag.threads":             "maximum number of threads",
+	"flag.concurrent":          "maximum number of concurrent tasks",
+	"flag.speed":               "rate limit, N seconds per task; 5-60 recommended for cuhk",
+	"flag.retry":               "number of download retries",
+	"flag.timeout":             "download timeout, e.g. 300s",
+	"flag.auto-detect":         "auto-detect download URLs [0|1|2]. 0=default;\n1=generic batch image download;\n2=detect IIIF manifest.json and download images; without a manifest, analyze OpenSeadragon, Zoomify and pdf.js viewers on the page",
+	"flag.help":                "show help",
+	"flag.version":             "show version -v",
+	"flag.dezoomify-rs-args":   "dezoomify-rs arguments",
+	"flag.template":            "image URL template for unattended batch download, e.g. https://example.org/{vol:03}/{page:04}{side:r,v}.jpg",
+	"flag.template-file":       "file with one template per line, followed by options such as vol=1:3 pages=120,98,143 start=1 ext=.jpg",
+	"flag.template-vols":       "volume range for --template, e.g. 1:3",
+	"flag.template-pages":      "pages per volume for --template, comma separated; one number for all volumes; auto=probe before downloading",
+	"flag.template-start":      "first page number for --template, default 1",
+
+	//subcommand flags
+	"flag.dedup.requeue":         "delete placeholders (and exact duplicates when duplicates is set in config.ini) and download them again",
+	"flag.harvest.full":          "ignore the saved checkpoint and process the whole activity stream again",
+	"flag.iiif-export.base-url":  "URL of the book directory on the static file server",
+	"flag.iiif-export.direction": "reading direction auto|rtl|ltr; auto is right-to-left for CJK books",
+	"flag.iiif-export.label":     "book title, defaults to the directory name",
+	"flag.iiif-export.tile-size": "tile size",
+	"flag.search.page":           "result page, starting at 1",
+	"flag.search.site":           "site to search: %s",
+	"flag.search.urls":           "print only book URLs, one per line",
+	"flag.verify.repair":         "download pages that are missing or fail verification again",
+	"flag.view.listen":           "listen address of the local reading server",
+
+	//cmd
+	"cmd.init_failed":         "configuration failed",
+	"cmd.metrics_failed":      "metrics listen failed: %v",
+	"cmd.log_failed":          "logging setup failed: %v",
+	"cmd.events_format":       "unsupported event stream format: %s",
+	"cmd.events_open":         "failed to open event stream file: %v",
+	"cmd.read_failed":         "failed to read %s: %v",
+	"cmd.browser_cookie_read": "failed to read browser cookies: %v",
+	"cmd.browser_cookie_save": "failed to save browser cookies: %v",
+	"cmd.browser_cookie_done": "read %[2]d cookies from %[1]s",
+	"cookies.no_sqlite3":      "reading browser cookies needs the sqlite3 command-line tool, please install it (e.g. apt install sqlite3)",
+	"cookies.no_secret_tool":  "%d cookies could not be decrypted: reading the system keyring needs secret-tool, please install it (e.g. apt install libsecret-tools)",
+	"cmd.download_complete":   "Download complete.",
+	"cmd.urls_read":           "cannot read URL file: %w",
+	"cmd.urls_empty":          "no valid URLs in URL file",
+	"cmd.url_parse":           "invalid URL: %s, error: %v",
+	"cmd.url_parse_err":       "invalid URL: %w",
+	"cmd.enter_url":           "Enter an URL:",
+	"cmd.input_failed":        "failed to read input: %w",
+	"cmd.invalid_url":         "invalid URL: %s",
+	"cmd.cookie_cleanup":      "failed to remove cookie file: %v",
+	"cmd.version_check":       "version check failed: %v",
+	"cmd.new_version":         "New version available: %s (current: %s)",
+	"cmd.upgrade":             "Please upgrade at %s.",
+	"cmd.latest":              "Already up to date: %s",
+	"cmd.usage":               "Usage: bookget %s",
+	"cmd.verify_failed":       "%d book(s) failed verification",
+	"cmd.harvest_failed":      "%d manifest(s) failed, they will be retried on the next run",
+	"cmd.search_unsupported":  "search is not supported for %s",
+	"cmd.search_none":         "no results",
+
+	//batch image download
+	"img.mode":             "=== Mode: batch image download ===",
+	"img.exit_hint":        "Type 'exit' to quit",
+	"img.template":         "Image URL template ({page:04}, {vol:03}, {side:a,b}, or [PAGE], [VOL], [AB]): ",
+	"img.page_format":      "Digits of [PAGE] (4 for 0001, 3 for 001): ",
+	"img.need_page_format": "Input error: the page number width is required",
+	"img.ext":              "Cannot tell the file extension from the URL, enter it (e.g. .jpg, .png): ",
+	"img.need_ext":         "Input error: the file extension is required",
+	"img.input_error":      "Input error: %v",
+	"img.total_pages":      "Number of pages (auto=probe): ",
+	"img.need_total":       "Input error: the number of pages is required",
+	"img.vol_pages":        "Pages per volume, comma separated, e.g. 120,98,143 (one number for all volumes, auto=probe): ",
+	"img.probe_vol":        "volume %d: found %d pages",
+	"img.probe":            "found %d pages",
+	"img.summary":          "About to download:\nURL template: %s\nTotal pages: %d\nExtension: %s",
+	"img.summary_vol":      "About to download:\nURL template: %s\nVolumes: %d-%d\nPages per volume: %s\nTotal pages: %d\nExtension: %s",
+	"img.confirm":          "Start downloading? (y/n): ",
+	"img.continue":         "Download complete! Download another URL template? (y/n): ",
+	"img.bye":              "Bye",
+	"img.start_vol":        "First volume: ",
+	"img.end_vol":          "Last volume: ",
+	"img.vol_order":        "the first volume cannot be after the last volume",
+	"img.progress":         "Total",
+	"img.mkdir":            "failed to create directory %s: %v",
+	"img.done":             "Download complete! %d files downloaded",
+	"img.empty":            "empty file",
+	"img.write":            "failed to write file: %v",
+
+	//libraries
+	"app.cookies_saved":       "saved %d cookies to %s",
+	"app.url_not_found":       "requested URL was not found.",
+	"app.pages_failed":        "%d pages failed to download",
+	"app.date_range_required": "%s is a whole newspaper run, set -date-from or -date-to to choose the issues",
+	"router.unsupported":      "unsupported URL: %s",
+	"sniff.found":             "found %d %s",
+	"sniff.none":              "no downloadable viewer or images found on the page: %s",
+	"expand.found":            "%s: %d book(s)",
+
+	//下载与提示
+	"app.img_urls_empty":       "image URL list is empty",
+	"app.view_shelf":           "bookshelf: %s",
+	"app.view_open":            "open %s in a browser, press Ctrl+C to quit.",
+	"app.harvard_ip_blocked":   "access from your region is restricted; try another way. The site can email the PDF, see \"Print/Save\" PDF on the page",
+	"app.preparing":            "preparing %d/%d",
+	"app.catalog_fetch":        "fetching the table of contents...",
+	"app.catalog_fetch_failed": "failed to fetch the table of contents: %w",
+	"app.catalog_parse_failed": "failed to parse the table of contents: %w",
+	"app.pagemap_fetch":        "fetching page numbers...",
+	"app.pagemap_fetch_failed": "failed to fetch page numbers: %w",
+	"app.pagemap_parse_failed": "failed to parse page numbers: %w",
+	"app.pagemap_done":         "got %d page numbers",
+	"app.catalog_save_failed":  "failed to save the file: %w",
+	"serial.bad_range":         "invalid issue range %q, want N, N:M or N:",
+	"app.catalog_saved":        "saved the table of contents to %s, %d entries",
+	"app.catalog_unknown_page": "unknown",
+	"http.status":              "server returned status code %d",
+	"auth.open":                "open the address below in a browser, complete the captcha / login as the page describes, and send the cookies back to bookget:",
+	"auth.target":              "page to verify: %s",
+	"auth.ssh":                 "when running over SSH, first run on your machine: ssh -L %s:127.0.0.1:%s <server>",
+	"auth.wait":                "waiting up to %s; press Ctrl+C or click \"Cancel\" on the page to quit.",
+	"auth.received":            "bookget received %d cookies, %d localStorage and %d sessionStorage items. You can close this page.",
+	"auth.canceled":            "Canceled.",
+	"auth.page.title":          "bookget verification",
+	"auth.page.heading":        "bookget captcha / login",
+	"auth.page.drag":           "Drag this button to the bookmarks bar: ",
+	"auth.page.bookmarklet":    "Send to bookget",
+	"auth.page.verify":         "Open the book page and complete the captcha / login.",
+	"auth.page.click":          "On that page, click \"Send to bookget\" in the bookmarks bar.",
+	"auth.page.extension":      "A browser extension can POST JSON in this form to the callback address:",
+	"auth.page.manual":         "Paste manually",
+	"auth.page.manual_hint":    "Some cookies are HttpOnly and cannot be read by the bookmarklet. Copy the Cookie request header from the Network panel of the developer tools and paste it here:",
+	"auth.page.url":            "URL: ",
+	"auth.page.submit":         "Submit",
+	"auth.page.cancel":         "Cancel",
+	"progress.job_done":        "%s  %d pages done  %s  in %s",
+	"progress.more":            "… %d more jobs",
+	"progress.pages":           "%d/%d pages",
+	"progress.files":           "%d files",
+	"progress.total":           "total",
+	"progress.eta":             "ETA %s",
+	"util.request_create":      "failed to create request: %v",
+	"util.request_failed":      "request failed: %v",
+	"version.latest_failed":    "failed to get the latest version: %w",
+	"version.cache_failed":     "failed to update the cache: %w",
+	"version.api_failed":       "GitHub API request failed: %w",
+	"version.api_status":       "GitHub API returned status code %d",
+	"version.read_failed":      "failed to read the response body: %w",
+	"version.json_failed":      "failed to parse JSON: %w",
+	"downloader.start":         "starting downloads (max concurrency %d), %d tasks",
+	"downloader.failed":        "download failed: %s (%v)",
+	"downloader.done":          "downloads finished: %d ok, %d failed, took %v",
+	"downloader.warning":       "warning: %v",
+	"downloader.mkdir":         "failed to create directory: %w",
+	"downloader.write":         "failed to write file: %w",
+	"downloader.no_size_multi": "cannot download in parallel: file size unknown",
+	"downloader.parse_size":    "failed to parse file size: %w",
+	"downloader.no_size":       "cannot determine file size: no Content-Length header and not chunked",
+}
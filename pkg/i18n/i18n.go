@@ -0,0 +1,149 @@
+// Package i18n 命令行提示、参数说明与常见错误的多语言消息目录。
+// 语言按 --lang、LC_ALL、LC_MESSAGES、LANG 的顺序选择，未设置时为简体中文。
+// 目录缺少的消息回退到简体中文，仍缺少时原样返回消息 ID。
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	ZhHans = "zh-Hans"
+	ZhHant = "zh-Hant"
+	En     = "en"
+	Ja     = "ja"
+)
+
+// catalogs 语言 → 消息 ID → 文本（可含 fmt 占位符）
+var catalogs = map[string]map[string]string{
+	ZhHans: zhHans,
+	ZhHant: zhHant,
+	En:     en,
+	Ja:     ja,
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(ZhHans)
+}
+
+// Langs 支持的语言列表
+func Langs() []string {
+	return []string{ZhHans, ZhHant, En, Ja}
+}
+
+// Lang 当前语言
+func Lang() string {
+	return current.Load().(string)
+}
+
+// SetLang 设置当前语言，lang 可以是 zh-Hant、ja、en_US.UTF-8 等形式
+func SetLang(lang string) error {
+	l, ok := Match(lang)
+	if !ok {
+		return fmt.Errorf("unsupported language: %q (%s)", lang, strings.Join(Langs(), ", "))
+	}
+	current.Store(l)
+	return nil
+}
+
+// Match 把 locale 名称对应到支持的语言：zh_TW、zh-HK、zh-Hant → zh-Hant，其它中文 → zh-Hans，
+// ja_JP → ja，其它语言一律英文。空值、C、POSIX 返回 false
+func Match(locale string) (string, bool) {
+	s := strings.ToLower(strings.TrimSpace(locale))
+	//en_US.UTF-8@euro、zh_CN.GB18030
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.ReplaceAll(s, "_", "-")
+	switch {
+	case s == "" || s == "c" || s == "posix":
+		return "", false
+	case s == "zh" || strings.HasPrefix(s, "zh-"):
+		for _, sub := range strings.Split(s, "-")[1:] {
+			switch sub {
+			case "hant", "tw", "hk", "mo":
+				return ZhHant, true
+			}
+		}
+		return ZhHans, true
+	case s == "ja" || strings.HasPrefix(s, "ja-"):
+		return Ja, true
+	}
+	return En, true
+}
+
+// Detect 从命令行参数（--lang xx / -lang=xx）与环境变量选择语言
+func Detect(args []string) string {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+		name, val, hasVal := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if name != "lang" {
+			continue
+		}
+		if !hasVal && i+1 < len(args) {
+			val = args[i+1]
+		}
+		if l, ok := Match(val); ok {
+			return l
+		}
+	}
+	for _, k := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l, ok := Match(os.Getenv(k)); ok {
+			return l
+		}
+	}
+	return ZhHans
+}
+
+// IDs 当前语言目录中的消息 ID
+func IDs() []string {
+	ids := make([]string, 0, len(catalogs[Lang()]))
+	for id := range catalogs[Lang()] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// T 当前语言的消息，有参数时按 fmt.Sprintf 格式化
+func T(id string, args ...any) string {
+	msg := lookup(Lang(), id)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Errorf 与 fmt.Errorf 相同，格式取自消息目录，支持 %w
+func Errorf(id string, args ...any) error {
+	return fmt.Errorf(lookup(Lang(), id), args...)
+}
+
+// Error 不带参数的错误
+func Error(id string) error {
+	return errors.New(lookup(Lang(), id))
+}
+
+func lookup(lang, id string) string {
+	if msg, ok := catalogs[lang][id]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[ZhHans][id]; ok {
+		return msg
+	}
+	return id
+}
@@ -0,0 +1,149 @@
+package i18n_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"bookget/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	for locale, want := range map[string]string{
+		"zh_CN.UTF-8":    i18n.ZhHans,
+		"zh-Hans":        i18n.ZhHans,
+		"zh_SG":          i18n.ZhHans,
+		"zh_TW.Big5":     i18n.ZhHant,
+		"zh-HK":          i18n.ZhHant,
+		"zh-Hant-CN":     i18n.ZhHant,
+		"ja_JP.UTF-8":    i18n.Ja,
+		"en_US.UTF-8":    i18n.En,
+		"de_DE@euro":     i18n.En,
+		"fr":             i18n.En,
+		"ZH_tw.utf8":     i18n.ZhHant,
+		"ja-JP-u-ca-jpn": i18n.Ja,
+	} {
+		got, ok := i18n.Match(locale)
+		assert.True(t, ok, locale)
+		assert.Equal(t, want, got, locale)
+	}
+	for _, locale := range []string{"", "C", "POSIX", "C.UTF-8"} {
+		_, ok := i18n.Match(locale)
+		assert.False(t, ok, locale)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "C.UTF-8")
+	assert.Equal(t, i18n.ZhHans, i18n.Detect(nil))
+
+	t.Setenv("LANG", "ja_JP.UTF-8")
+	assert.Equal(t, i18n.Ja, i18n.Detect([]string{"https://example.org/"}))
+	t.Setenv("LC_ALL", "zh_TW.UTF-8")
+	assert.Equal(t, i18n.ZhHant, i18n.Detect(nil))
+
+	assert.Equal(t, i18n.En, i18n.Detect([]string{"--lang", "en", "https://example.org/"}))
+	assert.Equal(t, i18n.Ja, i18n.Detect([]string{"-output", "x", "-lang=ja"}))
+	assert.Equal(t, i18n.ZhHant, i18n.Detect([]string{"--", "--lang=en"}))
+}
+
+func TestT(t *testing.T) {
+	defer i18n.SetLang(i18n.Lang())
+
+	require.NoError(t, i18n.SetLang("en_GB.UTF-8"))
+	assert.Equal(t, i18n.En, i18n.Lang())
+	assert.Equal(t, "unsupported URL: https://a.org/", i18n.T("router.unsupported", "https://a.org/"))
+	assert.Equal(t, "read 3 cookies from firefox", i18n.T("cmd.browser_cookie_done", "firefox", 3))
+	assert.Equal(t, "no.such.message", i18n.T("no.such.message"))
+
+	err := i18n.Errorf("cmd.urls_read", fs.ErrNotExist)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	require.NoError(t, i18n.SetLang(i18n.ZhHant))
+	assert.Equal(t, "不支援的 URL: x", i18n.T("router.unsupported", "x"))
+	assert.Error(t, i18n.SetLang("C"))
+	assert.Equal(t, i18n.ZhHant, i18n.Lang())
+}
+
+var verbRe = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+func verbs(s string) string {
+	var out []string
+	for _, m := range verbRe.FindAllStringSubmatch(s, -1) {
+		out = append(out, m[1])
+	}
+	sort.Strings(out)
+	return strings.Join(out, "")
+}
+
+// 各语言目录的消息 ID 与格式占位符必须与简体中文一致
+func TestCatalogs(t *testing.T) {
+	defer i18n.SetLang(i18n.Lang())
+
+	require.NoError(t, i18n.SetLang(i18n.ZhHans))
+	ids := i18n.IDs()
+	require.NotEmpty(t, ids)
+	base := map[string]string{}
+	for _, id := range ids {
+		base[id] = i18n.T(id)
+	}
+	for _, lang := range i18n.Langs() {
+		require.NoError(t, i18n.SetLang(lang))
+		assert.ElementsMatch(t, ids, i18n.IDs(), lang)
+		for _, id := range ids {
+			msg := i18n.T(id)
+			assert.NotEmpty(t, msg, "%s %s", lang, id)
+			assert.Equal(t, verbs(base[id]), verbs(msg), "%s %s", lang, id)
+		}
+	}
+}
+
+var idRe = regexp.MustCompile(`i18n\.(?:T|Error|Errorf)\("([^"]+)"`)
+
+// 源码中用到的消息 ID 在每个语言目录中都必须存在
+func TestSourceIDs(t *testing.T) {
+	defer i18n.SetLang(i18n.Lang())
+
+	used := map[string]string{}
+	err := filepath.WalkDir("../..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != "../.." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range idRe.FindAllStringSubmatch(string(bs), -1) {
+			used[m[1]] = path
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, used)
+
+	for _, lang := range i18n.Langs() {
+		require.NoError(t, i18n.SetLang(lang))
+		ids := map[string]bool{}
+		for _, id := range i18n.IDs() {
+			ids[id] = true
+		}
+		for id, path := range used {
+			assert.True(t, ids[id], "%s: %s missing in %s", path, id, lang)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package i18n
+
+// ja 日本語
+var ja = map[string]string{
+	//config
+	"config.bad_dir":       "プログラムのフォルダにスペースや日本語・中国語などの特殊文字を含めることはできません。推奨：D:\\bookget",
+	"config.press_enter":   "Enter キーを押すと終了します。",
+	"config.created":       "設定ファイルを作成しました: %s",
+	"config.mkdir_failed":  "ディレクトリの作成に失敗しました: %w",
+	"config.write_failed":  "設定ファイルの作成に失敗しました: %w",
+	"config.stat_failed":   "設定ファイルの確認に失敗しました: %w",
+	"config.error":         "エラー: %v",
+	"config.bad_date":      "日付 %q の形式が正しくありません。YYYY、YYYY-MM または YYYY-MM-DD で指定してください",
+	"config.text_only_off": "-text-only は -text off と同時に指定できません",
+	"help.usage":           "使い方: bookget [オプション]... [URL]...",
+
+	//グローバルオプション
+	"flag.input":               "ダウンロードする URL の一覧ファイル（例：urls.txt）",
+	"flag.output":              "保存先ディレクトリ",
+	"flag.sequence":            "ページ範囲（例：4:434）",
+	"flag.volume":              "複数冊の資料の冊範囲。10:20 で第 10〜20 冊のみダウンロード。modernhistory.org.cn の雑誌では絞り込み後の号を数える",
+	"flag.format":              "IIIF 画像リクエスト URI: full/full/0/default.jpg",
+	"flag.user-agent":          "user-agent",
+	"flag.bookmark":            "目次のみダウンロード [0|1]。gj.tianyige.com.cn のみ有効。",
+	"flag.text":                "各ページのテキスト層もダウンロードする：off|plain=プレーンテキスト|ocr=レイアウト付き OCR|both（HathiTrust）",
+	"flag.text-only":           "-text で指定したテキスト層（未指定時は plain）だけをダウンロードし、画像は取得しない",
+	"flag.quality":             "複数のファイルがある場合の優先順位：best=最高解像度、または tiff,jp2,jpeg,pdf のように試す順に指定（loc.gov）。既定は -extension に従う",
+	"flag.date-from":           "新聞・雑誌をダウンロードする開始日（例：1905、1905-03、1905-03-01）",
+	"flag.date-to":             "新聞・雑誌をダウンロードする終了日（例：1906、1906-12-31）",
+	"flag.issue":               "雑誌をダウンロードする号の範囲（例：5、3:10、3:）",
+	"flag.dezoomify-rs":        "dezoomify-rs でダウンロード（IIIF 対応サイトのみ）。",
+	"flag.cookie":              "cookie.txt のパス",
+	"flag.cookie-from-browser": "ローカルのブラウザから cookie を読み込む（Linux のみ）[firefox|chrome|chromium|edge|brave]、firefox:プロファイルディレクトリ の形式も可",
+	"flag.local-storage":       "localStorage.txt のパス、または JSON エクスポート（サイトごとの localStorage、sessionStorage、トークン、リクエストヘッダー）",
+	"flag.auth-listen":         "認証／ログインのコールバックサーバーの待ち受けアドレス（例：127.0.0.1:8765）。リモートでは 0.0.0.0:8765",
+	"flag.auth-timeout":        "ブラウザから cookie が返されるまでの待ち時間（例：30m）",
+	"flag.page-names":          "ファイル名の付け方 [seq|label]。seq=0001.jpg、label=サイトのページ／丁番号（例：0001_f001r.jpg）",
+	"flag.split":               "見開きを分割 [rtl|ltr]。rtl=右ページが先（和漢古書）、ltr=左ページが先",
+	"flag.crop":                "スキャンの黒枠を切り取る",
+	"flag.deskew":              "傾き補正",
+	"flag.color":               "色変換 [gray|bitonal]",
+	"flag.max-width":           "画像の最大幅（ピクセル）、0=制限なし",
+	"flag.imageproc-output":    "画像処理結果を保存する資料ディレクトリ内のサブディレクトリ、空=元画像を上書き",
+	"flag.dedup":               "重複ページとプレースホルダー画像の検査 [off|report|requeue]。report=dedup.json に記録、requeue=プレースホルダーを受け付けず再ダウンロード（完全な重複ページは config.ini の duplicates 設定時のみ）",
+	"flag.events":              "機械可読のイベントストリームを出力 [json]。1 行に 1 つの JSON オブジェクト（NDJSON）",
+	"flag.events-file":         "イベントストリームの出力先ファイル。既定は stdout（その他の出力は stderr へ）",
+	"flag.metrics":             "指定アドレスで Prometheus メトリクス /metrics を提供（例：:9090）",
+	"flag.log-level":           "ログレベル [debug|info|warn|error]",
+	"flag.quiet":               "警告とエラーのみ表示し、進捗を表示しない",
+	"flag.log-file":            "ログをファイルにも書き込む（Cookie、Authorization、sign/token パラメータは伏せ字）",
+	"flag.lang":                "表示言語 [zh-Hans|zh-Hant|en|ja]。既定は環境変数 LANG に従う",
+	"flag.extension":           "ファイル拡張子 [.jpg|.tif|.png] など",
+	"flag.threads":             "最大スレッド数",
+	"flag.concurrent":          "最大同時タスク数",
+	"flag.speed":               "速度制限（N 秒／タスク）。cuhk は 5〜60 を推奨",
+	"flag.retry":               "ダウンロードの再試行回数",
+	"flag.timeout":             "ダウンロードのタイムアウト（例：300s）",
+	"flag.auto-detect":         "ダウンロード URL の自動検出 [0|1|2]。0=既定;\n1=汎用の画像一括ダウンロード;\n2=IIIF manifest.json を検出して画像をダウンロード。manifest がなければ OpenSeadragon、Zoomify、pdf.js のビューアーを解析",
+	"flag.help":                "ヘルプを表示",
+	"flag.version":             "バージョンを表示 -v",
+	"flag.dezoomify-rs-args":   "dezoomify-rs の引数",
+	"flag.template":            "無人の一括ダウンロード用の画像 URL テンプレート（例：https://example.org/{vol:03}/{page:04}{side:r,v}.jpg）",
+	"flag.template-file":       "1 行に 1 つのテンプレートと vol=1:3 pages=120,98,143 start=1 ext=.jpg などのオプションを書いたファイル",
+	"flag.template-vols":       "--template の冊範囲（例：1:3）",
+	"flag.template-pages":      "--template の各冊のページ数（カンマ区切り、1 つだけなら全冊同じ、auto=ダウンロード前に自動検出）",
+	"flag.template-start":      "--template の最初のページ番号（既定 1）",
+
+	//サブコマンドのオプション
+	"flag.dedup.requeue":         "プレースホルダー（config.ini で duplicates 設定時は完全な重複ページも）を削除して再ダウンロード",
+	"flag.harvest.full":          "保存済みの進捗を無視してアクティビティストリーム全体を処理し直す",
+	"flag.iiif-export.base-url":  "静的ファイルサーバー上の資料ディレクトリの URL",
+	"flag.iiif-export.direction": "読み方向 auto|rtl|ltr。auto では和漢古書は右から左",
+	"flag.iiif-export.label":     "書名。既定はディレクトリ名",
+	"flag.iiif-export.tile-size": "タイルサイズ",
+	"flag.search.page":           "結果のページ番号（1 から）",
+	"flag.search.site":           "検索するサイト：%s",
+	"flag.search.urls":           "資料の URL だけを 1 行に 1 つ出力する",
+	"flag.verify.repair":         "検証に失敗したページや欠けているページを再ダウンロード",
+	"flag.view.listen":           "ローカル閲覧サーバーの待ち受けアドレス",
+
+	//cmd
+	"cmd.init_failed":         "設定の初期化に失敗しました",
+	"cmd.metrics_failed":      "metrics の待ち受けに失敗しました: %v",
+	"cmd.log_failed":          "ログの設定に失敗しました: %v",
+	"cmd.events_format":       "未対応のイベントストリーム形式: %s",
+	"cmd.events_open":         "イベントストリームのファイルを開けません: %v",
+	"cmd.read_failed":         "%s の読み込みに失敗しました: %v",
+	"cmd.browser_cookie_read": "ブラウザの cookie を読み込めません: %v",
+	"cmd.browser_cookie_save": "ブラウザの cookie を保存できません: %v",
+	"cmd.browser_cookie_done": "%s から %d 個の cookie を読み込みました",
+	"cookies.no_sqlite3":      "ブラウザの cookie を読むには sqlite3 コマンドが必要です。インストールしてください（例：apt install sqlite3）",
+	"cookies.no_secret_tool":  "%d 個の cookie を復号できません。システムのキーリングを読むには secret-tool が必要です。インストールしてください（例：apt install libsecret-tools）",
+	"cmd.download_complete":   "ダウンロードが完了しました。",
+	"cmd.urls_read":           "URL ファイルを読み込めません: %w",
+	"cmd.urls_empty":          "URL ファイルに有効な URL がありません",
+	"cmd.url_parse":           "URL の解析に失敗しました: %s, エラー: %v",
+	"cmd.url_parse_err":       "URL の解析に失敗しました: %w",
+	"cmd.enter_url":           "URL を入力してください:",
+	"cmd.input_failed":        "入力の読み込みに失敗しました: %w",
+	"cmd.invalid_url":         "無効な URL: %s",
+	"cmd.cookie_cleanup":      "cookie ファイルを削除できません: %v",
+	"cmd.version_check":       "バージョンの確認に失敗しました: %v",
+	"cmd.new_version":         "新しいバージョンがあります: %s（現在: %s）",
+	"cmd.upgrade":             "%s からアップグレードしてください。",
+	"cmd.latest":              "最新バージョンです: %s",
+	"cmd.usage":               "使い方: bookget %s",
+	"cmd.verify_failed":       "%d 冊の資料が検証に失敗しました",
+	"cmd.harvest_failed":      "%d 件の manifest のダウンロードに失敗しました。次回の実行で再試行します",
+	"cmd.search_unsupported":  "%s は検索に対応していません",
+	"cmd.search_none":         "結果が見つかりません",
+
+	//画像一括ダウンロード
+	"img.mode":             "=== モード：画像一括ダウンロード ===",
+	"img.exit_hint":        "'exit' と入力すると終了します",
+	"img.template":         "画像 URL テンプレート（{page:04}、{vol:03}、{side:a,b}、または [PAGE]、[VOL]、[AB]）: ",
+	"img.page_format":      "[PAGE] の桁数（4 で 0001、3 で 001）: ",
+	"img.need_page_format": "入力エラー: ページ番号の桁数を指定してください",
+	"img.ext":              "URL から拡張子を判別できません。入力してください（例：.jpg、.png）: ",
+	"img.need_ext":         "入力エラー: 拡張子を指定してください",
+	"img.input_error":      "入力エラー: %v",
+	"img.total_pages":      "ページ数（auto=自動検出）: ",
+	"img.need_total":       "入力エラー: ページ数を指定してください",
+	"img.vol_pages":        "各冊のページ数をカンマ区切りで（例：120,98,143。1 つだけなら全冊同じ、auto=自動検出）: ",
+	"img.probe_vol":        "第 %d 冊：%d ページ見つかりました",
+	"img.probe":            "%d ページ見つかりました",
+	"img.summary":          "ダウンロードを開始します:\nURL テンプレート: %s\n総ページ数: %d\n拡張子: %s",
+	"img.summary_vol":      "ダウンロードを開始します:\nURL テンプレート: %s\n冊範囲: %d-%d\n各冊のページ数: %s\n総ページ数: %d\n拡張子: %s",
+	"img.confirm":          "ダウンロードを開始しますか？(y/n): ",
+	"img.continue":         "ダウンロード完了！別の URL テンプレートを続けますか？(y/n): ",
+	"img.bye":              "終了します",
+	"img.start_vol":        "開始冊番号: ",
+	"img.end_vol":          "終了冊番号: ",
+	"img.vol_order":        "開始冊番号は終了冊番号以下にしてください",
+	"img.progress":         "全体の進捗",
+	"img.mkdir":            "ディレクトリ %s を作成できません: %v",
+	"img.done":             "ダウンロード完了！%d 個のファイルをダウンロードしました",
+	"img.empty":            "0 バイトのファイルです",
+	"img.write":            "ファイルの書き込みに失敗しました: %v",
+
+	//図書館
+	"app.cookies_saved":       "%d 個の cookie を %s に保存しました",
+	"app.url_not_found":       "指定された URL が見つかりません。",
+	"app.pages_failed":        "%d ページのダウンロードに失敗しました",
+	"app.date_range_required": "%s は新聞全体です。-date-from または -date-to で日付範囲を指定してください",
+	"router.unsupported":      "未対応の URL: %s",
+	"sniff.found":             "%d 件の %s が見つかりました",
+	"sniff.none":              "ページにダウンロードできるビューアーや画像が見つかりません: %s",
+	"expand.found":            "%s：%d 件の資料",
+
+	//下载与提示
+	"app.img_urls_empty":       "画像URLが空です",
+	"app.view_shelf":           "書架: %s",
+	"app.view_open":            "ブラウザで %s を開いてください。Ctrl+C で終了します。",
+	"app.harvard_ip_blocked":   "現在の地域の IP からはアクセスが制限されています。別の方法をお試しください。PDF はメールで受け取れます（ページの「Print/Save」PDF を参照）",
+	"app.preparing":            "準備中 %d/%d",
+	"app.catalog_fetch":        "目次データを取得しています...",
+	"app.catalog_fetch_failed": "目次データの取得に失敗しました: %w",
+	"app.catalog_parse_failed": "目次データの解析に失敗しました: %w",
+	"app.pagemap_fetch":        "ページ番号データを取得しています...",
+	"app.pagemap_fetch_failed": "ページ番号データの取得に失敗しました: %w",
+	"app.pagemap_parse_failed": "ページ番号データの解析に失敗しました: %w",
+	"app.pagemap_done":         "%d 件のページ番号を取得しました",
+	"app.catalog_save_failed":  "ファイルの保存に失敗しました: %w",
+	"serial.bad_range":         "号の範囲 %q が不正です。N、N:M、N: の形式で指定してください",
+	"app.catalog_saved":        "目次を %s に保存しました（%d 項目）",
+	"app.catalog_unknown_page": "不明",
+	"http.status":              "サーバーがエラーステータスコードを返しました: %d",
+	"auth.open":                "ブラウザで次のアドレスを開き、ページの案内に従って「画像認証 / ログイン」を済ませ、cookie を bookget に送ってください：",
+	"auth.target":              "認証が必要なページ：%s",
+	"auth.ssh":                 "SSH 経由で実行している場合は、先に手元で実行してください: ssh -L %s:127.0.0.1:%s <サーバー>",
+	"auth.wait":                "最大 %s 待機します。Ctrl+C またはページの「キャンセル」で終了します。",
+	"auth.received":            "bookget は cookie %d 件、localStorage %d 件、sessionStorage %d 件を受け取りました。このページは閉じてかまいません。",
+	"auth.canceled":            "キャンセルしました。",
+	"auth.page.title":          "bookget 認証",
+	"auth.page.heading":        "bookget 画像認証 / ログイン",
+	"auth.page.drag":           "このボタンをブラウザのブックマークバーにドラッグしてください：",
+	"auth.page.bookmarklet":    "bookget に送信",
+	"auth.page.verify":         "資料のページを開き、「画像認証 / ログイン」を済ませてください。",
+	"auth.page.click":          "そのページでブックマークバーの「bookget に送信」をクリックしてください。",
+	"auth.page.extension":      "ブラウザ拡張機能からは次の形式の JSON をコールバックアドレスに POST できます：",
+	"auth.page.manual":         "手動で貼り付け",
+	"auth.page.manual_hint":    "HttpOnly の cookie はブックマークレットから読めません。開発者ツールの「ネットワーク」パネルでリクエストヘッダーの Cookie をコピーしてここに貼り付けてください：",
+	"auth.page.url":            "URL：",
+	"auth.page.submit":         "送信",
+	"auth.page.cancel":         "キャンセル",
+	"progress.job_done":        "%s  %d ページ完了  %s  所要 %s",
+	"progress.more":            "… 他に %d 件のタスク",
+	"progress.pages":           "%d/%d ページ",
+	"progress.files":           "%d ファイル",
+	"progress.total":           "合計",
+	"progress.eta":             "残り %s",
+	"util.request_create":      "リクエストの作成に失敗しました: %v",
+	"util.request_failed":      "リクエストに失敗しました: %v",
+	"version.latest_failed":    "最新バージョンの取得に失敗しました: %w",
+	"version.cache_failed":     "キャッシュの更新に失敗しました: %w",
+	"version.api_failed":       "GitHub API へのリクエストに失敗しました: %w",
+	"version.api_status":       "GitHub API が 200 以外のステータスコードを返しました: %d",
+	"version.read_failed":      "レスポンスの読み込みに失敗しました: %w",
+	"version.json_failed":      "JSON の解析に失敗しました: %w",
+	"downloader.start":         "ダウンロードを開始します（最大並列数: %d）、タスク数: %d",
+	"downloader.failed":        "ダウンロードに失敗しました: %s (%v)",
+	"downloader.done":          "ダウンロード完了: 成功 %d、失敗 %d、所要 %v",
+	"downloader.warning":       "警告: %v",
+	"downloader.mkdir":         "ディレクトリの作成に失敗しました: %w",
+	"downloader.write":         "ファイルの書き込みに失敗しました: %w",
+	"downloader.no_size_multi": "並列ダウンロードできません: ファイルサイズが不明です",
+	"downloader.parse_size":    "ファイルサイズの解析に失敗しました: %w",
+	"downloader.no_size":       "ファイルサイズを特定できません: Content-Length ヘッダーがなく、チャンク転送でもありません",
+}
@@ -0,0 +1,209 @@
+package i18n
+
+// zhHans 简体中文，也是其它目录缺少消息时的回退
+var zhHans = map[string]string{
+	//config
+	"config.bad_dir":       "本软件存放目录，不能包含空格、中文等特殊符号。推荐：D:\\bookget",
+	"config.press_enter":   "按回车键终止程序。Press Enter to exit ...",
+	"config.created":       "配置文件已创建: %s",
+	"config.mkdir_failed":  "创建目录失败: %w",
+	"config.write_failed":  "创建配置文件失败: %w",
+	"config.stat_failed":   "检查配置文件失败: %w",
+	"config.error":         "错误: %v",
+	"config.bad_date":      "日期 %q 格式不正确，应为 YYYY、YYYY-MM 或 YYYY-MM-DD",
+	"config.text_only_off": "-text-only 不能与 -text off 同时使用",
+	"help.usage":           "用法: bookget [选项]... [URL]...",
+
+	//全局参数
+	"flag.input":               "下载的URLs，指定任意本地文件，例如：urls.txt",
+	"flag.output":              "下载保存到目录",
+	"flag.sequence":            "页面范围，如4:434",
+	"flag.volume":              "多册图书，如10:20册，只下载10至20册；抗日战争与中日关系文献数据平台的期刊按筛选后的各期计数",
+	"flag.format":              "IIIF 图像请求URI: full/full/0/default.jpg",
+	"flag.user-agent":          "user-agent",
+	"flag.bookmark":            "只下载书签目录，可选值[0|1]。0=否，1=是。仅对 gj.tianyige.com.cn 有效。",
+	"flag.text":                "同时下载每页的全文层：off|plain=纯文本|ocr=带版面的 OCR|both（HathiTrust）",
+	"flag.text-only":           "只下载 -text 指定的全文层（未指定时为 plain），不下载图片",
+	"flag.quality":             "有多种文件可选时的偏好：best=分辨率最高，或依次尝试的类型如 tiff,jp2,jpeg,pdf（loc.gov）。默认按 -extension",
+	"flag.date-from":           "报刊下载的起始日期，如 1905、1905-03 或 1905-03-01",
+	"flag.date-to":             "报刊下载的结束日期，如 1906 或 1906-12-31",
+	"flag.issue":               "期刊下载的期号范围，如 5、3:10 或 3:",
+	"flag.dezoomify-rs":        "使用dezoomify-rs下载，仅对支持iiif的网站生效。",
+	"flag.cookie":              "指定cookie.txt文件路径",
+	"flag.cookie-from-browser": "从本地浏览器读取cookie（仅Linux），可选值[firefox|chrome|chromium|edge|brave]，可写成 firefox:配置目录",
+	"flag.local-storage":       "指定localStorage.txt文件路径，也可以是 JSON 导出（按站点保存 localStorage、sessionStorage、token、请求头）",
+	"flag.auth-listen":         "真人验证/登录回调服务监听地址，如 127.0.0.1:8765，远程使用可设为 0.0.0.0:8765",
+	"flag.auth-timeout":        "等待浏览器回传cookie的超时，如 30m",
+	"flag.page-names":          "文件命名方式，可选值[seq|label]。seq=0001.jpg，label=按网站页码/叶码命名，如 0001_f001r.jpg",
+	"flag.split":               "拆分跨页，可选值[rtl|ltr]。rtl=右页在前（古籍），ltr=左页在前",
+	"flag.crop":                "裁掉扫描黑边",
+	"flag.deskew":              "纠偏",
+	"flag.color":               "颜色转换，可选值[gray|bitonal]",
+	"flag.max-width":           "图片最大宽度（像素），0=不限制",
+	"flag.imageproc-output":    "图像处理结果保存到图书目录下的子目录，空值=覆盖原图",
+	"flag.dedup":               "重复页与占位图检查，可选值[off|report|requeue]。report=写入 dedup.json，requeue=同时拒收占位图以便重新下载（完全重复的页面需在 config.ini 设置 duplicates）",
+	"flag.events":              "输出机器可读的事件流，可选值[json]。每行一个 JSON 对象（NDJSON）",
+	"flag.events-file":         "事件流写入的文件，默认 stdout（此时其它输出改到 stderr）",
+	"flag.metrics":             "在指定地址提供 Prometheus 指标 /metrics，如 :9090",
+	"flag.log-level":           "日志级别，可选值[debug|info|warn|error]",
+	"flag.quiet":               "只输出警告与错误，不显示进度",
+	"flag.log-file":            "同时把日志写入文件（Cookie、Authorization、sign/token 参数已隐去）",
+	"flag.lang":                "界面语言，可选值[zh-Hans|zh-Hant|en|ja]，默认按环境变量 LANG 选择",
+	"flag.extension":           "指定文件扩展名[.jpg|.tif|.png]等",
+	"flag.threads":             "最大线程数",
+	"flag.concurrent":          "最大并发任务数",
+	"flag.speed":               "下载限速 N 秒/任务，cuhk推荐5-60",
+	"flag.retry":               "下载重试次数",
+	"flag.timeout":             "下载超时，如 300s",
+	"flag.auto-detect":         "自动检测下载URL。可选值[0|1|2]，;0=默认;\n1=通用批量下载（类似IDM、迅雷）;\n2= IIIF manifest.json 自动检测下载图片，网页中没有 manifest 时分析 OpenSeadragon、Zoomify、pdf.js 查看器",
+	"flag.help":                "显示帮助",
+	"flag.version":             "显示版本 -v",
+	"flag.dezoomify-rs-args":   "dezoomify-rs 参数",
+	"flag.template":            "图片URL模板，无人值守批量下载，如 https://example.org/{vol:03}/{page:04}{side:r,v}.jpg",
+	"flag.template-file":       "模板文件，每行一个模板，后跟 vol=1:3 pages=120,98,143 start=1 ext=.jpg 等选项",
+	"flag.template-vols":       "--template 的册范围，如 1:3",
+	"flag.template-pages":      "--template 的每册页数，逗号分隔；只写一个数表示每册相同；auto=下载前自动探测",
+	"flag.template-start":      "--template 的首页页码，默认 1",
+
+	//子命令参数
+	"flag.dedup.requeue":         "删除占位图（config.ini 设置 duplicates 时包括完全重复的页面）并重新下载",
+	"flag.harvest.full":          "忽略抓取进度，重新处理整个活动流",
+	"flag.iiif-export.base-url":  "图书目录在静态文件服务器上的 URL",
+	"flag.iiif-export.direction": "阅读方向 auto|rtl|ltr，auto 时中日韩古籍为从右往左",
+	"flag.iiif-export.label":     "书名，默认为目录名",
+	"flag.iiif-export.tile-size": "切片大小",
+	"flag.search.page":           "结果页码，从 1 开始",
+	"flag.search.site":           "检索的站点：%s",
+	"flag.search.urls":           "只输出图书网址，每行一个",
+	"flag.verify.repair":         "重新下载校验失败或缺失的页面",
+	"flag.view.listen":           "本地阅读服务监听地址",
+
+	//cmd
+	"cmd.init_failed":         "配置初始化失败",
+	"cmd.metrics_failed":      "metrics 监听失败: %v",
+	"cmd.log_failed":          "日志设置失败: %v",
+	"cmd.events_format":       "不支持的事件流格式: %s",
+	"cmd.events_open":         "打开事件流文件失败: %v",
+	"cmd.read_failed":         "读取 %s 失败: %v",
+	"cmd.browser_cookie_read": "读取浏览器cookie失败: %v",
+	"cmd.browser_cookie_save": "保存浏览器cookie失败: %v",
+	"cmd.browser_cookie_done": "已从 %s 读取 %d 个cookie",
+	"cookies.no_sqlite3":      "读取浏览器cookie需要 sqlite3 命令行工具，请先安装（如 apt install sqlite3）",
+	"cookies.no_secret_tool":  "有 %d 个cookie无法解密：读取系统密钥环需要 secret-tool，请先安装（如 apt install libsecret-tools）",
+	"cmd.download_complete":   "下载完成。",
+	"cmd.urls_read":           "无法读取URL文件: %w",
+	"cmd.urls_empty":          "URL文件中没有有效的URL",
+	"cmd.url_parse":           "URL解析失败: %s, 错误: %v",
+	"cmd.url_parse_err":       "URL解析失败: %w",
+	"cmd.enter_url":           "请输入URL:",
+	"cmd.input_failed":        "读取输入失败: %w",
+	"cmd.invalid_url":         "无效的URL: %s",
+	"cmd.cookie_cleanup":      "清理cookie文件失败: %v",
+	"cmd.version_check":       "版本检查失败: %v",
+	"cmd.new_version":         "新版本可用: %s (当前版本: %s)",
+	"cmd.upgrade":             "请访问 %s 升级。",
+	"cmd.latest":              "当前已是最新版本: %s",
+	"cmd.usage":               "用法: bookget %s",
+	"cmd.verify_failed":       "%d 本书校验未通过",
+	"cmd.harvest_failed":      "%d 个 manifest 下载失败，下次运行时重试",
+	"cmd.search_unsupported":  "%s 不支持检索",
+	"cmd.search_none":         "没有找到结果",
+
+	//图片批量下载
+	"img.mode":             "=== 当前模式：图片批量下载 ===",
+	"img.exit_hint":        "输入 'exit' 退出程序",
+	"img.template":         "请输入图片URL模板（{page:04}、{vol:03}、{side:a,b}，也可用[PAGE]、[VOL]、[AB]）: ",
+	"img.page_format":      "请输入[PAGE]的页码位数（如4表示0001，3表示001）: ",
+	"img.need_page_format": "输入错误: 必须指定页码格式化位数",
+	"img.ext":              "无法从URL中识别扩展名，请手动输入（如.jpg、.png）: ",
+	"img.need_ext":         "输入错误: 必须指定文件扩展名",
+	"img.input_error":      "输入错误: %v",
+	"img.total_pages":      "请输入总页数（auto=自动探测）: ",
+	"img.need_total":       "输入错误: 必须指定页数",
+	"img.vol_pages":        "请输入每册页数，逗号分隔，如 120,98,143（只填一个数表示每册相同，auto=自动探测）: ",
+	"img.probe_vol":        "第 %d 册：探测到 %d 页",
+	"img.probe":            "探测到 %d 页",
+	"img.summary":          "即将开始下载:\nURL模板: %s\n总页数: %d\n扩展名: %s",
+	"img.summary_vol":      "即将开始下载:\nURL模板: %s\n册数范围: %d-%d\n每册页数: %s\n总页数: %d\n扩展名: %s",
+	"img.confirm":          "确认开始下载？(y/n): ",
+	"img.continue":         "下载完成！是否继续下载其他URL模板？(y/n): ",
+	"img.bye":              "程序退出",
+	"img.start_vol":        "请输入起始册号: ",
+	"img.end_vol":          "请输入结束册号: ",
+	"img.vol_order":        "起始册号不能大于结束册号",
+	"img.progress":         "总下载进度",
+	"img.mkdir":            "创建目录 %s 失败: %v",
+	"img.done":             "下载完成！共成功下载 %d 个文件",
+	"img.empty":            "发现0字节文件",
+	"img.write":            "写入文件失败: %v",
+
+	//图书馆
+	"app.cookies_saved":       "已保存 %d 个cookie到 %s",
+	"app.url_not_found":       "未找到请求的URL。",
+	"app.pages_failed":        "%d 页下载失败",
+	"app.date_range_required": "%s 是整份报纸，请用 -date-from 或 -date-to 指定日期范围",
+	"router.unsupported":      "不支持的URL: %s",
+	"sniff.found":             "找到 %d 个 %s",
+	"sniff.none":              "网页中没有找到可下载的查看器或图片: %s",
+	"expand.found":            "%s：共 %d 部书",
+
+	//下载与提示
+	"app.img_urls_empty":       "图片URLs为空",
+	"app.view_shelf":           "书架: %s",
+	"app.view_open":            "请在浏览器中打开 %s ，按 Ctrl+C 退出。",
+	"app.harvard_ip_blocked":   "当前地区 IP 受限访问，请使用其它方法。该站可使用Email接收PDF。详见网页 “Print/Save” PDF",
+	"app.preparing":            "准备中 %d/%d",
+	"app.catalog_fetch":        "正在获取目录结构数据...",
+	"app.catalog_fetch_failed": "获取目录结构失败: %w",
+	"app.catalog_parse_failed": "解析目录结构失败: %w",
+	"app.pagemap_fetch":        "正在获取页码映射数据...",
+	"app.pagemap_fetch_failed": "获取页码映射失败: %w",
+	"app.pagemap_parse_failed": "解析页码映射失败: %w",
+	"app.pagemap_done":         "获取到 %d 条页码映射数据",
+	"app.catalog_save_failed":  "保存文件失败: %w",
+	"serial.bad_range":         "期号范围 %q 无效，应为 N、N:M 或 N:",
+	"app.catalog_saved":        "目录已保存到 %s，共 %d 条目录项",
+	"app.catalog_unknown_page": "未知",
+	"http.status":              "服务器返回错误状态码: %d",
+	"auth.open":                "请在浏览器中打开下面的网址，按页面提示完成「真人验证 / 登录用户」，并把 cookie 发送回 bookget：",
+	"auth.target":              "需要验证的网址：%s",
+	"auth.ssh":                 "通过 SSH 远程运行时，请先在本机执行: ssh -L %s:127.0.0.1:%s <服务器>",
+	"auth.wait":                "最长等待 %s，按 Ctrl+C 或在页面上点击「取消」退出。",
+	"auth.received":            "bookget 已收到 %d 个 cookie、%d 项 localStorage、%d 项 sessionStorage，可以关闭此页面。",
+	"auth.canceled":            "已取消。",
+	"auth.page.title":          "bookget 验证",
+	"auth.page.heading":        "bookget 真人验证 / 登录",
+	"auth.page.drag":           "把这个按钮拖到浏览器书签栏：",
+	"auth.page.bookmarklet":    "发送到 bookget",
+	"auth.page.verify":         "打开图书网页，完成「真人验证 / 登录用户」。",
+	"auth.page.click":          "在该网页上点击书签栏中的「发送到 bookget」。",
+	"auth.page.extension":      "浏览器扩展可直接把下面格式的 JSON POST 到回调地址：",
+	"auth.page.manual":         "手动粘贴",
+	"auth.page.manual_hint":    "部分 cookie 带有 HttpOnly 属性，书签小工具读取不到。可在开发者工具「网络」面板中复制请求头 Cookie 粘贴到这里：",
+	"auth.page.url":            "网址：",
+	"auth.page.submit":         "提交",
+	"auth.page.cancel":         "取消",
+	"progress.job_done":        "%s  完成 %d 页  %s  用时 %s",
+	"progress.more":            "… 还有 %d 个任务",
+	"progress.pages":           "%d/%d 页",
+	"progress.files":           "%d 个文件",
+	"progress.total":           "合计",
+	"progress.eta":             "剩余 %s",
+	"util.request_create":      "创建请求失败: %v",
+	"util.request_failed":      "请求失败: %v",
+	"version.latest_failed":    "获取最新版本失败: %w",
+	"version.cache_failed":     "更新缓存失败: %w",
+	"version.api_failed":       "GitHub API请求失败: %w",
+	"version.api_status":       "GitHub API返回非200状态码: %d",
+	"version.read_failed":      "读取响应体失败: %w",
+	"version.json_failed":      "解析JSON失败: %w",
+	"downloader.start":         "开始下载任务 (最大并发数: %d)，总任务数: %d",
+	"downloader.failed":        "下载失败: %s (%v)",
+	"downloader.done":          "下载完成! 成功: %d, 失败: %d, 耗时: %v",
+	"downloader.warning":       "警告: %v",
+	"downloader.mkdir":         "创建目录失败: %w",
+	"downloader.write":         "写入文件失败: %w",
+	"downloader.no_size_multi": "无法使用多线程下载: 文件大小未知",
+	"downloader.parse_size":    "解析文件大小失败: %w",
+	"downloader.no_size":       "无法确定文件大小: 没有Content-Length头且不是分块传输",
+}
@@ -0,0 +1,209 @@
+package i18n
+
+// zhHant 繁體中文
+var zhHant = map[string]string{
+	//config
+	"config.bad_dir":       "本軟體存放目錄，不能包含空格、中文等特殊符號。推薦：D:\\bookget",
+	"config.press_enter":   "按 Enter 鍵結束程式。",
+	"config.created":       "設定檔已建立: %s",
+	"config.mkdir_failed":  "建立目錄失敗: %w",
+	"config.write_failed":  "建立設定檔失敗: %w",
+	"config.stat_failed":   "檢查設定檔失敗: %w",
+	"config.error":         "錯誤: %v",
+	"config.bad_date":      "日期 %q 格式不正確，應為 YYYY、YYYY-MM 或 YYYY-MM-DD",
+	"config.text_only_off": "-text-only 不能與 -text off 同時使用",
+	"help.usage":           "用法: bookget [選項]... [URL]...",
+
+	//全域參數
+	"flag.input":               "下載的 URL 清單，可指定任意本機檔案，例如：urls.txt",
+	"flag.output":              "下載儲存目錄",
+	"flag.sequence":            "頁面範圍，如 4:434",
+	"flag.volume":              "多冊圖書，如 10:20，只下載第 10 至 20 冊；抗日戰爭與中日關係文獻數據平台的期刊按篩選後的各期計數",
+	"flag.format":              "IIIF 影像請求 URI: full/full/0/default.jpg",
+	"flag.user-agent":          "user-agent",
+	"flag.bookmark":            "只下載書籤目錄，可選值[0|1]。0=否，1=是。僅對 gj.tianyige.com.cn 有效。",
+	"flag.text":                "同時下載每頁的全文層：off|plain=純文字|ocr=帶版面的 OCR|both（HathiTrust）",
+	"flag.text-only":           "只下載 -text 指定的全文層（未指定時為 plain），不下載圖片",
+	"flag.quality":             "有多種檔案可選時的偏好：best=解析度最高，或依次嘗試的類型如 tiff,jp2,jpeg,pdf（loc.gov）。預設依 -extension",
+	"flag.date-from":           "報刊下載的起始日期，如 1905、1905-03 或 1905-03-01",
+	"flag.date-to":             "報刊下載的結束日期，如 1906 或 1906-12-31",
+	"flag.issue":               "期刊下載的期號範圍，如 5、3:10 或 3:",
+	"flag.dezoomify-rs":        "使用 dezoomify-rs 下載，僅對支援 IIIF 的網站有效。",
+	"flag.cookie":              "指定 cookie.txt 檔案路徑",
+	"flag.cookie-from-browser": "從本機瀏覽器讀取 cookie（僅 Linux），可選值[firefox|chrome|chromium|edge|brave]，可寫成 firefox:設定檔目錄",
+	"flag.local-storage":       "指定 localStorage.txt 檔案路徑，也可以是 JSON 匯出（按網站保存 localStorage、sessionStorage、token、請求標頭）",
+	"flag.auth-listen":         "真人驗證／登入回呼服務監聽位址，如 127.0.0.1:8765，遠端使用可設為 0.0.0.0:8765",
+	"flag.auth-timeout":        "等待瀏覽器回傳 cookie 的逾時，如 30m",
+	"flag.page-names":          "檔案命名方式，可選值[seq|label]。seq=0001.jpg，label=按網站頁碼／葉碼命名，如 0001_f001r.jpg",
+	"flag.split":               "拆分跨頁，可選值[rtl|ltr]。rtl=右頁在前（古籍），ltr=左頁在前",
+	"flag.crop":                "裁掉掃描黑邊",
+	"flag.deskew":              "糾偏",
+	"flag.color":               "色彩轉換，可選值[gray|bitonal]",
+	"flag.max-width":           "圖片最大寬度（像素），0=不限制",
+	"flag.imageproc-output":    "影像處理結果儲存到圖書目錄下的子目錄，空值=覆寫原圖",
+	"flag.dedup":               "重複頁與佔位圖檢查，可選值[off|report|requeue]。report=寫入 dedup.json，requeue=同時拒收佔位圖以便重新下載（完全重複的頁面需在 config.ini 設定 duplicates）",
+	"flag.events":              "輸出機器可讀的事件串流，可選值[json]。每行一個 JSON 物件（NDJSON）",
+	"flag.events-file":         "事件串流寫入的檔案，預設 stdout（此時其他輸出改到 stderr）",
+	"flag.metrics":             "在指定位址提供 Prometheus 指標 /metrics，如 :9090",
+	"flag.log-level":           "日誌等級，可選值[debug|info|warn|error]",
+	"flag.quiet":               "只輸出警告與錯誤，不顯示進度",
+	"flag.log-file":            "同時把日誌寫入檔案（Cookie、Authorization、sign/token 參數已隱去）",
+	"flag.lang":                "介面語言，可選值[zh-Hans|zh-Hant|en|ja]，預設依環境變數 LANG 選擇",
+	"flag.extension":           "指定副檔名[.jpg|.tif|.png]等",
+	"flag.threads":             "最大執行緒數",
+	"flag.concurrent":          "最大並行任務數",
+	"flag.speed":               "下載限速 N 秒／任務，cuhk 建議 5-60",
+	"flag.retry":               "下載重試次數",
+	"flag.timeout":             "下載逾時，如 300s",
+	"flag.auto-detect":         "自動偵測下載 URL。可選值[0|1|2]，;0=預設;\n1=通用批次下載（類似 IDM）;\n2= IIIF manifest.json 自動偵測下載圖片，網頁中沒有 manifest 時分析 OpenSeadragon、Zoomify、pdf.js 檢視器",
+	"flag.help":                "顯示說明",
+	"flag.version":             "顯示版本 -v",
+	"flag.dezoomify-rs-args":   "dezoomify-rs 參數",
+	"flag.template":            "圖片 URL 範本，無人值守批次下載，如 https://example.org/{vol:03}/{page:04}{side:r,v}.jpg",
+	"flag.template-file":       "範本檔案，每行一個範本，後接 vol=1:3 pages=120,98,143 start=1 ext=.jpg 等選項",
+	"flag.template-vols":       "--template 的冊數範圍，如 1:3",
+	"flag.template-pages":      "--template 的每冊頁數，以逗號分隔；只寫一個數表示每冊相同；auto=下載前自動偵測",
+	"flag.template-start":      "--template 的首頁頁碼，預設 1",
+
+	//子命令參數
+	"flag.dedup.requeue":         "刪除佔位圖（config.ini 設定 duplicates 時包括完全重複的頁面）並重新下載",
+	"flag.harvest.full":          "忽略抓取進度，重新處理整個活動串流",
+	"flag.iiif-export.base-url":  "圖書目錄在靜態檔案伺服器上的 URL",
+	"flag.iiif-export.direction": "閱讀方向 auto|rtl|ltr，auto 時中日韓古籍為由右至左",
+	"flag.iiif-export.label":     "書名，預設為目錄名稱",
+	"flag.iiif-export.tile-size": "切片大小",
+	"flag.search.page":           "結果頁碼，從 1 開始",
+	"flag.search.site":           "檢索的網站：%s",
+	"flag.search.urls":           "只輸出圖書網址，每行一個",
+	"flag.verify.repair":         "重新下載校驗失敗或缺少的頁面",
+	"flag.view.listen":           "本機閱讀服務監聽位址",
+
+	//cmd
+	"cmd.init_failed":         "設定初始化失敗",
+	"cmd.metrics_failed":      "metrics 監聽失敗: %v",
+	"cmd.log_failed":          "日誌設定失敗: %v",
+	"cmd.events_format":       "不支援的事件串流格式: %s",
+	"cmd.events_open":         "開啟事件串流檔案失敗: %v",
+	"cmd.read_failed":         "讀取 %s 失敗: %v",
+	"cmd.browser_cookie_read": "讀取瀏覽器 cookie 失敗: %v",
+	"cmd.browser_cookie_save": "儲存瀏覽器 cookie 失敗: %v",
+	"cmd.browser_cookie_done": "已從 %s 讀取 %d 個 cookie",
+	"cookies.no_sqlite3":      "讀取瀏覽器cookie需要 sqlite3 命令列工具，請先安裝（如 apt install sqlite3）",
+	"cookies.no_secret_tool":  "有 %d 個cookie無法解密：讀取系統金鑰圈需要 secret-tool，請先安裝（如 apt install libsecret-tools）",
+	"cmd.download_complete":   "下載完成。",
+	"cmd.urls_read":           "無法讀取 URL 檔案: %w",
+	"cmd.urls_empty":          "URL 檔案中沒有有效的 URL",
+	"cmd.url_parse":           "URL 解析失敗: %s, 錯誤: %v",
+	"cmd.url_parse_err":       "URL 解析失敗: %w",
+	"cmd.enter_url":           "請輸入 URL:",
+	"cmd.input_failed":        "讀取輸入失敗: %w",
+	"cmd.invalid_url":         "無效的 URL: %s",
+	"cmd.cookie_cleanup":      "清理 cookie 檔案失敗: %v",
+	"cmd.version_check":       "版本檢查失敗: %v",
+	"cmd.new_version":         "有新版本: %s（目前版本: %s）",
+	"cmd.upgrade":             "請前往 %s 升級。",
+	"cmd.latest":              "目前已是最新版本: %s",
+	"cmd.usage":               "用法: bookget %s",
+	"cmd.verify_failed":       "%d 本書校驗未通過",
+	"cmd.harvest_failed":      "%d 個 manifest 下載失敗，下次執行時重試",
+	"cmd.search_unsupported":  "%s 不支援檢索",
+	"cmd.search_none":         "沒有找到結果",
+
+	//圖片批次下載
+	"img.mode":             "=== 目前模式：圖片批次下載 ===",
+	"img.exit_hint":        "輸入 'exit' 結束程式",
+	"img.template":         "請輸入圖片 URL 範本（{page:04}、{vol:03}、{side:a,b}，也可用[PAGE]、[VOL]、[AB]）: ",
+	"img.page_format":      "請輸入[PAGE]的頁碼位數（如 4 表示 0001，3 表示 001）: ",
+	"img.need_page_format": "輸入錯誤: 必須指定頁碼位數",
+	"img.ext":              "無法從 URL 判斷副檔名，請手動輸入（如 .jpg、.png）: ",
+	"img.need_ext":         "輸入錯誤: 必須指定副檔名",
+	"img.input_error":      "輸入錯誤: %v",
+	"img.total_pages":      "請輸入總頁數（auto=自動偵測）: ",
+	"img.need_total":       "輸入錯誤: 必須指定頁數",
+	"img.vol_pages":        "請輸入每冊頁數，以逗號分隔，如 120,98,143（只填一個數表示每冊相同，auto=自動偵測）: ",
+	"img.probe_vol":        "第 %d 冊：偵測到 %d 頁",
+	"img.probe":            "偵測到 %d 頁",
+	"img.summary":          "即將開始下載:\nURL 範本: %s\n總頁數: %d\n副檔名: %s",
+	"img.summary_vol":      "即將開始下載:\nURL 範本: %s\n冊數範圍: %d-%d\n每冊頁數: %s\n總頁數: %d\n副檔名: %s",
+	"img.confirm":          "確認開始下載？(y/n): ",
+	"img.continue":         "下載完成！是否繼續下載其他 URL 範本？(y/n): ",
+	"img.bye":              "程式結束",
+	"img.start_vol":        "請輸入起始冊號: ",
+	"img.end_vol":          "請輸入結束冊號: ",
+	"img.vol_order":        "起始冊號不能大於結束冊號",
+	"img.progress":         "總下載進度",
+	"img.mkdir":            "建立目錄 %s 失敗: %v",
+	"img.done":             "下載完成！共成功下載 %d 個檔案",
+	"img.empty":            "發現 0 位元組檔案",
+	"img.write":            "寫入檔案失敗: %v",
+
+	//圖書館
+	"app.cookies_saved":       "已儲存 %d 個 cookie 到 %s",
+	"app.url_not_found":       "找不到請求的 URL。",
+	"app.pages_failed":        "%d 頁下載失敗",
+	"app.date_range_required": "%s 是整份報紙，請用 -date-from 或 -date-to 指定日期範圍",
+	"router.unsupported":      "不支援的 URL: %s",
+	"sniff.found":             "找到 %d 個 %s",
+	"sniff.none":              "網頁中沒有找到可下載的檢視器或圖片: %s",
+	"expand.found":            "%s：共 %d 部書",
+
+	//下载与提示
+	"app.img_urls_empty":       "圖片URLs為空",
+	"app.view_shelf":           "書架: %s",
+	"app.view_open":            "請在瀏覽器中開啟 %s ，按 Ctrl+C 結束。",
+	"app.harvard_ip_blocked":   "目前地區 IP 受限存取，請使用其他方法。該站可使用Email接收PDF。詳見網頁「Print/Save」PDF",
+	"app.preparing":            "準備中 %d/%d",
+	"app.catalog_fetch":        "正在取得目錄結構資料...",
+	"app.catalog_fetch_failed": "取得目錄結構失敗: %w",
+	"app.catalog_parse_failed": "解析目錄結構失敗: %w",
+	"app.pagemap_fetch":        "正在取得頁碼對應資料...",
+	"app.pagemap_fetch_failed": "取得頁碼對應失敗: %w",
+	"app.pagemap_parse_failed": "解析頁碼對應失敗: %w",
+	"app.pagemap_done":         "取得 %d 筆頁碼對應資料",
+	"app.catalog_save_failed":  "儲存檔案失敗: %w",
+	"serial.bad_range":         "期號範圍 %q 無效，應為 N、N:M 或 N:",
+	"app.catalog_saved":        "目錄已儲存到 %s，共 %d 筆目錄項",
+	"app.catalog_unknown_page": "未知",
+	"http.status":              "伺服器回傳錯誤狀態碼: %d",
+	"auth.open":                "請在瀏覽器中開啟下面的網址，依頁面提示完成「真人驗證 / 登入使用者」，並把 cookie 傳回 bookget：",
+	"auth.target":              "需要驗證的網址：%s",
+	"auth.ssh":                 "透過 SSH 遠端執行時，請先在本機執行: ssh -L %s:127.0.0.1:%s <伺服器>",
+	"auth.wait":                "最長等待 %s，按 Ctrl+C 或在頁面上點擊「取消」結束。",
+	"auth.received":            "bookget 已收到 %d 個 cookie、%d 項 localStorage、%d 項 sessionStorage，可以關閉此頁面。",
+	"auth.canceled":            "已取消。",
+	"auth.page.title":          "bookget 驗證",
+	"auth.page.heading":        "bookget 真人驗證 / 登入",
+	"auth.page.drag":           "把這個按鈕拖到瀏覽器書籤列：",
+	"auth.page.bookmarklet":    "傳送到 bookget",
+	"auth.page.verify":         "開啟圖書網頁，完成「真人驗證 / 登入使用者」。",
+	"auth.page.click":          "在該網頁上點擊書籤列中的「傳送到 bookget」。",
+	"auth.page.extension":      "瀏覽器擴充功能可直接把下面格式的 JSON POST 到回呼位址：",
+	"auth.page.manual":         "手動貼上",
+	"auth.page.manual_hint":    "部分 cookie 帶有 HttpOnly 屬性，書籤小工具讀取不到。可在開發者工具「網路」面板中複製請求標頭 Cookie 貼到這裡：",
+	"auth.page.url":            "網址：",
+	"auth.page.submit":         "送出",
+	"auth.page.cancel":         "取消",
+	"progress.job_done":        "%s  完成 %d 頁  %s  用時 %s",
+	"progress.more":            "… 還有 %d 個任務",
+	"progress.pages":           "%d/%d 頁",
+	"progress.files":           "%d 個檔案",
+	"progress.total":           "合計",
+	"progress.eta":             "剩餘 %s",
+	"util.request_create":      "建立請求失敗: %v",
+	"util.request_failed":      "請求失敗: %v",
+	"version.latest_failed":    "取得最新版本失敗: %w",
+	"version.cache_failed":     "更新快取失敗: %w",
+	"version.api_failed":       "GitHub API請求失敗: %w",
+	"version.api_status":       "GitHub API回傳非200狀態碼: %d",
+	"version.read_failed":      "讀取回應內容失敗: %w",
+	"version.json_failed":      "解析JSON失敗: %w",
+	"downloader.start":         "開始下載任務 (最大並行數: %d)，總任務數: %d",
+	"downloader.failed":        "下載失敗: %s (%v)",
+	"downloader.done":          "下載完成! 成功: %d, 失敗: %d, 耗時: %v",
+	"downloader.warning":       "警告: %v",
+	"downloader.mkdir":         "建立目錄失敗: %w",
+	"downloader.write":         "寫入檔案失敗: %w",
+	"downloader.no_size_multi": "無法使用多執行緒下載: 檔案大小未知",
+	"downloader.parse_size":    "解析檔案大小失敗: %w",
+	"downloader.no_size":       "無法確定檔案大小: 沒有Content-Length標頭且不是分塊傳輸",
+}
@@ -0,0 +1,272 @@
+// Package iiifexport 把已下载的图书目录导出为 IIIF Presentation 3.0 manifest 和 Image API level 0 静态切片，
+// 用任意静态文件服务器即可在 Mirador / Universal Viewer 中阅读本地副本。
+package iiifexport
+
+import (
+	"bookget/model/iiif"
+	"bookget/pkg/catalog"
+	"bookget/pkg/verify"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ManifestName 导出的 manifest 文件名，位于图书目录下
+const ManifestName = "manifest.json"
+
+// TileDir 切片目录，位于图书目录下
+const TileDir = "iiif"
+
+// 阅读方向
+const (
+	DirectionAuto = "auto"
+	DirectionRTL  = "rtl"
+	DirectionLTR  = "ltr"
+)
+
+// DefaultBaseUrl 默认在图书目录下执行 python3 -m http.server 8000
+const DefaultBaseUrl = "http://localhost:8000"
+
+type Options struct {
+	BaseUrl   string //图书目录对外的 URL
+	Direction string //auto|rtl|ltr
+	Label     string //默认为目录名
+	TileSize  int
+	Quality   int
+	OnPage    func(i, total int, file string)
+}
+
+type Result struct {
+	Manifest string
+	Pages    int
+	Ranges   int
+	Skipped  []string //不支持切片的页面（tif/jp2/pdf 等）
+}
+
+// page 图书目录下的一页
+type page struct {
+	file  string //相对图书目录，/ 分隔
+	label string
+}
+
+var ErrNoPages = errors.New("no jpg/png pages found")
+
+// Export 导出 dir 下的图书。多卷图书（vol.* 子目录）合并为一个 manifest，页序与全书页码一致
+func Export(dir string, o Options) (*Result, error) {
+	if o.BaseUrl == "" {
+		o.BaseUrl = DefaultBaseUrl
+	}
+	o.BaseUrl = strings.TrimRight(o.BaseUrl, "/")
+	if o.TileSize <= 0 {
+		o.TileSize = 512
+	}
+	if o.Quality <= 0 {
+		o.Quality = 85
+	}
+	pages, info, err := collect(dir)
+	if err != nil {
+		return nil, err
+	}
+	sourceUrl := info.Url
+	if o.Label == "" {
+		o.Label = info.Title
+	}
+	if o.Label == "" {
+		o.Label = filepath.Base(filepath.Clean(dir))
+	}
+	toc := catalog.Read(dir)
+
+	r := &Result{Manifest: filepath.Join(dir, ManifestName)}
+	m := &iiif.Manifest3{
+		Context: iiif.PresentationContext3,
+		Id:      o.BaseUrl + "/" + ManifestName,
+		Type:    "Manifest",
+		Label:   iiif.NewLanguageMap("none", o.Label),
+		Items:   make([]*iiif.Canvas3, 0, len(pages)),
+	}
+	if sourceUrl != "" {
+		m.Homepage = []iiif.Resource3{{Id: sourceUrl, Type: "Text", Format: "text/html", Label: iiif.NewLanguageMap("none", o.Label)}}
+		m.Metadata = []iiif.MetadataKV3{{Label: iiif.NewLanguageMap("en", "Source"), Value: iiif.NewLanguageMap("none", sourceUrl)}}
+	}
+	m.ViewingDirection = "left-to-right"
+	if Direction(o.Direction, sourceUrl, o.Label, toc) == DirectionRTL {
+		m.ViewingDirection = "right-to-left"
+	}
+	m.Behavior = []string{"paged"}
+
+	bySeq := make(map[int]string, len(pages)) //全书顺序号 → canvas，跳过的页面没有
+	for i, p := range pages {
+		if o.OnPage != nil {
+			o.OnPage(i, len(pages), p.file)
+		}
+		id := fmt.Sprintf("p%04d", i+1)
+		info, err := writeTiles(filepath.Join(dir, filepath.FromSlash(p.file)), filepath.Join(dir, TileDir, id),
+			o.BaseUrl+"/"+TileDir+"/"+id, o.TileSize, o.Quality)
+		if errors.Is(err, errUnsupported) {
+			r.Skipped = append(r.Skipped, p.file)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.file, err)
+		}
+		c := canvas(o.BaseUrl, id, p, info)
+		m.Items = append(m.Items, c)
+		bySeq[i+1] = c.Id
+	}
+	if len(m.Items) == 0 {
+		return nil, ErrNoPages
+	}
+	m.Structures = ranges(o.BaseUrl, toc, m.Items, bySeq)
+	r.Pages, r.Ranges = len(m.Items), len(m.Structures)
+
+	bs, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return r, os.WriteFile(r.Manifest, bs, 0644)
+}
+
+func canvas(base, id string, p page, info *iiif.ImageInfo3) *iiif.Canvas3 {
+	canvasId := base + "/canvas/" + id
+	label := p.label
+	if label == "" {
+		label = strings.TrimPrefix(id, "p")
+	}
+	c := &iiif.Canvas3{
+		Id:     canvasId,
+		Type:   "Canvas",
+		Label:  iiif.NewLanguageMap("none", label),
+		Width:  info.Width,
+		Height: info.Height,
+		Items: []*iiif.AnnotationPage{{
+			Id:   canvasId + "/page",
+			Type: "AnnotationPage",
+			Items: []*iiif.Annotation{{
+				Id:         canvasId + "/page/image",
+				Type:       "Annotation",
+				Motivation: "painting",
+				Target:     canvasId,
+				Body: iiif.Resource3{
+					Id:      base + "/" + escapePath(p.file),
+					Type:    "Image",
+					Format:  mimeType(p.file),
+					Width:   info.Width,
+					Height:  info.Height,
+					Service: []iiif.Service3{{Id: info.Id, Type: "ImageService3", Profile: "level0"}},
+				},
+			}},
+		}},
+	}
+	if n := len(info.Sizes); n > 0 {
+		s := info.Sizes[n-1]
+		c.Thumbnail = []iiif.Resource3{{
+			Id:     fmt.Sprintf("%s/full/%d,%d/0/default.jpg", info.Id, s.Width, s.Height),
+			Type:   "Image",
+			Format: "image/jpeg",
+			Width:  s.Width,
+			Height: s.Height,
+		}}
+	}
+	return c
+}
+
+// collect 按页序列出图书目录下的图片。优先使用 bookget.json 中的页序与页码标签
+func collect(dir string) (pages []page, info *verify.Manifest, err error) {
+	pages, info, err = collectDir(dir, "")
+	if err != nil || len(pages) > 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), "vol.") {
+			continue
+		}
+		vol, m, err := collectDir(filepath.Join(dir, e.Name()), e.Name()+"/")
+		if err != nil {
+			return nil, nil, err
+		}
+		if info.Url == "" {
+			info.Url = m.Url
+		}
+		if info.Title == "" {
+			info.Title = m.Title
+		}
+		pages = append(pages, vol...)
+	}
+	if len(pages) == 0 {
+		return nil, nil, ErrNoPages
+	}
+	return pages, info, nil
+}
+
+func collectDir(dir, prefix string) ([]page, *verify.Manifest, error) {
+	m, err := verify.Load(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pages []page
+	for _, p := range m.Pages {
+		if _, err := os.Stat(filepath.Join(dir, p.File)); err == nil {
+			pages = append(pages, page{file: prefix + p.File, label: p.Label})
+		}
+	}
+	return pages, m, nil
+}
+
+// Direction 返回 rtl 或 ltr。d 为 auto 时按来源站点与书名/目录中的中日韩文字判断，古籍默认从右往左翻
+func Direction(d, sourceUrl, label string, toc []catalog.Entry) string {
+	if d == DirectionRTL || d == DirectionLTR {
+		return d
+	}
+	if u, err := url.Parse(sourceUrl); err == nil && u.Host != "" {
+		host := u.Hostname()
+		for _, tld := range []string{".cn", ".tw", ".hk", ".mo", ".jp", ".kr"} {
+			if strings.HasSuffix(host, tld) {
+				return DirectionRTL
+			}
+		}
+	}
+	text := label
+	for _, e := range toc {
+		text += e.Title
+	}
+	if hasCJK(text) {
+		return DirectionRTL
+	}
+	return DirectionLTR
+}
+
+func hasCJK(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
+
+func mimeType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png":
+		return "image/png"
+	default:
+		return "image/jpeg"
+	}
+}
+
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, "/")
+}
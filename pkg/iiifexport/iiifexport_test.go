@@ -0,0 +1,142 @@
+package iiifexport_test
+
+import (
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/iiifexport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeJPEG(t *testing.T, path string, w, h int) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, jpeg.Encode(f, image.NewGray(image.Rect(0, 0, w, h)), nil))
+	require.NoError(t, f.Close())
+}
+
+func TestExportVolumesAndToc(t *testing.T) {
+	dir := t.TempDir()
+	writeJPEG(t, filepath.Join(dir, "vol.0001", "0001.jpg"), 300, 200)
+	writeJPEG(t, filepath.Join(dir, "vol.0001", "0002.jpg"), 100, 80)
+	writeJPEG(t, filepath.Join(dir, "vol.0002", "0001.jpg"), 100, 80)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bookmark.txt"),
+		[]byte("#版本=1.0\r\n卷一......1\r\n卷二......3\r\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.txt"),
+		[]byte("#版本=1.0\n序 ………… 1\n正文 ………… 未知\n\t第一回 ………… 2\n\t第二回 ………… 3\n附录 ………… 未知"), 0644))
+
+	r, err := iiifexport.Export(dir, iiifexport.Options{BaseUrl: "http://localhost:8000/book/", TileSize: 128})
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Pages)
+	assert.Equal(t, 2, r.Ranges)
+
+	var m struct {
+		Id               string `json:"id"`
+		ViewingDirection string `json:"viewingDirection"`
+		Items            []struct {
+			Id     string `json:"id"`
+			Width  int    `json:"width"`
+			Height int    `json:"height"`
+			Items  []struct {
+				Items []struct {
+					Body struct {
+						Id      string `json:"id"`
+						Service []struct {
+							Id string `json:"id"`
+						} `json:"service"`
+					} `json:"body"`
+				} `json:"items"`
+			} `json:"items"`
+		} `json:"items"`
+		Structures []struct {
+			Label map[string][]string `json:"label"`
+			Items []json.RawMessage   `json:"items"`
+		} `json:"structures"`
+	}
+	bs, err := os.ReadFile(r.Manifest)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bs, &m))
+
+	assert.Equal(t, "http://localhost:8000/book/manifest.json", m.Id)
+	assert.Equal(t, "right-to-left", m.ViewingDirection)
+	require.Len(t, m.Items, 3)
+	assert.Equal(t, 300, m.Items[0].Width)
+	body := m.Items[2].Items[0].Items[0].Body
+	assert.Equal(t, "http://localhost:8000/book/vol.0002/0001.jpg", body.Id)
+	assert.Equal(t, "http://localhost:8000/book/iiif/p0003", body.Service[0].Id)
+
+	// catalog.txt 优先；“正文”无页码但有子目录，“附录”为空被去掉
+	require.Len(t, m.Structures, 2)
+	assert.Equal(t, []string{"正文"}, m.Structures[1].Label["none"])
+	assert.Len(t, m.Structures[1].Items, 2)
+
+	tiles := filepath.Join(dir, "iiif", "p0001")
+	for _, name := range []string{
+		"info.json",
+		"0,0,128,128/128,128/0/default.jpg",
+		"256,128,44,72/44,72/0/default.jpg",
+		"0,0,256,200/128,100/0/default.jpg",
+		"256,0,44,200/22,100/0/default.jpg",
+		"full/75,50/0/default.jpg",
+	} {
+		assert.FileExists(t, filepath.Join(tiles, filepath.FromSlash(name)))
+	}
+	assert.FileExists(t, filepath.Join(dir, "iiif", "p0002", "full", "max", "0", "default.jpg"))
+}
+
+func TestExportDirection(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "book")
+	writeJPEG(t, filepath.Join(dir, "0001.jpg"), 16, 16)
+
+	r, err := iiifexport.Export(dir, iiifexport.Options{})
+	require.NoError(t, err)
+	bs, _ := os.ReadFile(r.Manifest)
+	assert.Contains(t, string(bs), `"left-to-right"`)
+
+	_, err = iiifexport.Export(dir, iiifexport.Options{Direction: iiifexport.DirectionRTL})
+	require.NoError(t, err)
+	bs, _ = os.ReadFile(r.Manifest)
+	assert.Contains(t, string(bs), `"right-to-left"`)
+
+	_, err = iiifexport.Export(t.TempDir(), iiifexport.Options{})
+	assert.ErrorIs(t, err, iiifexport.ErrNoPages)
+}
+
+func TestExportTocSkippedPage(t *testing.T) {
+	dir := t.TempDir()
+	writeJPEG(t, filepath.Join(dir, "0001.jpg"), 16, 16)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0002.tif"), []byte("II*\x00"), 0644))
+	writeJPEG(t, filepath.Join(dir, "0003.jpg"), 16, 16)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.txt"),
+		[]byte("#版本=1.0\n一 ………… 1\n二 ………… 2\n三 ………… 3\n"), 0644))
+
+	r, err := iiifexport.Export(dir, iiifexport.Options{BaseUrl: "http://localhost/b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0002.tif"}, r.Skipped)
+	assert.Equal(t, 2, r.Pages)
+
+	var m struct {
+		Items []struct {
+			Id string `json:"id"`
+		} `json:"items"`
+		Structures []struct {
+			Label map[string][]string `json:"label"`
+			Items []struct {
+				Id string `json:"id"`
+			} `json:"items"`
+		} `json:"structures"`
+	}
+	bs, err := os.ReadFile(r.Manifest)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bs, &m))
+	//“二”指向跳过的页面被去掉，“三”仍指向原第 3 页
+	require.Len(t, m.Structures, 2)
+	assert.Equal(t, []string{"三"}, m.Structures[1].Label["none"])
+	assert.Equal(t, m.Items[1].Id, m.Structures[1].Items[0].Id)
+}
@@ -0,0 +1,160 @@
+package iiifexport
+
+import (
+	"bookget/model/iiif"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errUnsupported = errors.New("unsupported image format")
+
+// writeTiles 生成 Image API 3.0 level 0 静态切片，路径与 OpenSeadragon / Mirador 请求的 URL 一致：
+//
+//	{x},{y},{w},{h}/{tw},{th}/0/default.jpg  切片
+//	full/{w},{h}/0/default.jpg             整图能放进一个切片的缩放级别
+//	full/max/0/default.jpg                 原尺寸能放进一个切片时
+//
+// 已有 info.json 且尺寸一致时不重复生成
+func writeTiles(src, outDir, id string, tileSize, quality int) (*iiif.ImageInfo3, error) {
+	switch strings.ToLower(filepath.Ext(src)) {
+	case ".jpg", ".jpeg", ".png":
+	default:
+		return nil, errUnsupported
+	}
+	fp, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	cfg, _, err := image.DecodeConfig(fp)
+	if err != nil {
+		return nil, err
+	}
+	infoFile := filepath.Join(outDir, "info.json")
+	if old, err := loadInfo(infoFile); err == nil && old.Id == id && old.Width == cfg.Width && old.Height == cfg.Height {
+		return old, nil
+	}
+
+	if _, err = fp.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	src0, _, err := image.Decode(fp)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, cfg.Width, cfg.Height))
+	draw.Draw(img, img.Bounds(), src0, src0.Bounds().Min, draw.Src)
+
+	info := &iiif.ImageInfo3{
+		Context:  iiif.ImageContext3,
+		Id:       id,
+		Type:     "ImageService3",
+		Protocol: "http://iiif.io/api/image",
+		Profile:  "level0",
+		Width:    cfg.Width,
+		Height:   cfg.Height,
+	}
+	tile := iiif.ImageTile{Width: tileSize, Height: tileSize}
+	opts := &jpeg.Options{Quality: quality}
+	w, h := cfg.Width, cfg.Height
+	for scale := 1; ; scale *= 2 {
+		tile.ScaleFactors = append(tile.ScaleFactors, scale)
+		lw, lh := img.Bounds().Dx(), img.Bounds().Dy()
+		if lw <= tileSize && lh <= tileSize {
+			sizes := []string{fmt.Sprintf("%d,%d", lw, lh)}
+			if scale == 1 {
+				sizes = append(sizes, "max")
+			}
+			for _, size := range sizes {
+				if err = saveJPEG(filepath.Join(outDir, "full", size, "0", "default.jpg"), img, opts); err != nil {
+					return nil, err
+				}
+			}
+			info.Sizes = append(info.Sizes, iiif.ImageSize{Width: lw, Height: lh})
+			break
+		}
+		step := tileSize * scale
+		for y := 0; y < h; y += step {
+			for x := 0; x < w; x += step {
+				rw, rh := min(step, w-x), min(step, h-y)
+				r := image.Rect(x/scale, y/scale, min((x+rw+scale-1)/scale, lw), min((y+rh+scale-1)/scale, lh))
+				name := filepath.Join(outDir, fmt.Sprintf("%d,%d,%d,%d", x, y, rw, rh),
+					fmt.Sprintf("%d,%d", r.Dx(), r.Dy()), "0", "default.jpg")
+				if err = saveJPEG(name, img.SubImage(r), opts); err != nil {
+					return nil, err
+				}
+			}
+		}
+		img = half(img)
+	}
+	info.Tiles = []iiif.ImageTile{tile}
+
+	bs, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return info, os.WriteFile(infoFile, bs, 0644)
+}
+
+func loadInfo(name string) (*iiif.ImageInfo3, error) {
+	bs, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	info := new(iiif.ImageInfo3)
+	return info, json.Unmarshal(bs, info)
+}
+
+func saveJPEG(name string, img image.Image, opts *jpeg.Options) error {
+	if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return err
+	}
+	fp, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err = jpeg.Encode(fp, img, opts); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// half 2×2 取平均缩小一半，奇数边向上取整
+func half(src *image.RGBA) *image.RGBA {
+	sb := src.Bounds()
+	w, h := (sb.Dx()+1)/2, (sb.Dy()+1)/2
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [4]int
+			n := 0
+			for dy := 0; dy < 2; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					sx, sy := sb.Min.X+2*x+dx, sb.Min.Y+2*y+dy
+					if sx >= sb.Max.X || sy >= sb.Max.Y {
+						continue
+					}
+					i := src.PixOffset(sx, sy)
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[i+c])
+					}
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
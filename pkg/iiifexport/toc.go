@@ -0,0 +1,80 @@
+package iiifexport
+
+import (
+	"bookget/model/iiif"
+	"bookget/pkg/catalog"
+	"fmt"
+	"strconv"
+)
+
+// ranges 把目录转换为 structures。页码为全书顺序号（从1开始，bySeq 为顺序号 → canvas），也可以是页码标签；
+// 无法对应到页面（包括导出时跳过的页面）的条目只保留其子目录
+func ranges(base string, toc []catalog.Entry, canvases []*iiif.Canvas3, bySeq map[int]string) []*iiif.Range3 {
+	if len(toc) == 0 {
+		return nil
+	}
+	byLabel := make(map[string]string, len(canvases))
+	for _, c := range canvases {
+		for _, v := range c.Label {
+			if len(v) > 0 {
+				byLabel[v[0]] = c.Id
+			}
+		}
+	}
+	target := func(p string) string {
+		if n, err := strconv.Atoi(p); err == nil {
+			return bySeq[n]
+		}
+		return byLabel[p]
+	}
+
+	var top []*iiif.Range3
+	var stack []*iiif.Range3
+	for i, e := range toc {
+		r := &iiif.Range3{
+			Id:    fmt.Sprintf("%s/range/r%d", base, i+1),
+			Type:  "Range",
+			Label: iiif.NewLanguageMap("none", e.Title),
+			Items: []iiif.RangeItem{},
+		}
+		if id := target(e.Page); id != "" {
+			r.Items = append(r.Items, iiif.CanvasRef{Id: id, Type: "Canvas"})
+		}
+		level := e.Level
+		if level > len(stack) {
+			level = len(stack)
+		}
+		stack = stack[:level]
+		if level == 0 {
+			top = append(top, r)
+		} else {
+			parent := stack[level-1]
+			parent.Items = append(parent.Items, r)
+		}
+		stack = append(stack, r)
+	}
+	return prune(top)
+}
+
+// prune 去掉没有页面的空条目
+func prune(list []*iiif.Range3) []*iiif.Range3 {
+	out := list[:0]
+	for _, r := range list {
+		if !pruneRange(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func pruneRange(r *iiif.Range3) (empty bool) {
+	items := r.Items[:0]
+	for _, it := range r.Items {
+		if child, ok := it.(*iiif.Range3); ok && pruneRange(child) {
+			continue
+		}
+		items = append(items, it)
+	}
+	r.Items = items
+	return len(items) == 0
+}
@@ -0,0 +1,408 @@
+// Package imageproc 下载后的图像处理：拆分跨页（筒子页）、裁掉扫描黑边、纠偏、灰度/黑白化、限制最大宽度。
+// 只处理 JPEG/PNG，其它格式（TIFF、JP2、PDF）原样保留。
+package imageproc
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SpreadRatio 宽高比超过该值视为跨页
+const SpreadRatio = 1.15
+
+// 拆分跨页时的页面顺序
+const (
+	SplitRTL = "rtl" //右页在前（中文古籍）
+	SplitLTR = "ltr" //左页在前
+)
+
+// 颜色转换
+const (
+	ColorGray    = "gray"
+	ColorBitonal = "bitonal"
+)
+
+type Options struct {
+	Split    string //rtl | ltr，空为不拆分
+	Crop     bool   //裁掉扫描仪黑边
+	Deskew   bool   //纠偏
+	Color    string //gray | bitonal，空为保持原色
+	MaxWidth int    //最大宽度，0 为不限制
+	Quality  int    //JPEG 质量，默认 90
+	Output   string //输出子目录（相对图片所在目录），空为覆盖原文件
+}
+
+// Enabled 是否需要处理
+func (o Options) Enabled() bool {
+	return o.Split != "" || o.Crop || o.Deskew || o.Color != "" || o.MaxWidth > 0
+}
+
+// File 处理一个图片文件，返回生成的文件。拆分后的两页命名为 0001_a.jpg、0001_b.jpg（a 为先读的一页）
+func File(path string, o Options) ([]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	pages := Process(img, o)
+	outDir := filepath.Dir(path)
+	if o.Output != "" {
+		outDir = filepath.Join(outDir, o.Output)
+		if err = os.MkdirAll(outDir, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	files := make([]string, 0, len(pages))
+	for i, page := range pages {
+		name := base + filepath.Ext(path)
+		if len(pages) > 1 {
+			name = fmt.Sprintf("%s_%c%s", base, 'a'+i, filepath.Ext(path))
+		}
+		dest := filepath.Join(outDir, name)
+		if err = save(dest, page, ext, o.Quality); err != nil {
+			return files, err
+		}
+		files = append(files, dest)
+	}
+	//覆盖模式下拆分后删除原跨页
+	if o.Output == "" && len(pages) > 1 {
+		_ = os.Remove(path)
+	}
+	return files, nil
+}
+
+// Process 依次裁边、纠偏、拆分、颜色转换、缩放
+func Process(img image.Image, o Options) []image.Image {
+	if o.Crop {
+		img = Crop(img)
+	}
+	if o.Deskew {
+		if angle := Skew(img); math.Abs(angle) >= 0.1 {
+			img = rotate(img, -angle)
+		}
+	}
+	pages := []image.Image{img}
+	if o.Split != "" && IsSpread(img) {
+		right, left := Split(img)
+		if o.Split == SplitLTR {
+			pages = []image.Image{left, right}
+		} else {
+			pages = []image.Image{right, left}
+		}
+	}
+	for i, page := range pages {
+		switch o.Color {
+		case ColorGray:
+			page = toGray(page)
+		case ColorBitonal:
+			page = bitonal(toGray(page))
+		}
+		if o.MaxWidth > 0 && page.Bounds().Dx() > o.MaxWidth {
+			page = resize(page, o.MaxWidth)
+		}
+		pages[i] = page
+	}
+	return pages
+}
+
+// IsSpread 是否为跨页
+func IsSpread(img image.Image) bool {
+	b := img.Bounds()
+	return b.Dy() > 0 && float64(b.Dx())/float64(b.Dy()) > SpreadRatio
+}
+
+// Split 从中缝拆为右、左两页
+func Split(img image.Image) (right, left image.Image) {
+	b := img.Bounds()
+	mid := b.Min.X + b.Dx()/2
+	left = subImage(img, image.Rect(b.Min.X, b.Min.Y, mid, b.Max.Y))
+	right = subImage(img, image.Rect(mid, b.Min.Y, b.Max.X, b.Max.Y))
+	return right, left
+}
+
+// darkLevel 平均亮度低于该值的边缘行/列视为扫描黑边
+const darkLevel = 70
+
+// Crop 从四边向内裁掉黑边，每边最多裁掉 1/4
+func Crop(img image.Image) image.Image {
+	g := toGray(img)
+	b := g.Bounds()
+	w, h := b.Dx(), b.Dy()
+	rowMean := func(y int) int {
+		sum := 0
+		row := g.Pix[(y-b.Min.Y)*g.Stride : (y-b.Min.Y)*g.Stride+w]
+		for _, v := range row {
+			sum += int(v)
+		}
+		return sum / w
+	}
+	colMean := func(x int) int {
+		sum := 0
+		for y := 0; y < h; y++ {
+			sum += int(g.Pix[y*g.Stride+x-b.Min.X])
+		}
+		return sum / h
+	}
+	top, bottom, left, right := b.Min.Y, b.Max.Y, b.Min.X, b.Max.X
+	for top < b.Min.Y+h/4 && rowMean(top) < darkLevel {
+		top++
+	}
+	for bottom > b.Max.Y-h/4 && rowMean(bottom-1) < darkLevel {
+		bottom--
+	}
+	for left < b.Min.X+w/4 && colMean(left) < darkLevel {
+		left++
+	}
+	for right > b.Max.X-w/4 && colMean(right-1) < darkLevel {
+		right--
+	}
+	r := image.Rect(left, top, right, bottom)
+	if r == b {
+		return img
+	}
+	return subImage(img, r)
+}
+
+// Skew 估计文字行/栏线相对水平方向的倾斜角度（度），范围 ±5°
+func Skew(img image.Image) float64 {
+	g := toGray(img)
+	//缩小后估计，加快速度
+	if g.Bounds().Dx() > 800 {
+		g = toGray(resize(g, 800))
+	}
+	t := otsu(g)
+	b := g.Bounds()
+	type point struct{ x, y float64 }
+	cx, cy := float64(b.Dx())/2, float64(b.Dy())/2
+	var ink []point
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if g.Pix[y*g.Stride+x] < t {
+				ink = append(ink, point{float64(x) - cx, float64(y) - cy})
+			}
+		}
+	}
+	if len(ink) == 0 {
+		return 0
+	}
+	diag := int(math.Hypot(cx, cy)) + 2
+	hist := make([]int, 2*diag)
+	best, bestScore := 0.0, -1.0
+	for step := -50; step <= 50; step++ {
+		angle := float64(step) / 10
+		rad := angle * math.Pi / 180
+		sin, cos := math.Sin(rad), math.Cos(rad)
+		for i := range hist {
+			hist[i] = 0
+		}
+		for _, p := range ink {
+			row := int(p.y*cos-p.x*sin) + diag
+			if row >= 0 && row < len(hist) {
+				hist[row]++
+			}
+		}
+		//行投影越集中（相邻行差异越大），角度越准确
+		score := 0.0
+		for i := 1; i < len(hist); i++ {
+			d := float64(hist[i] - hist[i-1])
+			score += d * d
+		}
+		if score > bestScore || (score == bestScore && math.Abs(angle) < math.Abs(best)) {
+			best, bestScore = angle, score
+		}
+	}
+	return best
+}
+
+// rotate 绕中心旋转 angle 度（逆时针为正），空白处填白色
+func rotate(img image.Image, angle float64) image.Image {
+	src := toRGBA(img)
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	rad := angle * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+	cx, cy := float64(b.Min.X)+float64(b.Dx())/2, float64(b.Min.Y)+float64(b.Dy())/2
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			sx := int(math.Round(dx*cos + dy*sin + cx))
+			sy := int(math.Round(-dx*sin + dy*cos + cy))
+			i := dst.PixOffset(x, y)
+			if !(image.Point{X: sx, Y: sy}).In(b) {
+				copy(dst.Pix[i:i+4], []uint8{0xff, 0xff, 0xff, 0xff})
+				continue
+			}
+			j := src.PixOffset(sx, sy)
+			copy(dst.Pix[i:i+4], src.Pix[j:j+4])
+		}
+	}
+	if _, ok := img.(*image.Gray); ok {
+		return toGray(dst)
+	}
+	return dst
+}
+
+// otsu 大津法阈值
+func otsu(g *image.Gray) uint8 {
+	var hist [256]int
+	b := g.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		for _, v := range g.Pix[y*g.Stride : y*g.Stride+b.Dx()] {
+			hist[v]++
+		}
+	}
+	total := b.Dx() * b.Dy()
+	sum := 0
+	for i, n := range hist {
+		sum += i * n
+	}
+	var sumB, wB int
+	var best uint8
+	maxVar := -1.0
+	for t := 0; t < 256; t++ {
+		wB += hist[t]
+		if wB == 0 {
+			continue
+		}
+		wF := total - wB
+		if wF == 0 {
+			break
+		}
+		sumB += t * hist[t]
+		mB := float64(sumB) / float64(wB)
+		mF := float64(sum-sumB) / float64(wF)
+		v := float64(wB) * float64(wF) * (mB - mF) * (mB - mF)
+		if v > maxVar {
+			maxVar, best = v, uint8(t)
+		}
+	}
+	return best + 1
+}
+
+func bitonal(g *image.Gray) *image.Gray {
+	t := otsu(g)
+	b := g.Bounds()
+	out := image.NewGray(b)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if g.Pix[y*g.Stride+x] >= t {
+				out.Pix[y*out.Stride+x] = 0xff
+			}
+		}
+	}
+	return out
+}
+
+// resize 按宽度等比缩小（区域平均）
+func resize(img image.Image, width int) image.Image {
+	src := toRGBA(img)
+	b := src.Bounds()
+	height := int(math.Max(1, math.Round(float64(b.Dy())*float64(width)/float64(b.Dx()))))
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := b.Min.Y + (y+1)*b.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := b.Min.X + (x+1)*b.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[i+c])
+					}
+					i += 4
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	if _, ok := img.(*image.Gray); ok {
+		return toGray(dst)
+	}
+	return dst
+}
+
+func toGray(img image.Image) *image.Gray {
+	if g, ok := img.(*image.Gray); ok {
+		return g
+	}
+	b := img.Bounds()
+	g := image.NewGray(b)
+	draw.Draw(g, b, img, b.Min, draw.Src)
+	return g
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if m, ok := img.(*image.RGBA); ok {
+		return m
+	}
+	b := img.Bounds()
+	m := image.NewRGBA(b)
+	draw.Draw(m, b, img, b.Min, draw.Src)
+	return m
+}
+
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	m := image.NewRGBA(r)
+	draw.Draw(m, r, img, r.Min, draw.Src)
+	return m
+}
+
+// save 先写临时文件再改名，避免中断时留下损坏的图片
+func save(dest string, img image.Image, ext string, quality int) error {
+	if quality <= 0 {
+		quality = 90
+	}
+	tmp := dest + ".processing"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if ext == ".png" {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: quality})
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
@@ -0,0 +1,88 @@
+package imageproc_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/imageproc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spread 白底跨页，四周 20px 黑边，右半页画一条竖线以区分左右
+func spread() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 440, 220))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(20, 20, 420, 200), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(300, 40, 310, 180), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	return img
+}
+
+func TestCropAndSplitRTL(t *testing.T) {
+	cropped := imageproc.Crop(spread())
+	assert.Equal(t, image.Rect(20, 20, 420, 200), cropped.Bounds())
+
+	pages := imageproc.Process(spread(), imageproc.Options{Crop: true, Split: imageproc.SplitRTL})
+	require.Len(t, pages, 2)
+	assert.Equal(t, image.Rect(220, 20, 420, 200), pages[0].Bounds())
+	assert.Equal(t, image.Rect(20, 20, 220, 200), pages[1].Bounds())
+
+	pages = imageproc.Process(spread(), imageproc.Options{Crop: true, Split: imageproc.SplitLTR})
+	assert.Equal(t, 20, pages[0].Bounds().Min.X)
+}
+
+func TestColorAndMaxWidth(t *testing.T) {
+	pages := imageproc.Process(spread(), imageproc.Options{Color: imageproc.ColorBitonal, MaxWidth: 110})
+	require.Len(t, pages, 1)
+	g, ok := pages[0].(*image.Gray)
+	require.True(t, ok)
+	assert.Equal(t, 110, g.Bounds().Dx())
+	assert.Equal(t, 55, g.Bounds().Dy())
+	assert.Equal(t, uint8(0), g.GrayAt(0, 0).Y)
+	assert.Equal(t, uint8(0), g.GrayAt(76, 27).Y)
+	assert.Equal(t, uint8(255), g.GrayAt(50, 27).Y)
+}
+
+func TestSkew(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 400, 400))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	slope := math.Tan(2 * math.Pi / 180)
+	for row := 40; row < 360; row += 20 {
+		for x := 20; x < 380; x++ {
+			y := row + int(math.Round(float64(x-200)*slope))
+			img.SetGray(x, y, color.Gray{})
+			img.SetGray(x, y+1, color.Gray{})
+		}
+	}
+	assert.InDelta(t, 2, math.Abs(imageproc.Skew(img)), 0.3)
+
+	pages := imageproc.Process(img, imageproc.Options{Deskew: true})
+	assert.InDelta(t, 0, imageproc.Skew(pages[0]), 0.3)
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "0001.jpg")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, jpeg.Encode(f, spread(), nil))
+	f.Close()
+
+	files, err := imageproc.File(path, imageproc.Options{Split: imageproc.SplitRTL, Output: "processed"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "processed", "0001_a.jpg"),
+		filepath.Join(dir, "processed", "0001_b.jpg"),
+	}, files)
+	assert.FileExists(t, path)
+
+	files, err = imageproc.File(filepath.Join(dir, "0001.tif"), imageproc.Options{Crop: true})
+	assert.NoError(t, err)
+	assert.Nil(t, files)
+}
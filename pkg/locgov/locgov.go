@@ -0,0 +1,133 @@
+// Package locgov 美国国会图书馆 loc.gov JSON API（?fo=json）：解析条目的分册与逐页文件，按清晰度偏好选取文件，
+// 列出 Chronicling America 报纸某一刊名的各期
+package locgov
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// File 一页的一种文件（缩略图、各级 JPEG、JP2、TIFF、PDF 等）
+type File struct {
+	Url      string `json:"url"`
+	Mimetype string `json:"mimetype"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int    `json:"size"`
+}
+
+// Part 条目中的一部分（多册条目的一册、报纸的一期、分段），Pages 为逐页的可选文件
+type Part struct {
+	Url     string   `json:"url"`
+	Caption string   `json:"caption"`
+	Pages   [][]File `json:"files"`
+}
+
+var (
+	itemRe  = regexp.MustCompile(`/(?:item|resource|lccn)/([A-Za-z0-9][A-Za-z0-9._-]*)(?:/(\d{4}-\d{2}-\d{2}/ed-\d+))?`)
+	issueRe = regexp.MustCompile(`/item/([A-Za-z0-9]+)/(\d{4}-\d{2}-\d{2})/(ed-\d+)/?$`)
+)
+
+// ItemId 由 www.loc.gov/item/、www.loc.gov/resource/ 或 chroniclingamerica.loc.gov/lccn/ 地址取得条目号；
+// 报纸某一期另有 issue，如 1905-01-01/ed-1
+func ItemId(u string) (id, issue string) {
+	if m := itemRe.FindStringSubmatch(u); m != nil {
+		return m[1], m[2]
+	}
+	return "", ""
+}
+
+// ItemUrl 条目（或报纸某一期）的 JSON 地址
+func ItemUrl(id, issue string) string {
+	if issue != "" {
+		return fmt.Sprintf("https://www.loc.gov/item/%s/%s/?fo=json", id, issue)
+	}
+	return fmt.Sprintf("https://www.loc.gov/item/%s/?fo=json", id)
+}
+
+// JsonUrl 给任意 loc.gov 地址加上 fo=json
+func JsonUrl(u string) string {
+	if strings.Contains(u, "fo=json") {
+		return u
+	}
+	if strings.Contains(u, "?") {
+		return u + "&fo=json"
+	}
+	return u + "?fo=json"
+}
+
+// IsNewspaper 以 sn 开头的 LCCN 是报刊的刊名记录，没有 issue 时按日期列出各期
+func IsNewspaper(id, issue string) bool {
+	return issue == "" && strings.HasPrefix(id, "sn")
+}
+
+// ParseItem 解析条目或 resource 的 JSON：resources 与 segments 中的每一项为一部分；
+// resource 页面直接给出 files 时视为一部分。某部分没有 files 时需再请求其 Url 取得
+func ParseItem(body []byte) ([]Part, error) {
+	var v struct {
+		Resources []Part   `json:"resources"`
+		Segments  []Part   `json:"segments"`
+		Files     [][]File `json:"files"`
+	}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	parts := append(v.Resources, v.Segments...)
+	if len(parts) == 0 && len(v.Files) > 0 {
+		parts = append(parts, Part{Pages: v.Files})
+	}
+	return parts, nil
+}
+
+// MaxPages 列出各期时最多读取的页数
+const MaxPages = 1000
+
+// Issue 报纸的一期
+type Issue struct {
+	Url     string
+	Date    string //1905-01-01
+	Edition string //ed-1
+}
+
+// Dir 保存目录名，如 1905-01-01_ed-1
+func (i Issue) Dir() string {
+	return i.Date + "_" + i.Edition
+}
+
+// IssuesUrl 在 Chronicling America 合集中按刊名列出各期；from、to 为年份（可为空），页码从 1 开始
+func IssuesUrl(lccn, from, to string, page int) string {
+	u := fmt.Sprintf("https://www.loc.gov/collections/chronicling-america/?fa=number_lccn:%s&fo=json&c=100&sp=%d", lccn, page)
+	if from != "" || to != "" {
+		if from == "" {
+			from = "1690"
+		}
+		if to == "" {
+			to = "9999"
+		}
+		u += fmt.Sprintf("&dates=%s/%s", from, to)
+	}
+	return u
+}
+
+// ParseIssues 解析 IssuesUrl 的结果，more 表示还有下一页
+func ParseIssues(body []byte) (issues []Issue, more bool, err error) {
+	var v struct {
+		Results []struct {
+			Url string `json:"url"`
+		} `json:"results"`
+		Pagination struct {
+			Next *string `json:"next"`
+		} `json:"pagination"`
+	}
+	if err = json.Unmarshal(body, &v); err != nil {
+		return nil, false, err
+	}
+	for _, r := range v.Results {
+		if m := issueRe.FindStringSubmatch(r.Url); m != nil {
+			issues = append(issues, Issue{Url: r.Url, Date: m[2], Edition: m[3]})
+		}
+	}
+	return issues, v.Pagination.Next != nil && *v.Pagination.Next != "", nil
+}
@@ -0,0 +1,108 @@
+package locgov_test
+
+import (
+	"testing"
+
+	"bookget/pkg/locgov"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 一页的几种文件，与 loc.gov 的 resources[].files[] 相同
+var page = []locgov.File{
+	{Url: "https://tile.loc.gov/image-services/iiif/service:rbc:0001/full/pct:12.5/0/default.jpg", Mimetype: "image/jpeg", Width: 400, Height: 600},
+	{Url: "https://tile.loc.gov/image-services/iiif/service:rbc:0001/full/pct:100/0/default.jpg", Mimetype: "image/jpeg", Width: 3200, Height: 4800},
+	{Url: "https://tile.loc.gov/image-services/iiif/service:rbc:0001/full/pct:50/0/default.jpg", Mimetype: "image/jpeg", Width: 1600, Height: 2400},
+	{Url: "https://tile.loc.gov/storage-services/service/rbc/0001.jp2", Mimetype: "image/jp2", Width: 3200, Height: 4800, Size: 3000000},
+	{Url: "https://tile.loc.gov/storage-services/service/rbc/0001.tif", Mimetype: "image/tiff", Width: 3300, Height: 4900, Size: 48000000},
+}
+
+func pick(t *testing.T, quality, ext string) (string, string) {
+	q, err := locgov.ParseQuality(quality, ext)
+	require.NoError(t, err)
+	f, fileExt, ok := q.Pick(page)
+	require.True(t, ok)
+	return f.Url, fileExt
+}
+
+func TestQuality(t *testing.T) {
+	u, ext := pick(t, "", ".jpg")
+	assert.Equal(t, "https://tile.loc.gov/image-services/iiif/service:rbc:0001/full/pct:100/0/default.jpg", u)
+	assert.Equal(t, ".jpg", ext)
+
+	u, ext = pick(t, "", ".jp2")
+	assert.Equal(t, "https://tile.loc.gov/storage-services/service/rbc/0001.jp2", u)
+	assert.Equal(t, ".jp2", ext)
+
+	u, _ = pick(t, "best", ".jpg")
+	assert.Equal(t, "https://tile.loc.gov/storage-services/service/rbc/0001.tif", u)
+
+	//没有 PDF 时依次尝试下一种
+	u, ext = pick(t, "pdf,jp2", ".jpg")
+	assert.Equal(t, "https://tile.loc.gov/storage-services/service/rbc/0001.jp2", u)
+	assert.Equal(t, ".jp2", ext)
+
+	_, err := locgov.ParseQuality("webp", ".jpg")
+	assert.Error(t, err)
+
+	q, _ := locgov.ParseQuality("pdf", ".jpg")
+	_, _, ok := q.Pick(page)
+	assert.False(t, ok)
+}
+
+func TestItemId(t *testing.T) {
+	for u, want := range map[string][2]string{
+		"https://www.loc.gov/item/2012402381/":                                {"2012402381", ""},
+		"https://www.loc.gov/item/sn84026749/1905-01-01/ed-1/":                {"sn84026749", "1905-01-01/ed-1"},
+		"https://chroniclingamerica.loc.gov/lccn/sn84026749/":                 {"sn84026749", ""},
+		"https://chroniclingamerica.loc.gov/lccn/sn84026749/1905-01-01/ed-1/": {"sn84026749", "1905-01-01/ed-1"},
+		"https://www.loc.gov/resource/rbc0001.2013rosen0051/?sp=1":            {"rbc0001.2013rosen0051", ""},
+		"https://www.loc.gov/collections/":                                    {"", ""},
+	} {
+		id, issue := locgov.ItemId(u)
+		assert.Equal(t, want, [2]string{id, issue}, u)
+	}
+	assert.True(t, locgov.IsNewspaper("sn84026749", ""))
+	assert.False(t, locgov.IsNewspaper("sn84026749", "1905-01-01/ed-1"))
+	assert.False(t, locgov.IsNewspaper("2012402381", ""))
+	assert.Equal(t, "https://www.loc.gov/resource/x/?sp=2&fo=json", locgov.JsonUrl("https://www.loc.gov/resource/x/?sp=2"))
+}
+
+func TestParseItem(t *testing.T) {
+	parts, err := locgov.ParseItem([]byte(`{"item":{"title":"Tao te ching"},"resources":[
+{"caption":"v.1","url":"https://www.loc.gov/resource/rbc0001.1/","files":[[{"url":"a.jpg","mimetype":"image/jpeg","width":10,"height":null}],[{"url":"b.tif","mimetype":"image/tiff"}]]},
+{"caption":"v.2","url":"https://www.loc.gov/resource/rbc0001.2/","files":[]}],
+"segments":[{"url":"https://www.loc.gov/resource/rbc0001.3/"}]}`))
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+	assert.Equal(t, "v.1", parts[0].Caption)
+	assert.Len(t, parts[0].Pages, 2)
+	assert.Equal(t, 10, parts[0].Pages[0][0].Width)
+	assert.Empty(t, parts[1].Pages)
+	assert.Equal(t, "https://www.loc.gov/resource/rbc0001.3/", parts[2].Url)
+
+	//resource 页面直接给出 files
+	parts, err = locgov.ParseItem([]byte(`{"files":[[{"url":"c.jp2","mimetype":"image/jp2"}]]}`))
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	assert.Equal(t, "c.jp2", parts[0].Pages[0][0].Url)
+}
+
+func TestIssues(t *testing.T) {
+	assert.Equal(t, "https://www.loc.gov/collections/chronicling-america/?fa=number_lccn:sn84026749&fo=json&c=100&sp=2&dates=1905/9999",
+		locgov.IssuesUrl("sn84026749", "1905", "", 2))
+	assert.Equal(t, "https://www.loc.gov/collections/chronicling-america/?fa=number_lccn:sn84026749&fo=json&c=100&sp=1",
+		locgov.IssuesUrl("sn84026749", "", "", 1))
+
+	issues, more, err := locgov.ParseIssues([]byte(`{"results":[
+{"url":"https://www.loc.gov/item/sn84026749/1905-01-02/ed-1/"},
+{"url":"https://www.loc.gov/resource/sn84026749/1905-01-02/ed-1/?sp=1"},
+{"url":"https://www.loc.gov/item/sn84026749/1905-01-01/ed-2/"}],"pagination":{"next":"https://www.loc.gov/...&sp=2"}}`))
+	require.NoError(t, err)
+	assert.True(t, more)
+	assert.Equal(t, []locgov.Issue{
+		{Url: "https://www.loc.gov/item/sn84026749/1905-01-02/ed-1/", Date: "1905-01-02", Edition: "ed-1"},
+		{Url: "https://www.loc.gov/item/sn84026749/1905-01-01/ed-2/", Date: "1905-01-01", Edition: "ed-2"},
+	}, issues)
+	assert.Equal(t, "1905-01-01_ed-2", issues[1].Dir())
+}
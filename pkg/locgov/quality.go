@@ -0,0 +1,101 @@
+package locgov
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 文件类型与 MIME 类型、扩展名
+var types = map[string]struct{ mime, ext string }{
+	"tiff": {"image/tiff", ".tif"},
+	"jp2":  {"image/jp2", ".jp2"},
+	"jpeg": {"image/jpeg", ".jpg"},
+	"pdf":  {"application/pdf", ".pdf"},
+}
+
+// defaultOrder 未指定的类型按此顺序作为后备
+var defaultOrder = []string{"tiff", "jp2", "jpeg"}
+
+// Quality 选取文件的偏好
+type Quality struct {
+	Types []string //依次尝试的类型
+	Best  bool     //在 Types 中取像素最多的，不论类型
+}
+
+// ParseQuality 解析 --quality：
+//
+//	""            按 --extension 选类型（.jpg=jpeg，.tif=tiff，.jp2=jp2，.pdf=pdf），其余类型作为后备
+//	best          各类型中分辨率最高的
+//	jp2,jpeg      依次尝试的类型，可选 tiff、jp2、jpeg、pdf
+func ParseQuality(s, fileExt string) (Quality, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		first := "jpeg"
+		for t, v := range types {
+			if v.ext == fileExt || (t == "tiff" && fileExt == ".tiff") {
+				first = t
+			}
+		}
+		q := Quality{Types: []string{first}}
+		for _, t := range defaultOrder {
+			if t != first {
+				q.Types = append(q.Types, t)
+			}
+		}
+		return q, nil
+	case "best":
+		return Quality{Types: defaultOrder, Best: true}, nil
+	}
+	var q Quality
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "tif" {
+			t = "tiff"
+		}
+		if _, ok := types[t]; !ok {
+			return q, fmt.Errorf("invalid quality %q, want best or a list of tiff,jp2,jpeg,pdf", s)
+		}
+		q.Types = append(q.Types, t)
+	}
+	return q, nil
+}
+
+// Pick 从一页的各种文件中选取一个，同类型中取像素最多的（相同时取文件最大的）；返回文件与扩展名
+func (q Quality) Pick(files []File) (File, string, bool) {
+	var (
+		best     File
+		bestExt  string
+		bestRank = -1
+		found    bool
+	)
+	for _, f := range files {
+		rank := -1
+		for i, t := range q.Types {
+			if types[t].mime == f.Mimetype {
+				rank = i
+				break
+			}
+		}
+		if rank < 0 {
+			continue
+		}
+		better := !found
+		if found {
+			switch {
+			case !q.Best && rank != bestRank:
+				better = rank < bestRank
+			case f.Width*f.Height != best.Width*best.Height:
+				better = f.Width*f.Height > best.Width*best.Height
+			case q.Best && rank != bestRank:
+				better = rank < bestRank
+			default:
+				better = f.Size > best.Size
+			}
+		}
+		if better {
+			best, bestRank, bestExt, found = f, rank, types[q.Types[rank]].ext, true
+		}
+	}
+	return best, bestExt, found
+}
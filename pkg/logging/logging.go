@@ -0,0 +1,179 @@
+// Package logging 基于 log/slog 的分级日志：控制台与 --log-file 同时输出，带任务上下文字段
+// （site、bookId、volume、page），并自动隐去 Cookie、Authorization 与 sign/token 参数，
+// 日志可以直接附在问题报告里。Setup 后标准库 log.Printf 也经由这里按 INFO 级别输出。
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ParseLevel debug | info | warn | error
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
+
+// Setup 设置默认 logger：console 为控制台输出，file 不为空时同时追加写入文件。
+// 返回的 io.Closer 用于关闭日志文件
+func Setup(console io.Writer, level, file string) (io.Closer, error) {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	handlers := []slog.Handler{NewConsoleHandler(console, l)}
+	var closer io.Closer = nopCloser{}
+	if file != "" {
+		fp, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, slog.NewTextHandler(fp, &slog.HandlerOptions{Level: l}))
+		closer = fp
+	}
+	var h slog.Handler = handlers[0]
+	if len(handlers) > 1 {
+		h = multiHandler(handlers)
+	}
+	slog.SetDefault(slog.New(NewRedactHandler(h)))
+	return closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Job 一本书的 logger，之后可再 With("volume", ...)、With("page", ...)
+func Job(site, bookId string) *slog.Logger {
+	return slog.Default().With("site", site, "bookId", bookId)
+}
+
+// DebugEnabled 是否输出 DEBUG 级别
+func DebugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// multiHandler 同时写入多个 handler，各自按级别过滤
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// consoleHandler 控制台格式与原来的 log.Printf 相同：时间 消息 key=value，INFO 以外加级别
+type consoleHandler struct {
+	w      io.Writer
+	level  slog.Leveler
+	prefix string //WithGroup 的组名前缀
+	attrs  string //WithAttrs 预先格式化的字段
+}
+
+func NewConsoleHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	return &consoleHandler{w: w, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	if !r.Time.IsZero() {
+		sb.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+	}
+	if r.Level != slog.LevelInfo {
+		sb.WriteString(r.Level.String() + " ")
+	}
+	sb.WriteString(strings.TrimRight(r.Message, "\n"))
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&sb, h.prefix, a)
+		return true
+	})
+	sb.WriteByte('\n')
+	//整行一次写入，progress.Reporter 据此擦除与重绘进度行
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var sb strings.Builder
+	for _, a := range attrs {
+		appendAttr(&sb, h.prefix, a)
+	}
+	c := *h
+	c.attrs += sb.String()
+	return &c
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.prefix += name + "."
+	return &c
+}
+
+func appendAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			appendAttr(sb, p, g)
+		}
+		return
+	}
+	v := a.Value.String()
+	if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+		v = fmt.Sprintf("%q", v)
+	}
+	sb.WriteString(" " + prefix + a.Key + "=" + v)
+}
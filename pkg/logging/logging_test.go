@@ -0,0 +1,67 @@
+package logging_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bookget/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	dump := "GET /img?id=3&sign=abc123&x=1 HTTP/1.1\r\nHost: example.org\r\nCookie: JSESSIONID=secret; uid=7\r\nAuthorization: Bearer eyJhbGci\r\n\r\n"
+	got := logging.Redact(dump)
+	assert.NotContains(t, got, "abc123")
+	assert.NotContains(t, got, "secret")
+	assert.NotContains(t, got, "eyJhbGci")
+	assert.Contains(t, got, "id=3&sign=[REDACTED]&x=1")
+	assert.Contains(t, got, "Host: example.org\r\n")
+	assert.Contains(t, got, "Cookie: [REDACTED]\r\n")
+
+	assert.Equal(t, `https://a.org/b?access_token=[REDACTED]#p1`, logging.Redact("https://a.org/b?access_token=xyz#p1"))
+	assert.Equal(t, `{"Cookie":"[REDACTED]","Accept":"*/*"}`, logging.Redact(`{"Cookie":"a=b","Accept":"*/*"}`))
+	assert.Equal(t, `map[Accept:[*/*] Cookie:[[REDACTED]]]`, logging.Redact(`map[Accept:[*/*] Cookie:[a=b]]`))
+	assert.Equal(t, "读取浏览器cookie失败: no profile", logging.Redact("读取浏览器cookie失败: no profile"))
+}
+
+func TestSetup(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.LstdFlags)
+
+	var console bytes.Buffer
+	file := filepath.Join(t.TempDir(), "bookget.log")
+	closer, err := logging.Setup(&console, "warn", file)
+	require.NoError(t, err)
+
+	logging.Job("example.org", "b1").With("volume", "0002").Info("quiet")
+	logging.Job("example.org", "b1").With("page", 3).Warn("Get https://example.org/1.jpg?token=t0k",
+		"cookie", "JSESSIONID=1", "header", http.Header{"Authorization": {"Basic x"}}, "err", errors.New("GET /p?sign=s1 failed"))
+	log.Printf("plain log line\n")
+	require.NoError(t, closer.Close())
+
+	out := console.String()
+	assert.NotContains(t, out, "quiet")
+	assert.NotContains(t, out, "plain log line", "log.Printf is INFO")
+	assert.Contains(t, out, "WARN Get https://example.org/1.jpg?token=[REDACTED] site=example.org bookId=b1 page=3 cookie=[REDACTED]")
+	assert.Contains(t, out, "err=\"GET /p?sign=[REDACTED] failed\"")
+	assert.NotContains(t, out, "Basic x")
+	assert.Equal(t, 1, strings.Count(out, "\n"))
+
+	bs, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(bs), "level=WARN")
+	assert.Contains(t, string(bs), "site=example.org bookId=b1 page=3")
+	assert.NotContains(t, string(bs), "t0k")
+
+	_, err = logging.Setup(&console, "loud", "")
+	assert.Error(t, err)
+}
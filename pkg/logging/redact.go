@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Redacted 替换敏感内容的占位符
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys 字段名或请求头名（小写）
+var sensitiveKeys = map[string]bool{
+	"cookie":              true,
+	"set-cookie":          true,
+	"authorization":       true,
+	"proxy-authorization": true,
+	"token":               true,
+	"sign":                true,
+	"password":            true,
+}
+
+var (
+	// 请求转储、错误信息中的 Cookie: ... / Authorization: Bearer ...，直到行尾（[ 开头的交给 fmtMapRe）
+	headerRe = regexp.MustCompile(`(?i)\b((?:set-)?cookie|(?:proxy-)?authorization)(\s*:\s*)[^\[\r\n][^\r\n]*`)
+	// JSON 或 fmt 输出的 http.Header："Cookie":"..." / Cookie:[...]
+	jsonRe   = regexp.MustCompile(`(?i)("(?:set-)?cookie"|"(?:proxy-)?authorization")(\s*:\s*)("(?:[^"\\]|\\.)*"|\[[^\]]*\])`)
+	fmtMapRe = regexp.MustCompile(`(?i)\b((?:set-)?cookie|(?:proxy-)?authorization)(:)\[[^\]]*\]`)
+	// URL 中的 sign、token、access_token 等参数
+	queryRe = regexp.MustCompile(`(?i)([?&;](?:sign|signature|token|[a-z0-9]*_?token|password)=)[^&#\s"'<>]*`)
+)
+
+// Redact 隐去字符串中的 Cookie、Authorization 与 sign/token 参数
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	s = jsonRe.ReplaceAllString(s, `$1$2"`+Redacted+`"`)
+	s = fmtMapRe.ReplaceAllString(s, `$1$2[`+Redacted+`]`)
+	s = headerRe.ReplaceAllString(s, `$1$2`+Redacted)
+	return queryRe.ReplaceAllString(s, `$1`+Redacted)
+}
+
+// RedactHeader 复制一份请求头并隐去敏感字段
+func RedactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for k := range out {
+		if sensitiveKeys[strings.ToLower(k)] {
+			out[k] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+type redactHandler struct {
+	next slog.Handler
+}
+
+// NewRedactHandler 在写入 next 前隐去消息与字段中的敏感内容
+func NewRedactHandler(next slog.Handler) slog.Handler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(out)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		out := make([]any, len(group))
+		for i, g := range group {
+			out[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, out...)
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case http.Header:
+			return slog.Any(a.Key, RedactHeader(v))
+		case error:
+			return slog.String(a.Key, Redact(v.Error()))
+		}
+	}
+	return a
+}
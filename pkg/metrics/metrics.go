@@ -0,0 +1,268 @@
+// Package metrics --metrics :9090 时以 Prometheus 文本格式输出下载指标：页数、字节数、请求耗时、
+// HTTP 状态码与重试次数（按站点 host 与 handler 分组），以及队列长度。
+// 页面指标来自 events 事件流，请求指标来自 Transport 包装的 http.RoundTripper。
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bookget/pkg/events"
+)
+
+var Default = new(Registry)
+
+var (
+	latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+	pagesDone    = Default.NewCounterVec("bookget_pages_downloaded_total", "Pages saved to disk.", "host", "handler")
+	pagesFailed  = Default.NewCounterVec("bookget_pages_failed_total", "Pages that failed to download, by error class.", "host", "handler", "class")
+	bytesDone    = Default.NewCounterVec("bookget_bytes_downloaded_total", "Bytes of saved pages.", "host", "handler")
+	pageDuration = Default.NewHistogramVec("bookget_page_duration_seconds", "Time to download one page, including retries inside the engine.", latencyBuckets, "host", "handler")
+	pagesActive  = Default.NewGaugeVec("bookget_pages_in_flight", "Pages queued or downloading.", "handler")
+
+	reqDuration = Default.NewHistogramVec("bookget_http_request_duration_seconds", "HTTP request latency until response headers.", latencyBuckets, "host", "handler")
+	responses   = Default.NewCounterVec("bookget_http_responses_total", "HTTP responses by status code; code=\"error\" for transport errors.", "host", "handler", "code")
+	retries     = Default.NewCounterVec("bookget_http_retries_total", "Requests repeating a URL whose previous attempt failed.", "host", "handler")
+
+	jobsStarted  = Default.NewCounterVec("bookget_jobs_started_total", "Book URLs started.", "handler")
+	jobsFinished = Default.NewCounterVec("bookget_jobs_finished_total", "Book URLs finished.", "handler", "status")
+	jobsActive   = Default.NewGaugeVec("bookget_jobs_active", "Book URLs being processed.", "handler")
+)
+
+var enabled int32
+
+// Enable 开始采集：订阅事件流，Transport 开始记录请求
+func Enable() {
+	if atomic.CompareAndSwapInt32(&enabled, 0, 1) {
+		events.Default.Subscribe(observe)
+	}
+}
+
+// Enabled 是否已开始采集
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// Handler /metrics
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Default.Expose(w)
+	})
+}
+
+// Serve 在 addr（如 :9090）上提供 /metrics，返回实际监听地址
+func Serve(addr string) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	Enable()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	go func() {
+		_ = http.Serve(ln, mux)
+	}()
+	return ln.Addr(), nil
+}
+
+// Queue 可以报告长度的队列，如 queue.ConcurrentQueue
+type Queue interface {
+	Pending() int
+	CurrentCount() int
+}
+
+// RegisterQueue 输出队列中等待与执行中的任务数。同名队列再次登记时替换之前的
+func RegisterQueue(name string, q Queue) {
+	Default.GaugeFunc("bookget_queue_depth", "Tasks waiting in the queue.", []string{"queue"}, []string{name},
+		func() float64 { return float64(q.Pending()) })
+	Default.GaugeFunc("bookget_queue_running", "Tasks running from the queue.", []string{"queue"}, []string{name},
+		func() float64 { return float64(q.CurrentCount()) })
+}
+
+// handler 归属：请求与页面事件不带 handler。任务 URL 的 host 与任务登记的页面 host（Claim）
+// 在任务进行期间对应到该任务的 handler，任务结束即释放；
+// 未登记的 host 在只有一个 handler 进行时归到它，否则为空
+var owners = struct {
+	sync.Mutex
+	jobs   map[string]*ownedJob //任务 URL
+	byHost map[string]*hostOwner
+	active map[string]int
+}{jobs: make(map[string]*ownedJob), byHost: make(map[string]*hostOwner), active: make(map[string]int)}
+
+type ownedJob struct {
+	handler string
+	hosts   map[string]bool
+}
+
+type hostOwner struct {
+	handler string
+	refs    int
+}
+
+// Claim 登记任务 jobUrl 要请求的地址（如图片 URL），这些 host 的请求与页面在任务结束前归到该任务的 handler
+func Claim(jobUrl string, urls []string) {
+	owners.Lock()
+	defer owners.Unlock()
+	job, ok := owners.jobs[jobUrl]
+	if !ok {
+		return
+	}
+	for _, u := range urls {
+		if host := hostOf(u); host != "" {
+			job.own(host)
+		}
+	}
+}
+
+func (job *ownedJob) own(host string) {
+	if job.hosts[host] {
+		return
+	}
+	job.hosts[host] = true
+	o, ok := owners.byHost[host]
+	if !ok {
+		o = &hostOwner{}
+		owners.byHost[host] = o
+	}
+	o.handler = job.handler
+	o.refs++
+}
+
+func (job *ownedJob) release() {
+	for host := range job.hosts {
+		if o := owners.byHost[host]; o != nil {
+			if o.refs--; o.refs <= 0 {
+				delete(owners.byHost, host)
+			}
+		}
+	}
+}
+
+func handlerFor(host string) string {
+	owners.Lock()
+	defer owners.Unlock()
+	if o, ok := owners.byHost[host]; ok {
+		return o.handler
+	}
+	if len(owners.active) != 1 {
+		return ""
+	}
+	for h := range owners.active {
+		return h
+	}
+	return ""
+}
+
+func hostOf(rawUrl string) string {
+	if u, err := url.Parse(rawUrl); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+func observe(e events.Event) {
+	switch e.Type {
+	case events.JobStarted:
+		owners.Lock()
+		if old, ok := owners.jobs[e.Url]; ok {
+			old.release()
+		}
+		job := &ownedJob{handler: e.Handler, hosts: make(map[string]bool)}
+		job.own(hostOf(e.Url))
+		owners.jobs[e.Url] = job
+		owners.active[e.Handler]++
+		owners.Unlock()
+		jobsStarted.Inc(e.Handler)
+		jobsActive.Add(1, e.Handler)
+	case events.JobFinished:
+		if e.Handler == "" { //不支持的 URL
+			jobsFinished.Inc(e.Handler, e.Status)
+			return
+		}
+		owners.Lock()
+		if job, ok := owners.jobs[e.Url]; ok {
+			job.release()
+			delete(owners.jobs, e.Url)
+		}
+		if owners.active[e.Handler]--; owners.active[e.Handler] <= 0 {
+			delete(owners.active, e.Handler)
+		}
+		owners.Unlock()
+		jobsFinished.Inc(e.Handler, e.Status)
+		jobsActive.Add(-1, e.Handler)
+	case events.PageQueued:
+		pagesActive.Add(1, handlerFor(hostOf(e.Url)))
+	case events.PageDone, events.PageFailed:
+		host := hostOf(e.Url)
+		h := handlerFor(host)
+		pagesActive.Add(-1, h)
+		pageDuration.Observe(float64(e.Ms)/1000, host, h)
+		if e.Type == events.PageFailed {
+			pagesFailed.Inc(host, h, e.Class)
+			return
+		}
+		pagesDone.Inc(host, h)
+		bytesDone.Add(float64(e.Bytes), host, h)
+	}
+}
+
+// Transport 包装 rt（nil 为 http.DefaultTransport），记录请求耗时、状态码与重试
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{next: rt}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// failed 上次请求失败的 URL，再次请求即为重试
+var failed = struct {
+	sync.Mutex
+	urls map[string]bool
+}{urls: make(map[string]bool)}
+
+const maxFailed = 4096
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Enabled() {
+		return t.next.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	host := req.URL.Host
+	h := handlerFor(host)
+	reqDuration.Observe(time.Since(start).Seconds(), host, h)
+
+	code, bad := "error", true
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		bad = resp.StatusCode >= 400
+	}
+	responses.Inc(host, h, code)
+
+	key := req.Method + " " + req.URL.String()
+	failed.Lock()
+	if failed.urls[key] {
+		retries.Inc(host, h)
+	}
+	if bad {
+		if len(failed.urls) >= maxFailed {
+			failed.urls = make(map[string]bool)
+		}
+		failed.urls[key] = true
+	} else {
+		delete(failed.urls, key)
+	}
+	failed.Unlock()
+	return resp, err
+}
@@ -0,0 +1,125 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bookget/pkg/events"
+	"bookget/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func expose(t *testing.T, r *metrics.Registry) string {
+	var sb strings.Builder
+	require.NoError(t, r.Expose(&sb))
+	return sb.String()
+}
+
+func TestRegistry(t *testing.T) {
+	r := new(metrics.Registry)
+	c := r.NewCounterVec("c_total", "help", "host")
+	c.Inc(`a"b`)
+	c.Add(2, `a"b`)
+	h := r.NewHistogramVec("h_seconds", "help", []float64{0.1, 1}, "host")
+	h.Observe(0.05, "x")
+	h.Observe(0.5, "x")
+	h.Observe(5, "x")
+	r.GaugeFunc("q", "help", []string{"queue"}, []string{"urls"}, func() float64 { return 7 })
+
+	out := expose(t, r)
+	assert.Contains(t, out, "# TYPE c_total counter\n")
+	assert.Contains(t, out, `c_total{host="a\"b"} 3`)
+	assert.Contains(t, out, `h_seconds_bucket{host="x",le="0.1"} 1`)
+	assert.Contains(t, out, `h_seconds_bucket{host="x",le="1"} 2`)
+	assert.Contains(t, out, `h_seconds_bucket{host="x",le="+Inf"} 3`)
+	assert.Contains(t, out, `h_seconds_sum{host="x"} 5.55`)
+	assert.Contains(t, out, `h_seconds_count{host="x"} 3`)
+	assert.Contains(t, out, `q{queue="urls"} 7`)
+}
+
+type queue struct{}
+
+func (queue) Pending() int      { return 5 }
+func (queue) CurrentCount() int { return 2 }
+
+func TestCollect(t *testing.T) {
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	addr, err := metrics.Serve("127.0.0.1:0")
+	require.NoError(t, err)
+	metrics.RegisterQueue("urls", queue{})
+
+	events.Emit(events.Event{Type: events.JobStarted, Url: "https://book.example.org/1", Handler: "Demo"})
+	cli := &http.Client{Transport: metrics.Transport(nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := cli.Get(srv.URL + "/0001.jpg")
+		require.NoError(t, err)
+		resp.Body.Close()
+		fail = false
+	}
+	events.Emit(events.Event{Type: events.PageQueued, Url: srv.URL + "/0001.jpg"})
+	events.Emit(events.Event{Type: events.PageDone, Url: srv.URL + "/0001.jpg", Bytes: 2048, Ms: 300})
+	events.Emit(events.Event{Type: events.JobFinished, Url: "https://book.example.org/1", Handler: "Demo", Status: "ok"})
+
+	resp, err := http.Get("http://" + addr.String() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	bs, _ := io.ReadAll(resp.Body)
+	out := string(bs)
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	assert.Contains(t, out, `bookget_http_responses_total{host="`+host+`",handler="Demo",code="429"} 1`)
+	assert.Contains(t, out, `bookget_http_responses_total{host="`+host+`",handler="Demo",code="200"} 1`)
+	assert.Contains(t, out, `bookget_http_retries_total{host="`+host+`",handler="Demo"} 1`)
+	assert.Contains(t, out, `bookget_http_request_duration_seconds_count{host="`+host+`",handler="Demo"} 2`)
+	assert.Contains(t, out, `bookget_pages_downloaded_total{host="`+host+`",handler="Demo"} 1`)
+	assert.Contains(t, out, `bookget_bytes_downloaded_total{host="`+host+`",handler="Demo"} 2048`)
+	assert.Contains(t, out, `bookget_pages_in_flight{handler="Demo"} 0`)
+	assert.Contains(t, out, `bookget_jobs_finished_total{handler="Demo",status="ok"} 1`)
+	assert.Contains(t, out, `bookget_jobs_active{handler="Demo"} 0`)
+	assert.Contains(t, out, `bookget_queue_depth{queue="urls"} 5`)
+	assert.Contains(t, out, `bookget_queue_running{queue="urls"} 2`)
+}
+
+func TestClaim(t *testing.T) {
+	metrics.Enable()
+	events.Emit(events.Event{Type: events.JobStarted, Url: "https://a.example.org/book/1", Handler: "Alpha"})
+	events.Emit(events.Event{Type: events.JobStarted, Url: "https://b.example.org/book/2", Handler: "Beta"})
+	metrics.Claim("https://a.example.org/book/1", []string{"https://img-a.example.org/1.jpg", "https://img-a.example.org/2.jpg"})
+	metrics.Claim("https://b.example.org/book/2", []string{"https://img-b.example.org/1.jpg"})
+
+	for _, u := range []string{"https://img-a.example.org/1.jpg", "https://img-b.example.org/1.jpg", "https://img-c.example.org/1.jpg"} {
+		events.Emit(events.Event{Type: events.PageDone, Url: u, Bytes: 10})
+	}
+	events.Emit(events.Event{Type: events.JobFinished, Url: "https://a.example.org/book/1", Handler: "Alpha", Status: "ok"})
+	events.Emit(events.Event{Type: events.PageDone, Url: "https://img-a.example.org/2.jpg", Bytes: 10})
+	events.Emit(events.Event{Type: events.JobFinished, Url: "https://b.example.org/book/2", Handler: "Beta", Status: "ok"})
+
+	out := expose(t, metrics.Default)
+	assert.Contains(t, out, `bookget_pages_downloaded_total{host="img-a.example.org",handler="Alpha"} 1`)
+	assert.Contains(t, out, `bookget_pages_downloaded_total{host="img-b.example.org",handler="Beta"} 1`)
+	assert.Contains(t, out, `bookget_pages_downloaded_total{host="img-c.example.org",handler=""} 1`)
+	//Alpha 结束后 img-a 不再归它，只剩 Beta 进行
+	assert.Contains(t, out, `bookget_pages_downloaded_total{host="img-a.example.org",handler="Beta"} 1`)
+}
+
+func TestRegisterQueueReplace(t *testing.T) {
+	r := new(metrics.Registry)
+	r.GaugeFunc("q", "help", []string{"queue"}, []string{"urls"}, func() float64 { return 1 })
+	r.GaugeFunc("q", "help", []string{"queue"}, []string{"urls"}, func() float64 { return 2 })
+	out := expose(t, r)
+	assert.Contains(t, out, `q{queue="urls"} 2`)
+	assert.Equal(t, 1, strings.Count(out, `q{queue="urls"}`))
+}
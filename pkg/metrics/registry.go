@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry 指标集合，按 Prometheus 文本格式输出
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Expose 按注册顺序输出所有指标
+func (r *Registry) Expose(w io.Writer) error {
+	r.mu.Lock()
+	list := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, c := range list {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (r *Registry) add(c collector) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mu.Unlock()
+}
+
+// series 一组标签取值对应的序列
+type series struct {
+	values []string
+	value  float64
+	counts []uint64 //直方图各桶（不累计）
+	count  uint64
+}
+
+type vec struct {
+	name, help, kind string
+	labels           []string
+	buckets          []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func (v *vec) get(values []string) *series {
+	if len(values) != len(v.labels) {
+		panic("metrics: " + v.name + ": wrong number of label values")
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		if v.buckets != nil {
+			s.counts = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *vec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.kind)
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := v.series[k]
+		if v.buckets == nil {
+			fmt.Fprintf(w, "%s%s %s\n", v.name, labelString(v.labels, s.values, ""), formatFloat(s.value))
+			continue
+		}
+		var cum uint64
+		for i, le := range v.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, labelString(v.labels, s.values, formatFloat(le)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, labelString(v.labels, s.values, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, labelString(v.labels, s.values, ""), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, labelString(v.labels, s.values, ""), s.count)
+	}
+}
+
+// CounterVec 只增不减的计数
+type CounterVec struct{ vec }
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec{name: name, help: help, kind: "counter", labels: labels, series: make(map[string]*series)}}
+	r.add(c)
+	return c
+}
+
+func (c *CounterVec) Add(n float64, values ...string) {
+	c.mu.Lock()
+	c.get(values).value += n
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// GaugeVec 可增可减的当前值
+type GaugeVec struct{ vec }
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{vec{name: name, help: help, kind: "gauge", labels: labels, series: make(map[string]*series)}}
+	r.add(g)
+	return g
+}
+
+func (g *GaugeVec) Add(n float64, values ...string) {
+	g.mu.Lock()
+	g.get(values).value += n
+	g.mu.Unlock()
+}
+
+func (g *GaugeVec) Set(n float64, values ...string) {
+	g.mu.Lock()
+	g.get(values).value = n
+	g.mu.Unlock()
+}
+
+// HistogramVec 分布，buckets 为升序的上界
+type HistogramVec struct{ vec }
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{vec{name: name, help: help, kind: "histogram", labels: labels, buckets: buckets, series: make(map[string]*series)}}
+	r.add(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(values)
+	s.value += v
+	s.count++
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+}
+
+// gaugeFunc 输出时才取值的仪表，如队列长度
+type gaugeFunc struct {
+	name, help string
+	labels     []string
+
+	mu    sync.Mutex
+	funcs []labelledFunc
+}
+
+type labelledFunc struct {
+	values []string
+	fn     func() float64
+}
+
+// GaugeFunc 注册一个输出时取值的仪表，同名的可以多次注册不同标签，标签相同时替换取值函数
+func (r *Registry) GaugeFunc(name, help string, labels []string, values []string, fn func() float64) {
+	r.mu.Lock()
+	for _, c := range r.collectors {
+		if g, ok := c.(*gaugeFunc); ok && g.name == name {
+			r.mu.Unlock()
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			for i, f := range g.funcs {
+				if slices.Equal(f.values, values) {
+					g.funcs[i].fn = fn
+					return
+				}
+			}
+			g.funcs = append(g.funcs, labelledFunc{values, fn})
+			return
+		}
+	}
+	r.collectors = append(r.collectors, &gaugeFunc{name: name, help: help, labels: labels, funcs: []labelledFunc{{values, fn}}})
+	r.mu.Unlock()
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+	for _, f := range g.funcs {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, labelString(g.labels, f.values, ""), formatFloat(f.fn()))
+	}
+}
+
+func labelString(names, values []string, le string) string {
+	if len(names) == 0 && le == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(n + `="` + escape(values[i]) + `"`)
+	}
+	if le != "" {
+		if len(names) > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`le="` + le + `"`)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,438 @@
+// Package progress 统一的下载进度显示：每个进行中的任务一行，最后一行为汇总（页数、字节、速率、剩余时间）。
+// stdout 不是终端时不刷新进度行，改为定期输出普通日志行。
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bookget/pkg/i18n"
+	"github.com/rivo/uniseg"
+	"golang.org/x/term"
+)
+
+// Unit 任务的计数单位
+type Unit int
+
+const (
+	Pages Unit = iota //图书、分卷，按页计数，结束时输出一行小结
+	Bytes             //单个文件，按字节计数，结束后直接移除
+)
+
+// maxLines 终端中最多显示的任务行数
+const maxLines = 8
+
+// Default 下载器与各站点共用的进度显示
+var Default = New(os.Stdout, isTerminal(os.Stdout))
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// SetOutput 改为输出到 f，如 stdout 用于事件流时改用 stderr
+func SetOutput(f *os.File) {
+	Default = New(f, isTerminal(f))
+}
+
+// Silence 不再输出进度行与汇总（--quiet）
+func Silence() {
+	Default = New(io.Discard, false)
+}
+
+// Printf 通过 Default 输出一行，不会打乱进度行
+func Printf(format string, a ...interface{}) {
+	s := fmt.Sprintf(format, a...)
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, _ = Default.Write([]byte(s))
+}
+
+type Reporter struct {
+	Interval time.Duration //刷新间隔，终端默认 200ms，非终端默认 10s
+
+	mu      sync.Mutex
+	out     io.Writer
+	tty     bool
+	width   func() int
+	jobs    []*Job
+	lines   int //上次绘制的行数
+	running bool
+
+	pages      int64 //已完成页数
+	pagesTotal int64
+	files      int64 //已完成的单个文件
+	bytes      int64
+
+	// 速率取最近几次刷新的滑动平均
+	last       time.Time
+	lastPages  int64
+	lastBytes  int64
+	pageRate   float64
+	byteRate   float64
+	lastLogged string
+}
+
+// New out 为终端时 tty 传 true
+func New(out io.Writer, tty bool) *Reporter {
+	r := &Reporter{out: out, tty: tty, Interval: 10 * time.Second}
+	if tty {
+		r.Interval = 200 * time.Millisecond
+		r.width = func() int {
+			if f, ok := out.(*os.File); ok {
+				if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 0 {
+					return w
+				}
+			}
+			return 100
+		}
+	}
+	return r
+}
+
+// Terminal 是否在终端中刷新进度行
+func (r *Reporter) Terminal() bool {
+	return r.tty
+}
+
+// Job 一个进行中的任务
+type Job struct {
+	r     *Reporter
+	name  string
+	unit  Unit
+	start time.Time
+	total int64
+	done  int64
+	bytes int64
+	ended int32
+}
+
+// Start 开始一个任务，total 未知时传 0
+func (r *Reporter) Start(name string, unit Unit, total int64) *Job {
+	j := &Job{r: r, name: name, unit: unit, start: time.Now(), total: total}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs = append(r.jobs, j)
+	if unit == Pages {
+		atomic.AddInt64(&r.pagesTotal, total)
+	}
+	if !r.running {
+		r.running = true
+		r.last = time.Now()
+		go r.loop()
+	}
+	return j
+}
+
+// SetTotal 修改任务总量，例如逐卷解析出页数后
+func (j *Job) SetTotal(n int64) {
+	old := atomic.SwapInt64(&j.total, n)
+	if j.unit == Pages {
+		atomic.AddInt64(&j.r.pagesTotal, n-old)
+	}
+}
+
+// Add 完成 n 页；Bytes 任务为 n 字节
+func (j *Job) Add(n int64) {
+	atomic.AddInt64(&j.done, n)
+	if j.unit == Pages {
+		atomic.AddInt64(&j.r.pages, n)
+	} else {
+		atomic.AddInt64(&j.bytes, n)
+		atomic.AddInt64(&j.r.bytes, n)
+	}
+}
+
+// Set 设置已完成量，用于只知道当前大小的下载
+func (j *Job) Set(n int64) {
+	j.Add(n - atomic.LoadInt64(&j.done))
+}
+
+// AddBytes 记录 Pages 任务下载的字节数
+func (j *Job) AddBytes(n int64) {
+	if j.unit == Bytes {
+		j.Add(n)
+		return
+	}
+	atomic.AddInt64(&j.bytes, n)
+	atomic.AddInt64(&j.r.bytes, n)
+}
+
+// Done 结束任务，可重复调用。跳过的页不再计入汇总的总页数
+func (j *Job) Done() {
+	if !atomic.CompareAndSwapInt32(&j.ended, 0, 1) {
+		return
+	}
+	r := j.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range r.jobs {
+		if v == j {
+			r.jobs = append(r.jobs[:i], r.jobs[i+1:]...)
+			break
+		}
+	}
+	done, total := atomic.LoadInt64(&j.done), atomic.LoadInt64(&j.total)
+	if j.unit == Bytes {
+		atomic.AddInt64(&r.files, 1)
+		return
+	}
+	if total > done {
+		atomic.AddInt64(&r.pagesTotal, done-total)
+	}
+	r.clear()
+	s := i18n.T("progress.job_done", j.name, done, byteString(atomic.LoadInt64(&j.bytes)), duration(time.Since(j.start))) + "\n"
+	if !r.tty {
+		s = time.Now().Format("2006/01/02 15:04:05 ") + s
+	}
+	_, _ = io.WriteString(r.out, s)
+	r.draw()
+}
+
+// Write 供 log.SetOutput 等使用：先清除进度行，输出后重新绘制
+func (r *Reporter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clear()
+	n, err := r.out.Write(p)
+	r.draw()
+	return n, err
+}
+
+// Stats 汇总
+type Stats struct {
+	Pages      int64
+	PagesTotal int64
+	Files      int64
+	Bytes      int64
+	Rate       float64       //字节/秒
+	ETA        time.Duration //未知时为 0
+	Active     int
+}
+
+func (r *Reporter) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats()
+}
+
+func (r *Reporter) stats() Stats {
+	s := Stats{
+		Pages:      atomic.LoadInt64(&r.pages),
+		PagesTotal: atomic.LoadInt64(&r.pagesTotal),
+		Files:      atomic.LoadInt64(&r.files),
+		Bytes:      atomic.LoadInt64(&r.bytes),
+		Rate:       r.byteRate,
+		Active:     len(r.jobs),
+	}
+	if left := s.PagesTotal - s.Pages; left > 0 && r.pageRate > 0 {
+		s.ETA = time.Duration(float64(left) / r.pageRate * float64(time.Second))
+	} else if r.byteRate > 0 {
+		var rest int64
+		for _, j := range r.jobs {
+			if j.unit == Bytes {
+				rest += max(0, atomic.LoadInt64(&j.total)-atomic.LoadInt64(&j.done))
+			}
+		}
+		s.ETA = time.Duration(float64(rest) / r.byteRate * float64(time.Second))
+	}
+	return s
+}
+
+// Flush 立即刷新一次
+func (r *Reporter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sample()
+	r.clear()
+	r.draw()
+	if !r.tty {
+		r.log()
+	}
+}
+
+func (r *Reporter) loop() {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.mu.Lock()
+		r.sample()
+		if len(r.jobs) == 0 {
+			r.clear()
+			r.running = false
+			r.mu.Unlock()
+			return
+		}
+		if r.tty {
+			r.clear()
+			r.draw()
+		} else {
+			r.log()
+		}
+		r.mu.Unlock()
+	}
+}
+
+// sample 更新速率
+func (r *Reporter) sample() {
+	now := time.Now()
+	dt := now.Sub(r.last).Seconds()
+	if dt <= 0 {
+		return
+	}
+	pages, bytes := atomic.LoadInt64(&r.pages), atomic.LoadInt64(&r.bytes)
+	pr, br := float64(pages-r.lastPages)/dt, float64(bytes-r.lastBytes)/dt
+	if r.pageRate == 0 && r.byteRate == 0 {
+		r.pageRate, r.byteRate = pr, br
+	} else {
+		// 终端刷新快，平滑系数小一些
+		a := 0.3
+		if r.tty {
+			a = 0.1
+		}
+		r.pageRate = r.pageRate*(1-a) + pr*a
+		r.byteRate = r.byteRate*(1-a) + br*a
+	}
+	r.last, r.lastPages, r.lastBytes = now, pages, bytes
+}
+
+// clear 擦除上次绘制的进度行
+func (r *Reporter) clear() {
+	if !r.tty || r.lines == 0 {
+		return
+	}
+	_, _ = io.WriteString(r.out, strings.Repeat("\x1b[1A\x1b[2K", r.lines))
+	r.lines = 0
+}
+
+// draw 终端中绘制任务行与汇总行，每行截断到终端宽度，避免折行后无法擦除
+func (r *Reporter) draw() {
+	if !r.tty || len(r.jobs) == 0 {
+		return
+	}
+	width := r.width() - 1
+	var sb strings.Builder
+	n := 0
+	for i, j := range r.jobs {
+		if i == maxLines {
+			sb.WriteString("  " + i18n.T("progress.more", len(r.jobs)-maxLines) + "\n")
+			n++
+			break
+		}
+		sb.WriteString(truncate(j.line(), width) + "\n")
+		n++
+	}
+	sb.WriteString(truncate(r.stats().line(), width) + "\n")
+	r.lines = n + 1
+	_, _ = io.WriteString(r.out, sb.String())
+}
+
+// log 非终端时输出一行汇总与各图书任务，内容不变时不重复输出
+func (r *Reporter) log() {
+	var parts []string
+	for _, j := range r.jobs {
+		if j.unit == Pages {
+			parts = append(parts, fmt.Sprintf("%s %d/%d", j.name, atomic.LoadInt64(&j.done), atomic.LoadInt64(&j.total)))
+		}
+	}
+	s := r.stats().line()
+	if len(parts) > 0 {
+		s += "  [" + strings.Join(parts, ", ") + "]"
+	}
+	if s == r.lastLogged {
+		return
+	}
+	r.lastLogged = s
+	_, _ = io.WriteString(r.out, time.Now().Format("2006/01/02 15:04:05 ")+s+"\n")
+}
+
+func (j *Job) line() string {
+	done, total := atomic.LoadInt64(&j.done), atomic.LoadInt64(&j.total)
+	name := truncate(j.name, 24)
+	if j.unit == Bytes {
+		if total <= 0 {
+			return fmt.Sprintf("  %-24s %s", name, byteString(done))
+		}
+		return fmt.Sprintf("  %-24s %s %3d%%  %s/%s", name, bar(done, total, 20), done*100/total, byteString(done), byteString(total))
+	}
+	s := fmt.Sprintf("  %-24s %s %s", name, bar(done, total, 20), i18n.T("progress.pages", done, total))
+	if b := atomic.LoadInt64(&j.bytes); b > 0 {
+		s += "  " + byteString(b)
+	}
+	return s
+}
+
+func (s Stats) line() string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("progress.total") + " ")
+	if s.PagesTotal > 0 {
+		sb.WriteString(i18n.T("progress.pages", s.Pages, s.PagesTotal))
+	} else {
+		sb.WriteString(i18n.T("progress.files", s.Files))
+	}
+	sb.WriteString("  " + byteString(s.Bytes))
+	sb.WriteString("  " + byteString(int64(s.Rate)) + "/s")
+	if s.ETA > 0 {
+		sb.WriteString("  " + i18n.T("progress.eta", duration(s.ETA)))
+	} else {
+		sb.WriteString("  " + i18n.T("progress.eta", "--"))
+	}
+	return sb.String()
+}
+
+func bar(done, total int64, width int) string {
+	if total <= 0 {
+		return "[" + strings.Repeat(" ", width) + "]"
+	}
+	n := int(min(done, total) * int64(width) / total)
+	s := strings.Repeat("=", n)
+	if n < width {
+		s += ">" + strings.Repeat(" ", width-n-1)
+	}
+	return "[" + s + "]"
+}
+
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB"}
+
+func byteString(n int64) string {
+	size := float64(n)
+	i := 0
+	for size >= 1000 && i < len(byteUnits)-1 {
+		size /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", size, byteUnits[i])
+}
+
+func duration(d time.Duration) string {
+	if d >= time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// truncate 按显示宽度截断，中日文字符占两列
+func truncate(s string, width int) string {
+	if uniseg.StringWidth(s) <= width {
+		return s
+	}
+	var sb strings.Builder
+	w := 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		if w+g.Width() > width-1 {
+			break
+		}
+		w += g.Width()
+		sb.WriteString(g.Str())
+	}
+	return sb.String() + "…"
+}
@@ -0,0 +1,78 @@
+package progress_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"bookget/pkg/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	r := progress.New(&bytes.Buffer{}, false)
+	r.Interval = time.Hour
+	book := r.Start("book", progress.Pages, 10)
+	book.Add(3)
+	book.AddBytes(2000)
+	file := r.Start("0001.jpg", progress.Bytes, 500)
+	file.Set(200)
+	file.Set(500)
+	file.Done()
+	file.Done()
+
+	s := r.Stats()
+	assert.Equal(t, int64(3), s.Pages)
+	assert.Equal(t, int64(10), s.PagesTotal)
+	assert.Equal(t, int64(2500), s.Bytes)
+	assert.Equal(t, int64(1), s.Files)
+	assert.Equal(t, 1, s.Active)
+
+	// 跳过的页不计入总页数
+	book.Done()
+	s = r.Stats()
+	assert.Equal(t, int64(3), s.PagesTotal)
+	assert.Equal(t, 0, s.Active)
+}
+
+func TestPlainLog(t *testing.T) {
+	var out bytes.Buffer
+	r := progress.New(&out, false)
+	r.Interval = time.Hour
+	job := r.Start("book", progress.Pages, 4)
+	job.Add(1)
+	r.Flush()
+	r.Flush()
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"), "unchanged progress is logged once")
+	assert.Contains(t, out.String(), "合计 1/4 页")
+	assert.Contains(t, out.String(), "[book 1/4]")
+	assert.NotContains(t, out.String(), "\x1b[")
+
+	_, _ = r.Write([]byte("message\n"))
+	job.Done()
+	assert.Contains(t, out.String(), "message\n")
+	assert.Contains(t, out.String(), "book  完成 1 页")
+}
+
+func TestTerminal(t *testing.T) {
+	var out bytes.Buffer
+	r := progress.New(&out, true)
+	r.Interval = time.Hour
+	job := r.Start("book", progress.Pages, 2)
+	file := r.Start("0001.jpg", progress.Bytes, 1000)
+	file.Set(500)
+	r.Flush()
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "0/2 页")
+	assert.Contains(t, lines[1], " 50%  500 B/1.0 KB")
+	assert.Contains(t, lines[2], "合计 0/2 页")
+
+	out.Reset()
+	_, _ = r.Write([]byte("message\n"))
+	assert.True(t, strings.HasPrefix(out.String(), strings.Repeat("\x1b[1A\x1b[2K", 3)+"message\n"))
+
+	file.Done()
+	job.Done()
+}
@@ -3,6 +3,7 @@ package queue
 import (
 	"log"
 	"sync"
+	"sync/atomic"
 )
 
 // ConcurrentQueue 提供带有限制的并发执行队列
@@ -10,6 +11,7 @@ type ConcurrentQueue struct {
 	capacity int            // 最大并发数
 	sem      chan struct{}  // 信号量通道，用于控制并发
 	wg       sync.WaitGroup // 等待所有任务完成
+	pending  int64          // 等待槽位的任务数
 }
 
 // NewConcurrentQueue 创建一个新的并发队列
@@ -28,8 +30,10 @@ func NewConcurrentQueue(capacity int) *ConcurrentQueue {
 // 如果队列已满，会阻塞直到有可用槽位
 func (q *ConcurrentQueue) Go(task func()) {
 	q.wg.Add(1)
+	atomic.AddInt64(&q.pending, 1)
 	go func() {
 		q.sem <- struct{}{} // 获取信号量
+		atomic.AddInt64(&q.pending, -1)
 		defer func() {
 			<-q.sem // 释放信号量
 			q.wg.Done()
@@ -72,6 +76,11 @@ func (q *ConcurrentQueue) TryGo(task func()) bool {
 	}
 }
 
+// Pending 返回已提交、等待槽位的任务数量
+func (q *ConcurrentQueue) Pending() int {
+	return int(atomic.LoadInt64(&q.pending))
+}
+
 // CurrentCount 返回当前正在执行的任务数量
 func (q *ConcurrentQueue) CurrentCount() int {
 	return len(q.sem)
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"bookget/pkg/hathitrust"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Hathitrust HathiTrust 全文检索，只查可全文浏览的（lmt=ft）：
+// https://babel.hathitrust.org/cgi/ls?q1=...&anyall1=phrase&lmt=ft&pn=1&sz=20
+type Hathitrust struct{}
+
+var (
+	htTitleRe = regexp.MustCompile(`(?s)<h3[^>]*>(.*?)</h3>`)
+	htDateRe  = regexp.MustCompile(`(?s)<dt[^>]*>\s*Published\s*</dt>\s*<dd[^>]*>(.*?)</dd>`)
+	htIdRe    = regexp.MustCompile(`/cgi/pt\?id=([^&;"'#\s]+)`)
+)
+
+func init() {
+	Register(Hathitrust{}, "babel.hathitrust.org")
+}
+
+func (Hathitrust) Request(query string, page int) Request {
+	q := url.Values{"q1": {query}, "anyall1": {"phrase"}, "lmt": {"ft"}, "pn": {fmt.Sprint(page)}, "sz": {fmt.Sprint(PageSize)}}
+	return Request{Url: "https://babel.hathitrust.org/cgi/ls?" + q.Encode()}
+}
+
+// Parse 检索结果是网页，每条结果一个 <article>，一条结果即一册
+func (Hathitrust) Parse(body []byte) ([]Result, error) {
+	var results []Result
+	seen := map[string]bool{}
+	for _, block := range strings.Split(string(body), "<article")[1:] {
+		m := htIdRe.FindStringSubmatch(block)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		r := Result{Volumes: 1, Url: hathitrust.VolumeUrl(m[1])}
+		if t := htTitleRe.FindStringSubmatch(block); t != nil {
+			r.Title = clean(t[1])
+		}
+		if d := htDateRe.FindStringSubmatch(block); d != nil {
+			r.Date = clean(d[1])
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// Loc 美国国会图书馆 https://www.loc.gov/search/?q=...&fo=json&sp=1&c=20
+type Loc struct{}
+
+var locItemRe = regexp.MustCompile(`^https?://www\.loc\.gov/item/[A-Za-z0-9]+/?$`)
+
+func init() {
+	Register(Loc{}, "www.loc.gov")
+}
+
+func (Loc) Request(query string, page int) Request {
+	q := url.Values{"q": {query}, "fo": {"json"}, "sp": {fmt.Sprint(page)}, "c": {fmt.Sprint(PageSize)}}
+	return Request{Url: "https://www.loc.gov/search/?" + q.Encode()}
+}
+
+// Parse 只保留 /item/ 开头的结果（合集、网页等无法下载）；册数为 resources 的个数
+func (Loc) Parse(body []byte) ([]Result, error) {
+	var resp struct {
+		Results []struct {
+			Title     string            `json:"title"`
+			Date      string            `json:"date"`
+			Url       string            `json:"url"`
+			Resources []json.RawMessage `json:"resources"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, v := range resp.Results {
+		if !locItemRe.MatchString(v.Url) {
+			continue
+		}
+		results = append(results, Result{
+			Title:   clean(v.Title),
+			Date:    v.Date,
+			Volumes: len(v.Resources),
+			Url:     v.Url,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// NlcGuji 国家图书馆·中华古籍智慧化服务平台，与下载同属 api/anc 接口：
+// POST https://guji.nlc.cn/api/anc/ancSearch {"keyword":"...","pageNum":1,"pageSize":20}
+type NlcGuji struct{}
+
+func init() {
+	Register(NlcGuji{}, "guji.nlc.cn")
+}
+
+func (NlcGuji) Request(query string, page int) Request {
+	body, _ := json.Marshal(map[string]interface{}{
+		"keyword":  query,
+		"pageNum":  page,
+		"pageSize": PageSize,
+	})
+	return Request{
+		Url:         "https://guji.nlc.cn/api/anc/ancSearch",
+		Body:        body,
+		ContentType: "application/json",
+	}
+}
+
+// Parse {"code":200,"data":{"total":1,"list":[{"metadataId":...,"title":...,"dateOfPublication":...,"volumeNum":...}]}}
+func (NlcGuji) Parse(body []byte) ([]Result, error) {
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			List []struct {
+				MetadataId        json.Number `json:"metadataId"`
+				Title             string      `json:"title"`
+				DateOfPublication string      `json:"dateOfPublication"`
+				VolumeNum         int         `json:"volumeNum"`
+			} `json:"list"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 && resp.Code != 200 {
+		return nil, fmt.Errorf("guji.nlc.cn: code %d, %s", resp.Code, resp.Msg)
+	}
+	var results []Result
+	for _, v := range resp.Data.List {
+		if v.MetadataId == "" {
+			continue
+		}
+		results = append(results, Result{
+			Title:   clean(v.Title),
+			Date:    v.DateOfPublication,
+			Volumes: v.VolumeNum,
+			Url:     "https://guji.nlc.cn/guji/pmgs/book/turningPage?metadataId=" + url.QueryEscape(v.MetadataId.String()),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,96 @@
+// Package search 各站点检索接口：按关键词查询，返回可直接交给 bookget 下载的图书地址
+package search
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PageSize 每页结果数
+const PageSize = 20
+
+// Result 一条检索结果
+type Result struct {
+	Title   string
+	Date    string
+	Volumes int    //册数，未知时为 0
+	Url     string //规范化的图书地址，可写入 urls.txt 批量下载
+}
+
+// Request 检索请求；Body 不为空时用 POST
+type Request struct {
+	Url         string
+	Body        []byte
+	ContentType string
+}
+
+// Adapter 一个站点的检索适配：page 从 1 开始
+type Adapter interface {
+	Request(query string, page int) Request
+	Parse(body []byte) ([]Result, error)
+}
+
+var (
+	adapters = map[string]Adapter{}
+	tagRe    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Register 为站点（与 router 中的 host 相同）注册检索适配
+func Register(a Adapter, hosts ...string) {
+	for _, h := range hosts {
+		adapters[h] = a
+	}
+}
+
+// Lookup site 可以是 host，也可以是该站点的任意网址；省略 www. 亦可
+func Lookup(site string) (Adapter, bool) {
+	host := site
+	if u, err := url.Parse(site); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "/"))
+	if a, ok := adapters[host]; ok {
+		return a, true
+	}
+	a, ok := adapters["www."+host]
+	return a, ok
+}
+
+// Sites 支持检索的站点，按字母排序
+func Sites() []string {
+	sites := make([]string, 0, len(adapters))
+	for h := range adapters {
+		sites = append(sites, h)
+	}
+	sort.Strings(sites)
+	return sites
+}
+
+// Write 每条结果输出两行：# 书名  日期  册数，然后是地址。
+// urls.txt 只读取 http 开头的行，因此输出可以直接重定向为批量下载文件；urlsOnly 时只输出地址
+func Write(w io.Writer, results []Result, urlsOnly bool) {
+	for _, r := range results {
+		if !urlsOnly {
+			line := []string{r.Title}
+			if r.Date != "" {
+				line = append(line, r.Date)
+			}
+			if r.Volumes > 0 {
+				line = append(line, fmt.Sprintf("%d vol", r.Volumes))
+			}
+			fmt.Fprintf(w, "# %s\n", strings.Join(line, "  "))
+		}
+		fmt.Fprintln(w, r.Url)
+	}
+}
+
+// clean 去掉标题中的高亮标签与多余空白
+func clean(s string) string {
+	s = tagRe.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
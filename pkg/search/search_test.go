@@ -0,0 +1,97 @@
+package search_test
+
+import (
+	"bytes"
+	"testing"
+
+	"bookget/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, site, body string) []search.Result {
+	a, ok := search.Lookup(site)
+	require.True(t, ok, site)
+	results, err := a.Parse([]byte(body))
+	require.NoError(t, err)
+	return results
+}
+
+func TestLookup(t *testing.T) {
+	_, ok := search.Lookup("loc.gov")
+	assert.True(t, ok)
+	_, ok = search.Lookup("https://babel.hathitrust.org/cgi/pt?id=mdp.39015")
+	assert.True(t, ok)
+	_, ok = search.Lookup("www.example.org")
+	assert.False(t, ok)
+
+	a, _ := search.Lookup("guji.nlc.cn")
+	req := a.Request("论语", 2)
+	assert.Equal(t, "https://guji.nlc.cn/api/anc/ancSearch", req.Url)
+	assert.JSONEq(t, `{"keyword":"论语","pageNum":2,"pageSize":20}`, string(req.Body))
+
+	a, _ = search.Lookup("www.loc.gov")
+	assert.Equal(t, "https://www.loc.gov/search/?c=20&fo=json&q=tao+te+ching&sp=1", a.Request("tao te ching", 1).Url)
+}
+
+func TestWzlib(t *testing.T) {
+	results := parse(t, "oyjy.wzlib.cn", `{"Data":{"total":2,"list":[
+{"_id":"5f1e0a","dc_title":"<em>永嘉</em>郡志","dc_date":"清光緒","relate_count":4},
+{"_id":"","dc_title":"无 id"}]}}`)
+	assert.Equal(t, []search.Result{
+		{Title: "永嘉郡志", Date: "清光緒", Volumes: 4, Url: "https://oyjy.wzlib.cn/detail?id=5f1e0a"},
+	}, results)
+}
+
+func TestNlcGuji(t *testing.T) {
+	results := parse(t, "guji.nlc.cn", `{"code":200,"msg":"ok","data":{"total":1,"list":[
+{"metadataId":1001234,"title":"論語注疏","dateOfPublication":"明嘉靖","volumeNum":10}]}}`)
+	assert.Equal(t, []search.Result{
+		{Title: "論語注疏", Date: "明嘉靖", Volumes: 10, Url: "https://guji.nlc.cn/guji/pmgs/book/turningPage?metadataId=1001234"},
+	}, results)
+
+	a, _ := search.Lookup("guji.nlc.cn")
+	_, err := a.Parse([]byte(`{"code":500,"msg":"busy"}`))
+	assert.Error(t, err)
+}
+
+func TestLoc(t *testing.T) {
+	results := parse(t, "www.loc.gov", `{"results":[
+{"title":"Lao zi dao de jing","date":"1800","url":"https://www.loc.gov/item/2012402381/","resources":[{},{}]},
+{"title":"Asian collection","url":"https://www.loc.gov/collections/asian/"}]}`)
+	assert.Equal(t, []search.Result{
+		{Title: "Lao zi dao de jing", Date: "1800", Volumes: 2, Url: "https://www.loc.gov/item/2012402381/"},
+	}, results)
+}
+
+func TestHathitrust(t *testing.T) {
+	page := `<main>
+<article class="record">
+  <h3 class="record-title"><span class="title">The Chinese classics :</span> with a translation</h3>
+  <dl class="metadata"><div><dt>Published</dt><dd>1861</dd></div></dl>
+  <a href="https://babel.hathitrust.org/cgi/pt?id=hvd.32044&amp;q1=confucius">Full view</a>
+  <a href="/cgi/pt?id=hvd.32044;seq=7">p. 7</a>
+</article>
+<article class="record">
+  <h3>Lun yu</h3>
+  <a href="/cgi/pt?id=mdp.39015;seq=1">Full view</a>
+</article>
+</main>`
+	assert.Equal(t, []search.Result{
+		{Title: "The Chinese classics : with a translation", Date: "1861", Volumes: 1, Url: "https://babel.hathitrust.org/cgi/pt?id=hvd.32044"},
+		{Title: "Lun yu", Volumes: 1, Url: "https://babel.hathitrust.org/cgi/pt?id=mdp.39015"},
+	}, parse(t, "babel.hathitrust.org", page))
+}
+
+func TestWrite(t *testing.T) {
+	results := []search.Result{
+		{Title: "論語注疏", Date: "明嘉靖", Volumes: 10, Url: "https://example.org/1"},
+		{Title: "Lun yu", Url: "https://example.org/2"},
+	}
+	var buf bytes.Buffer
+	search.Write(&buf, results, false)
+	assert.Equal(t, "# 論語注疏  明嘉靖  10 vol\nhttps://example.org/1\n# Lun yu\nhttps://example.org/2\n", buf.String())
+	buf.Reset()
+	search.Write(&buf, results, true)
+	assert.Equal(t, "https://example.org/1\nhttps://example.org/2\n", buf.String())
+}
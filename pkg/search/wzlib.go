@@ -0,0 +1,52 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Wzlib 温州图书馆·瓯越记忆 https://oyjy.wzlib.cn/api/search/v1/resource/?keyword=...&page=1&size=20
+type Wzlib struct{}
+
+func init() {
+	Register(Wzlib{}, "oyjy.wzlib.cn")
+}
+
+func (Wzlib) Request(query string, page int) Request {
+	q := url.Values{"keyword": {query}, "page": {fmt.Sprint(page)}, "size": {fmt.Sprint(PageSize)}}
+	return Request{Url: "https://oyjy.wzlib.cn/api/search/v1/resource/?" + q.Encode()}
+}
+
+// Parse 结果在 Data.list 或 Data.items 中，字段与 resource/{id} 相同
+func (Wzlib) Parse(body []byte) ([]Result, error) {
+	type item struct {
+		Id          string `json:"_id"`
+		DcTitle     string `json:"dc_title"`
+		DcDate      string `json:"dc_date"`
+		DcPublisher string `json:"dc_publisher"`
+		RelateCount int    `json:"relate_count"`
+	}
+	var resp struct {
+		Data struct {
+			List  []item `json:"list"`
+			Items []item `json:"items"`
+		} `json:"Data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, v := range append(resp.Data.List, resp.Data.Items...) {
+		if v.Id == "" {
+			continue
+		}
+		results = append(results, Result{
+			Title:   clean(v.DcTitle),
+			Date:    v.DcDate,
+			Volumes: v.RelateCount,
+			Url:     "https://oyjy.wzlib.cn/detail?id=" + url.QueryEscape(v.Id),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,112 @@
+// Package serial 报纸、期刊的日期与期号：把网站上各种写法的日期规范为 2006-01-02，从期名中取期号，按期号范围筛选
+package serial
+
+import (
+	"bookget/pkg/i18n"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	digitsRe = regexp.MustCompile(`\d+`)
+	issueRe  = regexp.MustCompile(`(\d+)\s*[期号號]|(?i:\bno)\.?\s*(\d+)`)
+)
+
+// Year 取第一个四位数字作为年份，如 “1937年” → 1937；没有时返回空
+func Year(s string) string {
+	for _, d := range digitsRe.FindAllString(s, -1) {
+		if len(d) == 4 {
+			return d
+		}
+	}
+	return ""
+}
+
+// Date 由年、月与网站给出的日期拼成 2006-01-02。day 可以是完整日期（1937-01-05、19370105、1937年1月5日）、
+// 月日（01-05、0105）或只有日（5）；无法识别时返回空
+func Date(year, month, day string) string {
+	g := digitsRe.FindAllString(day, -1)
+	switch {
+	case len(g) >= 3:
+		year, month, day = g[0], g[1], g[2]
+	case len(g) == 2:
+		month, day = g[0], g[1]
+	case len(g) == 1 && len(g[0]) == 8:
+		year, month, day = g[0][:4], g[0][4:6], g[0][6:]
+	case len(g) == 1 && len(g[0]) == 4:
+		month, day = g[0][:2], g[0][2:]
+	case len(g) == 1:
+		day = g[0]
+	default:
+		return ""
+	}
+	y, _ := strconv.Atoi(Year(year))
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if y == 0 || m < 1 || m > 12 || d < 1 || d > 31 {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", y, m, d)
+}
+
+// Month 月份补足两位，如 3 → 03
+func Month(m string) string {
+	if n, err := strconv.Atoi(strings.TrimSpace(m)); err == nil {
+		return fmt.Sprintf("%02d", n)
+	}
+	return m
+}
+
+// IssueNumber 期名中的期号：优先取 “期”“号” 前或 “No.” 后的数字，如 “第3卷第12期(1937.5)” → 12、“Vol.2 No.7” → 7；
+// 否则取最后一个数字；没有数字时用 pos（在当年的序号，从 1 开始）
+func IssueNumber(label string, pos int) int {
+	if m := issueRe.FindAllStringSubmatch(label, -1); len(m) > 0 {
+		last := m[len(m)-1]
+		n, _ := strconv.Atoi(last[1] + last[2])
+		return n
+	}
+	g := digitsRe.FindAllString(label, -1)
+	if len(g) == 0 {
+		return pos
+	}
+	n, _ := strconv.Atoi(g[len(g)-1])
+	return n
+}
+
+// Range 期号范围，零值表示不限
+type Range struct {
+	Start, End int //End 为 0 表示到最后一期
+}
+
+// ParseRange 解析 --issue：5 | 3:10 | 3:
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Range{}, nil
+	}
+	a, b, found := strings.Cut(s, ":")
+	start, err := strconv.Atoi(a)
+	if err != nil || start < 1 {
+		return Range{}, i18n.Errorf("serial.bad_range", s)
+	}
+	r := Range{Start: start, End: start}
+	if found {
+		r.End = 0
+		if b != "" {
+			if r.End, err = strconv.Atoi(b); err != nil || r.End < start {
+				return Range{}, i18n.Errorf("serial.bad_range", s)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Contains n 是否在范围内
+func (r Range) Contains(n int) bool {
+	if r.Start == 0 {
+		return true
+	}
+	return n >= r.Start && (r.End == 0 || n <= r.End)
+}
@@ -0,0 +1,64 @@
+package serial_test
+
+import (
+	"testing"
+
+	"bookget/pkg/serial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDate(t *testing.T) {
+	for day, want := range map[string]string{
+		"1937-01-05": "1937-01-05",
+		"19370105":   "1937-01-05",
+		"1937年1月5日":  "1937-01-05",
+		"01-05":      "1937-01-05",
+		"0105":       "1937-01-05",
+		"5":          "1937-01-05",
+		"5日":         "1937-01-05",
+		"":           "",
+		"1937-13-01": "",
+	} {
+		assert.Equal(t, want, serial.Date("1937", "1", day), day)
+	}
+	assert.Equal(t, "1937", serial.Year("1937年"))
+	assert.Equal(t, "", serial.Year("民国"))
+	assert.Equal(t, "03", serial.Month("3"))
+	assert.Equal(t, "12", serial.Month("12"))
+}
+
+func TestIssueNumber(t *testing.T) {
+	assert.Equal(t, 12, serial.IssueNumber("第12期", 1))
+	assert.Equal(t, 3, serial.IssueNumber("1卷3期", 1))
+	assert.Equal(t, 4, serial.IssueNumber("创刊号", 4))
+	assert.Equal(t, 12, serial.IssueNumber("第3卷第12期(1937.5)", 1))
+	assert.Equal(t, 7, serial.IssueNumber("Vol.2 No.7 (1936)", 1))
+	assert.Equal(t, 6, serial.IssueNumber("1937年第6號", 1))
+}
+
+func TestRange(t *testing.T) {
+	r, err := serial.ParseRange("")
+	require.NoError(t, err)
+	assert.True(t, r.Contains(100))
+
+	r, err = serial.ParseRange("5")
+	require.NoError(t, err)
+	assert.True(t, r.Contains(5))
+	assert.False(t, r.Contains(6))
+
+	r, err = serial.ParseRange("3:10")
+	require.NoError(t, err)
+	assert.False(t, r.Contains(2))
+	assert.True(t, r.Contains(10))
+	assert.False(t, r.Contains(11))
+
+	r, err = serial.ParseRange("3:")
+	require.NoError(t, err)
+	assert.True(t, r.Contains(300))
+
+	for _, s := range []string{"x", "0", "5:3", "3:y"} {
+		_, err = serial.ParseRange(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,340 @@
+// Package sniffer 分析网页中的查看器，找出可以下载的资源：
+// IIIF manifest、OpenSeadragon 的 tileSources（DZI、IIIF info.json）、Zoomify 的 ImageProperties.xml、
+// IIPImage 的 FIF= 地址、pdf.js 的 viewer.html?file= / viewer.php?pdf= 链接，以及普通的 <img> 图片列表
+package sniffer
+
+import (
+	"bookget/pkg/tiles"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Kind 资源类型
+type Kind string
+
+const (
+	Manifest  Kind = "iiif-manifest" //IIIF Presentation API manifest
+	IIIFImage Kind = "iiif-image"    //IIIF Image API info.json
+	DZI       Kind = "dzi"           //Deep Zoom .dzi/.xml，或 JSON 格式的 DZI
+	Zoomify   Kind = "zoomify"       //Zoomify ImageProperties.xml
+	IIP       Kind = "iip"           //IIPImage 服务器?FIF=图片
+	PDF       Kind = "pdf"
+	Image     Kind = "image" //普通图片
+)
+
+// Find 一个找到的资源，Url 已解析为绝对地址
+type Find struct {
+	Kind Kind
+	Url  string
+}
+
+// MinGallery 同一目录下至少有这么多张 <img> 才当作图片列表，避免把图标、logo 当成书页
+const MinGallery = 3
+
+var (
+	reManifestParam = regexp.MustCompile(`[?&;]manifest=([^&"'\s<>]+)`)
+	reManifestAttr  = regexp.MustCompile(`(?i)(?:href|src|data-uri)\s*=\s*["']([^"']+)["']`)
+	reManifestVar   = regexp.MustCompile(`(?i)manifest(?:Id|Uri|Url)?["']?\s*[:=]\s*["']([^"']+)["']`)
+	reManifestPath  = regexp.MustCompile(`(?i)(?:^|[/_.-])manifest(?:\.json)?(?:$|\?)`)
+	reWebManifest   = regexp.MustCompile(`(?i)<link\s[^>]*\brel\s*=\s*["']?manifest\b[^>]*>`) //PWA 的 <link rel="manifest">
+
+	reTileSources = regexp.MustCompile(`tileSources["']?\s*[:=]\s*`)
+	reQuoted      = regexp.MustCompile(`["']([^"']+)["']`)
+	reIIIFId      = regexp.MustCompile(`["']?@?id["']?\s*:\s*["']([^"']+)["']`)
+	reTilesUrl    = regexp.MustCompile(`["']?tilesUrl["']?\s*:\s*["']([^"']+)["']`)
+
+	reZoomifyXml  = regexp.MustCompile(`(?i)["'=(]\s*([^"'\s=<>()]*ImageProperties\.xml)`)
+	reZoomifyPath = regexp.MustCompile(`(?i)zoomifyImagePath=([^&"'\s<>]+)`)
+	reZoomifyShow = regexp.MustCompile(`Z\.showImage\(\s*["'][^"']*["']\s*,\s*["']([^"']+)["']`)
+
+	reIIPUrl    = regexp.MustCompile(`[^"'\s<>()]*\?FIF=[^"'\s<>()]+`)
+	reIIPMoo    = regexp.MustCompile(`IIPMooViewer\s*\(`)
+	reIIPServer = regexp.MustCompile(`server\s*:\s*["']([^"']+)["']`)
+	reIIPImage  = regexp.MustCompile(`image\s*:\s*`)
+
+	rePdfViewer = regexp.MustCompile(`(?i)[^"'\s<>()]*viewer\.(?:html|php)\?[^"'\s<>()]+`)
+	rePdfLink   = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+\.pdf(?:\?[^"']*)?)["']`)
+
+	reImg     = regexp.MustCompile(`(?i)<img\s[^>]+>`)
+	reImgAttr = regexp.MustCompile(`(?i)\s(data-original|data-src|src)\s*=\s*["']([^"']+)["']`)
+)
+
+var imageExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".tif": true, ".tiff": true, ".jp2": true,
+}
+
+// Analyze 分析 pageUrl 的网页内容 body，按 manifest、IIIF 图像、DZI、Zoomify、IIPImage、PDF 的顺序返回找到的资源。
+// 只有找不到任何查看器时才返回 <img> 图片列表（查看器页面上的图片多半是缩略图）
+func Analyze(pageUrl string, body []byte) []Find {
+	base, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil
+	}
+	r := &result{base: base, seen: map[string]bool{}}
+	text := strings.ReplaceAll(string(body), `\/`, `/`)
+
+	r.manifests(text)
+	r.tileSources(text)
+	r.zoomify(text)
+	r.iip(text)
+	r.pdfs(text)
+	if len(r.finds) == 0 {
+		r.gallery(text)
+	}
+	return r.finds
+}
+
+// Of 返回 finds 中类型为 kind 的地址
+func Of(finds []Find, kind Kind) []string {
+	var out []string
+	for _, f := range finds {
+		if f.Kind == kind {
+			out = append(out, f.Url)
+		}
+	}
+	return out
+}
+
+type result struct {
+	base  *url.URL
+	seen  map[string]bool
+	finds []Find
+}
+
+func (r *result) add(kind Kind, ref *url.URL, s string) {
+	u := resolve(ref, s)
+	if u == "" || r.seen[u] {
+		return
+	}
+	r.seen[u] = true
+	r.finds = append(r.finds, Find{Kind: kind, Url: u})
+}
+
+// resolve 把网页或脚本中的地址解析为绝对地址，无法下载的地址返回空串
+func resolve(ref *url.URL, s string) string {
+	s = strings.TrimSpace(html.UnescapeString(s))
+	if s == "" || strings.HasPrefix(s, "data:") || strings.HasPrefix(s, "javascript:") || strings.HasPrefix(s, "#") {
+		return ""
+	}
+	u, err := ref.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+func (r *result) manifests(text string) {
+	text = reWebManifest.ReplaceAllString(text, "")
+	for _, m := range reManifestParam.FindAllStringSubmatch(text, -1) {
+		if v, err := url.QueryUnescape(html.UnescapeString(m[1])); err == nil {
+			r.add(Manifest, r.base, v)
+		}
+	}
+	for _, re := range []*regexp.Regexp{reManifestAttr, reManifestVar} {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			if strings.Contains(m[1], "manifest=") {
+				continue
+			}
+			if re == reManifestAttr && !reManifestPath.MatchString(m[1]) {
+				continue
+			}
+			r.add(Manifest, r.base, m[1])
+		}
+	}
+}
+
+// tileSources OpenSeadragon({tileSources: ...})，值可以是字符串、数组或内联的 IIIF/DZI 对象
+func (r *result) tileSources(text string) {
+	for _, loc := range reTileSources.FindAllStringIndex(text, -1) {
+		v := literal(text[loc[1]:])
+		if v == "" {
+			continue
+		}
+		if strings.Contains(strings.ToLower(v), "zoomify") {
+			for _, m := range reTilesUrl.FindAllStringSubmatch(v, -1) {
+				r.add(Zoomify, r.base, zoomifyXml(m[1]))
+			}
+			continue
+		}
+		for _, m := range reQuoted.FindAllStringSubmatch(v, -1) {
+			s := m[1]
+			if strings.Contains(s, "iiif.io/api") || strings.Contains(s, "schemas.microsoft.com") {
+				continue
+			}
+			switch ext := extOf(s); {
+			case strings.HasSuffix(strings.ToLower(s), "imageproperties.xml"):
+				r.add(Zoomify, r.base, s)
+			case strings.HasSuffix(strings.ToLower(strings.SplitN(s, "?", 2)[0]), "info.json"):
+				r.add(IIIFImage, r.base, s)
+			case ext == ".dzi" || ext == ".xml" || ext == ".json" || ext == ".js":
+				r.add(DZI, r.base, s)
+			case imageExts[ext]:
+				r.add(Image, r.base, s)
+			}
+		}
+		//内联的 IIIF info.json：{"@context": ".../image/2/context.json", "@id": "https://.../iiif/xxx", ...}
+		if strings.Contains(v, "iiif.io/api/image") {
+			for _, m := range reIIIFId.FindAllStringSubmatch(v, -1) {
+				r.add(IIIFImage, r.base, strings.TrimSuffix(m[1], "/")+"/info.json")
+			}
+		}
+	}
+}
+
+func (r *result) zoomify(text string) {
+	for _, m := range reZoomifyXml.FindAllStringSubmatch(text, -1) {
+		r.add(Zoomify, r.base, m[1])
+	}
+	for _, m := range reZoomifyPath.FindAllStringSubmatch(text, -1) {
+		if v, err := url.QueryUnescape(html.UnescapeString(m[1])); err == nil {
+			r.add(Zoomify, r.base, zoomifyXml(v))
+		}
+	}
+	for _, m := range reZoomifyShow.FindAllStringSubmatch(text, -1) {
+		r.add(Zoomify, r.base, zoomifyXml(m[1]))
+	}
+}
+
+// iip 带 FIF= 的地址，以及 IIPMooViewer({server: ..., image: ...})，server 缺省为 /fcgi-bin/iipsrv.fcgi
+func (r *result) iip(text string) {
+	for _, s := range reIIPUrl.FindAllString(text, -1) {
+		if p, err := tiles.ParseIIP(html.UnescapeString(s)); err == nil {
+			r.add(IIP, r.base, p.Url())
+		}
+	}
+	for _, loc := range reIIPMoo.FindAllStringIndex(text, -1) {
+		args := text[loc[1]:]
+		if end := strings.Index(args, "});"); end > 0 {
+			args = args[:end]
+		}
+		server := "/fcgi-bin/iipsrv.fcgi"
+		if m := reIIPServer.FindStringSubmatch(args); m != nil {
+			server = m[1]
+		}
+		loc := reIIPImage.FindStringIndex(args)
+		if loc == nil {
+			continue
+		}
+		for _, m := range reQuoted.FindAllStringSubmatch(literal(args[loc[1]:]), -1) {
+			r.add(IIP, r.base, server+"?FIF="+m[1])
+		}
+	}
+}
+
+// pdfs pdf.js 的 viewer.html?file=、viewer.php?pdf=，file 相对于 viewer 所在目录；以及直接的 .pdf 链接
+func (r *result) pdfs(text string) {
+	for _, s := range rePdfViewer.FindAllString(text, -1) {
+		viewer, err := r.base.Parse(html.UnescapeString(s))
+		if err != nil {
+			continue
+		}
+		q := viewer.Query()
+		file := q.Get("file")
+		if file == "" {
+			file = q.Get("pdf")
+		}
+		if file != "" {
+			r.add(PDF, viewer, file)
+		}
+	}
+	for _, m := range rePdfLink.FindAllStringSubmatch(text, -1) {
+		if rePdfViewer.MatchString(m[1]) {
+			continue
+		}
+		r.add(PDF, r.base, m[1])
+	}
+}
+
+// gallery 取同一目录下图片最多的一组 <img>，懒加载的 data-src/data-original 优先于 src
+func (r *result) gallery(text string) {
+	groups := map[string][]string{}
+	var order []string
+	for _, tag := range reImg.FindAllString(text, -1) {
+		attrs := map[string]string{}
+		for _, m := range reImgAttr.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2]
+		}
+		src := attrs["data-original"]
+		if src == "" {
+			src = attrs["data-src"]
+		}
+		if src == "" {
+			src = attrs["src"]
+		}
+		u := resolve(r.base, src)
+		if u == "" || !imageExts[extOf(u)] {
+			continue
+		}
+		dir := u[:strings.LastIndex(u, "/")+1]
+		if _, ok := groups[dir]; !ok {
+			order = append(order, dir)
+		}
+		groups[dir] = append(groups[dir], u)
+	}
+	best := ""
+	for _, dir := range order {
+		if len(groups[dir]) > len(groups[best]) {
+			best = dir
+		}
+	}
+	if len(groups[best]) < MinGallery {
+		return
+	}
+	for _, u := range groups[best] {
+		r.add(Image, r.base, u)
+	}
+}
+
+// literal 取出 text 开头的 JS 字面量：字符串，或配对的 [...] / {...}
+func literal(text string) string {
+	if text == "" {
+		return ""
+	}
+	switch c := text[0]; c {
+	case '"', '\'':
+		if end := strings.IndexByte(text[1:], c); end >= 0 {
+			return text[:end+2]
+		}
+		return ""
+	case '[', '{':
+		depth := 0
+		var quote byte
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+			switch {
+			case quote != 0:
+				if c == '\\' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				depth--
+				if depth == 0 {
+					return text[:i+1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func zoomifyXml(dir string) string {
+	if strings.HasSuffix(strings.ToLower(dir), "imageproperties.xml") {
+		return dir
+	}
+	return strings.TrimSuffix(dir, "/") + "/ImageProperties.xml"
+}
+
+func extOf(s string) string {
+	s = strings.SplitN(s, "?", 2)[0]
+	return strings.ToLower(path.Ext(s))
+}
@@ -0,0 +1,117 @@
+package sniffer_test
+
+import (
+	"testing"
+
+	"bookget/pkg/sniffer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest(t *testing.T) {
+	page := `<a href="/viewer/mirador?manifest=https%3A%2F%2Fexample.org%2Fiiif%2F12%2Fmanifest.json&amp;lang=ja">IIIF</a>
+<a href="/iiif/12/manifest.json"><img src="/img/iiif-logo.png"></a>
+<link rel="manifest" href="/site.webmanifest">
+<link rel="manifest" href="/manifest.json" crossorigin="use-credentials">
+<link href="/static/manifest.json" rel=manifest>
+<script>Mirador.viewer({windows: [{manifestId: "https:\/\/example.org\/iiif\/13\/manifest"}]});</script>`
+	finds := sniffer.Analyze("https://example.org/book/12", []byte(page))
+	assert.Equal(t, []string{
+		"https://example.org/iiif/12/manifest.json",
+		"https://example.org/iiif/13/manifest",
+	}, sniffer.Of(finds, sniffer.Manifest))
+	assert.Empty(t, sniffer.Of(finds, sniffer.Image))
+}
+
+func TestOpenSeadragon(t *testing.T) {
+	page := `<script>
+var viewer = OpenSeadragon({
+  id: "osd",
+  prefixUrl: "/openseadragon/images/",
+  tileSources: ["dzi/0001.dzi", "dzi/0002.xml", 'https://iiif.example.org/iiif/2/abc/info.json',
+    {type: 'image', url: '/full/0003.jpg'}]
+});
+OpenSeadragon({tileSources: {
+  "@context": "http://iiif.io/api/image/2/context.json",
+  "@id": "https://iiif.example.org/iiif/2/def",
+  "width": 4000, "height": 6000
+}});
+OpenSeadragon({tileSources: {type: "zoomifytileservice", width: 7026, height: 9221, tilesUrl: "/zoomify/0005/"}});
+</script>`
+	finds := sniffer.Analyze("https://example.org/book/view.html", []byte(page))
+	assert.Equal(t, []sniffer.Find{
+		{Kind: sniffer.DZI, Url: "https://example.org/book/dzi/0001.dzi"},
+		{Kind: sniffer.DZI, Url: "https://example.org/book/dzi/0002.xml"},
+		{Kind: sniffer.IIIFImage, Url: "https://iiif.example.org/iiif/2/abc/info.json"},
+		{Kind: sniffer.Image, Url: "https://example.org/full/0003.jpg"},
+		{Kind: sniffer.IIIFImage, Url: "https://iiif.example.org/iiif/2/def/info.json"},
+		{Kind: sniffer.Zoomify, Url: "https://example.org/zoomify/0005/ImageProperties.xml"},
+	}, finds)
+}
+
+func TestZoomify(t *testing.T) {
+	page := `<script>Z.showImage("zoomifyContainer", "/tiles/book1/0001", "zSkinPath=Assets/Skins/Default");</script>
+<param name="FlashVars" value="zoomifyImagePath=%2Ftiles%2Fbook1%2F0002%2F&zoomifyNavigator=1">
+<a href="http://tiles.example.org/0003/ImageProperties.xml">xml</a>`
+	finds := sniffer.Analyze("https://example.org/view", []byte(page))
+	assert.Equal(t, []string{
+		"http://tiles.example.org/0003/ImageProperties.xml",
+		"https://example.org/tiles/book1/0002/ImageProperties.xml",
+		"https://example.org/tiles/book1/0001/ImageProperties.xml",
+	}, sniffer.Of(finds, sniffer.Zoomify))
+}
+
+func TestPdf(t *testing.T) {
+	//臺灣華文電子書庫
+	huawen := `<a href="/pdfjs/web/viewer.html?file=/pdf/B0001/B0001_01.pdf&amp;page=1" target="_blank">第一冊</a>
+<a href="/pdfjs/web/viewer.html?file=%2Fpdf%2FB0001%2FB0001_02.pdf">第二冊</a>`
+	finds := sniffer.Analyze("https://taiwanebook.ncl.edu.tw/zh-tw/book/NCL-000000001/reader", []byte(huawen))
+	assert.Equal(t, []string{
+		"https://taiwanebook.ncl.edu.tw/pdf/B0001/B0001_01.pdf",
+		"https://taiwanebook.ncl.edu.tw/pdf/B0001/B0001_02.pdf",
+	}, sniffer.Of(finds, sniffer.PDF))
+
+	//洛阳市图书馆：file 相对于 viewer 所在目录
+	luoyang := `<a href='viewer.php?pdf=upload/gj/0001.pdf&id=3'>卷一</a> <a href="files/0002.pdf">卷二</a>`
+	finds = sniffer.Analyze("http://111.7.82.29:8090/lygj/show.php?id=3", []byte(luoyang))
+	assert.Equal(t, []string{
+		"http://111.7.82.29:8090/lygj/upload/gj/0001.pdf",
+		"http://111.7.82.29:8090/lygj/files/0002.pdf",
+	}, sniffer.Of(finds, sniffer.PDF))
+}
+
+func TestGallery(t *testing.T) {
+	page := `<img src="/static/logo.png"><img src="/static/banner.jpg">
+<img class="lazy" src="/static/loading.gif" data-original="/images/b1/0001.jpg">
+<img class="lazy" src="/static/loading.gif" data-src="/images/b1/0002.jpg">
+<img src="/images/b1/0003.jpg" alt="3">
+<img src="data:image/png;base64,AAAA">`
+	finds := sniffer.Analyze("https://example.org/book/1", []byte(page))
+	assert.Equal(t, []string{
+		"https://example.org/images/b1/0001.jpg",
+		"https://example.org/images/b1/0002.jpg",
+		"https://example.org/images/b1/0003.jpg",
+	}, sniffer.Of(finds, sniffer.Image))
+
+	//少于 MinGallery 张不算图片列表；有查看器时也不取 <img>
+	assert.Empty(t, sniffer.Analyze("https://example.org/", []byte(`<img src="/a/1.jpg"><img src="/a/2.jpg">`)))
+	finds = sniffer.Analyze("https://example.org/", []byte(page+`<a href="/a.pdf">PDF</a>`))
+	assert.Equal(t, []sniffer.Find{{Kind: sniffer.PDF, Url: "https://example.org/a.pdf"}}, finds)
+}
+
+func TestIIP(t *testing.T) {
+	page := `<img src="/fcgi-bin/iipsrv.fcgi?FIF=/images/b1/0001.tif&amp;WID=200&amp;CVT=jpeg">
+<script>
+var iipmooviewer = new IIPMooViewer( "viewer", {
+  image: ['/images/b1/0002.tif', '/images/b1/0003.tif'],
+  credit: 'Example Museum'
+});
+new IIPMooViewer("v2", {server: "https://iip.example.org/iipsrv", image: "x/0004.tif"});
+</script>`
+	finds := sniffer.Analyze("https://example.org/view", []byte(page))
+	assert.Equal(t, []string{
+		"https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/b1/0001.tif",
+		"https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/b1/0002.tif",
+		"https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/b1/0003.tif",
+		"https://iip.example.org/iipsrv?FIF=x/0004.tif",
+	}, sniffer.Of(finds, sniffer.IIP))
+}
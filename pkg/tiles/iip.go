@@ -0,0 +1,102 @@
+package tiles
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// IIPImage 服务器地址与 FIF 参数
+type IIPImage struct {
+	Server string //如 https://example.org/fcgi-bin/iipsrv.fcgi
+	FIF    string //如 /images/book/0001.tif
+}
+
+// ParseIIP 从任何带 FIF= 的地址（JTL 切片、CVT 缩略图、描述请求等）取出服务器与图片
+func ParseIIP(u string) (*IIPImage, error) {
+	server, query, ok := strings.Cut(u, "?")
+	if !ok {
+		return nil, fmt.Errorf("%s: missing FIF=", u)
+	}
+	//FIF 的值常常不做 URL 编码，逐个参数切分
+	for _, kv := range strings.Split(query, "&") {
+		k, v, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(k, "FIF") && v != "" {
+			if s, err := url.QueryUnescape(v); err == nil {
+				v = s
+			}
+			return &IIPImage{Server: server, FIF: v}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: missing FIF=", u)
+}
+
+func (p *IIPImage) query(extra string) string {
+	return p.Server + "?FIF=" + p.FIF + "&" + extra
+}
+
+// InfoUrl 描述请求：FIF=...&obj=IIP,1.0&obj=Max-size&obj=Tile-size&obj=Resolution-number
+func (p *IIPImage) InfoUrl() string {
+	return p.query("obj=IIP,1.0&obj=Max-size&obj=Tile-size&obj=Resolution-number")
+}
+
+// Url 规范化后的图片地址，用作书名与去重
+func (p *IIPImage) Url() string {
+	return p.Server + "?FIF=" + p.FIF
+}
+
+// Grid 解析描述请求的响应：
+//
+//	IIP:1.0
+//	Max-size:7000 9000
+//	Tile-size:256 256
+//	Resolution-number:7
+//
+// 切片地址为 FIF=...&JTL={level},{n}，level 为最高一级 Resolution-number-1，n 按行排列
+func (p *IIPImage) Grid(body []byte) (*Grid, error) {
+	var w, h, tw, th, levels int
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(sc.Text()), ":")
+		if !ok {
+			continue
+		}
+		f := strings.Fields(v)
+		switch strings.ToLower(k) {
+		case "max-size":
+			if len(f) == 2 {
+				w, _ = strconv.Atoi(f[0])
+				h, _ = strconv.Atoi(f[1])
+			}
+		case "tile-size":
+			if len(f) == 2 {
+				tw, _ = strconv.Atoi(f[0])
+				th, _ = strconv.Atoi(f[1])
+			}
+		case "resolution-number":
+			if len(f) == 1 {
+				levels, _ = strconv.Atoi(f[0])
+			}
+		}
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("%s: missing Max-size", p.Url())
+	}
+	if tw <= 0 || th <= 0 {
+		tw, th = 256, 256
+	}
+	if levels <= 0 {
+		//没有 Resolution-number 时按每级减半推算，直到一张切片放得下
+		levels = 1
+		for x, y := w, h; x > tw || y > th; x, y = x/2, y/2 {
+			levels++
+		}
+	}
+	cols := ceilDiv(w, tw)
+	return newGrid(w, h, tw, th, func(x, y int) string {
+		return p.query(fmt.Sprintf("JTL=%d,%d", levels-1, y*cols+x))
+	})
+}
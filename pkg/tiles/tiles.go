@@ -0,0 +1,161 @@
+// Package tiles 读取 Zoomify、IIPImage 的切片描述，计算最高分辨率一级的切片网格，下载切片并拼成整图
+package tiles
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Fetcher 取回切片内容
+type Fetcher func(url string) ([]byte, error)
+
+// Tile 一个切片及其在整图中的左上角位置
+type Tile struct {
+	Url  string
+	X, Y int
+}
+
+// Grid 最高分辨率一级的切片网格
+type Grid struct {
+	Width, Height int
+	TileW, TileH  int
+	Tiles         []Tile
+}
+
+// MaxPixels 整图像素上限（约 1.6 GB 内存），超过时拒绝拼接
+const MaxPixels = 400_000_000
+
+// newGrid 按行列生成切片，url(x, y) 返回第 x 列、第 y 行切片的地址
+func newGrid(w, h, tw, th int, url func(x, y int) string) (*Grid, error) {
+	if w <= 0 || h <= 0 || tw <= 0 || th <= 0 {
+		return nil, fmt.Errorf("invalid image size %dx%d, tile %dx%d", w, h, tw, th)
+	}
+	if w*h > MaxPixels {
+		return nil, fmt.Errorf("image %dx%d is too large to stitch", w, h)
+	}
+	g := &Grid{Width: w, Height: h, TileW: tw, TileH: th}
+	cols, rows := ceilDiv(w, tw), ceilDiv(h, th)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			g.Tiles = append(g.Tiles, Tile{Url: url(x, y), X: x * tw, Y: y * th})
+		}
+	}
+	return g, nil
+}
+
+// Stitch 以 concurrency 个并发下载全部切片并拼成整图；onTile 在每个切片完成后调用，可为 nil。
+// 任何切片下载或解码失败都返回错误，不会留下缺块的图片
+func Stitch(g *Grid, fetch Fetcher, concurrency int, onTile func(done, total int)) (image.Image, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Width, g.Height))
+	jobs := make(chan Tile)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		done     int
+	)
+	for n := 0; n < concurrency; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				img, err := fetchTile(t.Url, fetch)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					b := img.Bounds()
+					draw.Draw(canvas, image.Rect(t.X, t.Y, t.X+b.Dx(), t.Y+b.Dy()), img, b.Min, draw.Src)
+					done++
+					if onTile != nil {
+						onTile(done, len(g.Tiles))
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, t := range g.Tiles {
+		mu.Lock()
+		stop := firstErr != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+		jobs <- t
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return canvas, nil
+}
+
+func fetchTile(u string, fetch Fetcher) (image.Image, error) {
+	bs, err := fetch(u)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(bs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+	return img, nil
+}
+
+// Ext 拼接图保存的扩展名：只能编码 PNG 与 JPEG，.png 以外（如 .tif、.jp2）都保存为 .jpg
+func Ext(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".png":
+		return ".png"
+	case ".jpeg":
+		return ".jpeg"
+	}
+	return ".jpg"
+}
+
+// Save 按扩展名保存为 PNG 或 JPEG，先写临时文件再改名。其它扩展名返回错误，见 Ext
+func Save(dest string, img image.Image, ext string, quality int) error {
+	if Ext(ext) != strings.ToLower(ext) {
+		return fmt.Errorf("tiles: cannot encode %s, save as %s", ext, Ext(ext))
+	}
+	if quality <= 0 {
+		quality = 90
+	}
+	tmp := dest + ".stitching"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if Ext(ext) == ".png" {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: quality})
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,115 @@
+package tiles_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/tiles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZoomify(t *testing.T) {
+	xml := `<IMAGE_PROPERTIES WIDTH="7026" HEIGHT="9221" NUMTILES="1401" NUMIMAGES="1" VERSION="1.8" TILESIZE="256" />`
+	g, err := tiles.ParseZoomify("https://example.org/zoomify/0001/ImageProperties.xml", []byte(xml))
+	require.NoError(t, err)
+	assert.Equal(t, 7026, g.Width)
+	require.Len(t, g.Tiles, 28*37)
+	assert.Equal(t, tiles.Tile{Url: "https://example.org/zoomify/0001/TileGroup1/6-0-0.jpg"}, g.Tiles[0])
+	last := g.Tiles[len(g.Tiles)-1]
+	assert.Equal(t, "https://example.org/zoomify/0001/TileGroup5/6-27-36.jpg", last.Url)
+	assert.Equal(t, [2]int{27 * 256, 36 * 256}, [2]int{last.X, last.Y})
+
+	assert.Equal(t, "https://example.org/z/", tiles.ZoomifyBase("https://example.org/z"))
+	_, err = tiles.ParseZoomify("https://example.org/z/", []byte(`<IMAGE_PROPERTIES TILESIZE="256" />`))
+	assert.Error(t, err)
+}
+
+func TestIIP(t *testing.T) {
+	p, err := tiles.ParseIIP("https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/book 1/0001.tif&CVT=jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, "/images/book 1/0001.tif", p.FIF)
+	assert.Equal(t, "https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/book 1/0001.tif&obj=IIP,1.0&obj=Max-size&obj=Tile-size&obj=Resolution-number", p.InfoUrl())
+
+	g, err := p.Grid([]byte("IIP:1.0\r\nMax-size:1000 600\r\nTile-size:256 256\r\nResolution-number:3\r\n"))
+	require.NoError(t, err)
+	require.Len(t, g.Tiles, 4*3)
+	assert.Equal(t, "https://example.org/fcgi-bin/iipsrv.fcgi?FIF=/images/book 1/0001.tif&JTL=2,6", g.Tiles[6].Url)
+	assert.Equal(t, [2]int{512, 256}, [2]int{g.Tiles[6].X, g.Tiles[6].Y})
+
+	//没有 Resolution-number 时自行推算
+	g, err = p.Grid([]byte("Max-size:1000 600\nTile-size:256 256\n"))
+	require.NoError(t, err)
+	assert.Contains(t, g.Tiles[0].Url, "JTL=2,0")
+
+	_, err = tiles.ParseIIP("https://example.org/iipsrv.fcgi?IIIF=/a.tif/info.json")
+	assert.Error(t, err)
+}
+
+func TestStitch(t *testing.T) {
+	p, _ := tiles.ParseIIP("https://example.org/iip?FIF=a.tif")
+	g, err := p.Grid([]byte("Max-size:300 200\nTile-size:128 128\nResolution-number:2\n"))
+	require.NoError(t, err)
+
+	//每个切片填充不同颜色，边缘切片按实际大小
+	served := map[string][]byte{}
+	for i, tile := range g.Tiles {
+		w, h := min(128, g.Width-tile.X), min(128, g.Height-tile.Y)
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				img.Set(x, y, color.RGBA{R: uint8(i * 40), A: 255})
+			}
+		}
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		served[tile.Url] = buf.Bytes()
+	}
+	fetch := func(u string) ([]byte, error) {
+		if bs, ok := served[u]; ok {
+			return bs, nil
+		}
+		return nil, fmt.Errorf("404 %s", u)
+	}
+	calls := 0
+	img, err := tiles.Stitch(g, fetch, 3, func(done, total int) { calls++ })
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 300, 200), img.Bounds())
+	assert.Equal(t, len(g.Tiles), calls)
+	r, _, _, _ := img.At(299, 199).RGBA()
+	assert.Equal(t, uint32(5*40)*0x101, r, "bottom-right tile")
+	r, _, _, _ = img.At(130, 10).RGBA()
+	assert.Equal(t, uint32(1*40)*0x101, r)
+
+	delete(served, g.Tiles[4].Url)
+	_, err = tiles.Stitch(g, fetch, 2, nil)
+	assert.ErrorContains(t, err, "JTL=1,4")
+}
+
+func TestSave(t *testing.T) {
+	assert.Equal(t, ".jpg", tiles.Ext(".tif"))
+	assert.Equal(t, ".jpg", tiles.Ext(".jp2"))
+	assert.Equal(t, ".png", tiles.Ext(".PNG"))
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	dir := t.TempDir()
+	require.NoError(t, tiles.Save(filepath.Join(dir, "0001.png"), img, ".png", 0))
+	require.NoError(t, tiles.Save(filepath.Join(dir, "0002.jpg"), img, ".jpg", 0))
+	assert.Error(t, tiles.Save(filepath.Join(dir, "0003.tif"), img, ".tif", 0))
+	assert.NoFileExists(t, filepath.Join(dir, "0003.tif"))
+
+	for name, format := range map[string]string{"0001.png": "png", "0002.jpg": "jpeg"} {
+		bs, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		_, got, err := image.Decode(bytes.NewReader(bs))
+		require.NoError(t, err)
+		assert.Equal(t, format, got, name)
+	}
+}
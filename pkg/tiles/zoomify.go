@@ -0,0 +1,67 @@
+package tiles
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// zoomifyGroupSize 每个 TileGroup 目录存放的切片数
+const zoomifyGroupSize = 256
+
+// ZoomifyBase 由 ImageProperties.xml 的地址或图片目录得到切片根目录（以 / 结尾）
+func ZoomifyBase(u string) string {
+	u = strings.SplitN(u, "?", 2)[0]
+	if i := strings.LastIndex(strings.ToLower(u), "imageproperties.xml"); i >= 0 {
+		return u[:i]
+	}
+	return strings.TrimSuffix(u, "/") + "/"
+}
+
+// ParseZoomify 解析 ImageProperties.xml：
+//
+//	<IMAGE_PROPERTIES WIDTH="7026" HEIGHT="9221" NUMTILES="1401" NUMIMAGES="1" VERSION="1.8" TILESIZE="256" />
+//
+// 切片地址为 base/TileGroup{n}/{level}-{x}-{y}.jpg，n 为切片从最低一级起的序号除以 256
+func ParseZoomify(base string, body []byte) (*Grid, error) {
+	var p struct {
+		Width    int `xml:"WIDTH,attr"`
+		Height   int `xml:"HEIGHT,attr"`
+		TileSize int `xml:"TILESIZE,attr"`
+	}
+	if err := xml.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("ImageProperties.xml: %w", err)
+	}
+	if p.TileSize == 0 {
+		p.TileSize = 256
+	}
+	if p.Width <= 0 || p.Height <= 0 {
+		return nil, fmt.Errorf("ImageProperties.xml: missing WIDTH/HEIGHT")
+	}
+	levels := zoomifyLevels(p.Width, p.Height, p.TileSize)
+	top := len(levels) - 1
+	//最高一级之前各级的切片总数
+	offset := 0
+	for _, l := range levels[:top] {
+		offset += l[0] * l[1]
+	}
+	cols := levels[top][0]
+	base = ZoomifyBase(base)
+	return newGrid(p.Width, p.Height, p.TileSize, p.TileSize, func(x, y int) string {
+		group := (offset + y*cols + x) / zoomifyGroupSize
+		return fmt.Sprintf("%sTileGroup%d/%d-%d-%d.jpg", base, group, top, x, y)
+	})
+}
+
+// zoomifyLevels 各级的切片列数、行数，从最小的一级（一张切片）到原图；与 OpenSeadragon 的算法一致
+func zoomifyLevels(w, h, ts int) [][2]int {
+	levels := [][2]int{{ceilDiv(w, ts), ceilDiv(h, ts)}}
+	for w > ts || h > ts {
+		w, h = w/2, h/2
+		levels = append(levels, [2]int{ceilDiv(w, ts), ceilDiv(h, ts)})
+	}
+	for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+		levels[i], levels[j] = levels[j], levels[i]
+	}
+	return levels
+}
@@ -0,0 +1,57 @@
+package urltemplate
+
+// MaxProbePages 探测页数的上限，防止对任何页码都返回图片的网站无限探测下去
+const MaxProbePages = 20000
+
+// DefaultGap 探测时容忍的连续缺页数
+const DefaultGap = 2
+
+// FindLast 找出从 start 开始的最后一页：先按 1、2、4、8… 指数步长找到不存在的页，再二分查找。
+// 某页缺失但其后 gap 页内还有页面时仍视为存在（个别缺页）。exists 的结果会缓存，
+// 返回 start-1 表示一页也没有
+func FindLast(start, gap int, exists func(page int) bool) int {
+	cache := map[int]bool{}
+	has := func(n int) bool {
+		v, ok := cache[n]
+		if !ok {
+			v = exists(n)
+			cache[n] = v
+		}
+		return v
+	}
+	present := func(n int) bool {
+		for k := 0; k <= gap; k++ {
+			if has(n + k) {
+				return true
+			}
+		}
+		return false
+	}
+	if !present(start) {
+		return start - 1
+	}
+
+	lo, hi := start, start+1
+	for step := 1; present(hi); step *= 2 {
+		lo = hi
+		if hi-start >= MaxProbePages {
+			break
+		}
+		hi = start + step*2
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if present(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	//present(lo) 成立，lo 起 gap 页内最后一个存在的页即为末页
+	for k := gap; k > 0; k-- {
+		if has(lo + k) {
+			return lo + k
+		}
+	}
+	return lo
+}
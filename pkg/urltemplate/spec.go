@@ -0,0 +1,198 @@
+package urltemplate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Spec 一个下载任务：模板、册范围与每册页数
+type Spec struct {
+	Template *Template
+	VolStart int   //模板中没有 {vol} 时为 0
+	VolEnd   int   //
+	Pages    []int //每册页数，只有一个时各册相同
+	Start    int   //首页页码，默认 1
+	Ext      string
+	Probe    bool //pages=auto：下载前探测每册页数，结果写入 Pages
+	Gap      int  //探测时容忍的连续缺页数
+}
+
+// Page 一页（或一面）
+type Page struct {
+	Vol   int
+	Page  int
+	Sides []string //依次尝试的面，见 {side}
+}
+
+// NewSpec 由模板与选项创建任务。选项：
+//
+//	vol=1:3            册范围（模板有 {vol} 时必填，也可写 vol=5）
+//	pages=120,98,143   每册页数；只写一个数表示每册相同；auto 为下载前探测
+//	gap=2              探测时容忍的连续缺页数
+//	start=0            首页页码，默认 1
+//	ext=.jpg           保存的扩展名，默认取 URL 的扩展名
+func NewSpec(tpl string, opts map[string]string) (*Spec, error) {
+	t, err := Parse(tpl)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spec{Template: t, Start: 1, Gap: DefaultGap}
+	for k, v := range opts {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		switch k {
+		case "vol":
+			if s.VolStart, s.VolEnd, err = parseRange(v); err != nil {
+				return nil, fmt.Errorf("vol=%s: %w", v, err)
+			}
+		case "pages":
+			if v == "auto" {
+				s.Probe = true
+				continue
+			}
+			for _, n := range strings.Split(v, ",") {
+				c, err := strconv.Atoi(strings.TrimSpace(n))
+				if err != nil || c < 0 {
+					return nil, fmt.Errorf("pages=%s: invalid page count %q", v, n)
+				}
+				s.Pages = append(s.Pages, c)
+			}
+		case "start":
+			if s.Start, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("start=%s: %w", v, err)
+			}
+		case "gap":
+			if s.Gap, err = strconv.Atoi(v); err != nil || s.Gap < 0 {
+				return nil, fmt.Errorf("gap=%s: invalid gap", v)
+			}
+		case "ext":
+			s.Ext = v
+			if !strings.HasPrefix(s.Ext, ".") {
+				s.Ext = "." + s.Ext
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %s=%s", k, v)
+		}
+	}
+	if s.Probe {
+		s.Pages = nil
+	} else if len(s.Pages) == 0 {
+		return nil, fmt.Errorf("%s: pages= is required (or pages=auto)", tpl)
+	}
+	if t.HasVol() {
+		if s.VolStart == 0 && s.VolEnd == 0 {
+			return nil, fmt.Errorf("%s: vol= is required for {vol}", tpl)
+		}
+		if n := s.VolEnd - s.VolStart + 1; len(s.Pages) > 1 && len(s.Pages) != n {
+			return nil, fmt.Errorf("%s: %d page counts for %d volumes", tpl, len(s.Pages), n)
+		}
+	} else {
+		if len(s.Pages) > 1 {
+			return nil, fmt.Errorf("%s: several page counts need a {vol} placeholder", tpl)
+		}
+		s.VolStart, s.VolEnd = 0, 0
+	}
+	return s, nil
+}
+
+// ParseSpec 解析一行：模板后跟空格分隔的 key=value 选项，如
+//
+//	https://example.org/{vol:03}/{page:04}{side:r,v}.jpg vol=1:3 pages=120,98,143
+func ParseSpec(line string) (*Spec, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty template")
+	}
+	opts := make(map[string]string, len(fields)-1)
+	for _, f := range fields[1:] {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, fmt.Errorf("option %q is not key=value", f)
+		}
+		opts[k] = v
+	}
+	return NewSpec(fields[0], opts)
+}
+
+// ParseFile 每行一个任务，空行与 # 开头的行忽略
+func ParseFile(r io.Reader) ([]*Spec, error) {
+	var specs []*Spec
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s, err := ParseSpec(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		specs = append(specs, s)
+	}
+	return specs, sc.Err()
+}
+
+// Volumes 册号列表，模板没有 {vol} 时为 [0]
+func (s *Spec) Volumes() []int {
+	var out []int
+	for v := s.VolStart; v <= s.VolEnd; v++ {
+		out = append(out, v)
+	}
+	return out
+}
+
+// PagesOf 第 volume 册的页数
+func (s *Spec) PagesOf(volume int) int {
+	if len(s.Pages) == 1 {
+		return s.Pages[0]
+	}
+	i := volume - s.VolStart
+	if i < 0 || i >= len(s.Pages) {
+		return 0
+	}
+	return s.Pages[i]
+}
+
+// Total 各册页数之和（不计 {side} 的多面）
+func (s *Spec) Total() int {
+	n := 0
+	for _, v := range s.Volumes() {
+		n += s.PagesOf(v)
+	}
+	return n
+}
+
+// List 按册、页顺序列出全部页面
+func (s *Spec) List() []Page {
+	var out []Page
+	for _, v := range s.Volumes() {
+		for i := 0; i < s.PagesOf(v); i++ {
+			out = append(out, Page{Vol: v, Page: s.Start + i, Sides: s.Template.Sides})
+		}
+	}
+	return out
+}
+
+func parseRange(v string) (int, int, error) {
+	a, b, ok := strings.Cut(v, ":")
+	if !ok {
+		b = a
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(a))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(b))
+	if err != nil {
+		return 0, 0, err
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("%d > %d", start, end)
+	}
+	return start, end, nil
+}
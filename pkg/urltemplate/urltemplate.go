@@ -0,0 +1,173 @@
+// Package urltemplate 批量下载用的图片 URL 模板。
+//
+// 占位符：
+//
+//	{page}      页码；{page:04} 补零到 4 位；{page:a} / {page:A} 字母序号 a..z, aa, ab...
+//	{vol}       册号，格式同 {page}，如 {vol:03}
+//	{side:a,b}  每页的多个面，如 {side:r,v}，第一面不存在时改为不带面号下载
+//
+// 旧的 [PAGE]、[VOL]、[AB]、[ab] 写法见 Legacy。
+package urltemplate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type kind int
+
+const (
+	literal kind = iota
+	page
+	vol
+	side
+)
+
+type part struct {
+	kind  kind
+	text  string //literal 的文本
+	width int    //补零位数
+	alpha byte   //'a' 或 'A'：字母序号
+}
+
+// Template 解析后的 URL 模板
+type Template struct {
+	raw   string
+	parts []part
+	Sides []string //{side:...} 的各面，没有时为空
+}
+
+// Parse 解析模板，模板中必须有 {page}
+func Parse(s string) (*Template, error) {
+	t := &Template{raw: s}
+	hasPage := false
+	for s != "" {
+		i := strings.IndexByte(s, '{')
+		if i < 0 {
+			t.parts = append(t.parts, part{text: s})
+			break
+		}
+		if i > 0 {
+			t.parts = append(t.parts, part{text: s[:i]})
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			return nil, fmt.Errorf("unclosed placeholder in %q", t.raw)
+		}
+		p, err := t.parsePlaceholder(s[i+1 : i+j])
+		if err != nil {
+			return nil, err
+		}
+		hasPage = hasPage || p.kind == page
+		t.parts = append(t.parts, p)
+		s = s[i+j+1:]
+	}
+	if !hasPage {
+		return nil, fmt.Errorf("template %q has no {page} placeholder", t.raw)
+	}
+	return t, nil
+}
+
+func (t *Template) parsePlaceholder(s string) (part, error) {
+	name, arg, hasArg := strings.Cut(s, ":")
+	switch name {
+	case "page", "vol":
+		p := part{kind: page}
+		if name == "vol" {
+			p.kind = vol
+		}
+		switch {
+		case !hasArg:
+		case arg == "a" || arg == "A":
+			p.alpha = arg[0]
+		default:
+			w, err := strconv.Atoi(arg)
+			if err != nil || w < 0 || w > 12 {
+				return p, fmt.Errorf("invalid width in {%s}", s)
+			}
+			p.width = w
+		}
+		return p, nil
+	case "side":
+		if t.Sides != nil {
+			return part{}, errors.New("only one {side:...} placeholder is allowed")
+		}
+		for _, v := range strings.Split(arg, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				t.Sides = append(t.Sides, v)
+			}
+		}
+		if len(t.Sides) == 0 {
+			return part{}, fmt.Errorf("{side} needs a list such as {side:a,b}")
+		}
+		return part{kind: side}, nil
+	}
+	return part{}, fmt.Errorf("unknown placeholder {%s}", s)
+}
+
+// HasVol 模板中是否有 {vol}
+func (t *Template) HasVol() bool {
+	for _, p := range t.parts {
+		if p.kind == vol {
+			return true
+		}
+	}
+	return false
+}
+
+// String 原始模板
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Expand 第 volume 册第 pg 页 side 面的 URL，side 为空时 {side} 展开为空串
+func (t *Template) Expand(volume, pg int, sd string) string {
+	var sb strings.Builder
+	for _, p := range t.parts {
+		switch p.kind {
+		case literal:
+			sb.WriteString(p.text)
+		case page:
+			sb.WriteString(p.format(pg))
+		case vol:
+			sb.WriteString(p.format(volume))
+		case side:
+			sb.WriteString(sd)
+		}
+	}
+	return sb.String()
+}
+
+func (p part) format(n int) string {
+	if p.alpha != 0 {
+		return Alpha(n, p.alpha == 'A')
+	}
+	return fmt.Sprintf("%0*d", p.width, n)
+}
+
+// Alpha 字母序号：1=a, 26=z, 27=aa, 28=ab ...，n < 1 时为空串
+func Alpha(n int, upper bool) string {
+	base := byte('a')
+	if upper {
+		base = 'A'
+	}
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{base + byte(n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+// Legacy 把交互模式的 [PAGE]、[VOL]、[AB]、[ab] 换成新占位符，pageWidth 为页码补零位数
+func Legacy(s string, pageWidth int) string {
+	return strings.NewReplacer(
+		"[PAGE]", fmt.Sprintf("{page:%02d}", pageWidth),
+		"[VOL]", "{vol:04}",
+		"[AB]", "{side:A,B}",
+		"[ab]", "{side:a,b}",
+	).Replace(s)
+}
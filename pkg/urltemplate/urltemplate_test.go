@@ -0,0 +1,128 @@
+package urltemplate_test
+
+import (
+	"strings"
+	"testing"
+
+	"bookget/pkg/urltemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	tpl, err := urltemplate.Parse("https://example.org/{vol:03}/{page:04}{side:r,v}.jpg")
+	require.NoError(t, err)
+	assert.True(t, tpl.HasVol())
+	assert.Equal(t, []string{"r", "v"}, tpl.Sides)
+	assert.Equal(t, "https://example.org/002/0017v.jpg", tpl.Expand(2, 17, "v"))
+	assert.Equal(t, "https://example.org/002/0017.jpg", tpl.Expand(2, 17, ""))
+
+	tpl, err = urltemplate.Parse("https://example.org/plate_{page:A}.tif?v={vol}")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/plate_AB.tif?v=7", tpl.Expand(7, 28, ""))
+
+	for _, bad := range []string{
+		"https://example.org/{vol}.jpg",
+		"https://example.org/{page:x}.jpg",
+		"https://example.org/{page}{leaf}.jpg",
+		"https://example.org/{page}{side:}.jpg",
+		"https://example.org/{page.jpg",
+	} {
+		_, err := urltemplate.Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestAlpha(t *testing.T) {
+	assert.Equal(t, "a", urltemplate.Alpha(1, false))
+	assert.Equal(t, "z", urltemplate.Alpha(26, false))
+	assert.Equal(t, "aa", urltemplate.Alpha(27, false))
+	assert.Equal(t, "AZ", urltemplate.Alpha(52, true))
+	assert.Equal(t, "ZZ", urltemplate.Alpha(702, true))
+	assert.Equal(t, "aaa", urltemplate.Alpha(703, false))
+	assert.Equal(t, "", urltemplate.Alpha(0, false))
+}
+
+func TestLegacy(t *testing.T) {
+	tpl, err := urltemplate.Parse(urltemplate.Legacy("https://example.org/[VOL]/[PAGE][ab].jpg", 3))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/0001/012b.jpg", tpl.Expand(1, 12, "b"))
+}
+
+func TestParseFile(t *testing.T) {
+	specs, err := urltemplate.ParseFile(strings.NewReader(`
+# 每册页数不同
+https://example.org/{vol:03}/{page:04}{side:r,v}.jpg vol=1:3 pages=2,1,3
+https://example.org/single_{page}.png pages=3 start=0 ext=jpg
+`))
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+
+	s := specs[0]
+	assert.Equal(t, []int{1, 2, 3}, s.Volumes())
+	assert.Equal(t, 6, s.Total())
+	list := s.List()
+	require.Len(t, list, 6)
+	assert.Equal(t, urltemplate.Page{Vol: 2, Page: 1, Sides: []string{"r", "v"}}, list[2])
+	assert.Equal(t, urltemplate.Page{Vol: 3, Page: 3, Sides: []string{"r", "v"}}, list[5])
+
+	s = specs[1]
+	assert.Equal(t, []int{0}, s.Volumes())
+	assert.Equal(t, ".jpg", s.Ext)
+	list = s.List()
+	require.Len(t, list, 3)
+	assert.Equal(t, 0, list[0].Page)
+	assert.Equal(t, "https://example.org/single_2.png", s.Template.Expand(list[2].Vol, list[2].Page, ""))
+
+	_, err = urltemplate.ParseFile(strings.NewReader("https://example.org/{page}.jpg\nhttps://example.org/{vol}/{page}.jpg vol=1:2 pages=1,2,3\n"))
+	assert.ErrorContains(t, err, "line 1")
+	_, err = urltemplate.ParseSpec("https://example.org/{vol}/{page}.jpg vol=1:2 pages=1,2,3")
+	assert.ErrorContains(t, err, "3 page counts for 2 volumes")
+	_, err = urltemplate.ParseSpec("https://example.org/{vol}/{page}.jpg pages=10")
+	assert.ErrorContains(t, err, "vol=")
+	_, err = urltemplate.ParseSpec("https://example.org/{page}.jpg pages=10 size=3")
+	assert.ErrorContains(t, err, "unknown option")
+}
+
+func TestFindLast(t *testing.T) {
+	book := func(last int, missing ...int) func(int) bool {
+		return func(n int) bool {
+			for _, m := range missing {
+				if n == m {
+					return false
+				}
+			}
+			return n >= 1 && n <= last
+		}
+	}
+	assert.Equal(t, 1, urltemplate.FindLast(1, 0, book(1)))
+	assert.Equal(t, 137, urltemplate.FindLast(1, 0, book(137)))
+	assert.Equal(t, 256, urltemplate.FindLast(1, 2, book(256)))
+	assert.Equal(t, 0, urltemplate.FindLast(1, 2, book(0)))
+	assert.Equal(t, 98, urltemplate.FindLast(0, 2, book(98)), "start=0, page 0 missing")
+
+	//个别缺页
+	assert.Equal(t, 50, urltemplate.FindLast(1, 2, book(50, 4, 16, 17, 32)))
+	assert.Equal(t, 2, urltemplate.FindLast(1, 0, book(50, 3)), "gap=0 stops at a probed hole")
+	assert.Equal(t, 50, urltemplate.FindLast(1, 1, book(50, 3)))
+	assert.Equal(t, 50, urltemplate.FindLast(1, 2, book(50, 49)))
+
+	calls := 0
+	urltemplate.FindLast(1, 0, func(n int) bool { calls++; return n <= 1000 })
+	assert.Less(t, calls, 30)
+
+	assert.LessOrEqual(t, urltemplate.FindLast(1, 0, func(int) bool { return true }), 2*urltemplate.MaxProbePages)
+}
+
+func TestProbeSpec(t *testing.T) {
+	s, err := urltemplate.ParseSpec("https://example.org/{vol}/{page}.jpg vol=1:3 pages=auto gap=4")
+	require.NoError(t, err)
+	assert.True(t, s.Probe)
+	assert.Equal(t, 4, s.Gap)
+	s.Pages = []int{3, 0, 2}
+	assert.Equal(t, 5, s.Total())
+	assert.Len(t, s.List(), 5)
+
+	_, err = urltemplate.ParseSpec("https://example.org/{page}.jpg gap=-1 pages=auto")
+	assert.Error(t, err)
+}
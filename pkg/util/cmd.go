@@ -6,11 +6,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -60,21 +62,27 @@ func GetOutput(reader *bufio.Reader) {
 	return
 }
 
-// PrintSleepTime 打印0-60秒等待
+// PrintSleepTime 按 --speed 等待 0-60 秒，等待时间以 DEBUG 级别输出
 func PrintSleepTime(sec int) {
 	if sec <= 0 || sec > 60 {
 		return
 	}
-	fmt.Println()
-	for t := sec; t > 0; t-- {
-		seconds := strconv.Itoa(int(t))
-		if t < 10 {
-			seconds = fmt.Sprintf("0%d", t)
+	slog.Debug(fmt.Sprintf("please wait %d sec", sec))
+	time.Sleep(time.Duration(sec) * time.Second)
+}
+
+var devNull = sync.OnceValues(func() (*os.File, error) {
+	return os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+})
+
+// childFiles dezoomify-rs 的输出写到 stderr（stdout 可能是 --events 事件流），--quiet 时丢弃
+func childFiles() []*os.File {
+	if config.Conf.Quiet {
+		if null, err := devNull(); err == nil {
+			return []*os.File{os.Stdin, null, null}
 		}
-		fmt.Printf("\rplease wait.... [00:%s of appr. Max %d sec]", seconds, sec)
-		time.Sleep(time.Second)
 	}
-	fmt.Println()
+	return []*os.File{os.Stdin, os.Stderr, os.Stderr}
 }
 
 func StartProcess(inputUri string, outfile string, args []string) bool {
@@ -87,7 +95,7 @@ func StartProcess(inputUri string, outfile string, args []string) bool {
 
 func runOsLinux(inputUri string, outfile string, args []string) bool {
 	procAttr := &os.ProcAttr{
-		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+		Files: childFiles(),
 	}
 	userArgs := strings.Split(config.Conf.DezoomifyRs, " ")
 	argv := []string{""}
@@ -100,21 +108,20 @@ func runOsLinux(inputUri string, outfile string, args []string) bool {
 	argv = append(argv, inputUri, outfile)
 	process, err := os.StartProcess(config.Conf.DezoomifyPath, argv, procAttr)
 	if err != nil {
-		fmt.Println("start process error:", err)
+		log.Println("start process error:", err)
 		return false
 	}
 	_, err = process.Wait()
 	if err != nil {
-		fmt.Println("wait error:", err)
+		log.Println("wait error:", err)
 		return false
 	}
-	fmt.Println()
 	return true
 }
 
 func runOsWin(inputUri string, outfile string, args []string) bool {
 	procAttr := &os.ProcAttr{
-		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+		Files: childFiles(),
 	}
 	userArgs := strings.Split(config.Conf.DezoomifyRs, " ")
 	argv := []string{"/c", config.Conf.DezoomifyPath}
@@ -127,32 +134,35 @@ func runOsWin(inputUri string, outfile string, args []string) bool {
 	argv = append(argv, inputUri, outfile)
 	process, err := os.StartProcess("C:\\Windows\\System32\\cmd.exe", argv, procAttr)
 	if err != nil {
-		fmt.Println("start process error:", err)
+		log.Println("start process error:", err)
 		return false
 	}
 	_, err = process.Wait()
 	if err != nil {
-		fmt.Println("wait error:", err)
+		log.Println("wait error:", err)
 		return false
 	}
-	fmt.Println()
 	return true
 }
 
 func OpenGUI(args []string) bool {
 	procAttr := &os.ProcAttr{
-		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+		Files: childFiles(),
 	}
 	fPath, _ := os.Executable()
-	binDir := filepath.Dir(fPath)
+	guiPath := filepath.Join(filepath.Dir(fPath), "bookget-gui.exe")
+	//只有 Windows 版附带 bookget-gui，其它系统使用 authflow 本地回调页面
+	if _, err := os.Stat(guiPath); err != nil {
+		return false
+	}
 
 	argv := []string{"/c", "-i"}
 	if args != nil {
 		argv = append(argv, args...)
 	}
-	process, err := os.StartProcess(binDir+"\\bookget-gui.exe", argv, procAttr)
+	process, err := os.StartProcess(guiPath, argv, procAttr)
 	if err != nil {
-		fmt.Println("start process error:", err)
+		log.Println("start process error:", err)
 		return false
 	}
 	_ = process.Release()
@@ -2,6 +2,8 @@ package util
 
 import (
 	"bookget/config"
+	"bookget/pkg/i18n"
+	"bookget/pkg/metrics"
 	"crypto/tls"
 	"log"
 	"net/http"
@@ -61,15 +63,15 @@ func determineContentTypeByRequest(url string) string {
 	// 创建一次性使用的HTTP客户端
 	client := &http.Client{
 		Timeout: config.Conf.Timeout * time.Second,
-		Transport: &http.Transport{
+		Transport: metrics.Transport(&http.Transport{
 			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
 			DisableKeepAlives: true,
-		},
+		}),
 	}
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		log.Printf("创建请求失败: %v", err)
+		log.Println(i18n.T("util.request_create", err))
 		return "bookget"
 	}
 
@@ -78,7 +80,7 @@ func determineContentTypeByRequest(url string) string {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("请求失败: %v", err)
+		log.Println(i18n.T("util.request_failed", err))
 		return "bookget"
 	}
 	defer resp.Body.Close()
@@ -0,0 +1,226 @@
+package verify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 文件类型
+const (
+	TypeUnknown = ""
+	TypeJPEG    = "jpeg"
+	TypePNG     = "png"
+	TypeGIF     = "gif"
+	TypeTIFF    = "tiff"
+	TypeJP2     = "jp2"
+	TypeWebP    = "webp"
+	TypePDF     = "pdf"
+	TypeHTML    = "html"
+	TypeJSON    = "json"
+)
+
+var (
+	ErrEmpty     = errors.New("empty file")
+	ErrTruncated = errors.New("truncated file")
+	ErrHTML      = errors.New("html page saved as image")
+	ErrMismatch  = errors.New("file content does not match extension")
+	ErrCorrupt   = errors.New("corrupt file")
+)
+
+// 尾部最多读取的字节数
+const tailSize = 2048
+
+var (
+	jp2Signature  = []byte{0x00, 0x00, 0x00, 0x0C, 0x6A, 0x50, 0x20, 0x20, 0x0D, 0x0A, 0x87, 0x0A}
+	j2kCodestream = []byte{0xFF, 0x4F, 0xFF, 0x51}
+	pngIEND       = []byte{0x49, 0x45, 0x4E, 0x44, 0xAE, 0x42, 0x60, 0x82}
+)
+
+// Detect 根据文件头部字节识别文件类型
+func Detect(head []byte) string {
+	switch {
+	case len(head) >= 3 && head[0] == 0xFF && head[1] == 0xD8 && head[2] == 0xFF:
+		return TypeJPEG
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return TypePNG
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return TypeGIF
+	case bytes.HasPrefix(head, []byte("II*\x00")), bytes.HasPrefix(head, []byte("MM\x00*")):
+		return TypeTIFF
+	case bytes.HasPrefix(head, jp2Signature), bytes.HasPrefix(head, j2kCodestream):
+		return TypeJP2
+	case len(head) >= 12 && bytes.Equal(head[0:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		return TypeWebP
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return TypePDF
+	}
+	text := bytes.ToLower(bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))))
+	switch {
+	case bytes.HasPrefix(text, []byte("<!doctype html")), bytes.HasPrefix(text, []byte("<html")),
+		bytes.HasPrefix(text, []byte("<head")), bytes.HasPrefix(text, []byte("<body")),
+		bytes.HasPrefix(text, []byte("<?xml")) && bytes.Contains(text, []byte("<html")):
+		return TypeHTML
+	case bytes.HasPrefix(text, []byte("{")), bytes.HasPrefix(text, []byte("[")):
+		return TypeJSON
+	}
+	return TypeUnknown
+}
+
+// TypeByExt 根据扩展名推断期望的文件类型
+func TypeByExt(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return TypeJPEG
+	case ".png":
+		return TypePNG
+	case ".gif":
+		return TypeGIF
+	case ".tif", ".tiff":
+		return TypeTIFF
+	case ".jp2", ".j2k", ".jpx":
+		return TypeJP2
+	case ".webp":
+		return TypeWebP
+	case ".pdf":
+		return TypePDF
+	}
+	return TypeUnknown
+}
+
+// IsPageFile 是否为需要校验的页面文件
+func IsPageFile(name string) bool {
+	return TypeByExt(name) != TypeUnknown
+}
+
+// CheckFile 检查文件头部/尾部结构是否完整
+func CheckFile(path string) (fileType string, err error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	defer fp.Close()
+
+	fi, err := fp.Stat()
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if fi.Size() == 0 {
+		return TypeUnknown, ErrEmpty
+	}
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(fp, head)
+	head = head[:n]
+
+	tailLen := int64(tailSize)
+	if fi.Size() < tailLen {
+		tailLen = fi.Size()
+	}
+	tail := make([]byte, tailLen)
+	if _, err = fp.ReadAt(tail, fi.Size()-tailLen); err != nil && err != io.EOF {
+		return TypeUnknown, err
+	}
+
+	fileType = Detect(head)
+	if fileType == TypeHTML {
+		return fileType, ErrHTML
+	}
+	if want := TypeByExt(path); want != TypeUnknown && fileType != want {
+		if fileType == TypeUnknown {
+			return fileType, ErrCorrupt
+		}
+		return fileType, fmt.Errorf("%w: want %s, got %s", ErrMismatch, want, fileType)
+	}
+
+	switch fileType {
+	case TypeJPEG:
+		err = checkJPEG(fp, tail)
+	case TypePNG:
+		err = checkPNG(fp, tail)
+	case TypeGIF:
+		err = checkImageConfig(fp)
+		if err == nil && !bytes.HasSuffix(bytes.TrimRight(tail, "\x00"), []byte{0x3B}) {
+			err = ErrTruncated
+		}
+	case TypeTIFF:
+		err = checkTIFF(head, fi.Size())
+	case TypeJP2:
+		err = checkJP2(tail)
+	case TypePDF:
+		err = checkPDF(tail)
+	}
+	return fileType, err
+}
+
+func checkImageConfig(fp *os.File) error {
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, _, err := image.DecodeConfig(fp); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return nil
+}
+
+// checkJPEG 解码头部，并检查 EOI 标记(FFD9)，部分服务器会在末尾补零
+func checkJPEG(fp *os.File, tail []byte) error {
+	if err := checkImageConfig(fp); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(bytes.TrimRight(tail, "\x00\r\n"), []byte{0xFF, 0xD9}) {
+		return ErrTruncated
+	}
+	return nil
+}
+
+func checkPNG(fp *os.File, tail []byte) error {
+	if err := checkImageConfig(fp); err != nil {
+		return err
+	}
+	if !bytes.Contains(tail, pngIEND) {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// checkTIFF 检查第一个 IFD 偏移是否落在文件内
+func checkTIFF(head []byte, size int64) error {
+	if len(head) < 8 {
+		return ErrTruncated
+	}
+	var offset int64
+	if head[0] == 'I' {
+		offset = int64(head[4]) | int64(head[5])<<8 | int64(head[6])<<16 | int64(head[7])<<24
+	} else {
+		offset = int64(head[7]) | int64(head[6])<<8 | int64(head[5])<<16 | int64(head[4])<<24
+	}
+	if offset < 8 || offset+2 > size {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// checkJP2 JPEG 2000 码流以 EOC 标记(FFD9)结束
+func checkJP2(tail []byte) error {
+	if !bytes.HasSuffix(bytes.TrimRight(tail, "\x00"), []byte{0xFF, 0xD9}) {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// checkPDF 检查 startxref 与 %%EOF
+func checkPDF(tail []byte) error {
+	if !bytes.Contains(tail, []byte("%%EOF")) || !bytes.Contains(tail, []byte("startxref")) {
+		return ErrTruncated
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ManifestName 每个图书目录下记录下载信息与校验值的文件
+const ManifestName = "bookget.json"
+
+// PageMapName 页面对照表：序号 → 页码标签 → 来源 URL → 文件名
+const PageMapName = "pages.json"
+
+// 页面状态
+const (
+	StatusPending = "pending"
+	StatusOK      = "ok"
+	StatusMissing = "missing"
+	StatusBad     = "bad"
+)
+
+type Page struct {
+	Seq    int    `json:"seq"`
+	Label  string `json:"label,omitempty"` //网站提供的页码/叶码，如 f001r
+	Url    string `json:"url"`
+	File   string `json:"file"`
+	Size   int64  `json:"size,omitempty"`
+	MD5    string `json:"md5,omitempty"`
+	SHA1   string `json:"sha1,omitempty"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	Processed []string `json:"processed,omitempty"` //图像处理替换了原文件时生成的文件，如 0001_a.jpg、0001_b.jpg
+}
+
+type Manifest struct {
+	Url      string    `json:"url"`
+	Title    string    `json:"title,omitempty"`
+	Total    int       `json:"total"`    //图书总页数
+	Expected int       `json:"expected"` //本次下载范围内的页数
+	Pages    []Page    `json:"pages"`
+	Created  time.Time `json:"created"`
+	Verified time.Time `json:"verified,omitempty"`
+}
+
+// flushEvery RecordSaved 每记录这么多页写一次 bookget.json 与 pages.json，其余在 Flush 时写入
+const flushEvery = 50
+
+// book 下载过程中缓存的 bookget.json，files 为已记入的文件名
+type book struct {
+	m     *Manifest
+	files map[string]bool
+	dirty int
+}
+
+var (
+	mu    sync.Mutex
+	books = map[string]*book{} //目录 → 缓存，Flush 时清空
+)
+
+// open 读取并缓存目录下的 bookget.json，调用者持有 mu
+func open(dir string) (*book, error) {
+	if b, ok := books[dir]; ok {
+		return b, nil
+	}
+	m, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	b := &book{m: m, files: make(map[string]bool, len(m.Pages))}
+	for _, p := range m.Pages {
+		b.files[p.File] = true
+	}
+	books[dir] = b
+	return b, nil
+}
+
+// save 写入缓存的 bookget.json 与 pages.json，调用者持有 mu
+func (b *book) save(dir string) error {
+	b.dirty = 0
+	if err := b.m.Save(dir); err != nil {
+		return err
+	}
+	return b.m.SavePageMap(dir)
+}
+
+// Flush 写入 RecordSaved 尚未写入的页面并清空缓存，每个下载任务结束时调用
+func Flush() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var first error
+	for dir, b := range books {
+		if b.dirty > 0 {
+			if err := b.save(dir); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	clear(books)
+	return first
+}
+
+// release 写入 dir 尚未写入的页面并丢弃缓存，之后按磁盘上的 bookget.json 重新读取，调用者持有 mu
+func release(dir string) error {
+	b, ok := books[dir]
+	if !ok {
+		return nil
+	}
+	delete(books, dir)
+	if b.dirty > 0 {
+		return b.save(dir)
+	}
+	return nil
+}
+
+// LoadManifest 读取目录下的 bookget.json，不存在时返回 os.ErrNotExist
+func LoadManifest(dir string) (*Manifest, error) {
+	bs, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err = json.Unmarshal(bs, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save 写入 bookget.json
+func (m *Manifest) Save(dir string) error {
+	sort.Slice(m.Pages, func(i, j int) bool {
+		return m.Pages[i].Seq < m.Pages[j].Seq
+	})
+	bs, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestName), bs, 0644)
+}
+
+// Page 按序号查找页面
+func (m *Manifest) Page(seq int) *Page {
+	for i := range m.Pages {
+		if m.Pages[i].Seq == seq {
+			return &m.Pages[i]
+		}
+	}
+	return nil
+}
+
+// Load 读取 bookget.json，旧版本下载的目录没有时按文件名顺序生成（不写回）
+func Load(dir string) (*Manifest, error) {
+	mu.Lock()
+	err := release(dir)
+	mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	m, err := LoadManifest(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifestFromFiles(dir)
+	}
+	return m, err
+}
+
+// SetTitle 记录书名，供 bookget view 等显示
+func SetTitle(dir, title string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	b, err := open(dir)
+	if err != nil {
+		return err
+	}
+	if b.m.Title == title {
+		return nil
+	}
+	b.m.Title = title
+	return b.save(dir)
+}
+
+// Record 下载开始前记录期望的页面列表，已有的校验值会被保留
+func Record(dir, bookUrl string, total int, pages []Page) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	b, err := open(dir)
+	if err != nil {
+		b = &book{m: &Manifest{Created: time.Now()}, files: make(map[string]bool)}
+		books[dir] = b
+	}
+	m := b.m
+	m.Url = bookUrl
+	m.Total = total
+	for _, p := range pages {
+		if old := m.Page(p.Seq); old != nil {
+			if old.File != p.File || old.Url != p.Url || old.Label != p.Label {
+				delete(b.files, old.File)
+				*old = p
+			}
+		} else {
+			m.Pages = append(m.Pages, p)
+		}
+		b.files[p.File] = true
+	}
+	m.Expected = len(m.Pages)
+	return b.save(dir)
+}
+
+// RecordSaved 记录已保存的页面文件 dest：bookget.json 中没有时按文件名前的序号补上，并更新 pages.json。
+// 下载前没有调用 Record 的下载器由此也能生成页面对照表；每 flushEvery 页写一次，其余由 Flush 写入
+func RecordSaved(dest, src string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, file := filepath.Split(dest)
+	dir = filepath.Clean(dir)
+	b, err := open(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		b = &book{m: &Manifest{Created: time.Now()}, files: make(map[string]bool)}
+		books[dir] = b
+	} else if err != nil {
+		return err
+	}
+	if b.files[file] {
+		return nil
+	}
+	m := b.m
+	seq := seqOf(file)
+	if seq == 0 || m.Page(seq) != nil {
+		seq = 1
+		for _, p := range m.Pages {
+			seq = max(seq, p.Seq+1)
+		}
+	}
+	m.Pages = append(m.Pages, Page{Seq: seq, Url: src, File: file, Status: StatusPending})
+	m.Expected = len(m.Pages)
+	m.Total = max(m.Total, m.Expected)
+	b.files[file] = true
+	if b.dirty++; b.dirty >= flushEvery {
+		return b.save(dir)
+	}
+	return nil
+}
+
+// SetProcessed 记录页面文件 dest 已被图像处理替换为 files（同一目录下），verify 与续传时视为已下载
+func SetProcessed(dest string, files []string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, file := filepath.Split(dest)
+	dir = filepath.Clean(dir)
+	b, err := open(dir)
+	if err != nil {
+		return err
+	}
+	m := b.m
+	for i := range m.Pages {
+		if m.Pages[i].File != file {
+			continue
+		}
+		names := make([]string, 0, len(files))
+		for _, f := range files {
+			if rel, err := filepath.Rel(dir, f); err == nil {
+				names = append(names, filepath.ToSlash(rel))
+			}
+		}
+		m.Pages[i].Processed = names
+		return b.save(dir)
+	}
+	return nil
+}
+
+// IsProcessed 页面文件 dest 已被图像处理替换，且替换后的文件都在
+func IsProcessed(dest string) bool {
+	dir, file := filepath.Split(dest)
+	dir = filepath.Clean(dir)
+	mu.Lock()
+	b, err := open(dir)
+	var processed []string
+	if err == nil {
+		for _, p := range b.m.Pages {
+			if p.File == file {
+				processed = p.Processed
+				break
+			}
+		}
+	}
+	mu.Unlock()
+	if len(processed) == 0 {
+		return false
+	}
+	for _, name := range processed {
+		if _, err = os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// seqOf 文件名开头的序号，如 0012_f006v.jpg → 12，没有时为 0
+func seqOf(file string) int {
+	n := 0
+	for _, c := range file {
+		if c < '0' || c > '9' {
+			break
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
+
+// PageMapEntry pages.json 中的一项
+type PageMapEntry struct {
+	Seq   int    `json:"seq"`
+	Label string `json:"label"`
+	Url   string `json:"url"`
+	File  string `json:"file"`
+}
+
+// SavePageMap 写入 pages.json，便于按叶码引用与核对
+func (m *Manifest) SavePageMap(dir string) error {
+	entries := make([]PageMapEntry, 0, len(m.Pages))
+	for _, p := range m.Pages {
+		entries = append(entries, PageMapEntry{Seq: p.Seq, Label: p.Label, Url: p.Url, File: p.File})
+	}
+	bs, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, PageMapName), bs, 0644)
+}
@@ -0,0 +1,210 @@
+// Package verify 检查已下载图书的完整性：图片/PDF 结构、页数以及校验值。
+package verify
+
+import (
+	"bookget/pkg/hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Result struct {
+	Dir      string
+	Url      string
+	Expected int
+	Found    int
+	Missing  []Page
+	Bad      []Page
+}
+
+// OK 没有缺页和坏页
+func (r *Result) OK() bool {
+	return len(r.Missing) == 0 && len(r.Bad) == 0
+}
+
+// Dir 校验单个图书目录，并把校验值写回 bookget.json；
+// 旧版本下载的目录没有 bookget.json 时只校验不写入，repair=true 时才生成
+func Dir(dir string, repair bool) (*Result, error) {
+	m, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	_, err = os.Stat(filepath.Join(dir, ManifestName))
+	write := repair || err == nil
+
+	r := &Result{Dir: dir, Url: m.Url, Expected: m.Expected}
+	for i := range m.Pages {
+		p := &m.Pages[i]
+		checkPage(dir, p)
+		switch p.Status {
+		case StatusOK:
+			r.Found++
+		case StatusMissing:
+			r.Missing = append(r.Missing, *p)
+		default:
+			r.Found++
+			r.Bad = append(r.Bad, *p)
+		}
+	}
+	if !write {
+		return r, nil
+	}
+	m.Verified = time.Now()
+	if err = m.Save(dir); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// skipDirs Walk 时跳过的子目录，如 iiif-export 生成的切片目录、图像处理的输出目录
+var (
+	skipMu   sync.RWMutex
+	skipDirs = map[string]bool{"iiif": true, "processed": true}
+)
+
+// SkipDir 把子目录名 name 加入 Walk 时跳过的目录（如 --imageproc-output 指定的目录）
+func SkipDir(name string) {
+	if name == "" {
+		return
+	}
+	skipMu.Lock()
+	defer skipMu.Unlock()
+	skipDirs[filepath.Base(name)] = true
+}
+
+func isSkipDir(name string) bool {
+	skipMu.RLock()
+	defer skipMu.RUnlock()
+	return skipDirs[name]
+}
+
+// Walk 递归校验 root 下所有包含页面文件的目录，repair 见 Dir
+func Walk(root string, repair bool, fn func(r *Result, err error)) error {
+	return WalkDirs(root, func(dir string) {
+		fn(Dir(dir, repair))
+	})
+}
+
+// WalkDirs 递归查找 root 下所有包含 bookget.json 或页面文件的目录
+func WalkDirs(root string, fn func(dir string)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if path != root && isSkipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if isBookDir(path) {
+			fn(path)
+		}
+		return nil
+	})
+}
+
+func isBookDir(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ManifestName)); err == nil {
+		return true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && IsPageFile(e.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestFromFiles 旧版本下载的目录没有 bookget.json，按文件名顺序生成
+func manifestFromFiles(dir string) (*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && IsPageFile(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	m := &Manifest{Created: time.Now(), Pages: make([]Page, 0, len(names))}
+	for i, name := range names {
+		m.Pages = append(m.Pages, Page{Seq: i + 1, File: name})
+	}
+	m.Total = len(m.Pages)
+	m.Expected = len(m.Pages)
+	return m, nil
+}
+
+func checkPage(dir string, p *Page) {
+	p.Error = ""
+	dest := filepath.Join(dir, p.File)
+	fi, err := os.Stat(dest)
+	if err != nil && len(p.Processed) > 0 {
+		checkProcessed(dir, p)
+		return
+	}
+	if err != nil {
+		p.Status = StatusMissing
+		p.Size, p.MD5, p.SHA1 = 0, "", ""
+		return
+	}
+	p.Size = fi.Size()
+	if _, err = CheckFile(dest); err != nil {
+		p.Status = StatusBad
+		p.Error = err.Error()
+		return
+	}
+	if p.MD5, p.SHA1, err = sums(dest); err != nil {
+		p.Status = StatusBad
+		p.Error = err.Error()
+		return
+	}
+	p.Status = StatusOK
+}
+
+// checkProcessed 原文件已被图像处理替换（如覆盖模式下拆成两页），检查替换后的文件
+func checkProcessed(dir string, p *Page) {
+	p.Size, p.MD5, p.SHA1 = 0, "", ""
+	for _, name := range p.Processed {
+		dest := filepath.Join(dir, name)
+		fi, err := os.Stat(dest)
+		if err != nil {
+			p.Status = StatusMissing
+			return
+		}
+		p.Size += fi.Size()
+		if _, err = CheckFile(dest); err != nil {
+			p.Status = StatusBad
+			p.Error = err.Error()
+			return
+		}
+	}
+	p.Status = StatusOK
+}
+
+func sums(path string) (md5sum, sha1sum string, err error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer fp.Close()
+
+	mh, err := hash.NewMultiHasherTypes(hash.NewHashSet(hash.MD5, hash.SHA1))
+	if err != nil {
+		return "", "", err
+	}
+	if _, err = io.Copy(mh, fp); err != nil {
+		return "", "", err
+	}
+	md5sum, _ = mh.SumString(hash.MD5, false)
+	sha1sum, _ = mh.SumString(hash.SHA1, false)
+	return md5sum, sha1sum, nil
+}
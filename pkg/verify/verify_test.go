@@ -0,0 +1,185 @@
+package verify_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/verify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeJPEG(t *testing.T, path string) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 16)), nil))
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	for _, test := range []struct {
+		head []byte
+		want string
+	}{
+		{[]byte{0xFF, 0xD8, 0xFF, 0xE0}, verify.TypeJPEG},
+		{[]byte("\x89PNG\r\n\x1a\n...."), verify.TypePNG},
+		{[]byte("II*\x00\x08\x00\x00\x00"), verify.TypeTIFF},
+		{[]byte{0x00, 0x00, 0x00, 0x0C, 0x6A, 0x50, 0x20, 0x20, 0x0D, 0x0A, 0x87, 0x0A}, verify.TypeJP2},
+		{[]byte("%PDF-1.7"), verify.TypePDF},
+		{[]byte("  <!DOCTYPE html><html>"), verify.TypeHTML},
+		{[]byte(`{"error":"forbidden"}`), verify.TypeJSON},
+		{[]byte("plain text"), verify.TypeUnknown},
+	} {
+		assert.Equal(t, test.want, verify.Detect(test.head), string(test.head))
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	dir := t.TempDir()
+
+	good := filepath.Join(dir, "0001.jpg")
+	data := writeJPEG(t, good)
+	_, err := verify.CheckFile(good)
+	assert.NoError(t, err)
+
+	truncated := filepath.Join(dir, "0002.jpg")
+	require.NoError(t, os.WriteFile(truncated, data[:len(data)-10], 0644))
+	_, err = verify.CheckFile(truncated)
+	assert.Error(t, err)
+
+	html := filepath.Join(dir, "0003.jpg")
+	require.NoError(t, os.WriteFile(html, []byte("<html><body>captcha</body></html>"), 0644))
+	_, err = verify.CheckFile(html)
+	assert.ErrorIs(t, err, verify.ErrHTML)
+
+	png := filepath.Join(dir, "0004.png")
+	require.NoError(t, os.WriteFile(png, data, 0644))
+	_, err = verify.CheckFile(png)
+	assert.ErrorIs(t, err, verify.ErrMismatch)
+
+	pdf := filepath.Join(dir, "0005.pdf")
+	require.NoError(t, os.WriteFile(pdf, []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"), 0644))
+	_, err = verify.CheckFile(pdf)
+	assert.ErrorIs(t, err, verify.ErrTruncated)
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	writeJPEG(t, filepath.Join(dir, "0001.jpg"))
+
+	pages := []verify.Page{
+		{Seq: 1, Url: "https://example.com/1.jpg", File: "0001.jpg"},
+		{Seq: 2, Url: "https://example.com/2.jpg", File: "0002.jpg"},
+	}
+	require.NoError(t, verify.Record(dir, "https://example.com/book", 2, pages))
+
+	r, err := verify.Dir(dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.Expected)
+	assert.Equal(t, 1, r.Found)
+	require.Len(t, r.Missing, 1)
+	assert.Equal(t, "0002.jpg", r.Missing[0].File)
+
+	m, err := verify.LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, verify.StatusOK, m.Page(1).Status)
+	assert.Len(t, m.Page(1).MD5, 32)
+	assert.Equal(t, verify.StatusMissing, m.Page(2).Status)
+
+	//旧目录没有 bookget.json：只校验不写入，repair 时才生成
+	dir = t.TempDir()
+	writeJPEG(t, filepath.Join(dir, "0001.jpg"))
+	r, err = verify.Dir(dir, false)
+	require.NoError(t, err)
+	assert.True(t, r.OK())
+	assert.NoFileExists(t, filepath.Join(dir, verify.ManifestName))
+	_, err = verify.Dir(dir, true)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, verify.ManifestName))
+}
+
+func TestRecordPageMap(t *testing.T) {
+	dir := t.TempDir()
+	pages := []verify.Page{
+		{Seq: 2, Label: "f001v", Url: "https://example.com/2.jpg", File: "0002_f001v.jpg"},
+		{Seq: 1, Label: "f001r", Url: "https://example.com/1.jpg", File: "0001_f001r.jpg"},
+	}
+	require.NoError(t, verify.Record(dir, "https://example.com/book", 2, pages))
+
+	bs, err := os.ReadFile(filepath.Join(dir, verify.PageMapName))
+	require.NoError(t, err)
+	var entries []verify.PageMapEntry
+	require.NoError(t, json.Unmarshal(bs, &entries))
+	assert.Equal(t, []verify.PageMapEntry{
+		{Seq: 1, Label: "f001r", Url: "https://example.com/1.jpg", File: "0001_f001r.jpg"},
+		{Seq: 2, Label: "f001v", Url: "https://example.com/2.jpg", File: "0002_f001v.jpg"},
+	}, entries)
+}
+
+func TestRecordSaved(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"0002.jpg", "0001.jpg", "0002.jpg", "cover.jpg"} {
+		require.NoError(t, verify.RecordSaved(filepath.Join(dir, f), "https://example.com/"+f))
+	}
+	//未满一批时等到 Flush 才写入
+	assert.NoFileExists(t, filepath.Join(dir, verify.PageMapName))
+	require.NoError(t, verify.Flush())
+	bs, err := os.ReadFile(filepath.Join(dir, verify.PageMapName))
+	require.NoError(t, err)
+	var entries []verify.PageMapEntry
+	require.NoError(t, json.Unmarshal(bs, &entries))
+	assert.Equal(t, []verify.PageMapEntry{
+		{Seq: 1, Url: "https://example.com/0001.jpg", File: "0001.jpg"},
+		{Seq: 2, Url: "https://example.com/0002.jpg", File: "0002.jpg"},
+		{Seq: 3, Url: "https://example.com/cover.jpg", File: "cover.jpg"},
+	}, entries)
+
+	//已由 Record 记录的页面不重复添加
+	dir = t.TempDir()
+	require.NoError(t, verify.Record(dir, "https://example.com/book", 1, []verify.Page{{Seq: 1, Label: "f001r", File: "0001_f001r.jpg"}}))
+	require.NoError(t, verify.RecordSaved(filepath.Join(dir, "0001_f001r.jpg"), "https://example.com/1.jpg"))
+	m, err := verify.LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Len(t, m.Pages, 1)
+	assert.Equal(t, "f001r", m.Pages[0].Label)
+}
+
+func TestProcessed(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, verify.Record(dir, "https://example.com/book", 1, []verify.Page{{Seq: 1, File: "0001.jpg"}}))
+	//覆盖模式下拆分：原文件删除，生成两页
+	writeJPEG(t, filepath.Join(dir, "0001_a.jpg"))
+	writeJPEG(t, filepath.Join(dir, "0001_b.jpg"))
+	dest := filepath.Join(dir, "0001.jpg")
+	assert.False(t, verify.IsProcessed(dest))
+	require.NoError(t, verify.SetProcessed(dest, []string{filepath.Join(dir, "0001_a.jpg"), filepath.Join(dir, "0001_b.jpg")}))
+	assert.True(t, verify.IsProcessed(dest))
+
+	r, err := verify.Dir(dir, false)
+	require.NoError(t, err)
+	assert.True(t, r.OK())
+	assert.Equal(t, 1, r.Found)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "0001_b.jpg")))
+	assert.False(t, verify.IsProcessed(dest))
+	r, err = verify.Dir(dir, false)
+	require.NoError(t, err)
+	assert.Len(t, r.Missing, 1)
+
+	//图像处理的输出目录不当作图书目录
+	root := t.TempDir()
+	writeJPEG(t, filepath.Join(root, "0001.jpg"))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "processed"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "out"), 0755))
+	writeJPEG(t, filepath.Join(root, "processed", "0001.jpg"))
+	writeJPEG(t, filepath.Join(root, "out", "0001.jpg"))
+	verify.SkipDir("out")
+	var dirs []string
+	require.NoError(t, verify.WalkDirs(root, func(d string) { dirs = append(dirs, d) }))
+	assert.Equal(t, []string{root}, dirs)
+}
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"bookget/pkg/i18n"
 )
 
 const (
@@ -52,7 +54,7 @@ func (c *Checker) CheckForUpdate() (string, bool, error) {
 	// 获取最新版本
 	latestVersion, err := c.getLatestVersion()
 	if err != nil {
-		return "", false, fmt.Errorf("获取最新版本失败: %w", err)
+		return "", false, i18n.Errorf("version.latest_failed", err)
 	}
 
 	c.LastChecked = time.Now()
@@ -83,7 +85,7 @@ func (c *Checker) getLatestVersion() (string, error) {
 
 	// 更新缓存
 	if err := c.writeCache(version); err != nil {
-		return "", fmt.Errorf("更新缓存失败: %w", err)
+		return "", i18n.Errorf("version.cache_failed", err)
 	}
 
 	return version, nil
@@ -93,22 +95,22 @@ func (c *Checker) fetchFromGitHub() (string, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", c.RepoOwner, c.RepoName)
 	resp, err := http.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("GitHub API请求失败: %w", err)
+		return "", i18n.Errorf("version.api_failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API返回非200状态码: %d", resp.StatusCode)
+		return "", i18n.Errorf("version.api_status", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应体失败: %w", err)
+		return "", i18n.Errorf("version.read_failed", err)
 	}
 
 	var release githubRelease
 	if err := json.Unmarshal(body, &release); err != nil {
-		return "", fmt.Errorf("解析JSON失败: %w", err)
+		return "", i18n.Errorf("version.json_failed", err)
 	}
 
 	return strings.TrimPrefix(release.TagName, "v"), nil
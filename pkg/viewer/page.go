@@ -0,0 +1,200 @@
+package viewer
+
+import "html/template"
+
+var indexTpl = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head><meta charset="utf-8"><title>bookget 书架</title>
+<style>
+body{font-family:sans-serif;max-width:960px;margin:2em auto;padding:0 1em;line-height:1.6;color:#222}
+h2{border-bottom:1px solid #ddd;font-size:1.1em;margin-top:2em}
+ul{list-style:none;padding:0}li{padding:.2em 0}
+a{color:#0645ad;text-decoration:none}a:hover{text-decoration:underline}
+.meta{color:#777;font-size:.9em;margin-left:.8em}
+</style></head>
+<body>
+<h1>bookget 书架</h1>
+<p class="meta">{{.Root}}</p>
+{{range .Groups}}<h2>{{if .Host}}{{.Host}}{{else}}—{{end}}</h2>
+<ul>{{range .Books}}
+<li><a href="/book?key={{.Key}}">{{.Title}}</a><span class="meta">{{.BookId}} · {{len .Volumes}} 卷 · {{.Pages}} 页{{if .Toc}} · 有目录{{end}}</span></li>{{end}}
+</ul>
+{{else}}<p>没有找到已下载的图书。</p>{{end}}
+</body></html>
+`))
+
+// readerHtml 阅读页面：单页 / 双页 / 竖向滚动，从右往左或从左往右，分卷切换与目录面板。
+// 快捷键：←/→ 翻页（随阅读方向），↑/↓ PageUp/PageDown 空格 上/下一页，Home/End，
+// [ ] 上/下一卷，m 切换模式，d 切换方向，t 目录
+const readerHtml = `<!DOCTYPE html>
+<html lang="zh">
+<head><meta charset="utf-8"><title>bookget</title>
+<style>
+*{box-sizing:border-box}
+html,body{margin:0;height:100%;background:#333;color:#eee;font-family:sans-serif}
+#bar{position:fixed;top:0;left:0;right:0;height:40px;display:flex;gap:.6em;align-items:center;padding:0 .8em;background:#222;z-index:2;font-size:14px}
+#bar a{color:#9cf;text-decoration:none}
+#title{flex:1;white-space:nowrap;overflow:hidden;text-overflow:ellipsis}
+#bar select,#bar button,#bar input{background:#444;color:#eee;border:1px solid #666;border-radius:3px;padding:2px 6px}
+#pos{width:4.5em;text-align:right}
+#toc{position:fixed;top:40px;bottom:0;left:0;width:300px;overflow:auto;background:#2a2a2a;padding:.5em;display:none;z-index:2;font-size:14px}
+#toc.open{display:block}
+#toc div{padding:2px 4px;cursor:pointer;border-radius:3px}
+#toc div:hover{background:#444}
+#toc div.none{color:#888;cursor:default}
+#stage{position:absolute;top:40px;left:0;right:0;bottom:0;display:flex;justify-content:center;align-items:center;overflow:hidden}
+#stage img{max-height:100%;max-width:100%;object-fit:contain;background:#fff}
+#stage.spread img{max-width:50%}
+#stage.rtl.spread{flex-direction:row-reverse}
+#stage.scroll{display:block;overflow:auto;text-align:center}
+#stage.scroll img{display:block;margin:0 auto 8px;max-width:min(100%,1200px);max-height:none}
+#label{position:fixed;bottom:6px;right:10px;background:rgba(0,0,0,.5);padding:2px 8px;border-radius:3px;font-size:13px}
+</style></head>
+<body>
+<div id="bar">
+<a href="/">书架</a>
+<span id="title"></span>
+<select id="vol" title="分卷 [ ]"></select>
+<select id="mode" title="模式 m"><option value="single">单页</option><option value="spread">双页</option><option value="scroll">竖向滚动</option></select>
+<button id="dir" title="阅读方向 d"></button>
+<button id="tocBtn" title="目录 t">目录</button>
+<input id="pos" title="页码"> / <span id="total"></span>
+</div>
+<div id="toc"></div>
+<div id="stage"></div>
+<div id="label"></div>
+<script>
+(function(){
+var $=function(id){return document.getElementById(id)};
+var book,vol=0,page=0,mode=localStorage.getItem("bookget.mode")||"single",dir="rtl";
+var stage=$("stage");
+
+function pages(){return book.volumes[vol].pages}
+function step(){return mode==="spread"?2:1}
+function saveHash(){history.replaceState(null,"","#v="+vol+"&p="+(page+1))}
+
+function render(){
+  var ps=pages();
+  page=Math.max(0,Math.min(page,ps.length-1));
+  stage.className=mode+" "+dir;
+  $("dir").textContent=dir==="rtl"?"从右往左":"从左往右";
+  $("total").textContent=ps.length;
+  $("pos").value=page+1;
+  var p=ps[page];
+  $("label").textContent=(p.label?p.label+"  ":"")+p.file;
+  if(mode==="scroll"){
+    if(stage.dataset.vol!==String(vol)||!stage.querySelector("img")){
+      stage.innerHTML="";
+      ps.forEach(function(p,i){var img=document.createElement("img");img.loading="lazy";img.src=p.src;img.dataset.i=i;img.title=p.label||p.file;stage.appendChild(img)});
+      stage.dataset.vol=vol;
+    }
+    var img=stage.querySelector('img[data-i="'+page+'"]');
+    if(img)img.scrollIntoView();
+  }else{
+    stage.dataset.vol="";
+    stage.innerHTML="";
+    for(var i=page;i<Math.min(page+step(),ps.length);i++){
+      var img=document.createElement("img");img.src=ps[i].src;img.title=ps[i].label||ps[i].file;stage.appendChild(img);
+    }
+    if(page+step()<ps.length){new Image().src=ps[page+step()].src}
+  }
+  saveHash();
+}
+
+function go(n){page=n;render()}
+function next(){if(page+step()<pages().length)go(page+step());else if(vol<book.volumes.length-1){vol++;page=0;syncVol();render()}}
+function prev(){if(page>0)go(page-step());else if(vol>0){vol--;page=pages().length-1;syncVol();render()}}
+function setVol(v){if(v<0||v>=book.volumes.length)return;vol=v;page=0;syncVol();render()}
+function syncVol(){$("vol").value=vol}
+
+// 目录中的页码为全书顺序号，换算为 卷/页
+function locate(n){
+  for(var v=0;v<book.volumes.length;v++){
+    var size=book.volumes[v].pages.length;
+    if(n<=size)return [v,n-1];
+    n-=size;
+  }
+  return null;
+}
+function locateLabel(label){
+  for(var v=0;v<book.volumes.length;v++){
+    var ps=book.volumes[v].pages;
+    for(var i=0;i<ps.length;i++)if(ps[i].label===label)return [v,i];
+  }
+  return null;
+}
+
+function buildToc(){
+  var toc=$("toc");
+  if(!book.toc||!book.toc.length){$("tocBtn").disabled=true;return}
+  book.toc.forEach(function(e){
+    var d=document.createElement("div");
+    d.textContent=e.title+(e.page?"  "+e.page:"");
+    d.style.paddingLeft=(4+e.level*16)+"px";
+    var loc=/^\d+$/.test(e.page)?locate(parseInt(e.page,10)):locateLabel(e.page);
+    if(loc){d.onclick=function(){vol=loc[0];page=loc[1];syncVol();render()}}else{d.className="none"}
+    toc.appendChild(d);
+  });
+}
+
+document.addEventListener("keydown",function(e){
+  if(e.target.tagName==="INPUT"||e.target.tagName==="SELECT"||e.ctrlKey||e.metaKey||e.altKey)return;
+  var k=e.key;
+  if(mode==="scroll"&&(k==="ArrowUp"||k==="ArrowDown"||k===" "))return;
+  if(k==="ArrowLeft"){dir==="rtl"?next():prev()}
+  else if(k==="ArrowRight"){dir==="rtl"?prev():next()}
+  else if(k==="ArrowDown"||k==="PageDown"||k===" "||k==="j"){next()}
+  else if(k==="ArrowUp"||k==="PageUp"||k==="k"){prev()}
+  else if(k==="Home"){go(0)}
+  else if(k==="End"){go(pages().length-1)}
+  else if(k==="["){setVol(vol-1)}
+  else if(k==="]"){setVol(vol+1)}
+  else if(k==="m"){var o=["single","spread","scroll"];mode=o[(o.indexOf(mode)+1)%o.length];$("mode").value=mode;localStorage.setItem("bookget.mode",mode);render()}
+  else if(k==="d"){dir=dir==="rtl"?"ltr":"rtl";render()}
+  else if(k==="t"){$("toc").classList.toggle("open")}
+  else return;
+  e.preventDefault();
+});
+stage.addEventListener("click",function(e){
+  if(mode==="scroll")return;
+  var left=e.clientX<window.innerWidth/2;
+  if(left===(dir==="rtl"))next();else prev();
+});
+stage.addEventListener("scroll",function(){
+  if(mode!=="scroll")return;
+  var imgs=stage.querySelectorAll("img");
+  for(var i=0;i<imgs.length;i++){
+    if(imgs[i].offsetTop+imgs[i].offsetHeight>stage.scrollTop+10){
+      page=+imgs[i].dataset.i;$("pos").value=page+1;
+      var p=pages()[page];$("label").textContent=(p.label?p.label+"  ":"")+p.file;
+      saveHash();break;
+    }
+  }
+});
+$("vol").onchange=function(){setVol(+this.value);this.blur()};
+$("mode").onchange=function(){mode=this.value;localStorage.setItem("bookget.mode",mode);render();this.blur()};
+$("dir").onclick=function(){dir=dir==="rtl"?"ltr":"rtl";render();this.blur()};
+$("tocBtn").onclick=function(){$("toc").classList.toggle("open");this.blur()};
+$("pos").onchange=function(){go((parseInt(this.value,10)||1)-1);this.blur()};
+
+var key=new URLSearchParams(location.search).get("key")||".";
+fetch("/api/book?key="+encodeURIComponent(key)).then(function(r){
+  if(!r.ok)throw new Error(r.status+" "+r.statusText);
+  return r.json();
+}).then(function(b){
+  book=b;dir=b.direction||"rtl";
+  document.title=b.title+" - bookget";
+  $("title").textContent=b.title;
+  $("title").title=b.url||"";
+  b.volumes.forEach(function(v,i){var o=document.createElement("option");o.value=i;o.textContent=v.name?"第 "+v.name+" 卷":"全一卷";$("vol").appendChild(o)});
+  if(b.volumes.length<2)$("vol").disabled=true;
+  $("mode").value=mode;
+  var h=new URLSearchParams(location.hash.slice(1));
+  vol=Math.max(0,Math.min(parseInt(h.get("v"),10)||0,b.volumes.length-1));
+  page=(parseInt(h.get("p"),10)||1)-1;
+  syncVol();buildToc();render();
+}).catch(function(err){stage.textContent=err.message});
+})();
+</script>
+</body></html>
+`
@@ -0,0 +1,241 @@
+// Package viewer 本地阅读服务：按 CreateDirectory 的目录结构（<域名>_<图书ID>/[vol.<卷号>/]）列出已下载的图书，
+// 在浏览器中逐页校对，无需手工打开成千上万张图片。
+package viewer
+
+import (
+	"bookget/pkg/catalog"
+	"bookget/pkg/iiifexport"
+	"bookget/pkg/verify"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Book struct {
+	Key       string          `json:"key"` //相对根目录的路径，/ 分隔
+	Host      string          `json:"host"`
+	BookId    string          `json:"bookId"`
+	Title     string          `json:"title"`
+	Url       string          `json:"url,omitempty"`
+	Direction string          `json:"direction"` //rtl|ltr
+	Pages     int             `json:"pages"`
+	Volumes   []Volume        `json:"volumes"`
+	Toc       []catalog.Entry `json:"toc,omitempty"`
+}
+
+type Volume struct {
+	Name  string `json:"name"`
+	Pages []Page `json:"pages"`
+}
+
+type Page struct {
+	Src   string `json:"src"`
+	File  string `json:"file"`
+	Label string `json:"label,omitempty"`
+}
+
+// Scan 列出 root 下的图书；root 本身就是图书目录时只返回这一本
+func Scan(root string) ([]*Book, error) {
+	if b, err := LoadBook(root, "."); err == nil {
+		return []*Book{b}, nil
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var books []*Book
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if b, err := LoadBook(root, e.Name()); err == nil {
+			books = append(books, b)
+		}
+	}
+	sort.SliceStable(books, func(i, j int) bool {
+		if books[i].Host != books[j].Host {
+			return books[i].Host < books[j].Host
+		}
+		return books[i].BookId < books[j].BookId
+	})
+	return books, nil
+}
+
+// LoadBook 读取 root 下 key 目录中的图书：卷、页序、页码标签、书名与目录
+func LoadBook(root, key string) (*Book, error) {
+	key = path.Clean("/" + filepath.ToSlash(key))[1:]
+	if key == "" {
+		key = "."
+	}
+	dir := filepath.Join(root, filepath.FromSlash(key))
+	b := &Book{Key: key, Volumes: []Volume{}}
+	name := filepath.Base(filepath.Clean(dir))
+	b.Host, b.BookId = splitDirName(name)
+
+	vol, m, err := loadVolume(dir, key, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(vol.Pages) > 0 {
+		b.Volumes = append(b.Volumes, vol)
+		b.Url, b.Title = m.Url, m.Title
+	} else {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() || !strings.HasPrefix(e.Name(), "vol.") {
+				continue
+			}
+			vol, m, err := loadVolume(filepath.Join(dir, e.Name()), key, e.Name())
+			if err != nil || len(vol.Pages) == 0 {
+				continue
+			}
+			b.Volumes = append(b.Volumes, vol)
+			if b.Url == "" {
+				b.Url = m.Url
+			}
+			if b.Title == "" {
+				b.Title = m.Title
+			}
+		}
+	}
+	if len(b.Volumes) == 0 {
+		return nil, os.ErrNotExist
+	}
+	for _, v := range b.Volumes {
+		b.Pages += len(v.Pages)
+	}
+	if b.Title == "" {
+		b.Title = b.BookId
+	}
+	b.Toc = catalog.Read(dir)
+	b.Direction = iiifexport.Direction(iiifexport.DirectionAuto, b.Url, b.Title, b.Toc)
+	return b, nil
+}
+
+func loadVolume(dir, key, name string) (Volume, *verify.Manifest, error) {
+	v := Volume{Name: strings.TrimPrefix(name, "vol."), Pages: []Page{}}
+	m, err := verify.Load(dir)
+	if err != nil {
+		return v, nil, err
+	}
+	for _, p := range m.Pages {
+		if _, err := os.Stat(filepath.Join(dir, p.File)); err != nil || strings.EqualFold(path.Ext(p.File), ".pdf") {
+			continue
+		}
+		file := path.Join(name, p.File)
+		v.Pages = append(v.Pages, Page{Src: "/files/" + escapePath(path.Join(key, file)), File: file, Label: p.Label})
+	}
+	return v, m, nil
+}
+
+// splitDirName www.example.com_123 → www.example.com, 123；端口号中的 : 被替换成了 _
+func splitDirName(name string) (host, bookId string) {
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return "", name
+	}
+	n := 1
+	if len(parts) > 2 && isDigits(parts[1]) {
+		n = 2
+	}
+	return strings.Join(parts[:n], ":"), strings.Join(parts[n:], "_")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, "/")
+}
+
+// Handler 阅读服务的路由：
+//
+//	/               图书列表（按网站分组）
+//	/book?key=      阅读页面
+//	/api/books      图书列表 JSON
+//	/api/book?key=  单本图书 JSON
+//	/files/…        图片文件
+func Handler(root string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		books, err := Scan(root)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = indexTpl.Execute(w, struct {
+			Root   string
+			Groups []group
+		}{root, groupByHost(books)})
+	})
+	mux.HandleFunc("/book", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(readerHtml))
+	})
+	mux.HandleFunc("/api/books", func(w http.ResponseWriter, r *http.Request) {
+		books, err := Scan(root)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, books)
+	})
+	mux.HandleFunc("/api/book", func(w http.ResponseWriter, r *http.Request) {
+		b, err := LoadBook(root, r.URL.Query().Get("key"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, b)
+	})
+	mux.Handle("/files/", http.StripPrefix("/files/", http.FileServer(http.Dir(root))))
+	return mux
+}
+
+type group struct {
+	Host  string
+	Books []*Book
+}
+
+func groupByHost(books []*Book) []group {
+	var groups []group
+	for _, b := range books {
+		if n := len(groups); n > 0 && groups[n-1].Host == b.Host {
+			groups[n-1].Books = append(groups[n-1].Books, b)
+			continue
+		}
+		groups = append(groups, group{Host: b.Host, Books: []*Book{b}})
+	}
+	return groups
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
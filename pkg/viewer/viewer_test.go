@@ -0,0 +1,100 @@
+package viewer_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bookget/pkg/verify"
+	"bookget/pkg/viewer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func touch(t *testing.T, path string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+}
+
+func library(t *testing.T) string {
+	root := t.TempDir()
+	book := filepath.Join(root, "gj.tianyige.com.cn_abc")
+	touch(t, filepath.Join(book, "vol.0001", "0001.jpg"))
+	touch(t, filepath.Join(book, "vol.0001", "0002.jpg"))
+	touch(t, filepath.Join(book, "vol.0002", "0001.jpg"))
+	require.NoError(t, os.WriteFile(filepath.Join(book, "bookmark.txt"), []byte("#版本=1.0\r\n卷二......3\r\n"), 0644))
+
+	other := filepath.Join(root, "localhost_8080_book_1")
+	touch(t, filepath.Join(other, "0001.jpg"))
+	require.NoError(t, verify.Record(other, "http://localhost:8080/book/1", 1,
+		[]verify.Page{{Seq: 1, Label: "f1r", Url: "http://localhost:8080/1.jpg", File: "0001.jpg"}}))
+	require.NoError(t, verify.SetTitle(other, "Book One"))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "empty_dir"), os.ModePerm))
+	return root
+}
+
+func TestScan(t *testing.T) {
+	root := library(t)
+	books, err := viewer.Scan(root)
+	require.NoError(t, err)
+	require.Len(t, books, 2)
+
+	b := books[0]
+	assert.Equal(t, "gj.tianyige.com.cn", b.Host)
+	assert.Equal(t, "abc", b.BookId)
+	assert.Equal(t, "abc", b.Title)
+	assert.Equal(t, "rtl", b.Direction)
+	assert.Equal(t, 3, b.Pages)
+	require.Len(t, b.Volumes, 2)
+	assert.Equal(t, "0002", b.Volumes[1].Name)
+	assert.Equal(t, "/files/gj.tianyige.com.cn_abc/vol.0002/0001.jpg", b.Volumes[1].Pages[0].Src)
+	assert.Equal(t, "卷二", b.Toc[0].Title)
+
+	b = books[1]
+	assert.Equal(t, "localhost:8080", b.Host)
+	assert.Equal(t, "book_1", b.BookId)
+	assert.Equal(t, "Book One", b.Title)
+	assert.Equal(t, "ltr", b.Direction)
+	assert.Equal(t, "f1r", b.Volumes[0].Pages[0].Label)
+
+	// 直接打开单本图书目录
+	books, err = viewer.Scan(filepath.Join(root, "localhost_8080_book_1"))
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Equal(t, "/files/0001.jpg", books[0].Volumes[0].Pages[0].Src)
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(viewer.Handler(library(t)))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		bs, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(bs)
+	}
+
+	code, body := get("/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "localhost:8080")
+	assert.Contains(t, body, `href="/book?key=localhost_8080_book_1"`)
+
+	code, body = get("/api/book?key=gj.tianyige.com.cn_abc")
+	assert.Equal(t, http.StatusOK, code)
+	var b viewer.Book
+	require.NoError(t, json.Unmarshal([]byte(body), &b))
+	assert.Len(t, b.Volumes, 2)
+
+	code, body = get(b.Volumes[0].Pages[1].Src)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "x", body)
+
+	code, _ = get("/api/book?key=../../etc")
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -4,10 +4,15 @@ package router
 import (
 	"bookget/app"
 	"bookget/config"
+	"bookget/pkg/events"
+	"bookget/pkg/i18n"
 	"bookget/pkg/util"
-	"errors"
+	"bookget/pkg/verify"
+	"fmt"
+	"log"
 	"strings"
 	"sync"
+	"time"
 )
 
 type RouterInit interface {
@@ -24,13 +29,19 @@ func FactoryRouter(siteID string, sUrl string) (map[string]interface{}, error) {
 	// 自动检测逻辑
 	if config.Conf.AutoDetect == 1 {
 		siteID = "bookget"
-	} else if config.Conf.AutoDetect == 2 || strings.Contains(sUrl, ".json") {
+	} else if strings.Contains(sUrl, ".json") {
 		siteID = "iiif.io"
+	} else if config.Conf.AutoDetect == 2 {
+		//网页：先找 IIIF manifest，再找 OpenSeadragon、Zoomify、pdf.js 查看器
+		siteID = "sniffer"
 	}
 
 	if strings.Contains(sUrl, "tiles/infos.json") {
 		siteID = "dzicnlib"
 	}
+	if strings.Contains(sUrl, "ImageProperties.xml") || strings.Contains(sUrl, "FIF=") {
+		siteID = "tiles"
+	}
 
 	// 初始化路由器(线程安全)
 	doInit.Do(func() {
@@ -150,6 +161,7 @@ func FactoryRouter(siteID string, sUrl string) (map[string]interface{}, error) {
 		Router["iiif.lib.harvard.edu"] = app.NewHarvard()
 		Router["listview.lib.harvard.edu"] = app.NewHarvard()
 		Router["curiosity.lib.harvard.edu"] = app.NewHarvard()
+		Router["nrs.harvard.edu"] = app.NewHarvard()
 
 		//[美国]hathitrust 数字图书馆
 		Router["babel.hathitrust.org"] = app.NewHathitrust()
@@ -160,6 +172,7 @@ func FactoryRouter(siteID string, sUrl string) (map[string]interface{}, error) {
 
 		//[美国]国会图书馆
 		Router["www.loc.gov"] = app.NewLoc()
+		Router["chroniclingamerica.loc.gov"] = app.NewLoc()
 
 		//[美国]斯坦福大学图书馆
 		Router["searchworks.stanford.edu"] = app.NewStanford()
@@ -223,6 +236,8 @@ func FactoryRouter(siteID string, sUrl string) (map[string]interface{}, error) {
 		Router["bookget"] = app.NewImageDownloader()
 		Router["dzicnlib"] = app.NewDziCnLib()
 		Router["iiif.io"] = app.NewIiifRouter()
+		Router["sniffer"] = app.NewSniffer()
+		Router["tiles"] = app.NewTiles()
 	})
 
 	// 检查路由器是否存在
@@ -232,13 +247,28 @@ func FactoryRouter(siteID string, sUrl string) (map[string]interface{}, error) {
 			siteID = "iiif.io"
 		} else if urlType == "bookget" {
 			siteID = "bookget"
+		} else if urlType == "html" && config.Conf.AutoDetect > 0 {
+			siteID = "sniffer"
 		}
 
 		if _, ok := Router[siteID]; !ok {
-			return nil, errors.New("unsupported URL: " + sUrl)
+			err := i18n.Errorf("router.unsupported", sUrl)
+			events.Emit(events.Event{Type: events.JobFinished, Url: sUrl, Site: siteID, Status: "failed", Class: "unsupported", Error: err.Error()})
+			return nil, err
 		}
 	}
 
-	return Router[siteID].GetRouterInit(sUrl)
-
+	start := time.Now()
+	handler := strings.TrimPrefix(fmt.Sprintf("%T", Router[siteID]), "*app.")
+	events.Emit(events.Event{Type: events.JobStarted, Url: sUrl, Site: siteID, Handler: handler})
+	result, err := Router[siteID].GetRouterInit(sUrl)
+	if e := verify.Flush(); e != nil {
+		log.Printf("pages.json: %v\n", e)
+	}
+	e := events.Event{Type: events.JobFinished, Url: sUrl, Site: siteID, Handler: handler, Ms: time.Since(start).Milliseconds(), Status: "ok"}
+	if err != nil {
+		e.Status, e.Class, e.Error = "failed", events.Classify(err), err.Error()
+	}
+	events.Emit(e)
+	return result, err
 }